@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gameserver/games/dicegame"
+	"gameserver/games/owe_drahn"
+	"gameserver/games/tictactoe"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/protocol"
+)
+
+// action is a message a command line is sent as
+type action struct {
+	msgType string
+	payload interface{}
+}
+
+// gameCommand turns the arguments of a command into actions, state is the latest game state
+// of the room and nil before the first one arrived
+type gameCommand struct {
+	usage string
+	parse func(args []string, state *protocol.Response) ([]action, error)
+}
+
+// gameCommands are the verbs of the games hubctl knows by game type
+var gameCommands = map[string]map[string]gameCommand{
+	"dicegame": {
+		"roll":    {usage: "roll", parse: fixed("roll", nil)},
+		"select":  {usage: "select <index>... (toggles dice by their index)", parse: parseDiceSelect},
+		"aside":   {usage: "aside [end] (sets the selected dice aside, end banks the turn)", parse: parseDiceSetAside},
+		"ready":   {usage: "ready", parse: fixed("ready", dicegame.ReadyActionPayload{Ready: true})},
+		"unready": {usage: "unready", parse: fixed("ready", dicegame.ReadyActionPayload{Ready: false})},
+		"start":   {usage: "start (the host starts once everyone is ready)", parse: fixed("start", nil)},
+		"rematch": {usage: "rematch (votes for a rematch once the game is over, again takes the vote back)", parse: fixed("rematch", nil)},
+	},
+	"tictactoe": {
+		"move":    {usage: "move <row> <col>", parse: parseTicTacToeMove},
+		"restart": {usage: "restart", parse: fixed("restart_game", nil)},
+		"rematch": {usage: "rematch (votes for a rematch once the game is over, again takes the vote back)", parse: fixed("rematch", nil)},
+	},
+	"owedrahn": {
+		"ready":   {usage: "ready", parse: fixed("ready", true)},
+		"unready": {usage: "unready", parse: fixed("ready", false)},
+		"roll":    {usage: "roll", parse: fixed("roll", nil)},
+		"life":    {usage: "life (loses a life instead of rolling)", parse: fixed("loseLife", nil)},
+		"next":    {usage: "next <player> (chooses who plays next by name or id)", parse: parseOweDrahnNext},
+	},
+}
+
+// fixed is a command without arguments that always sends the same action
+func fixed(msgType string, payload interface{}) func([]string, *protocol.Response) ([]action, error) {
+	return func(args []string, _ *protocol.Response) ([]action, error) {
+		if len(args) > 0 {
+			return nil, errTooManyArguments
+		}
+		return []action{{msgType: msgType, payload: payload}}, nil
+	}
+}
+
+func parseDiceSelect(args []string, _ *protocol.Response) ([]action, error) {
+	if len(args) == 0 {
+		return nil, errors.New("select needs at least one dice index")
+	}
+
+	actions := make([]action, 0, len(args))
+	for _, arg := range args {
+		index, err := strconv.Atoi(arg)
+		if err != nil || index < 0 {
+			return nil, fmt.Errorf("invalid dice index %q", arg)
+		}
+		actions = append(actions, action{msgType: "select", payload: dicegame.SelectActionPayload{DiceIndex: index}})
+	}
+	return actions, nil
+}
+
+func parseDiceSetAside(args []string, _ *protocol.Response) ([]action, error) {
+	payload := dicegame.SetAsideActionPayload{}
+	switch {
+	case len(args) == 0:
+	case len(args) == 1 && args[0] == "end":
+		payload.EndTurn = true
+	default:
+		return nil, errors.New("usage: aside [end]")
+	}
+	return []action{{msgType: "set_aside", payload: payload}}, nil
+}
+
+func parseTicTacToeMove(args []string, _ *protocol.Response) ([]action, error) {
+	if len(args) != 2 {
+		return nil, errors.New("usage: move <row> <col>")
+	}
+
+	row, err := strconv.Atoi(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid row %q", args[0])
+	}
+	col, err := strconv.Atoi(args[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid col %q", args[1])
+	}
+	return []action{{msgType: "make_move", payload: tictactoe.MovePayload{Row: row, Col: col}}}, nil
+}
+
+func parseOweDrahnNext(args []string, state *protocol.Response) ([]action, error) {
+	if len(args) != 1 {
+		return nil, errors.New("usage: next <player>")
+	}
+
+	id := args[0]
+	if state != nil {
+		var game owe_drahn.GameStateDTO
+		if err := decodeData(state, &game); err == nil {
+			for _, player := range game.Players {
+				if strings.EqualFold(player.Name, args[0]) {
+					id = player.ID
+					break
+				}
+			}
+		}
+	}
+	return []action{{msgType: "chooseNextPlayer", payload: owe_drahn.NextPlayerPayload{NextPlayerId: id}}}, nil
+}
+
+// parseSend reads "send <type> [json]" arguments, the data is sent as it was typed
+func parseSend(args []string) (action, error) {
+	if len(args) == 0 {
+		return action{}, errMessageTypeRequired
+	}
+	if len(args) == 1 {
+		return action{msgType: args[0]}, nil
+	}
+
+	data := json.RawMessage(strings.Join(args[1:], " "))
+	if !json.Valid(data) {
+		return action{}, fmt.Errorf("invalid json %s", data)
+	}
+	return action{msgType: args[0], payload: data}, nil
+}
+
+// parseBot reads "bot [strategy] [difficulty]" arguments
+func parseBot(args []string) (action, error) {
+	if len(args) > 2 {
+		return action{}, errors.New("usage: bot [strategy] [difficulty]")
+	}
+
+	var options interfaces.BotOptions
+	if len(args) > 0 {
+		options.Strategy = args[0]
+	}
+	if len(args) > 1 {
+		options.Difficulty = interfaces.Difficulty(args[1])
+	}
+	return action{msgType: "add_bot", payload: options}, nil
+}
+
+var errTooManyArguments = errors.New("command takes no arguments")
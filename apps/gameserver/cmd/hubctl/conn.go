@@ -0,0 +1,224 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gameserver/internal/protocol"
+
+	"github.com/gorilla/websocket"
+)
+
+const closeTimeout = 2 * time.Second
+
+// received is a response together with the JSON it was decoded from
+type received struct {
+	*protocol.Response
+	raw json.RawMessage
+}
+
+// conn is a websocket connection to the server. The server batches its responses into JSON
+// arrays, conn unpacks them and delivers one response at a time.
+type conn struct {
+	ws        *websocket.Conn
+	writeMu   sync.Mutex
+	responses chan received
+	done      chan struct{} // closed once the server hung up
+	err       error         // why the read loop stopped, set before responses is closed
+}
+
+func dial(server string) (*conn, error) {
+	ws, _, err := websocket.DefaultDialer.Dial(server, nil)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", server, err)
+	}
+
+	c := &conn{
+		ws:        ws,
+		responses: make(chan received, 64),
+		done:      make(chan struct{}),
+	}
+	go c.readLoop()
+	return c, nil
+}
+
+func (c *conn) readLoop() {
+	defer close(c.done)
+	defer close(c.responses)
+
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err != nil {
+			c.err = err
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				// the server answers the close right away and hangs up once it stored the session
+				io.Copy(io.Discard, c.ws.UnderlyingConn())
+			}
+			return
+		}
+
+		batch, err := splitBatch(data)
+		if err != nil {
+			c.err = err
+			return
+		}
+		for _, item := range batch {
+			var response protocol.Response
+			if err := json.Unmarshal(item, &response); err != nil {
+				c.err = fmt.Errorf("invalid response %s: %w", item, err)
+				return
+			}
+			c.responses <- received{Response: &response, raw: item}
+		}
+	}
+}
+
+// send sends a message of the given type, the payload is encoded as its data
+func (c *conn) send(msgType string, payload interface{}) error {
+	message := protocol.Message{Type: msgType}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		message.Data = data
+	}
+
+	encoded, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return c.write(encoded)
+}
+
+// sendRaw sends a message typed in by hand after checking that it is a protocol message
+func (c *conn) sendRaw(line string) error {
+	var message protocol.Message
+	if err := json.Unmarshal([]byte(line), &message); err != nil {
+		return fmt.Errorf("not a message: %w", err)
+	}
+	if message.Type == "" {
+		return errMessageTypeRequired
+	}
+	return c.write([]byte(line))
+}
+
+func (c *conn) write(data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	return c.ws.WriteMessage(websocket.TextMessage, data)
+}
+
+// await drops responses until one of the given type arrives
+func (c *conn) await(responseType string) (*protocol.Response, error) {
+	for response := range c.responses {
+		if response.Type == responseType {
+			return response.Response, nil
+		}
+	}
+	return nil, c.closedErr()
+}
+
+func (c *conn) closedErr() error {
+	if c.err != nil {
+		return c.err
+	}
+	return errConnectionClosed
+}
+
+// close closes the connection and waits until the server hung up, by then the seat can be
+// reconnected to
+func (c *conn) close() error {
+	c.writeMu.Lock()
+	c.ws.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	c.writeMu.Unlock()
+
+	c.ws.SetReadDeadline(time.Now().Add(closeTimeout))
+	for range c.responses {
+		// nobody might be reading anymore, the read loop must not block
+	}
+	<-c.done
+	return c.ws.Close()
+}
+
+// splitBatch splits a websocket message into its responses, single responses are accepted too
+func splitBatch(data []byte) ([]json.RawMessage, error) {
+	trimmed := strings.TrimSpace(string(data))
+	if !strings.HasPrefix(trimmed, "[") {
+		return []json.RawMessage{json.RawMessage(trimmed)}, nil
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal([]byte(trimmed), &batch); err != nil {
+		return nil, fmt.Errorf("invalid batch: %w", err)
+	}
+	return batch, nil
+}
+
+// decodeData decodes the data of a response into target
+func decodeData(response *protocol.Response, target interface{}) error {
+	data, err := json.Marshal(response.Data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// fetchGames asks the server which game types are registered
+func fetchGames(server string) ([]string, error) {
+	endpoint, err := httpURL(server, "/games")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.Get(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("listing games: %s", resp.Status)
+	}
+
+	var body struct {
+		Games []string `json:"games"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, err
+	}
+	return body.Games, nil
+}
+
+// httpURL turns the websocket url of the server into the url of one of its http endpoints
+func httpURL(server string, path string) (string, error) {
+	u, err := url.Parse(server)
+	if err != nil {
+		return "", err
+	}
+
+	switch u.Scheme {
+	case "ws":
+		u.Scheme = "http"
+	case "wss":
+		u.Scheme = "https"
+	case "http", "https":
+	default:
+		return "", fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+	u.Path = path
+	u.RawQuery = ""
+	return u.String(), nil
+}
+
+var (
+	errConnectionClosed    = errors.New("connection closed")
+	errMessageTypeRequired = errors.New("message type is required")
+)
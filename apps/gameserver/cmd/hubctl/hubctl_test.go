@@ -0,0 +1,386 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gameserver/games/dicegame"
+	"gameserver/games/owe_drahn"
+	"gameserver/games/tictactoe"
+	"gameserver/internal/protocol"
+	"gameserver/internal/testicles"
+)
+
+// lockedBuffer collects the output of a session, which is written from its connection goroutine
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// waitFor waits until the output contains text
+func (b *lockedBuffer) waitFor(t *testing.T, text string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if strings.Contains(b.String(), text) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("output never contained %q:\n%s", text, b.String())
+}
+
+// payloadJSON encodes the payloads of actions the way they are sent
+func payloadJSON(t *testing.T, actions []action) []string {
+	t.Helper()
+	encoded := make([]string, len(actions))
+	for i, a := range actions {
+		data, err := json.Marshal(a.payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded[i] = a.msgType + " " + string(data)
+	}
+	return encoded
+}
+
+func TestCommands(t *testing.T) {
+	t.Run("game commands", func(t *testing.T) {
+		state := protocol.NewSuccessResponse("game_state", &owe_drahn.GameStateDTO{
+			Players: []*owe_drahn.Player{{ID: "seat-1", Name: "Alice"}, {ID: "seat-2", Name: "Bob"}},
+		})
+
+		tests := []struct {
+			game string
+			line string
+			want []string
+		}{
+			{"dicegame", "roll", []string{"roll null"}},
+			{"dicegame", "select 0 3", []string{`select {"diceIndex":0}`, `select {"diceIndex":3}`}},
+			{"dicegame", "aside end", []string{`set_aside {"endTurn":true}`}},
+			{"dicegame", "ready", []string{`ready {"ready":true}`}},
+			{"dicegame", "start", []string{"start null"}},
+			{"dicegame", "rematch", []string{"rematch null"}},
+			{"tictactoe", "move 1 2", []string{`make_move {"row":1,"col":2}`}},
+			{"tictactoe", "restart", []string{"restart_game null"}},
+			{"tictactoe", "rematch", []string{"rematch null"}},
+			{"owedrahn", "ready", []string{"ready true"}},
+			{"owedrahn", "life", []string{"loseLife null"}},
+			{"owedrahn", "next bob", []string{`chooseNextPlayer {"nextPlayerId":"seat-2"}`}},
+			{"owedrahn", "next seat-1", []string{`chooseNextPlayer {"nextPlayerId":"seat-1"}`}},
+		}
+
+		for _, tt := range tests {
+			fields := strings.Fields(tt.line)
+			command, ok := gameCommands[tt.game][fields[0]]
+			if !ok {
+				t.Fatalf("%s has no command %s", tt.game, fields[0])
+			}
+			actions, err := command.parse(fields[1:], state)
+			if err != nil {
+				t.Fatalf("%s %q: %v", tt.game, tt.line, err)
+			}
+			got := payloadJSON(t, actions)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("%s %q sent %v, want %v", tt.game, tt.line, got, tt.want)
+			}
+		}
+	})
+
+	t.Run("invalid arguments", func(t *testing.T) {
+		tests := []struct {
+			game string
+			line string
+		}{
+			{"dicegame", "select"},
+			{"dicegame", "select x"},
+			{"dicegame", "aside now"},
+			{"dicegame", "roll twice"},
+			{"dicegame", "start now"},
+			{"tictactoe", "move 1"},
+			{"tictactoe", "move a b"},
+			{"owedrahn", "next"},
+		}
+
+		for _, tt := range tests {
+			fields := strings.Fields(tt.line)
+			if _, err := gameCommands[tt.game][fields[0]].parse(fields[1:], nil); err == nil {
+				t.Errorf("%s %q should fail", tt.game, tt.line)
+			}
+		}
+	})
+
+	t.Run("send", func(t *testing.T) {
+		a, err := parseSend([]string{"set_main_bet", `{"bet":`, `5}`})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := payloadJSON(t, []action{a})[0]; got != `set_main_bet {"bet":5}` {
+			t.Errorf("sent %s", got)
+		}
+
+		if _, err := parseSend([]string{"roll", "{"}); err == nil {
+			t.Error("invalid json should fail")
+		}
+		if _, err := parseSend(nil); err != errMessageTypeRequired {
+			t.Errorf("expected errMessageTypeRequired, got %v", err)
+		}
+	})
+
+	t.Run("bot", func(t *testing.T) {
+		a, err := parseBot([]string{"cautious", "hard"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := payloadJSON(t, []action{a})[0]; got != `add_bot {"strategy":"cautious","difficulty":"hard"}` {
+			t.Errorf("sent %s", got)
+		}
+	})
+}
+
+func TestRender(t *testing.T) {
+	t.Run("dicegame", func(t *testing.T) {
+		response := protocol.NewSuccessResponse("game_state", &dicegame.GameState{
+			Players: map[string]*dicegame.Player{
+				"a": {ID: "a", Name: "Alice", Score: 1200, TurnScore: 300},
+				"b": {ID: "b", Name: "Bob", BotControlled: true},
+			},
+			Started:      true,
+			CurrentTurn:  "a",
+			Dice:         []int{5, 1, 3},
+			SelectedDice: []int{1},
+			TargetScore:  4000,
+			Scoring: &dicegame.Scoring{Score: 150, Combinations: []dicegame.Combination{
+				{Name: dicegame.CombinationSingle, Face: 1, Dice: []int{1}, Points: 100},
+				{Name: dicegame.CombinationSingle, Face: 5, Dice: []int{0}, Points: 50},
+			}},
+			SelectedScoring: &dicegame.Scoring{Score: 100, Valid: true},
+		})
+
+		text := render("dicegame", response, "a")
+		for _, want := range []string{"first to 4000", "> Alice (you)", "1200", "[bot]", "[0]5 [1]1 [2]3", "single [1] 100, single [0] 50", "selected:  1 (100)", "set aside: -"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("missing %q in\n%s", want, text)
+			}
+		}
+	})
+
+	t.Run("dicegame lobby", func(t *testing.T) {
+		response := protocol.NewSuccessResponse("game_state", &dicegame.GameState{
+			Players: map[string]*dicegame.Player{
+				"a": {ID: "a", Name: "Alice", Ready: true},
+				"b": {ID: "b", Name: "Bob"},
+			},
+			PlayerOrder: []string{"b", "a"},
+			Host:        "b",
+			TargetScore: 3000,
+		})
+
+		text := render("dicegame", response, "a")
+		for _, want := range []string{"0 (host)", "0 (ready)", "waiting until everyone is ready"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("missing %q in\n%s", want, text)
+			}
+		}
+		if strings.Index(text, "Bob") > strings.Index(text, "Alice") {
+			t.Errorf("expected the players in turn order:\n%s", text)
+		}
+	})
+
+	t.Run("tictactoe", func(t *testing.T) {
+		state := &tictactoe.GameState{
+			Players: map[string]tictactoe.PlayerInfo{
+				"a": {Symbol: "X", Name: "Alice"},
+				"b": {Symbol: "O", Name: "Bob"},
+			},
+			CurrentTurn: "b",
+			Winner:      "a",
+			GameOver:    true,
+		}
+		state.Board[0] = [3]string{"X", "X", "X"}
+		state.Board[1][1] = "O"
+
+		text := render("tictactoe", protocol.NewSuccessResponse("game_state", state), "b")
+		for _, want := range []string{"0   X | X | X", "1     | O |  ", "  O Bob (you)", "winner: Alice"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("missing %q in\n%s", want, text)
+			}
+		}
+		if strings.Contains(text, ">") {
+			t.Errorf("a finished game has no turn:\n%s", text)
+		}
+	})
+
+	t.Run("owe drahn", func(t *testing.T) {
+		response := protocol.NewSuccessResponse("gameInit", &owe_drahn.GameStateDTO{
+			Players: []*owe_drahn.Player{
+				{ID: "a", Name: "Alice", Life: 3, IsConnected: true, IsChoosing: true},
+				{ID: "b", Name: "Bob", Life: 0},
+			},
+			Started:      true,
+			CurrentTurn:  "a",
+			CurrentValue: 11,
+		})
+
+		text := render("owedrahn", response, "b")
+		for _, want := range []string{"value 11/15", "> Alice", "♥♥♥ (choosing)", "Bob (you)", "dead (offline)"} {
+			if !strings.Contains(text, want) {
+				t.Errorf("missing %q in\n%s", want, text)
+			}
+		}
+	})
+
+	t.Run("other responses", func(t *testing.T) {
+		if got := render("dicegame", protocol.NewErrorResponse("error", "not your turn"), ""); got != "! error: not your turn" {
+			t.Errorf("got %q", got)
+		}
+		if got := render("tellit", protocol.NewSuccessResponse("game_state", map[string]int{"round": 2}), ""); got != `< game_state {"round":2}` {
+			t.Errorf("got %q", got)
+		}
+	})
+}
+
+func TestConnHelpers(t *testing.T) {
+	batch, err := splitBatch([]byte(`[{"type":"a","success":true},{"type":"b","success":true}]`))
+	if err != nil || len(batch) != 2 {
+		t.Fatalf("split %v, %v", batch, err)
+	}
+	single, err := splitBatch([]byte(` {"type":"a","success":true}`))
+	if err != nil || len(single) != 1 {
+		t.Fatalf("split %v, %v", single, err)
+	}
+
+	tests := map[string]string{
+		"ws://localhost:6969/ws":       "http://localhost:6969/games",
+		"wss://games.example.com/ws?x": "https://games.example.com/games",
+	}
+	for server, want := range tests {
+		if got, err := httpURL(server, "/games"); err != nil || got != want {
+			t.Errorf("httpURL(%s) = %s, %v, want %s", server, got, err, want)
+		}
+	}
+	if _, err := httpURL("ftp://localhost", "/games"); err == nil {
+		t.Error("ftp should not be supported")
+	}
+}
+
+// newTestServer serves the game server endpoints with tictactoe registered
+func newTestServer(t *testing.T) string {
+	t.Helper()
+
+	helper := testicles.NewE2EHelper(t)
+	tictactoe.RegisterTicTacToeGame(helper.Registry)
+	return helper.URL("/ws")
+}
+
+func TestPlayTicTacToe(t *testing.T) {
+	server := newTestServer(t)
+
+	var aliceOut, bobOut lockedBuffer
+	alice := newSession(server, "tictactoe", "Alice", nil, &aliceOut)
+	if err := alice.connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer alice.close()
+	if err := alice.join(""); err != nil {
+		t.Fatal(err)
+	}
+	aliceOut.waitFor(t, "< join_room_result")
+
+	alice.mu.Lock()
+	roomID := alice.roomID
+	alice.mu.Unlock()
+	if roomID == "" {
+		t.Fatal("alice has no room")
+	}
+
+	bob := newSession(server, "tictactoe", "Bob", nil, &bobOut)
+	if err := bob.connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer bob.close()
+	if _, err := bob.execute("join " + roomID); err != nil {
+		t.Fatal(err)
+	}
+	bobOut.waitFor(t, "== tictactoe ==")
+	bobOut.waitFor(t, "< join_room_result")
+	if _, err := bob.execute("state"); err != nil {
+		t.Fatal(err)
+	}
+	bobOut.waitFor(t, "(you)")
+
+	// whoever's turn it is plays the center
+	players := map[string]*session{alice.seat(): alice, bob.seat(): bob}
+	var state tictactoe.GameState
+	bob.mu.Lock()
+	err := decodeData(bob.state, &state)
+	bob.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := players[state.CurrentTurn].execute("move 1 1"); err != nil {
+		t.Fatal(err)
+	}
+	mark := state.Players[state.CurrentTurn].Symbol
+	aliceOut.waitFor(t, "1     | "+mark+" |  ")
+
+	// a new connection takes the same seat
+	seat := bob.seat()
+	if _, err := bob.execute("reconnect"); err != nil {
+		t.Fatal(err)
+	}
+	bobOut.waitFor(t, "< reconnect_result")
+	if bob.seat() != seat {
+		t.Errorf("reconnected to seat %s, want %s", bob.seat(), seat)
+	}
+
+	if _, err := bob.execute("dance"); err == nil {
+		t.Error("unknown commands should fail")
+	}
+	if quit, _ := bob.execute("quit"); !quit {
+		t.Error("quit should end the session")
+	}
+}
+
+func TestRaw(t *testing.T) {
+	server := newTestServer(t)
+
+	c, err := dial(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out lockedBuffer
+	in, input := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- runRaw(c, in, &out)
+	}()
+
+	io.WriteString(input, `{"type":"get_room_list","data":{"gameType":"tictactoe"}}`+"\nnot json\n")
+	out.waitFor(t, "! not a message")
+	out.waitFor(t, `"type":"room_list_update"`)
+
+	input.Close()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	out.waitFor(t, "! connection closed")
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"gameserver/internal/interfaces"
+	"gameserver/internal/router"
+)
+
+const responseTimeout = 10 * time.Second
+
+// hubctl is a terminal client for the game server. It plays dicegame, tictactoe and owe drahn
+// rooms as text, and speaks raw protocol messages for everything else.
+//
+//	go run ./cmd/hubctl [-server ws://localhost:6969/ws] games
+//	go run ./cmd/hubctl rooms <gameType>
+//	go run ./cmd/hubctl [-name hubctl] [-options '{"botTakeover":true}'] play <gameType> [roomId]
+//	go run ./cmd/hubctl raw
+func main() {
+	server := flag.String("server", "ws://localhost:6969/ws", "websocket endpoint of the server")
+	name := flag.String("name", "hubctl", "player name used when joining rooms")
+	options := flag.String("options", "", "room options as json, used when play creates a room")
+	flag.Usage = usage
+	flag.Parse()
+
+	var err error
+	switch flag.Arg(0) {
+	case "games":
+		err = listGames(*server)
+	case "rooms":
+		if flag.NArg() != 2 {
+			usage()
+			os.Exit(2)
+		}
+		err = listRooms(*server, flag.Arg(1))
+	case "play":
+		if flag.NArg() < 2 || flag.NArg() > 3 {
+			usage()
+			os.Exit(2)
+		}
+		err = play(*server, flag.Arg(1), flag.Arg(2), *name, *options)
+	case "raw":
+		err = raw(*server)
+	default:
+		usage()
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "hubctl:", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: hubctl [flags] games | rooms <gameType> | play <gameType> [roomId] | raw")
+	flag.PrintDefaults()
+}
+
+func listGames(server string) error {
+	games, err := fetchGames(server)
+	if err != nil {
+		return err
+	}
+	for _, game := range games {
+		fmt.Println(game)
+	}
+	return nil
+}
+
+func listRooms(server string, gameType string) error {
+	c, err := dial(server)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+
+	if err := c.send("get_room_list", interfaces.M{"gameType": gameType}); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	var rooms []router.RoomListInfo
+	go func() {
+		response, err := c.await("room_list_update")
+		if err == nil {
+			err = decodeData(response, &rooms)
+		}
+		done <- err
+	}()
+
+	select {
+	case err = <-done:
+	case <-time.After(responseTimeout):
+		return errTimeout
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(rooms) == 0 {
+		fmt.Printf("no %s rooms\n", gameType)
+		return nil
+	}
+	for _, room := range rooms {
+		status := "waiting"
+		if room.GameStarted {
+			status = "started"
+		}
+		fmt.Printf("%-24s %d players  %s\n", room.RoomId, room.PlayerCount, status)
+	}
+	return nil
+}
+
+func play(server string, gameType string, roomID string, name string, options string) error {
+	var roomOptions json.RawMessage
+	if options != "" {
+		roomOptions = json.RawMessage(options)
+		if !json.Valid(roomOptions) {
+			return fmt.Errorf("invalid room options %s", options)
+		}
+	}
+
+	s := newSession(server, gameType, name, roomOptions, os.Stdout)
+	if err := s.connect(); err != nil {
+		return err
+	}
+	defer s.close()
+
+	if err := s.join(roomID); err != nil {
+		return err
+	}
+
+	s.printf("type help for commands\n")
+	return s.run(os.Stdin)
+}
+
+func raw(server string) error {
+	c, err := dial(server)
+	if err != nil {
+		return err
+	}
+	return runRaw(c, os.Stdin, os.Stdout)
+}
+
+var errTimeout = errors.New("no response from server")
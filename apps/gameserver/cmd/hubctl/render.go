@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gameserver/games/dicegame"
+	"gameserver/games/owe_drahn"
+	"gameserver/games/tictactoe"
+	"gameserver/internal/protocol"
+)
+
+// renderer draws the state a game sends as text, self is the seat id of the player
+type renderer func(response *protocol.Response, self string) (string, error)
+
+// stateRenderers by game type and the response types that carry their state
+var stateRenderers = map[string]struct {
+	types  []string
+	render renderer
+}{
+	"dicegame":  {types: []string{"game_state"}, render: renderDicegame},
+	"tictactoe": {types: []string{"game_state"}, render: renderTicTacToe},
+	"owedrahn":  {types: []string{"game_state", "gameInit"}, render: renderOweDrahn},
+}
+
+// render formats a response for the terminal. Game states of known games are drawn,
+// everything else is shown as its type and data.
+func render(gameType string, response *protocol.Response, self string) string {
+	if !response.Success {
+		return fmt.Sprintf("! %s: %s", response.Type, response.Error)
+	}
+
+	if game, ok := stateRenderers[gameType]; ok && isStateType(game.types, response.Type) {
+		text, err := game.render(response, self)
+		if err == nil {
+			return text
+		}
+		return fmt.Sprintf("! could not render %s: %v", response.Type, err)
+	}
+
+	if response.Data == nil {
+		return "< " + response.Type
+	}
+	data, _ := json.Marshal(response.Data)
+	return fmt.Sprintf("< %s %s", response.Type, data)
+}
+
+func isStateType(types []string, responseType string) bool {
+	for _, t := range types {
+		if t == responseType {
+			return true
+		}
+	}
+	return false
+}
+
+func renderDicegame(response *protocol.Response, self string) (string, error) {
+	var state dicegame.GameState
+	if err := decodeData(response, &state); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "== dicegame, first to %d ==\n", state.TargetScore)
+
+	// players in turn order, states without one by id
+	ids := state.PlayerOrder
+	if len(ids) == 0 {
+		for id := range state.Players {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+	}
+	for _, id := range ids {
+		player, exists := state.Players[id]
+		if !exists {
+			continue
+		}
+		var flags []string
+		if id == state.Host && !state.Started {
+			flags = append(flags, "host")
+		}
+		if player.Ready && !state.Started {
+			flags = append(flags, "ready")
+		}
+		status := ""
+		if len(flags) > 0 {
+			status = " (" + strings.Join(flags, ", ") + ")"
+		}
+		fmt.Fprintf(&b, "%s %-16s score %5d  turn %5d  round %5d%s%s\n",
+			turnMarker(id == state.CurrentTurn), playerLabel(player.Name, id, self), player.Score, player.TurnScore, player.RoundScore, status, botLabel(player.BotControlled))
+	}
+
+	switch {
+	case state.Winner != "":
+		fmt.Fprintf(&b, "winner: %s\n", state.Winner)
+	case !state.Started:
+		b.WriteString("waiting until everyone is ready and the host starts\n")
+	default:
+		fmt.Fprintf(&b, "dice:      %s\n", indexedDice(state.Dice))
+		if state.Scoring != nil {
+			fmt.Fprintf(&b, "scores:    %s\n", combinations(state.Scoring))
+		}
+		selected := joinInts(state.SelectedDice)
+		if state.SelectedScoring != nil {
+			selected += fmt.Sprintf(" (%d)", state.SelectedScoring.Score)
+		}
+		fmt.Fprintf(&b, "selected:  %s\n", selected)
+		fmt.Fprintf(&b, "set aside: %s\n", joinInts(state.SetAside))
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+func renderTicTacToe(response *protocol.Response, self string) (string, error) {
+	var state tictactoe.GameState
+	if err := decodeData(response, &state); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("== tictactoe ==\n")
+	b.WriteString("    0   1   2\n")
+	for row, cells := range state.Board {
+		marks := make([]string, len(cells))
+		for col, cell := range cells {
+			marks[col] = cell
+			if cell == "" {
+				marks[col] = " "
+			}
+		}
+		fmt.Fprintf(&b, "%d   %s\n", row, strings.Join(marks, " | "))
+		if row < len(state.Board)-1 {
+			b.WriteString("   ---+---+---\n")
+		}
+	}
+
+	ids := make([]string, 0, len(state.Players))
+	for id := range state.Players {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return state.Players[ids[i]].Symbol < state.Players[ids[j]].Symbol })
+	for _, id := range ids {
+		player := state.Players[id]
+		fmt.Fprintf(&b, "%s %s %s\n", turnMarker(id == state.CurrentTurn && !state.GameOver), player.Symbol, playerLabel(player.Name, id, self))
+	}
+
+	switch {
+	case state.DrawGame:
+		b.WriteString("draw\n")
+	case state.GameOver:
+		fmt.Fprintf(&b, "winner: %s\n", playerLabel(state.Players[state.Winner].Name, state.Winner, self))
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+func renderOweDrahn(response *protocol.Response, self string) (string, error) {
+	var state owe_drahn.GameStateDTO
+	if err := decodeData(response, &state); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "== owe drahn, value %d/15 ==\n", state.CurrentValue)
+	for _, player := range state.Players {
+		var flags []string
+		if !state.Started && player.IsReady {
+			flags = append(flags, "ready")
+		}
+		if player.IsChoosing {
+			flags = append(flags, "choosing")
+		}
+		if !player.IsConnected {
+			flags = append(flags, "offline")
+		}
+		status := ""
+		if len(flags) > 0 {
+			status = " (" + strings.Join(flags, ", ") + ")"
+		}
+		fmt.Fprintf(&b, "%s %-16s life %s%s%s\n",
+			turnMarker(player.ID == state.CurrentTurn), playerLabel(player.Name, player.ID, self), lives(player.Life), status, botLabel(player.BotControlled))
+	}
+
+	switch {
+	case state.Over:
+		b.WriteString("game over\n")
+	case !state.Started:
+		b.WriteString("waiting until everyone is ready\n")
+	}
+	return strings.TrimRight(b.String(), "\n"), nil
+}
+
+func turnMarker(current bool) string {
+	if current {
+		return ">"
+	}
+	return " "
+}
+
+func playerLabel(name string, id string, self string) string {
+	if name == "" {
+		name = id
+	}
+	if id == self {
+		return name + " (you)"
+	}
+	return name
+}
+
+func botLabel(botControlled bool) string {
+	if botControlled {
+		return "  [bot]"
+	}
+	return ""
+}
+
+func lives(life int) string {
+	if life <= 0 {
+		return "dead"
+	}
+	return strings.Repeat("♥", life)
+}
+
+// indexedDice shows each die with the index select expects
+func indexedDice(dice []int) string {
+	parts := make([]string, len(dice))
+	for i, die := range dice {
+		parts[i] = fmt.Sprintf("[%d]%d", i, die)
+	}
+	return strings.Join(parts, " ")
+}
+
+// combinations lists the scoring combinations with the indexes of their dice
+func combinations(scoring *dicegame.Scoring) string {
+	if len(scoring.Combinations) == 0 {
+		return "nothing"
+	}
+	parts := make([]string, len(scoring.Combinations))
+	for i, combination := range scoring.Combinations {
+		parts[i] = fmt.Sprintf("%s [%s] %d", combination.Name, joinInts(combination.Dice), combination.Points)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func joinInts(values []int) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"gameserver/internal/interfaces"
+	"gameserver/internal/protocol"
+	"gameserver/internal/router"
+)
+
+// session is an interactive player in one game. It remembers its seat, so it can reconnect
+// to it on a new connection the way a browser tab does after a reload.
+type session struct {
+	server   string
+	gameType string
+	name     string
+	options  json.RawMessage // room options used when the session creates a room
+	out      io.Writer
+
+	mu     sync.Mutex
+	conn   *conn
+	seatID string
+	roomID string
+	state  *protocol.Response // the latest game state of the room
+}
+
+func newSession(server string, gameType string, name string, options json.RawMessage, out io.Writer) *session {
+	return &session{server: server, gameType: gameType, name: name, options: options, out: out}
+}
+
+// connect opens a new connection and shows everything the server sends on it
+func (s *session) connect() error {
+	c, err := dial(s.server)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	previous := s.conn
+	s.conn = c
+	s.mu.Unlock()
+
+	if previous != nil {
+		previous.close()
+	}
+	go s.show(c)
+	return nil
+}
+
+func (s *session) show(c *conn) {
+	for response := range c.responses {
+		s.observe(response.Response)
+		s.printf("%s\n", render(s.gameType, response.Response, s.seat()))
+	}
+
+	s.mu.Lock()
+	current := s.conn == c
+	s.mu.Unlock()
+	if current {
+		s.printf("! connection closed: %v\n", c.closedErr())
+	}
+}
+
+// observe keeps track of the seat, room and state of the session
+func (s *session) observe(response *protocol.Response) {
+	if !response.Success {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch response.Type {
+	case "join_room_result", "reconnect_result":
+		var joined router.JoinResponse
+		if err := decodeData(response, &joined); err == nil {
+			s.seatID = joined.ClientID
+			s.roomID = joined.RoomID
+		}
+	case "leave_room_result":
+		s.roomID = ""
+		s.state = nil
+	default:
+		if game, ok := stateRenderers[s.gameType]; ok && isStateType(game.types, response.Type) {
+			s.state = response
+		}
+	}
+}
+
+func (s *session) seat() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seatID
+}
+
+func (s *session) printf(format string, args ...interface{}) {
+	fmt.Fprintf(s.out, format, args...)
+}
+
+func (s *session) close() {
+	s.mu.Lock()
+	c := s.conn
+	s.conn = nil
+	s.mu.Unlock()
+	if c != nil {
+		c.close()
+	}
+}
+
+func (s *session) send(msgType string, payload interface{}) error {
+	s.mu.Lock()
+	c := s.conn
+	s.mu.Unlock()
+	return c.send(msgType, payload)
+}
+
+// join joins the room with the given id, an empty id creates a new room
+func (s *session) join(roomID string) error {
+	options := interfaces.CreateRoomOptions{
+		GameType:   s.gameType,
+		PlayerName: s.name,
+		Options:    s.options,
+	}
+	if roomID != "" {
+		options.RoomID = &roomID
+	}
+	return s.send("join_room", options)
+}
+
+// reconnect takes the seat of the session again on a new connection
+func (s *session) reconnect() error {
+	s.mu.Lock()
+	payload := router.ReconnectPayload{ClientID: s.seatID, RoomID: s.roomID}
+	s.mu.Unlock()
+	if payload.ClientID == "" {
+		return errNoSeat
+	}
+
+	if err := s.connect(); err != nil {
+		return err
+	}
+	return s.send("reconnect", payload)
+}
+
+// run reads commands until the input ends or the player quits
+func (s *session) run(in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		quit, err := s.execute(scanner.Text())
+		if err != nil {
+			s.printf("! %v\n", err)
+		}
+		if quit {
+			break
+		}
+	}
+	return scanner.Err()
+}
+
+// execute runs one command line and reports whether the player wants to quit
+func (s *session) execute(line string) (bool, error) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return false, nil
+	}
+	command, args := fields[0], fields[1:]
+
+	switch command {
+	case "quit", "exit":
+		return true, nil
+	case "help":
+		s.printf("%s", s.help())
+		return false, nil
+	case "state":
+		s.mu.Lock()
+		state := s.state
+		s.mu.Unlock()
+		if state == nil {
+			return false, errNoState
+		}
+		s.printf("%s\n", render(s.gameType, state, s.seat()))
+		return false, nil
+	case "join":
+		if len(args) != 1 {
+			return false, errors.New("usage: join <roomId>")
+		}
+		return false, s.join(args[0])
+	case "create":
+		return false, s.join("")
+	case "leave":
+		return false, s.send("leave_room", nil)
+	case "rooms":
+		return false, s.send("get_room_list", interfaces.M{"gameType": s.gameType})
+	case "reconnect":
+		return false, s.reconnect()
+	case "bot":
+		a, err := parseBot(args)
+		if err != nil {
+			return false, err
+		}
+		return false, s.send(a.msgType, a.payload)
+	case "send":
+		a, err := parseSend(args)
+		if err != nil {
+			return false, err
+		}
+		return false, s.send(a.msgType, a.payload)
+	}
+
+	game, ok := gameCommands[s.gameType][command]
+	if !ok {
+		return false, fmt.Errorf("unknown command %q, try help", command)
+	}
+
+	s.mu.Lock()
+	state := s.state
+	s.mu.Unlock()
+	actions, err := game.parse(args, state)
+	if err != nil {
+		return false, err
+	}
+	for _, a := range actions {
+		if err := s.send(a.msgType, a.payload); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+func (s *session) help() string {
+	var b strings.Builder
+	b.WriteString("commands:\n")
+	for _, usage := range []string{
+		"join <roomId>", "create", "leave", "rooms", "bot [strategy] [difficulty]",
+		"reconnect (takes your seat again on a new connection)", "state", "send <type> [json]", "quit",
+	} {
+		fmt.Fprintf(&b, "  %s\n", usage)
+	}
+
+	commands := gameCommands[s.gameType]
+	if len(commands) == 0 {
+		fmt.Fprintf(&b, "%s has no commands, use send\n", s.gameType)
+		return b.String()
+	}
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(&b, "%s:\n", s.gameType)
+	for _, name := range names {
+		fmt.Fprintf(&b, "  %s\n", commands[name].usage)
+	}
+	return b.String()
+}
+
+// runRaw sends every input line as a message and prints every response as the server sent it
+func runRaw(c *conn, in io.Reader, out io.Writer) error {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for response := range c.responses {
+			fmt.Fprintf(out, "%s\n", response.raw)
+		}
+		fmt.Fprintf(out, "! connection closed: %v\n", c.closedErr())
+	}()
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if err := c.sendRaw(line); err != nil {
+			fmt.Fprintf(out, "! %v\n", err)
+		}
+	}
+
+	c.close()
+	<-done
+	return scanner.Err()
+}
+
+var (
+	errNoSeat  = errors.New("not seated in a room yet")
+	errNoState = errors.New("no game state received yet")
+)
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"encoding/json"
+
+	"gameserver/games/dicegame"
+	"gameserver/games/owe_drahn"
+	"gameserver/games/tictactoe"
+)
+
+// move is an action a simulated player sends
+type move struct {
+	msgType string
+	payload interface{}
+}
+
+// turn is what a player sees when a game state arrives
+type turn struct {
+	data  json.RawMessage
+	self  string          // the seat id of the player
+	seats int             // how many players the room is waiting for
+	pick  func(n int) int // picks one of n valid moves, randomly or always the first
+	last  *move           // the move the player sent before in this game, nil if none
+}
+
+// policy plays one game type with valid moves only
+type policy struct {
+	minSeats   int
+	maxSeats   int
+	stateTypes []string
+	// next returns the move of the player in the state, nil while it waits for the others,
+	// over is set once the game ended
+	next func(t turn) (next *move, over bool, err error)
+}
+
+var policies = map[string]policy{
+	"tictactoe": {minSeats: 2, maxSeats: 2, stateTypes: []string{"game_state"}, next: nextTicTacToe},
+	"dicegame":  {minSeats: 2, maxSeats: 6, stateTypes: []string{"game_state"}, next: nextDicegame},
+	"owedrahn":  {minSeats: 2, maxSeats: 6, stateTypes: []string{"game_state", "gameInit"}, next: nextOweDrahn},
+}
+
+func (p policy) isState(responseType string) bool {
+	for _, t := range p.stateTypes {
+		if t == responseType {
+			return true
+		}
+	}
+	return false
+}
+
+func nextTicTacToe(t turn) (*move, bool, error) {
+	var state tictactoe.GameState
+	if err := json.Unmarshal(t.data, &state); err != nil {
+		return nil, false, err
+	}
+	if state.GameOver {
+		return nil, true, nil
+	}
+	if state.CurrentTurn != t.self {
+		return nil, false, nil
+	}
+
+	var free []tictactoe.MovePayload
+	for row, cells := range state.Board {
+		for col, cell := range cells {
+			if cell == "" {
+				free = append(free, tictactoe.MovePayload{Row: row, Col: col})
+			}
+		}
+	}
+	if len(free) == 0 {
+		return nil, false, nil
+	}
+	return &move{msgType: "make_move", payload: free[t.pick(len(free))]}, false, nil
+}
+
+// nextDicegame gets ready once the room is full and starts the game as host. In turn it rolls,
+// then selects every die that scores on its own or as part of a triple one by one and banks
+// them. A roll without such dice busted, the server ends the turn.
+func nextDicegame(t turn) (*move, bool, error) {
+	var state dicegame.GameState
+	if err := json.Unmarshal(t.data, &state); err != nil {
+		return nil, false, err
+	}
+	if state.Winner != "" {
+		return nil, true, nil
+	}
+	if !state.Started {
+		return dicegameLobby(t, state), false, nil
+	}
+	if state.CurrentTurn != t.self {
+		return nil, false, nil
+	}
+
+	rolled := false
+	for _, die := range state.Dice {
+		rolled = rolled || die != 0
+	}
+	if !rolled {
+		return &move{msgType: "roll"}, false, nil
+	}
+
+	scoring := scoringDice(state.Dice)
+	if len(scoring) == 0 {
+		return nil, false, nil
+	}
+
+	selected := make(map[int]bool, len(state.SelectedDice))
+	for _, index := range state.SelectedDice {
+		selected[index] = true
+	}
+	for _, index := range scoring {
+		if !selected[index] {
+			return &move{msgType: "select", payload: dicegame.SelectActionPayload{DiceIndex: index}}, false, nil
+		}
+	}
+	return &move{msgType: "set_aside", payload: dicegame.SetAsideActionPayload{EndTurn: true}}, false, nil
+}
+
+// dicegameLobby returns the ready of a player in a full room, or the start of the host once
+// everyone is ready
+func dicegameLobby(t turn, state dicegame.GameState) *move {
+	me, seated := state.Players[t.self]
+	if !seated || len(state.Players) < t.seats {
+		return nil
+	}
+	// the state of another player getting ready may not show our ready yet
+	if !me.Ready {
+		if t.last != nil && t.last.msgType == "ready" {
+			return nil
+		}
+		return &move{msgType: "ready", payload: dicegame.ReadyActionPayload{Ready: true}}
+	}
+	if state.Host != t.self || t.last != nil && t.last.msgType == "start" {
+		return nil
+	}
+	for _, player := range state.Players {
+		if !player.Ready {
+			return nil
+		}
+	}
+	return &move{msgType: "start"}
+}
+
+// scoringDice returns the indexes of all ones, fives and dice that show up at least three times
+func scoringDice(dice []int) []int {
+	counts := make(map[int]int)
+	for _, die := range dice {
+		counts[die]++
+	}
+
+	var indexes []int
+	for i, die := range dice {
+		if die == 1 || die == 5 || counts[die] >= 3 {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// safeRollValue is the highest total no roll can push over 15
+const safeRollValue = 9
+
+// nextOweDrahn gets ready once the room is full, rolls while it is safe and otherwise rolls or
+// loses a life, as long as it has more than one
+func nextOweDrahn(t turn) (*move, bool, error) {
+	var state owe_drahn.GameStateDTO
+	if err := json.Unmarshal(t.data, &state); err != nil {
+		return nil, false, err
+	}
+	if state.Over {
+		return nil, true, nil
+	}
+
+	var me *owe_drahn.Player
+	for _, player := range state.Players {
+		if player.ID == t.self {
+			me = player
+		}
+	}
+	if me == nil {
+		return nil, false, nil
+	}
+
+	if !state.Started {
+		// the state of another player getting ready may not show our ready yet
+		sentReady := t.last != nil && t.last.msgType == "ready"
+		if me.IsReady || sentReady || len(state.Players) < t.seats {
+			return nil, false, nil
+		}
+		return &move{msgType: "ready", payload: true}, false, nil
+	}
+	if state.CurrentTurn != me.ID {
+		return nil, false, nil
+	}
+
+	if me.IsChoosing {
+		var alive []string
+		for _, player := range state.Players {
+			if player.ID != me.ID && player.Life > 0 {
+				alive = append(alive, player.ID)
+			}
+		}
+		if len(alive) == 0 {
+			return nil, false, nil
+		}
+		return &move{msgType: "chooseNextPlayer", payload: owe_drahn.NextPlayerPayload{NextPlayerId: alive[t.pick(len(alive))]}}, false, nil
+	}
+
+	if state.CurrentValue <= safeRollValue || me.Life <= 1 || t.pick(2) == 0 {
+		return &move{msgType: "roll"}, false, nil
+	}
+	return &move{msgType: "loseLife"}, false, nil
+}
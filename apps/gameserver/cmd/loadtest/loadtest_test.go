@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"gameserver/games/dicegame"
+	"gameserver/games/owe_drahn"
+)
+
+func TestLoadInProcess(t *testing.T) {
+	if testing.Short() {
+		t.Skip("plays games for a few seconds")
+	}
+
+	ctx := context.Background()
+	server, err := startInProcessServer(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	for _, gameType := range []string{"tictactoe", "dicegame", "owedrahn"} {
+		t.Run(gameType, func(t *testing.T) {
+			report := run(ctx, config{
+				server:    server.URL,
+				gameTypes: []string{gameType},
+				rooms:     3,
+				duration:  2 * time.Second,
+				stall:     10 * time.Second,
+				random:    true,
+				seed:      1,
+			})
+
+			if report.Failed() {
+				var text strings.Builder
+				report.WriteText(&text)
+				t.Fatalf("load test failed:\n%s", text.String())
+			}
+			if report.Connections != 6 {
+				t.Errorf("expected 6 connections, got %d", report.Connections)
+			}
+			if report.Actions == 0 || report.Latency["action"].Count == 0 {
+				t.Errorf("no actions were measured: %+v", report)
+			}
+			if report.Latency["join"].Count == 0 {
+				t.Errorf("no joins were measured: %+v", report)
+			}
+		})
+	}
+}
+
+func TestPercentiles(t *testing.T) {
+	latencies := make([]time.Duration, 0, 100)
+	for i := 100; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	p := percentiles(latencies)
+	if p.Count != 100 || p.P50 != 50 || p.P90 != 90 || p.P99 != 99 || p.Max != 100 {
+		t.Errorf("unexpected percentiles %+v", p)
+	}
+	if empty := percentiles(nil); empty != (Percentiles{}) {
+		t.Errorf("expected no percentiles, got %+v", empty)
+	}
+}
+
+func TestScoringDice(t *testing.T) {
+	tests := []struct {
+		dice []int
+		want []int
+	}{
+		{[]int{1, 2, 3, 4, 6, 6}, []int{0}},
+		{[]int{2, 2, 2, 5, 3, 4}, []int{0, 1, 2, 3}},
+		{[]int{2, 3, 4, 6, 6, 2}, nil},
+	}
+
+	for _, tt := range tests {
+		got := scoringDice(tt.dice)
+		if len(got) != len(tt.want) {
+			t.Errorf("scoringDice(%v) = %v, want %v", tt.dice, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("scoringDice(%v) = %v, want %v", tt.dice, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestOweDrahnWaitsForTheRoom(t *testing.T) {
+	state := func(players ...*owe_drahn.Player) json.RawMessage {
+		data, _ := json.Marshal(owe_drahn.GameStateDTO{Players: players})
+		return data
+	}
+	first := func(int) int { return 0 }
+
+	next, _, _ := nextOweDrahn(turn{data: state(&owe_drahn.Player{ID: "a"}), self: "a", seats: 2, pick: first})
+	if next != nil {
+		t.Errorf("got ready before the room was full: %+v", next)
+	}
+
+	full := state(&owe_drahn.Player{ID: "a"}, &owe_drahn.Player{ID: "b", IsReady: true})
+	next, _, _ = nextOweDrahn(turn{data: full, self: "a", seats: 2, pick: first})
+	if next == nil || next.msgType != "ready" {
+		t.Fatalf("expected ready, got %+v", next)
+	}
+
+	next, _, _ = nextOweDrahn(turn{data: full, self: "a", seats: 2, pick: first, last: next})
+	if next != nil {
+		t.Errorf("sent ready twice: %+v", next)
+	}
+}
+
+func TestDicegameHostStartsOnceEveryoneIsReady(t *testing.T) {
+	state := func(players ...*dicegame.Player) json.RawMessage {
+		byID := make(map[string]*dicegame.Player)
+		for _, player := range players {
+			byID[player.ID] = player
+		}
+		data, _ := json.Marshal(dicegame.GameState{Players: byID, Host: "a"})
+		return data
+	}
+
+	next, _, _ := nextDicegame(turn{data: state(&dicegame.Player{ID: "a"}), self: "a", seats: 2})
+	if next != nil {
+		t.Errorf("got ready before the room was full: %+v", next)
+	}
+
+	full := state(&dicegame.Player{ID: "a"}, &dicegame.Player{ID: "b"})
+	next, _, _ = nextDicegame(turn{data: full, self: "a", seats: 2})
+	if next == nil || next.msgType != "ready" {
+		t.Fatalf("expected ready, got %+v", next)
+	}
+
+	waiting := state(&dicegame.Player{ID: "a", Ready: true}, &dicegame.Player{ID: "b"})
+	if next, _, _ = nextDicegame(turn{data: waiting, self: "a", seats: 2}); next != nil {
+		t.Errorf("started before everyone was ready: %+v", next)
+	}
+
+	ready := state(&dicegame.Player{ID: "a", Ready: true}, &dicegame.Player{ID: "b", Ready: true})
+	if next, _, _ = nextDicegame(turn{data: ready, self: "b", seats: 2}); next != nil {
+		t.Errorf("expected only the host to start, got %+v", next)
+	}
+	if next, _, _ = nextDicegame(turn{data: ready, self: "a", seats: 2}); next == nil || next.msgType != "start" {
+		t.Errorf("expected the host to start, got %+v", next)
+	}
+}
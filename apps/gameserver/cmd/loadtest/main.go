@@ -0,0 +1,120 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// loadtest plays many rooms at once over real websocket connections and reports throughput,
+// latency percentiles, dropped messages and errors. Without -server it starts the server in
+// process, which is what CI runs and what -mutexprofile profiles.
+//
+//	go run ./cmd/loadtest -games tictactoe,dicegame -rooms 200 -duration 30s
+//	go run ./cmd/loadtest -server ws://localhost:6969/ws -rooms 50 -random -json
+func main() {
+	server := flag.String("server", "", "websocket endpoint of the server, empty to start one in process")
+	games := flag.String("games", "tictactoe", "comma separated game types, rooms are spread over them")
+	rooms := flag.Int("rooms", 10, "rooms played at the same time")
+	seats := flag.Int("players", 0, "players per room, 0 for the fewest the game needs")
+	duration := flag.Duration("duration", 10*time.Second, "how long to play")
+	ramp := flag.Duration("ramp", time.Second, "room starts are spread over the ramp")
+	think := flag.Duration("think", 0, "how long players wait before each move")
+	stall := flag.Duration("stall", 15*time.Second, "how long players wait for the server before giving up on a room")
+	random := flag.Bool("random", false, "play random valid moves instead of always the first one")
+	seed := flag.Int64("seed", 1, "seed of the random moves")
+	asJSON := flag.Bool("json", false, "print the report as json")
+	strict := flag.Bool("strict", false, "exit with 1 if a connection failed, a room stalled, or the server dropped or rejected a message")
+	mutexProfile := flag.String("mutexprofile", "", "write a mutex contention profile of the in process server to this file")
+	verbose := flag.Bool("v", false, "show the logs of the in process server")
+	flag.Parse()
+
+	zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+	if *verbose {
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	}
+
+	cfg := config{
+		server:    *server,
+		gameTypes: strings.Split(*games, ","),
+		rooms:     *rooms,
+		seats:     *seats,
+		duration:  *duration,
+		ramp:      *ramp,
+		think:     *think,
+		stall:     *stall,
+		random:    *random,
+		seed:      *seed,
+	}
+	if err := cfg.validate(); err != nil {
+		fmt.Fprintln(os.Stderr, "loadtest:", err)
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+	if cfg.server == "" {
+		if *mutexProfile != "" {
+			runtime.SetMutexProfileFraction(1)
+		}
+		s, err := startInProcessServer(ctx)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "loadtest:", err)
+			os.Exit(1)
+		}
+		defer s.Close()
+		cfg.server = s.URL
+	} else if *mutexProfile != "" {
+		fmt.Fprintln(os.Stderr, "loadtest: -mutexprofile needs the in process server")
+		os.Exit(2)
+	}
+
+	report := run(ctx, cfg)
+
+	if *mutexProfile != "" {
+		if err := writeMutexProfile(*mutexProfile); err != nil {
+			fmt.Fprintln(os.Stderr, "loadtest:", err)
+		}
+	}
+
+	if *asJSON {
+		report.WriteJSON(os.Stdout)
+	} else {
+		report.WriteText(os.Stdout)
+	}
+
+	if *strict && report.Failed() {
+		os.Exit(1)
+	}
+}
+
+func (cfg config) validate() error {
+	for _, gameType := range cfg.gameTypes {
+		p, ok := policies[gameType]
+		if !ok {
+			return fmt.Errorf("unsupported game %q", gameType)
+		}
+		if cfg.seats != 0 && (cfg.seats < p.minSeats || cfg.seats > p.maxSeats) {
+			return fmt.Errorf("%s is played by %d to %d players", gameType, p.minSeats, p.maxSeats)
+		}
+	}
+	if cfg.rooms < 1 {
+		return fmt.Errorf("at least one room is needed")
+	}
+	return nil
+}
+
+func writeMutexProfile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return pprof.Lookup("mutex").WriteTo(f, 0)
+}
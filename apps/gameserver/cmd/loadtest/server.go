@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"gameserver/games/dicegame"
+	"gameserver/games/owe_drahn"
+	owedrahndb "gameserver/games/owe_drahn/database"
+	"gameserver/games/tictactoe"
+	"gameserver/internal/client"
+	"gameserver/internal/game"
+	"gameserver/internal/room"
+	"gameserver/internal/router"
+	"gameserver/internal/server"
+	"gameserver/internal/session"
+)
+
+// inProcessServer is the game server with the games the load test plays, without any
+// external dependencies
+type inProcessServer struct {
+	URL    string
+	server *http.Server
+}
+
+func startInProcessServer(ctx context.Context) (*inProcessServer, error) {
+	session.InitGlobalStore(900)
+
+	registry := game.NewRegistry()
+	tictactoe.RegisterTicTacToeGame(registry)
+	dicegame.RegisterDiceGame(registry)
+	registry.RegisterGame(owe_drahn.NewGame(&owedrahndb.DatabaseServiceMock{}))
+
+	clientManager := client.NewManager()
+	roomManager := room.NewRoomManager(registry)
+	messageRouter := router.NewRouter(ctx, clientManager, roomManager, registry)
+	roomManager.SetRoomListChangeCallback(messageRouter.BroadcastRoomListChange)
+
+	mux := http.NewServeMux()
+	server.Register(mux, server.Config{
+		Router:   messageRouter,
+		Clients:  clientManager,
+		Registry: registry,
+		Rooms:    roomManager,
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &inProcessServer{
+		URL:    "ws://" + listener.Addr().String() + "/ws",
+		server: &http.Server{Handler: mux},
+	}
+	go s.server.Serve(listener)
+	return s, nil
+}
+
+func (s *inProcessServer) Close() error {
+	return s.server.Close()
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"gameserver/internal/interfaces"
+	"gameserver/internal/protocol"
+	"gameserver/internal/router"
+
+	"github.com/gorilla/websocket"
+)
+
+// config of a load test run
+type config struct {
+	server    string
+	gameTypes []string // rooms are spread over the game types round robin
+	rooms     int      // rooms played at the same time
+	seats     int      // players per room, 0 for the fewest the game needs
+	duration  time.Duration
+	ramp      time.Duration // room starts are spread over the ramp
+	think     time.Duration // how long a player waits before each move
+	stall     time.Duration // how long a player waits for the server before giving up on a round
+	random    bool          // random valid moves instead of always the first one
+	seed      int64
+}
+
+// response is a protocol.Response with its data left undecoded, the players only decode the
+// states they act on
+type response struct {
+	Type    string          `json:"type"`
+	Success bool            `json:"success"`
+	Error   string          `json:"error,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// run plays rooms until the duration is over and reports what it measured
+func run(ctx context.Context, cfg config) Report {
+	ctx, cancel := context.WithTimeout(ctx, cfg.duration)
+	defer cancel()
+
+	s := newStats()
+	started := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.rooms; i++ {
+		gameType := cfg.gameTypes[i%len(cfg.gameTypes)]
+		delay := time.Duration(0)
+		if cfg.rooms > 1 {
+			delay = cfg.ramp * time.Duration(i) / time.Duration(cfg.rooms)
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return
+			}
+			r := &roomSim{cfg: cfg, gameType: gameType, policy: policies[gameType], stats: s, random: rand.New(rand.NewSource(cfg.seed + int64(i)))}
+			r.run(ctx)
+		}(i)
+	}
+	wg.Wait()
+
+	return s.report(cfg, time.Since(started))
+}
+
+// roomSim plays game after game with the same players, each in a new room
+type roomSim struct {
+	cfg      config
+	gameType string
+	policy   policy
+	stats    *stats
+	random   *rand.Rand // only used by the room goroutine to seed its players
+}
+
+func (r *roomSim) run(ctx context.Context) {
+	seats := r.cfg.seats
+	if seats == 0 {
+		seats = r.policy.minSeats
+	}
+
+	players := make([]*player, 0, seats)
+	defer func() {
+		for _, p := range players {
+			p.close()
+		}
+	}()
+	for i := 0; i < seats; i++ {
+		p, err := dialPlayer(r.cfg.server, r.gameType, r.stats)
+		if err != nil {
+			r.stats.add(func(s *stats) { s.failedConnections++ })
+			return
+		}
+		r.stats.add(func(s *stats) { s.connections++ })
+		p.name = fmt.Sprintf("load-%d", i)
+		p.random = rand.New(rand.NewSource(r.random.Int63()))
+		players = append(players, p)
+	}
+
+	for ctx.Err() == nil {
+		if !r.round(ctx, players) {
+			return
+		}
+	}
+}
+
+// round plays one game in a new room, it returns false once the players can't go on
+func (r *roomSim) round(ctx context.Context, players []*player) bool {
+	leader := players[0]
+	roomID, err := leader.join(ctx, "", r)
+	if err != nil {
+		r.stalled(ctx)
+		return false
+	}
+	for _, p := range players[1:] {
+		if _, err := p.join(ctx, roomID, r); err != nil {
+			r.stalled(ctx)
+			return false
+		}
+	}
+
+	var wg sync.WaitGroup
+	results := make(chan error, len(players))
+	for _, p := range players {
+		wg.Add(1)
+		go func(p *player) {
+			defer wg.Done()
+			results <- p.play(ctx, r, len(players))
+		}(p)
+	}
+	wg.Wait()
+	close(results)
+
+	for err := range results {
+		if err != nil {
+			r.stalled(ctx)
+			return false
+		}
+	}
+	r.stats.add(func(s *stats) { s.games++ })
+
+	for _, p := range players {
+		if err := p.leave(ctx, r); err != nil {
+			r.stalled(ctx)
+			return false
+		}
+	}
+	return true
+}
+
+// stalled counts a round the players gave up on, unless the run was over
+func (r *roomSim) stalled(ctx context.Context) {
+	if ctx.Err() != nil {
+		return
+	}
+	r.stats.add(func(s *stats) { s.stalledRounds++ })
+}
+
+// player is a simulated client on its own websocket connection
+type player struct {
+	conn      *websocket.Conn
+	stats     *stats
+	name      string
+	seat      string
+	random    *rand.Rand
+	responses chan response
+	backlog   []response // responses a request skipped while it waited for its result
+	done      chan struct{}
+}
+
+// dialPlayer connects like a browser on the page of the game, which gets room list updates
+func dialPlayer(server string, gameType string, s *stats) (*player, error) {
+	endpoint, err := url.Parse(server)
+	if err != nil {
+		return nil, err
+	}
+	query := endpoint.Query()
+	query.Set("game", gameType)
+	endpoint.RawQuery = query.Encode()
+
+	conn, _, err := websocket.DefaultDialer.Dial(endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &player{conn: conn, stats: s, responses: make(chan response, 256), done: make(chan struct{})}
+	go p.readLoop()
+	return p, nil
+}
+
+// readLoop unpacks the batches the server writes
+func (p *player) readLoop() {
+	defer close(p.responses)
+	for {
+		_, data, err := p.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var batch []response
+		if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+			err = json.Unmarshal(data, &batch)
+		} else {
+			batch = make([]response, 1)
+			err = json.Unmarshal(data, &batch[0])
+		}
+		if err != nil {
+			p.stats.add(func(s *stats) { s.errors["invalid response: "+err.Error()]++ })
+			continue
+		}
+
+		p.stats.add(func(s *stats) { s.received += len(batch) })
+		for _, r := range batch {
+			select {
+			case p.responses <- r:
+			case <-p.done:
+				return
+			}
+		}
+	}
+}
+
+func (p *player) send(msgType string, payload interface{}) error {
+	message := protocol.Message{Type: msgType}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		message.Data = data
+	}
+	data, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	return p.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// next waits for the next response, it fails once the server was quiet for longer than stall
+func (p *player) next(ctx context.Context, stall time.Duration) (response, error) {
+	if len(p.backlog) > 0 {
+		r := p.backlog[0]
+		p.backlog = p.backlog[1:]
+		return r, nil
+	}
+
+	select {
+	case r, ok := <-p.responses:
+		if !ok {
+			return response{}, errConnectionClosed
+		}
+		return r, nil
+	case <-time.After(stall):
+		return response{}, errStalled
+	case <-ctx.Done():
+		return response{}, ctx.Err()
+	}
+}
+
+// request sends a message and waits for its result. Responses that arrive in between are kept
+// for play, games broadcast their state before the router answers a join.
+func (p *player) request(ctx context.Context, r *roomSim, series string, msgType string, payload interface{}, resultType string) (response, error) {
+	sent := time.Now()
+	if err := p.send(msgType, payload); err != nil {
+		return response{}, err
+	}
+
+	var skipped []response
+	defer func() {
+		p.backlog = append(p.backlog, skipped...)
+	}()
+	for {
+		res, err := p.next(ctx, r.cfg.stall)
+		if errors.Is(err, errStalled) {
+			p.stats.add(func(s *stats) { s.dropped++ })
+		}
+		if err != nil {
+			return response{}, err
+		}
+		if res.Type != resultType {
+			skipped = append(skipped, res)
+			continue
+		}
+
+		p.stats.observe(series, time.Since(sent))
+		if !res.Success {
+			p.stats.add(func(s *stats) { s.errors[res.Type+": "+res.Error]++ })
+			return res, fmt.Errorf("%s: %s", res.Type, res.Error)
+		}
+		return res, nil
+	}
+}
+
+// join joins the room with the given id or creates one and returns its id
+func (p *player) join(ctx context.Context, roomID string, r *roomSim) (string, error) {
+	options := interfaces.CreateRoomOptions{GameType: r.gameType, PlayerName: p.name}
+	if roomID != "" {
+		options.RoomID = &roomID
+	}
+
+	res, err := p.request(ctx, r, "join", "join_room", options, "join_room_result")
+	if err != nil {
+		return "", err
+	}
+
+	var joined router.JoinResponse
+	if err := json.Unmarshal(res.Data, &joined); err != nil {
+		return "", err
+	}
+	p.seat = joined.ClientID
+	return joined.RoomID, nil
+}
+
+// leave leaves the room and forgets everything the room sent
+func (p *player) leave(ctx context.Context, r *roomSim) error {
+	p.backlog = nil
+	_, err := p.request(ctx, r, "leave", "leave_room", nil, "leave_room_result")
+	p.backlog = nil
+	return err
+}
+
+// play makes moves on every state until the game is over. An action is answered by the next
+// state or error the player receives, which is what its latency is measured up to.
+func (p *player) play(ctx context.Context, r *roomSim, seats int) error {
+	pick := func(int) int { return 0 }
+	if r.cfg.random {
+		pick = p.random.Intn
+	}
+
+	var pending time.Time
+	var last *move
+	for {
+		batch, err := p.arrived(ctx, r.cfg.stall)
+		if errors.Is(err, errStalled) && !pending.IsZero() {
+			p.stats.add(func(s *stats) { s.dropped++ })
+		}
+		if err != nil {
+			return err
+		}
+
+		// only a move on the latest state counts, moves on the ones before it would be stale
+		var next *move
+		for _, res := range batch {
+			isState := res.Success && r.policy.isState(res.Type)
+			if !res.Success {
+				p.stats.add(func(s *stats) { s.errors[res.Type+": "+res.Error]++ })
+			}
+			if (isState || !res.Success) && !pending.IsZero() {
+				p.stats.observe("action", time.Since(pending))
+				pending = time.Time{}
+			}
+			if !isState {
+				continue
+			}
+
+			move, over, err := r.policy.next(turn{data: res.Data, self: p.seat, seats: seats, pick: pick, last: last})
+			if err != nil {
+				p.stats.add(func(s *stats) { s.errors["invalid state: "+err.Error()]++ })
+				continue
+			}
+			if over {
+				return nil
+			}
+			next = move
+		}
+		if next == nil {
+			continue
+		}
+
+		if r.cfg.think > 0 {
+			select {
+			case <-time.After(r.cfg.think):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		if err := p.send(next.msgType, next.payload); err != nil {
+			return err
+		}
+		pending = time.Now()
+		last = next
+		p.stats.add(func(s *stats) { s.actions++ })
+	}
+}
+
+// arrived waits for the next response and returns it together with all that arrived with it
+func (p *player) arrived(ctx context.Context, stall time.Duration) ([]response, error) {
+	first, err := p.next(ctx, stall)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := append([]response{first}, p.backlog...)
+	p.backlog = nil
+	for {
+		select {
+		case res, ok := <-p.responses:
+			if !ok {
+				return batch, nil
+			}
+			batch = append(batch, res)
+		default:
+			return batch, nil
+		}
+	}
+}
+
+func (p *player) close() {
+	close(p.done)
+	p.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+	p.conn.Close()
+}
+
+var (
+	errConnectionClosed = errors.New("connection closed")
+	errStalled          = errors.New("server stopped answering")
+)
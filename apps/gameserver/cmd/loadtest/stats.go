@@ -0,0 +1,189 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// stats collects what the simulated players measure, it is shared by all of them
+type stats struct {
+	mu sync.Mutex
+
+	connections       int
+	failedConnections int
+	games             int
+	stalledRounds     int
+	actions           int
+	dropped           int // actions the server never answered
+	received          int
+	errors            map[string]int // error responses by message
+	latencies         map[string][]time.Duration
+}
+
+func newStats() *stats {
+	return &stats{
+		errors:    make(map[string]int),
+		latencies: make(map[string][]time.Duration),
+	}
+}
+
+func (s *stats) add(update func(s *stats)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	update(s)
+}
+
+func (s *stats) observe(series string, latency time.Duration) {
+	s.add(func(s *stats) {
+		s.latencies[series] = append(s.latencies[series], latency)
+	})
+}
+
+// Percentiles of a latency series in milliseconds
+type Percentiles struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50"`
+	P90   float64 `json:"p90"`
+	P99   float64 `json:"p99"`
+	Max   float64 `json:"max"`
+}
+
+// Report is the result of a load test run
+type Report struct {
+	Duration          time.Duration          `json:"duration"`
+	GameTypes         []string               `json:"gameTypes"`
+	Rooms             int                    `json:"rooms"`
+	Players           int                    `json:"players"`
+	Connections       int                    `json:"connections"`
+	FailedConnections int                    `json:"failedConnections"`
+	Games             int                    `json:"games"`
+	StalledRounds     int                    `json:"stalledRounds"`
+	Actions           int                    `json:"actions"`
+	Dropped           int                    `json:"dropped"`
+	Received          int                    `json:"received"`
+	Errors            map[string]int         `json:"errors"`
+	Latency           map[string]Percentiles `json:"latency"`
+}
+
+// ErrorCount returns the number of error responses
+func (r Report) ErrorCount() int {
+	count := 0
+	for _, n := range r.Errors {
+		count += n
+	}
+	return count
+}
+
+// Failed reports whether the run saw anything a healthy server never does
+func (r Report) Failed() bool {
+	return r.FailedConnections > 0 || r.StalledRounds > 0 || r.Dropped > 0 || r.ErrorCount() > 0
+}
+
+func (s *stats) report(cfg config, elapsed time.Duration) Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := Report{
+		Duration:          elapsed,
+		GameTypes:         cfg.gameTypes,
+		Rooms:             cfg.rooms,
+		Players:           s.connections + s.failedConnections,
+		Connections:       s.connections,
+		FailedConnections: s.failedConnections,
+		Games:             s.games,
+		StalledRounds:     s.stalledRounds,
+		Actions:           s.actions,
+		Dropped:           s.dropped,
+		Received:          s.received,
+		Errors:            make(map[string]int, len(s.errors)),
+		Latency:           make(map[string]Percentiles, len(s.latencies)),
+	}
+	for message, count := range s.errors {
+		report.Errors[message] = count
+	}
+	for series, latencies := range s.latencies {
+		report.Latency[series] = percentiles(latencies)
+	}
+	return report
+}
+
+func percentiles(latencies []time.Duration) Percentiles {
+	if len(latencies) == 0 {
+		return Percentiles{}
+	}
+
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	at := func(p float64) float64 {
+		index := int(math.Ceil(p*float64(len(sorted)))) - 1
+		if index < 0 {
+			index = 0
+		}
+		return milliseconds(sorted[index])
+	}
+	return Percentiles{
+		Count: len(sorted),
+		P50:   at(0.50),
+		P90:   at(0.90),
+		P99:   at(0.99),
+		Max:   milliseconds(sorted[len(sorted)-1]),
+	}
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// WriteText writes the report for humans
+func (r Report) WriteText(w io.Writer) {
+	seconds := r.Duration.Seconds()
+	rate := func(n int) float64 {
+		if seconds == 0 {
+			return 0
+		}
+		return float64(n) / seconds
+	}
+
+	fmt.Fprintf(w, "duration     %s, %s\n", r.Duration.Round(time.Millisecond), strings.Join(r.GameTypes, ", "))
+	fmt.Fprintf(w, "rooms        %d, %d players\n", r.Rooms, r.Players)
+	fmt.Fprintf(w, "connections  %d ok, %d failed\n", r.Connections, r.FailedConnections)
+	fmt.Fprintf(w, "games        %d finished (%.1f/s), %d stalled\n", r.Games, rate(r.Games), r.StalledRounds)
+	fmt.Fprintf(w, "actions      %d sent (%.1f/s), %d dropped\n", r.Actions, rate(r.Actions), r.Dropped)
+	fmt.Fprintf(w, "messages     %d received (%.1f/s)\n", r.Received, rate(r.Received))
+
+	fmt.Fprintf(w, "errors       %d\n", r.ErrorCount())
+	messages := make([]string, 0, len(r.Errors))
+	for message := range r.Errors {
+		messages = append(messages, message)
+	}
+	sort.Strings(messages)
+	for _, message := range messages {
+		fmt.Fprintf(w, "  %6d  %s\n", r.Errors[message], message)
+	}
+
+	series := make([]string, 0, len(r.Latency))
+	for name := range r.Latency {
+		series = append(series, name)
+	}
+	sort.Strings(series)
+	fmt.Fprintln(w, "latency (ms)   count      p50      p90      p99      max")
+	for _, name := range series {
+		p := r.Latency[name]
+		fmt.Fprintf(w, "  %-10s %7d %8.2f %8.2f %8.2f %8.2f\n", name, p.Count, p.P50, p.P90, p.P99, p.Max)
+	}
+}
+
+// WriteJSON writes the report for machines, e.g. to compare runs in CI
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"gameserver/games/dicegame"
+	"gameserver/games/owe_drahn"
+	owedrahndb "gameserver/games/owe_drahn/database"
+	"gameserver/games/tell_it"
+	tellitdb "gameserver/games/tell_it/database"
+	"gameserver/games/tictactoe"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/journal"
+	"gameserver/internal/replay"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// replay feeds recorded room journals back through fresh game instances and
+// reports whether the resulting states match the recording.
+//
+//	go run ./cmd/replay [-v] journals/<roomId>.jsonl ...
+func main() {
+	verbose := flag.Bool("v", false, "enable debug logging")
+	flag.Parse()
+
+	zerolog.SetGlobalLevel(zerolog.WarnLevel)
+	if *verbose {
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	}
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	if flag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "usage: replay [-v] <journal.jsonl>...")
+		os.Exit(2)
+	}
+
+	failed := false
+	for _, path := range flag.Args() {
+		if err := replayFile(path); err != nil {
+			fmt.Printf("FAIL %s: %v\n", path, err)
+			failed = true
+			continue
+		}
+		fmt.Printf("ok   %s\n", path)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func replayFile(path string) error {
+	j, err := journal.Load(path)
+	if err != nil {
+		return err
+	}
+
+	g, err := newGame(j.Header.GameType)
+	if err != nil {
+		return err
+	}
+
+	return replay.Run(context.Background(), g, j)
+}
+
+// newGame creates a fresh game instance without any external dependencies
+func newGame(gameType string) (interfaces.Game, error) {
+	switch gameType {
+	case "dicegame":
+		return dicegame.NewDiceGame(), nil
+	case "tictactoe":
+		return tictactoe.NewTicTacToe(), nil
+	case "owedrahn":
+		return owe_drahn.NewGame(&owedrahndb.DatabaseServiceMock{}), nil
+	case "tellit":
+		return tell_it.NewGame(&tellitdb.DatabaseServiceMock{}), nil
+	default:
+		return nil, errors.New("unsupported game type: " + gameType)
+	}
+}
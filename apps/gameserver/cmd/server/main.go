@@ -2,10 +2,9 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -13,44 +12,30 @@ import (
 	"time"
 
 	"gameserver/games/dicegame"
+	dicegamedb "gameserver/games/dicegame/database"
 	"gameserver/games/owe_drahn"
 	"gameserver/games/tell_it"
 	"gameserver/games/tictactoe"
+	"gameserver/internal/account"
+	"gameserver/internal/auth"
 	"gameserver/internal/client"
+	"gameserver/internal/cluster"
 	"gameserver/internal/game"
 	"gameserver/internal/interfaces"
-	"gameserver/internal/protocol"
+	"gameserver/internal/journal"
+	"gameserver/internal/rating"
+	"gameserver/internal/results"
 	"gameserver/internal/room"
 	"gameserver/internal/router"
+	"gameserver/internal/server"
 	"gameserver/internal/session"
+	"gameserver/internal/webhooks"
 
 	"github.com/getsentry/sentry-go"
-	"github.com/gorilla/websocket"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
-var upgrader = websocket.Upgrader{
-	ReadBufferSize:  1024,
-	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
-		origin := r.Header.Get("Origin")
-		if origin == "" {
-			return false // Reject requests with no origin
-		}
-
-		// Parse the origin URL
-		originURL, err := url.Parse(origin)
-		if err != nil {
-			log.Error().Err(err).Str("origin", origin).Msg("Failed to parse origin URL")
-			return false
-		}
-
-		// verify hostname
-		return strings.HasSuffix(originURL.Host, "drdreo.com")
-	},
-}
-
 func main() {
 	rootCtx, rootCancel := context.WithCancel(context.Background())
 	defer rootCancel() // Safety net - cancels if main exits unexpectedly
@@ -63,10 +48,6 @@ func main() {
 	if os.Getenv("STAGE") == "development" {
 		stage = interfaces.Development
 
-		// Allow all origins in development
-		upgrader.CheckOrigin = func(r *http.Request) bool {
-			return true
-		}
 	}
 
 	log.Info().Str("env.STAGE", os.Getenv("STAGE")).Str("stage", string(stage)).Msg("checking environment")
@@ -74,21 +55,97 @@ func main() {
 	// Initialize the global session store with 15 minute expiry
 	session.InitGlobalStore(900)
 
-	gameRegistry := game.NewRegistry()
+	var registryOpts []game.RegistryOption
+	if journalDir := os.Getenv("ROOM_JOURNAL_DIR"); journalDir != "" {
+		journalStore, err := journal.NewFileStore(journalDir)
+		if err != nil {
+			log.Fatal().Err(err).Str("dir", journalDir).Msg("Failed to create room journal store")
+		}
+		log.Info().Str("dir", journalDir).Msg("recording room journals")
+		registryOpts = append(registryOpts, game.WithRecorder(journalStore))
+	}
+
+	authenticator := initAuthenticator()
+	var routerOpts []router.RouterOption
+	if authenticator != nil {
+		routerOpts = append(routerOpts, router.WithAuthenticator(authenticator))
+	}
+
+	// Finished games are published here, stats and leaderboards subscribe to it
+	resultsBus := results.NewBus()
+	registryOpts = append(registryOpts, game.WithResults(resultsBus))
+
+	accounts := initAccounts(rootCtx, stage, authenticator)
+	if accounts != nil {
+		defer accounts.Close()
+		registryOpts = append(registryOpts, game.WithAccounts(accounts))
+		resultsBus.Subscribe("account-stats", accounts)
+	}
+
+	ratings := initRatings(rootCtx, stage, accounts)
+	if ratings != nil {
+		defer ratings.Close()
+		resultsBus.Subscribe("ratings", ratings)
+		routerOpts = append(routerOpts, router.WithLeaderboards(ratings, accounts))
+	}
+
+	// Room and game lifecycle events for external services, e.g. chat bots and analytics
+	var notifier *webhooks.Notifier
+	if dispatcher := initWebhooks(); dispatcher != nil {
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			dispatcher.Close(ctx)
+		}()
+		notifier = webhooks.NewNotifier(dispatcher)
+		resultsBus.Subscribe("webhooks", notifier)
+		registryOpts = append(registryOpts, game.WithGameStartedCallback(notifier.GameStarted))
+		registryOpts = append(registryOpts, game.WithPlayerCallbacks(notifier.PlayerJoined, notifier.PlayerLeft))
+	}
+
+	// Several nodes share the rooms, each room lives on one of them
+	node, clusterPubSub := initCluster()
+	if node != nil {
+		routerOpts = append(routerOpts, router.WithCluster(node))
+	}
+
+	gameRegistry := game.NewRegistry(registryOpts...)
 	clientManager := client.NewManager()
 	roomManager := room.NewRoomManager(gameRegistry)
-	messageRouter := router.NewRouter(rootCtx, clientManager, roomManager, gameRegistry)
+	messageRouter := router.NewRouter(rootCtx, clientManager, roomManager, gameRegistry, routerOpts...)
 
 	roomManager.SetRoomListChangeCallback(func(gameType string) {
+		if notifier != nil {
+			notifier.RoomListChanged(gameType, roomManager.GetAllRoomsByGameType(gameType))
+		}
+		if node != nil {
+			node.Announce()
+		}
 		messageRouter.BroadcastRoomListChange(gameType)
 	})
 
+	if node != nil {
+		node.SetLocalRooms(messageRouter.LocalRooms)
+		node.SetRoomListChangeCallback(messageRouter.BroadcastRoomListChange)
+		node.Start()
+		defer node.Stop()
+		http.Handle(cluster.PublishPath, clusterPubSub)
+	}
+
 	// Register all games
 	tictactoe.RegisterTicTacToeGame(gameRegistry)
-	dicegame.RegisterDiceGame(gameRegistry)
+	var dicegameOpts []dicegame.GameOption
+	dicegameHistory := initDicegameHistory(rootCtx, stage)
+	if dicegameHistory != nil {
+		defer dicegameHistory.Close()
+		dicegameOpts = append(dicegameOpts, dicegame.WithDatabase(dicegameHistory))
+	}
+	dicegame.RegisterDiceGame(gameRegistry, dicegameOpts...)
 	if err := owe_drahn.RegisterGame(rootCtx, gameRegistry, owe_drahn.GameConfig{
 		Stage:          stage,
 		CredentialsDir: "apps/gameserver/games/owe_drahn/database/credentials",
+		Authenticator:  authenticator,
+		Ratings:        ratings,
 	}); err != nil {
 		log.Fatal().Err(err).Msg("Failed to register owe_drahn")
 	}
@@ -98,20 +155,44 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to register tell_it")
 	}
 
-	http.HandleFunc("/", homeHandler)
-	http.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		wsHandler(w, r, messageRouter, clientManager)
+	var checkOrigin func(r *http.Request) bool
+	if stage == interfaces.Development {
+		// Allow all origins in development
+		checkOrigin = func(r *http.Request) bool {
+			return true
+		}
+	}
+	server.Register(http.DefaultServeMux, server.Config{
+		Router:        messageRouter,
+		Clients:       clientManager,
+		Registry:      gameRegistry,
+		Rooms:         roomManager,
+		Authenticator: authenticator,
+		Bots:          initBots(),
+		Node:          node,
+		CheckOrigin:   checkOrigin,
 	})
 
-	// Add a simple endpoint to list available games
-	http.HandleFunc("/games", func(w http.ResponseWriter, r *http.Request) {
-		gamesHandler(w, gameRegistry)
-	})
+	// Lets players verify the rolls of a provably fair owe_drahn round
+	http.HandleFunc("/owedrahn/verify", owe_drahn.VerifyHandler)
 
-	// Add a new endpoint to list all rooms
-	http.HandleFunc("/rooms", func(w http.ResponseWriter, r *http.Request) {
-		roomHandler(w, roomManager)
-	})
+	// Player profiles shared by all games
+	if accounts != nil {
+		accountHandler := account.NewHTTPHandler(accounts, authenticator)
+		http.Handle("/accounts/", accountHandler)
+	}
+
+	// Dicegame match history and player statistics
+	if dicegameHistory != nil {
+		http.Handle("/dicegame/", dicegame.NewHTTPHandler(dicegameHistory))
+	}
+
+	// Leaderboards and rating history
+	if ratings != nil {
+		ratingHandler := rating.NewHTTPHandler(ratings, accounts, authenticator)
+		http.Handle("/leaderboards/", ratingHandler)
+		http.Handle("/ratings/", ratingHandler)
+	}
 
 	port, _ := strconv.Atoi(os.Getenv("PORT"))
 	addr := fmt.Sprintf(":%d", port)
@@ -150,91 +231,163 @@ func initObservability() func() {
 	}
 }
 
-func initLogger() {
-	zerolog.CallerMarshalFunc = func(pc uintptr, file string, line int) string {
-		return filepath.Base(file) + ":" + strconv.Itoa(line)
+// initAuthenticator sets up ID token verification from AUTH_JWKS_FILE (or inline AUTH_JWKS),
+// AUTH_ISSUER and AUTH_AUDIENCE. Returns nil if no keys are configured.
+func initAuthenticator() auth.Authenticator {
+	var keys *auth.KeySet
+	var err error
+	if path := os.Getenv("AUTH_JWKS_FILE"); path != "" {
+		keys, err = auth.LoadJWKS(path)
+	} else if jwks := os.Getenv("AUTH_JWKS"); jwks != "" {
+		keys, err = auth.ParseJWKS([]byte(jwks))
+	} else {
+		log.Warn().Msg("AUTH_JWKS_FILE environment variable not set - client identities are not verified")
+		return nil
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load JWKS")
 	}
 
-	zerolog.SetGlobalLevel(zerolog.DebugLevel)
-	log.Logger = log.With().Caller().Logger().Output(zerolog.ConsoleWriter{Out: os.Stdout})
+	log.Info().Str("issuer", os.Getenv("AUTH_ISSUER")).Msg("verifying client ID tokens")
+	return auth.NewJWTAuthenticator(keys,
+		auth.WithIssuer(os.Getenv("AUTH_ISSUER")),
+		auth.WithAudience(os.Getenv("AUTH_AUDIENCE")),
+	)
+}
+
+// initAccounts sets up the player accounts shared by all games. Accounts are created from
+// verified identities, so they are only available when an authenticator is configured.
+func initAccounts(ctx context.Context, stage interfaces.Environment, authenticator auth.Authenticator) *account.Service {
+	if authenticator == nil {
+		return nil
+	}
+
+	initCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
 
+	accounts, err := account.NewFactory(stage).CreateService(initCtx)
+	if errors.Is(err, account.ErrNotConfigured) {
+		log.Warn().Msg("ACCOUNTS_DATABASE_URL environment variable not set - player accounts are disabled")
+		return nil
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize player accounts")
+	}
+	return accounts
 }
 
-func homeHandler(w http.ResponseWriter, r *http.Request) {
-	if r.URL.Path != "/" {
-		http.Error(w, "Not found", http.StatusNotFound)
-		return
+// initRatings sets up the ratings and leaderboards. Only accounts are rated, so they need player accounts.
+func initRatings(ctx context.Context, stage interfaces.Environment, accounts *account.Service) *rating.Service {
+	if accounts == nil {
+		return nil
 	}
-	if r.Method != "GET" {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		return
+
+	initCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	ratings, err := rating.NewFactory(stage).CreateService(initCtx)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to initialize ratings")
 	}
-	w.Header().Set("Content-Type", "application/json")
-	w.Write([]byte(`{"status": "Game server running"}`))
+	return ratings
 }
 
-func wsHandler(w http.ResponseWriter, r *http.Request, router *router.Router, clientManager *client.Manager) {
-	// Get interested game type info from query parameters
-	gameType := r.URL.Query().Get("game")
+// initDicegameHistory sets up the dicegame match history. Returns nil if no database is configured.
+func initDicegameHistory(ctx context.Context, stage interfaces.Environment) *dicegamedb.DatabaseService {
+	initCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
 
-	conn, err := upgrader.Upgrade(w, r, nil)
+	history, err := dicegamedb.NewDatabaseFactory(stage).CreateDatabaseService(initCtx)
+	if errors.Is(err, dicegamedb.ErrNotConfigured) {
+		log.Warn().Msg("DICEGAME_DATABASE_URL environment variable not set - dicegame matches are not stored")
+		return nil
+	}
 	if err != nil {
-		log.Error().Err(err).Msg("Error upgrading connection")
-		return
+		log.Fatal().Err(err).Msg("Failed to initialize the dicegame match history")
+	}
+	return history
+}
+
+// initCluster joins the cluster named by CLUSTER_NODE_ID. Other nodes reach this one at
+// CLUSTER_ADVERTISE_ADDR, clients at CLUSTER_PUBLIC_URL. CLUSTER_PEERS lists the addresses of the
+// other nodes, their messages are signed with CLUSTER_SECRET. Returns nil without a node id.
+func initCluster() (*cluster.Node, *cluster.HTTPPubSub) {
+	nodeID := os.Getenv("CLUSTER_NODE_ID")
+	if nodeID == "" {
+		log.Info().Msg("CLUSTER_NODE_ID environment variable not set - running as a single node")
+		return nil, nil
 	}
 
-	c := client.NewWebsocketClient(conn, clientManager, gameType)
+	secret := os.Getenv("CLUSTER_SECRET")
+	if secret == "" {
+		log.Fatal().Msg("CLUSTER_SECRET environment variable is required in cluster mode")
+	}
 
-	// Set message handler
-	c.OnMessage = func(message []byte) {
-		router.HandleMessage(c, message)
+	var peers []string
+	for _, peer := range strings.Split(os.Getenv("CLUSTER_PEERS"), ",") {
+		if peer = strings.TrimSpace(peer); peer != "" {
+			peers = append(peers, peer)
+		}
 	}
 
-	// Start read/write pumps
-	c.StartPumps()
+	pubsub := cluster.NewHTTPPubSub(peers, secret)
+	node := cluster.NewNode(cluster.NodeInfo{
+		ID:        nodeID,
+		Address:   os.Getenv("CLUSTER_ADVERTISE_ADDR"),
+		PublicURL: os.Getenv("CLUSTER_PUBLIC_URL"),
+	}, pubsub)
 
-	// Send welcome message
-	welcomeMsg := protocol.NewSuccessResponse("welcome", interfaces.M{
-		"message": "Connected to game server. Interested in game: " + gameType,
-	})
-	c.Send(welcomeMsg)
+	log.Info().Str("nodeId", nodeID).Int("peers", len(peers)).Msg("running in cluster mode")
+	return node, pubsub
 }
 
-func gamesHandler(w http.ResponseWriter, gameRegistry *game.Registry) {
-	w.Header().Set("Content-Type", "application/json")
-
-	games := gameRegistry.ListGames()
-	response := map[string][]string{"games": games}
+// initWebhooks sets up webhooks to WEBHOOK_URLS (comma separated), signed with WEBHOOK_SECRET.
+// WEBHOOK_EVENTS optionally limits the events sent. Returns nil if no URLs are configured.
+func initWebhooks() *webhooks.Dispatcher {
+	urls := os.Getenv("WEBHOOK_URLS")
+	if urls == "" {
+		log.Info().Msg("WEBHOOK_URLS environment variable not set - webhooks are disabled")
+		return nil
+	}
 
-	jsonData, err := json.Marshal(response)
+	endpoints, err := webhooks.ParseEndpoints(urls, os.Getenv("WEBHOOK_SECRET"), os.Getenv("WEBHOOK_EVENTS"))
 	if err != nil {
-		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
-		return
+		log.Fatal().Err(err).Msg("Failed to configure webhooks")
 	}
-
-	w.Write(jsonData)
+	return webhooks.NewDispatcher(endpoints)
 }
 
-func roomHandler(w http.ResponseWriter, roomManager *room.RoomManager) {
-	w.Header().Set("Content-Type", "application/json")
+// initBots reads the API keys of external bots from BOT_API_KEYS (comma separated name:key pairs)
+// and their time limit per action from BOT_ACTION_TIME_LIMIT. Returns nil if no keys are configured.
+func initBots() *server.Bots {
+	value := os.Getenv("BOT_API_KEYS")
+	if value == "" {
+		log.Info().Msg("BOT_API_KEYS environment variable not set - external bots are disabled")
+		return nil
+	}
 
-	rooms := roomManager.ListRooms()
-	response := make([]interfaces.M, 0, len(rooms))
+	keys, err := auth.ParseBotKeys(value)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to parse BOT_API_KEYS")
+	}
 
-	for _, r := range rooms {
-		roomInfo := interfaces.M{
-			"id":          r.ID(),
-			"type":        r.GameType(),
-			"clientCount": len(r.Clients()),
+	timeLimit := 10 * time.Second
+	if limit := os.Getenv("BOT_ACTION_TIME_LIMIT"); limit != "" {
+		if timeLimit, err = time.ParseDuration(limit); err != nil || timeLimit <= 0 {
+			log.Fatal().Str("value", limit).Msg("BOT_ACTION_TIME_LIMIT must be a positive duration, e.g. 10s")
 		}
-		response = append(response, roomInfo)
 	}
 
-	jsonData, err := json.Marshal(response)
-	if err != nil {
-		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
-		return
+	log.Info().Int("keys", len(keys)).Dur("timeLimit", timeLimit).Msg("accepting external bots")
+	return &server.Bots{Keys: keys, TimeLimit: timeLimit}
+}
+
+func initLogger() {
+	zerolog.CallerMarshalFunc = func(pc uintptr, file string, line int) string {
+		return filepath.Base(file) + ":" + strconv.Itoa(line)
 	}
 
-	w.Write(jsonData)
+	zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	log.Logger = log.With().Caller().Logger().Output(zerolog.ConsoleWriter{Out: os.Stdout})
+
 }
@@ -1,11 +1,17 @@
 package dicegame
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/testicles"
+	"slices"
 	"testing"
 )
 
 func TestCalculateScore(t *testing.T) {
-	diceGame := &DiceGame{}
+	rules := defaultRuleset
 
 	testCases := []struct {
 		name     string
@@ -280,7 +286,7 @@ func TestCalculateScore(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			score, valid := diceGame.CalculateScore(tc.dice)
+			score, valid := rules.CalculateScore(tc.dice)
 			if score != tc.expected {
 				t.Errorf("Expected score %d, got %d for dice %v", tc.expected, score, tc.dice)
 			}
@@ -290,3 +296,201 @@ func TestCalculateScore(t *testing.T) {
 		})
 	}
 }
+
+func TestCalculateScore_SixDiceCombinations(t *testing.T) {
+	rules, err := NewRuleset("", json.RawMessage(`{"threePairs":1500,"twoTriplets":2500,"fourOfAKindAndPair":1500}`))
+	if err != nil {
+		t.Fatalf("Failed to create ruleset: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		rules    *Ruleset
+		dice     []int
+		expected int
+		valid    bool
+	}{
+		{name: "three pairs", rules: rules, dice: []int{4, 2, 3, 2, 4, 3}, expected: 1500, valid: true},
+		{name: "three pairs of scoring dice", rules: rules, dice: []int{1, 1, 5, 5, 6, 6}, expected: 1500, valid: true},
+		{name: "two triplets", rules: rules, dice: []int{2, 2, 2, 3, 3, 3}, expected: 2500, valid: true},
+		{name: "two triplets beat three of a kind", rules: rules, dice: []int{1, 1, 1, 5, 5, 5}, expected: 2500, valid: true},
+		{name: "four of a kind and a pair", rules: rules, dice: []int{4, 6, 4, 4, 6, 4}, expected: 1500, valid: true},
+		{name: "four of a kind and a pair below four 1s", rules: rules, dice: []int{1, 1, 1, 1, 5, 5}, expected: 2100, valid: true},
+		{name: "six of a kind is no three pairs", rules: rules, dice: []int{2, 2, 2, 2, 2, 2}, expected: 1600, valid: true},
+		{name: "run", rules: rules, dice: []int{1, 2, 3, 4, 5, 6}, expected: 1500, valid: true},
+		{name: "two pairs", rules: rules, dice: []int{2, 2, 3, 3, 4, 6}, expected: 0, valid: false},
+		{name: "pairs of five dice", rules: rules, dice: []int{2, 2, 3, 3, 4}, expected: 0, valid: false},
+		{name: "disabled three pairs", rules: defaultRuleset, dice: []int{2, 2, 3, 3, 4, 4}, expected: 0, valid: false},
+		{name: "disabled two triplets", rules: defaultRuleset, dice: []int{2, 2, 2, 3, 3, 3}, expected: 500, valid: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			score, valid := tc.rules.CalculateScore(tc.dice)
+			if score != tc.expected || valid != tc.valid {
+				t.Errorf("Expected %v to score %d (valid=%v), got %d (valid=%v)", tc.dice, tc.expected, tc.valid, score, valid)
+			}
+		})
+	}
+}
+
+func TestRuleset_AdvancedRules(t *testing.T) {
+	rules, err := NewRuleset(RulesetClassic, json.RawMessage(`{"openingScore":500,"hotDice":false,"finalRound":true}`))
+	if err != nil {
+		t.Fatalf("Failed to create ruleset: %v", err)
+	}
+	if rules.canBank(0, 450) || !rules.canBank(0, 500) || !rules.canBank(500, 50) {
+		t.Error("Expected only the first banked score to need the opening score")
+	}
+	if !defaultRuleset.canBank(0, 50) {
+		t.Error("Expected the default rules to bank any score")
+	}
+
+	if dice := rules.diceLeftAfter(6, 6); dice != 0 {
+		t.Errorf("Expected no dice left without hot dice, got %d", dice)
+	}
+	if dice := defaultRuleset.diceLeftAfter(4, 4); dice != MAX_DICE {
+		t.Errorf("Expected hot dice to roll all dice again, got %d", dice)
+	}
+	if rules.scoringKey() == tableKey(t, RulesetClassic) {
+		t.Error("Expected hot dice to change the scoring of a turn")
+	}
+
+	for _, overrides := range []string{`{"threePairs":75}`, `{"openingScore":-50}`, `{"fourOfAKindAndPair":1225}`} {
+		if _, err := NewRuleset("", json.RawMessage(overrides)); !errors.Is(err, ErrInvalidRuleset) {
+			t.Errorf("Expected %s to be invalid, got %v", overrides, err)
+		}
+	}
+}
+
+func tableKey(t *testing.T, name string) string {
+	t.Helper()
+	rules, err := NewRuleset(name, nil)
+	if err != nil {
+		t.Fatalf("Failed to create ruleset: %v", err)
+	}
+	return rules.scoringKey()
+}
+
+// setupAdvancedGame starts a game played by the rules overridden
+func setupAdvancedGame(t *testing.T, players int, overrides string) (*testicles.TestHelper, []string, *GameState) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+	playerIds := setupStartedGame(helper, players)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+	rules, err := NewRuleset("", json.RawMessage(overrides))
+	if err != nil {
+		t.Fatalf("Failed to create ruleset: %v", err)
+	}
+	state := testRoom.State().(*GameState)
+	state.Ruleset = rules
+	state.TargetScore = rules.TargetScore
+	state.CurrentTurn = playerIds[0]
+	return helper, playerIds, state
+}
+
+func TestDiceGame_OpeningScore(t *testing.T) {
+	helper, playerIds, state := setupAdvancedGame(t, 2, `{"openingScore":500}`)
+	testRoom, _ := helper.GetRoom()
+	player := testRoom.Clients()[playerIds[0]]
+
+	state.Dice = []int{1, 2, 3, 4, 6, 2}
+	helper.SendMessage(playerIds[0], "select", interfaces.M{"diceIndex": 0})
+	if err := NewDiceGame().HandleMessage(player, testRoom, "set_aside", []byte(`{"endTurn":true}`)); !errors.Is(err, ErrBelowOpeningScore) {
+		t.Fatalf("Expected banking 100 to fail below the opening score, got %v", err)
+	}
+	if state.CurrentTurn != playerIds[0] || state.Players[playerIds[0]].Score != 0 {
+		t.Fatalf("Expected the turn to go on, got turn %s and score %d", state.CurrentTurn, state.Players[playerIds[0]].Score)
+	}
+
+	helper.SendMessage(playerIds[0], "set_aside", interfaces.M{"endTurn": false})
+	state.Dice = []int{1, 1, 1, 5, 3}
+	for idx := range 3 {
+		helper.SendMessage(playerIds[0], "select", interfaces.M{"diceIndex": idx})
+	}
+	helper.SendMessage(playerIds[0], "set_aside", interfaces.M{"endTurn": true})
+
+	if score := state.Players[playerIds[0]].Score; score != 1100 || state.CurrentTurn != playerIds[1] {
+		t.Errorf("Expected 1100 points on the board and the turn to pass on, got %d and turn %s", score, state.CurrentTurn)
+	}
+
+	// a bust leaves a player below the opening score off the board
+	state.Players[playerIds[1]].RoundScore = 400
+	NewDiceGame().EndTurn(testRoom, state)
+	if score := state.Players[playerIds[1]].Score; score != 0 {
+		t.Errorf("Expected 400 points below the opening score not to bank, got %d", score)
+	}
+}
+
+func TestDiceGame_HotDice(t *testing.T) {
+	for _, hotDice := range []bool{true, false} {
+		helper, playerIds, state := setupAdvancedGame(t, 2, fmt.Sprintf(`{"hotDice":%t}`, hotDice))
+
+		state.Dice = []int{1, 1, 1, 5, 5, 5}
+		for idx := range MAX_DICE {
+			helper.SendMessage(playerIds[0], "select", interfaces.M{"diceIndex": idx})
+		}
+		helper.SendMessage(playerIds[0], "set_aside", interfaces.M{"endTurn": false})
+
+		player := state.Players[playerIds[0]]
+		if hotDice && (state.CurrentTurn != playerIds[0] || player.RoundScore != 1500 || len(state.Dice) != MAX_DICE) {
+			t.Errorf("Expected hot dice to roll six dice again, got turn %s, round score %d and %d dice", state.CurrentTurn, player.RoundScore, len(state.Dice))
+		}
+		if !hotDice && (state.CurrentTurn != playerIds[1] || player.Score != 1500) {
+			t.Errorf("Expected the turn to end banking 1500 without hot dice, got turn %s and score %d", state.CurrentTurn, player.Score)
+		}
+	}
+}
+
+func TestDiceGame_FinalRound(t *testing.T) {
+	helper, playerIds, state := setupAdvancedGame(t, 3, `{"finalRound":true}`)
+	first, second, third := state.Players[playerIds[0]], state.Players[playerIds[1]], state.Players[playerIds[2]]
+	first.Score, second.Score, third.Score = 2900, 2950, 1000
+
+	bank := func(id string) {
+		state.Dice = []int{1, 2, 3, 4, 6, 2}
+		helper.SendMessage(id, "select", interfaces.M{"diceIndex": 0})
+		helper.SendMessage(id, "set_aside", interfaces.M{"endTurn": true})
+	}
+
+	bank(playerIds[0])
+	if !state.FinalRound || state.Winner != "" || !slices.Equal(state.FinalTurns, playerIds[1:]) || state.CurrentTurn != playerIds[1] {
+		t.Fatalf("Expected a final round for the other players, got final round %v with %v, winner %q", state.FinalRound, state.FinalTurns, state.Winner)
+	}
+
+	bank(playerIds[1])
+	if state.Winner != "" || state.CurrentTurn != playerIds[2] {
+		t.Fatalf("Expected the last player to get a final turn, got turn %s and winner %q", state.CurrentTurn, state.Winner)
+	}
+
+	bank(playerIds[2])
+	if state.Winner != second.Name || state.CurrentTurn != "" || len(state.FinalTurns) != 0 {
+		t.Errorf("Expected the highest score to win after the final round, got winner %q and turn %s", state.Winner, state.CurrentTurn)
+	}
+	if results := helper.GameResults(); len(results) != 1 {
+		t.Errorf("Expected 1 game result, got %d", len(results))
+	}
+}
+
+func TestDiceGame_FinalRoundEndsWhenTheLastPlayerLeaves(t *testing.T) {
+	helper, playerIds, state := setupAdvancedGame(t, 4, `{"finalRound":true}`)
+	testRoom, _ := helper.GetRoom()
+	state.Players[playerIds[0]].Score = 3000
+	state.FinalRound = true
+	state.FinalTurns = []string{playerIds[1], playerIds[2]}
+	state.CurrentTurn = playerIds[1]
+
+	g := NewDiceGame()
+	g.RemovePlayer(testRoom, state, playerIds[1])
+	if state.CurrentTurn != playerIds[2] || state.Winner != "" {
+		t.Fatalf("Expected the final turn to pass on, got turn %s and winner %q", state.CurrentTurn, state.Winner)
+	}
+	g.RemovePlayer(testRoom, state, playerIds[2])
+	if state.Winner != state.Players[playerIds[0]].Name || state.CurrentTurn != "" {
+		t.Errorf("Expected the game to end once nobody has a final turn left, got winner %q", state.Winner)
+	}
+}
@@ -0,0 +1,21 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"gameserver/games/dicegame/models"
+)
+
+// Database defines the methods required for database operations
+type Database interface {
+	StoreMatch(ctx context.Context, match models.Match) error
+	GetMatch(ctx context.Context, id string) (*models.Match, error)
+	GetPlayerMatches(ctx context.Context, playerID string, limit int) ([]models.PlayerMatch, error)
+	GetPlayerStats(ctx context.Context, playerID string) (*models.PlayerStats, error)
+	Close() error
+}
+
+var (
+	ErrNotConfigured = errors.New("DICEGAME_DATABASE_URL environment variable not set")
+	ErrMatchNotFound = errors.New("match not found")
+)
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"gameserver/games/dicegame/models"
+	"sync"
+)
+
+// DatabaseServiceMock provides a mock implementation for testing, it keeps the stored matches
+type DatabaseServiceMock struct {
+	mu      sync.Mutex
+	matches []models.Match
+}
+
+func (m *DatabaseServiceMock) StoreMatch(ctx context.Context, match models.Match) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.matches = append(m.matches, match)
+	return nil
+}
+
+func (m *DatabaseServiceMock) GetMatch(ctx context.Context, id string) (*models.Match, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, match := range m.matches {
+		if match.ID == id {
+			return &match, nil
+		}
+	}
+	return nil, ErrMatchNotFound
+}
+
+func (m *DatabaseServiceMock) GetPlayerMatches(ctx context.Context, playerID string, limit int) ([]models.PlayerMatch, error) {
+	return make([]models.PlayerMatch, 0), nil
+}
+
+func (m *DatabaseServiceMock) GetPlayerStats(ctx context.Context, playerID string) (*models.PlayerStats, error) {
+	return &models.PlayerStats{PlayerID: playerID}, nil
+}
+
+func (m *DatabaseServiceMock) Close() error {
+	return nil
+}
+
+// Matches returns the stored matches
+func (m *DatabaseServiceMock) Matches() []models.Match {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]models.Match(nil), m.matches...)
+}
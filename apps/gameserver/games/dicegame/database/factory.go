@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"gameserver/internal/database/sql"
+	"gameserver/internal/interfaces"
+	"github.com/rs/zerolog/log"
+	"os"
+)
+
+const (
+	matchesTable   = "dicegame_matches"
+	turnsTable     = "dicegame_turns"
+	standingsTable = "dicegame_standings"
+)
+
+// Factory creates and initializes database services
+type Factory struct {
+	env interfaces.Environment
+}
+
+// NewDatabaseFactory creates a new database factory
+func NewDatabaseFactory(env interfaces.Environment) *Factory {
+	return &Factory{
+		env: env,
+	}
+}
+
+// CreateDatabaseService connects to DICEGAME_DATABASE_URL (SQLite in development) and prepares the schema
+func (f *Factory) CreateDatabaseService(ctx context.Context) (*DatabaseService, error) {
+	dbURL := os.Getenv("DICEGAME_DATABASE_URL")
+	if dbURL == "" {
+		// Default to SQLite in development
+		if f.env == interfaces.Development {
+			dbURL = "file:./db.sqlite?cache=shared&mode=rwc"
+		} else {
+			return nil, ErrNotConfigured
+		}
+	}
+
+	opts := sql.WithAllowedTables([]string{matchesTable, turnsTable, standingsTable})
+	db, err := sql.New(ctx, dbURL, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := InitializeSchema(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	log.Info().Str("driver", db.Driver()).Msg("SQL database client initialized for dicegame")
+	return NewDatabaseService(db), nil
+}
+
+// InitializeSchema creates the match history tables if they don't exist
+func InitializeSchema(ctx context.Context, db sql.Database) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS dicegame_matches (
+			id TEXT PRIMARY KEY,
+			room_id TEXT NOT NULL,
+			ruleset TEXT NOT NULL,
+			target_score INTEGER NOT NULL,
+			winner TEXT NOT NULL,
+			turn_count INTEGER NOT NULL,
+			started_at TIMESTAMP NOT NULL,
+			finished_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS dicegame_turns (
+			id TEXT PRIMARY KEY,
+			match_id TEXT NOT NULL,
+			turn INTEGER NOT NULL,
+			player_id TEXT NOT NULL,
+			score INTEGER NOT NULL,
+			busted BOOLEAN NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_dicegame_turns_player ON dicegame_turns (player_id)`,
+		`CREATE INDEX IF NOT EXISTS idx_dicegame_turns_match ON dicegame_turns (match_id, turn)`,
+		`CREATE TABLE IF NOT EXISTS dicegame_standings (
+			id TEXT PRIMARY KEY,
+			match_id TEXT NOT NULL,
+			player_id TEXT NOT NULL,
+			account_id TEXT NOT NULL,
+			name TEXT NOT NULL,
+			placement INTEGER NOT NULL,
+			score INTEGER NOT NULL,
+			bot BOOLEAN NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_dicegame_standings_player ON dicegame_standings (player_id)`,
+	}
+
+	for _, statement := range statements {
+		if err := db.Exec(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gameserver/games/dicegame/models"
+	"gameserver/internal/database/sql"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DatabaseService handles database operations for the dicegame
+type DatabaseService struct {
+	db sql.Database
+}
+
+// NewDatabaseService creates a new instance of the database service on a database with the
+// match history schema (see InitializeSchema)
+func NewDatabaseService(db sql.Database) *DatabaseService {
+	return &DatabaseService{
+		db: db,
+	}
+}
+
+// StoreMatch stores a finished match with its turns and standings in one transaction
+func (s *DatabaseService) StoreMatch(ctx context.Context, match models.Match) error {
+	log.Info().Str("matchId", match.ID).Str("room", match.RoomID).Int("turns", len(match.Turns)).Msg("storing match")
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := tx.Create(ctx, matchesTable, &match.DBMatch); err != nil {
+		return fmt.Errorf("failed to store match: %w", err)
+	}
+	for _, turn := range match.Turns {
+		if err := tx.Create(ctx, turnsTable, &turn); err != nil {
+			return fmt.Errorf("failed to store turn: %w", err)
+		}
+	}
+	for _, standing := range match.Standings {
+		if err := tx.Create(ctx, standingsTable, &standing); err != nil {
+			return fmt.Errorf("failed to store standing: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetMatch retrieves a match with its turns in order and its standings by placement
+func (s *DatabaseService) GetMatch(ctx context.Context, id string) (*models.Match, error) {
+	match := models.Match{Turns: make([]models.DBTurn, 0), Standings: make([]models.DBStanding, 0)}
+	if err := s.db.Get(ctx, matchesTable, id, &match.DBMatch); err != nil {
+		if errors.Is(err, sql.ErrRecordNotFound) {
+			return nil, ErrMatchNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.db.Query(ctx, "SELECT * FROM dicegame_turns WHERE match_id = ? ORDER BY turn", &match.Turns, id); err != nil {
+		return nil, fmt.Errorf("failed to retrieve turns: %w", err)
+	}
+	if err := s.db.Query(ctx, "SELECT * FROM dicegame_standings WHERE match_id = ? ORDER BY placement, name", &match.Standings, id); err != nil {
+		return nil, fmt.Errorf("failed to retrieve standings: %w", err)
+	}
+	return &match, nil
+}
+
+// GetPlayerMatches retrieves the latest matches of a player with the player's standing
+func (s *DatabaseService) GetPlayerMatches(ctx context.Context, playerID string, limit int) ([]models.PlayerMatch, error) {
+	matches := make([]models.PlayerMatch, 0)
+	query := `SELECT m.*, s.placement, s.score
+		FROM dicegame_standings s
+		JOIN dicegame_matches m ON m.id = s.match_id
+		WHERE s.player_id = ?
+		ORDER BY m.finished_at DESC
+		LIMIT ?`
+	if err := s.db.Query(ctx, query, &matches, playerID, limit); err != nil {
+		return nil, fmt.Errorf("failed to retrieve matches: %w", err)
+	}
+	return matches, nil
+}
+
+// GetPlayerStats aggregates the matches and turns of a player, a player without any has zero stats
+func (s *DatabaseService) GetPlayerStats(ctx context.Context, playerID string) (*models.PlayerStats, error) {
+	var turns []struct {
+		Turns   int     `db:"turns"`
+		Busts   int     `db:"busts"`
+		Average float64 `db:"average"`
+		Highest int     `db:"highest"`
+	}
+	turnsQuery := `SELECT COUNT(*) AS turns,
+			COALESCE(SUM(CASE WHEN busted THEN 1 ELSE 0 END), 0) AS busts,
+			COALESCE(AVG(score), 0) AS average,
+			COALESCE(MAX(score), 0) AS highest
+		FROM dicegame_turns WHERE player_id = ?`
+	if err := s.db.Query(ctx, turnsQuery, &turns, playerID); err != nil {
+		return nil, fmt.Errorf("failed to aggregate turns: %w", err)
+	}
+
+	var matches []struct {
+		Matches int `db:"matches"`
+		Wins    int `db:"wins"`
+	}
+	matchesQuery := `SELECT COUNT(*) AS matches,
+			COALESCE(SUM(CASE WHEN placement = 1 THEN 1 ELSE 0 END), 0) AS wins
+		FROM dicegame_standings WHERE player_id = ?`
+	if err := s.db.Query(ctx, matchesQuery, &matches, playerID); err != nil {
+		return nil, fmt.Errorf("failed to aggregate matches: %w", err)
+	}
+
+	stats := &models.PlayerStats{PlayerID: playerID}
+	if len(turns) > 0 {
+		stats.Turns = turns[0].Turns
+		stats.Busts = turns[0].Busts
+		stats.AverageTurnScore = turns[0].Average
+		stats.HighestTurn = turns[0].Highest
+	}
+	if len(matches) > 0 {
+		stats.Matches = matches[0].Matches
+		stats.Wins = matches[0].Wins
+	}
+	if stats.Turns > 0 {
+		stats.BustRate = float64(stats.Busts) / float64(stats.Turns)
+	}
+	return stats, nil
+}
+
+// Close closes the database connection
+func (s *DatabaseService) Close() error {
+	return s.db.Close()
+}
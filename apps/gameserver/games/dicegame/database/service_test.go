@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gameserver/games/dicegame/models"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDatabaseService_StoreMatchAndGetStats(t *testing.T) {
+	// Use in-memory SQLite for testing
+	os.Setenv("DICEGAME_DATABASE_URL", "file:dicegame_service_test?mode=memory&cache=shared")
+	defer os.Unsetenv("DICEGAME_DATABASE_URL")
+
+	ctx := context.Background()
+	service, err := NewDatabaseFactory("development").CreateDatabaseService(ctx)
+	if err != nil {
+		t.Fatalf("Failed to create database service: %v", err)
+	}
+	defer service.Close()
+
+	finished := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	newMatch := func(id string, winner string, turns []models.DBTurn, standings []models.DBStanding) models.Match {
+		for i := range turns {
+			turns[i].MatchID = id
+			turns[i].ID = fmt.Sprintf("%s:%d", id, turns[i].Turn)
+		}
+		for i := range standings {
+			standings[i].MatchID = id
+			standings[i].ID = id + ":" + standings[i].PlayerID
+		}
+		return models.Match{
+			DBMatch: models.DBMatch{
+				ID: id, RoomID: "room-" + id, Ruleset: "kingdom_come", TargetScore: 3000, Winner: winner,
+				TurnCount: len(turns), StartedAt: finished.Add(-time.Hour), FinishedAt: finished,
+			},
+			Turns:     turns,
+			Standings: standings,
+		}
+	}
+
+	first := newMatch("match-1", "Alice", []models.DBTurn{
+		{Turn: 1, PlayerID: "alice", Score: 500},
+		{Turn: 2, PlayerID: "bob", Score: 0, Busted: true},
+		{Turn: 3, PlayerID: "alice", Score: 1200},
+		{Turn: 4, PlayerID: "bob", Score: 350},
+	}, []models.DBStanding{
+		{PlayerID: "alice", AccountID: "alice", Name: "Alice", Placement: 1, Score: 1700},
+		{PlayerID: "bob", Name: "Bob", Placement: 2, Score: 350},
+	})
+	second := newMatch("match-2", "Bob", []models.DBTurn{
+		{Turn: 1, PlayerID: "alice", Score: 0, Busted: true},
+		{Turn: 2, PlayerID: "bob", Score: 3000},
+	}, []models.DBStanding{
+		{PlayerID: "alice", AccountID: "alice", Name: "Alice", Placement: 2, Score: 0},
+		{PlayerID: "bob", Name: "Bob", Placement: 1, Score: 3000},
+	})
+	second.FinishedAt = finished.Add(time.Hour)
+
+	for _, match := range []models.Match{first, second} {
+		if err := service.StoreMatch(ctx, match); err != nil {
+			t.Fatalf("Failed to store match: %v", err)
+		}
+	}
+
+	stats, err := service.GetPlayerStats(ctx, "alice")
+	if err != nil {
+		t.Fatalf("Failed to get stats: %v", err)
+	}
+	if stats.Matches != 2 || stats.Wins != 1 || stats.Turns != 3 || stats.Busts != 1 || stats.HighestTurn != 1200 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+	if math.Abs(stats.BustRate-1.0/3) > 1e-9 || math.Abs(stats.AverageTurnScore-1700.0/3) > 1e-9 {
+		t.Errorf("Expected a bust rate of 1/3 and an average of 566.67, got %v and %v", stats.BustRate, stats.AverageTurnScore)
+	}
+
+	if stats, err := service.GetPlayerStats(ctx, "nobody"); err != nil || stats.Turns != 0 || stats.BustRate != 0 {
+		t.Errorf("Expected zero stats for an unknown player, got %+v (%v)", stats, err)
+	}
+
+	matches, err := service.GetPlayerMatches(ctx, "bob", 10)
+	if err != nil {
+		t.Fatalf("Failed to get matches: %v", err)
+	}
+	if len(matches) != 2 || matches[0].ID != "match-2" || matches[0].Placement != 1 || matches[1].Score != 350 {
+		t.Errorf("Expected the latest match first with bob's standing, got %+v", matches)
+	}
+
+	match, err := service.GetMatch(ctx, "match-1")
+	if err != nil {
+		t.Fatalf("Failed to get match: %v", err)
+	}
+	if match.Winner != "Alice" || len(match.Turns) != 4 || !match.Turns[1].Busted || match.Standings[0].PlayerID != "alice" {
+		t.Errorf("Unexpected match: %+v", match)
+	}
+	if _, err := service.GetMatch(ctx, "match-3"); !errors.Is(err, ErrMatchNotFound) {
+		t.Errorf("Expected an unknown match not to be found, got %v", err)
+	}
+}
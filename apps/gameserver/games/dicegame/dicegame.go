@@ -1,31 +1,55 @@
 package dicegame
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"gameserver/games/dicegame/database"
+	"gameserver/games/dicegame/models"
+	"gameserver/internal/client"
+	"gameserver/internal/clock"
 	"gameserver/internal/interfaces"
+	"gameserver/internal/rematch"
+	"gameserver/internal/rng"
 	"maps"
-	"math/rand"
 	"slices"
-	"sort"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/rs/zerolog/log"
 )
 
 const MAX_DICE = 6
 
-type DiceGame struct{}
+const (
+	MinPlayers = 2 // players the host needs to start a game
+	MaxPlayers = 6
+)
+
+type DiceGame struct {
+	registry   interfaces.GameRegistry
+	strategies *client.StrategyRegistry[Strategy]
+	db         database.Database // stores finished matches, nil without a match history
+}
 
 type Player struct {
 	ID         string `json:"id"`
+	AccountID  string `json:"accountId,omitempty"`
 	Name       string `json:"name"`
 	Score      int    `json:"score"`
 	TurnScore  int    `json:"turnScore"`
 	RoundScore int    `json:"roundScore"`
+	Ready      bool   `json:"ready"` // ready to start, only relevant in the lobby
+	Bot        bool   `json:"bot,omitempty"`
+
+	BotControlled bool `json:"botControlled,omitempty"` // set while a bot plays for the disconnected player
 }
 
 type GameState struct {
 	Players      map[string]*Player `json:"players"`
+	PlayerOrder  []string           `json:"playerOrder"` // the turn order, players in the order they joined
+	Host         string             `json:"host"`        // the player who starts the game, the first human in order
 	Started      bool               `json:"started"`
 	CurrentTurn  string             `json:"currentTurn"`
 	Winner       string             `json:"winner"` // the winners name
@@ -33,6 +57,43 @@ type GameState struct {
 	SelectedDice []int              `json:"selectedDice"`
 	SetAside     []int              `json:"setAside"`
 	TargetScore  int                `json:"targetScore"`
+	Ruleset      *Ruleset           `json:"ruleset"` // the scoring rules, TargetScore is the target score of it
+	// FinalRound is set once a player reached the target score with the final round rule,
+	// FinalTurns are the players that still get their last turn, the current one first
+	FinalRound bool     `json:"finalRound"`
+	FinalTurns []string `json:"finalTurns,omitempty"`
+	// Scoring breaks down the rolled dice, SelectedScoring the selected ones, both by indexes into Dice
+	Scoring         *Scoring `json:"scoring,omitempty"`
+	SelectedScoring *Scoring `json:"selectedScoring,omitempty"`
+	// Series is the score of the games played in the room and the votes for a rematch
+	Series *rematch.Series `json:"series"`
+
+	rng           rng.RNG
+	clock         clock.Clock // the room clock, see interfaces.Room
+	startedAt     time.Time
+	takeoverGrace time.Duration
+	busted        bool         // the current turn busted and ends once the animation played
+	turns         []turnRecord // the turns played, stored with the match
+}
+
+// turnRecord is a played turn, score are the points banked
+type turnRecord struct {
+	playerID string // see historyID
+	score    int
+	busted   bool
+}
+
+// RoomOptions are the options a room can be created with
+type RoomOptions struct {
+	// Ruleset names the ruleset to play by, see RulesetNames
+	Ruleset string `json:"ruleset,omitempty"`
+	// Rules override single rules of the ruleset, see NewRuleset
+	Rules json.RawMessage `json:"rules,omitempty"`
+	// TargetScore overrides the target score of the ruleset
+	TargetScore int `json:"targetScore,omitempty"`
+	// BestOf is the number of games of a series of rematches, 0 plays on without end
+	BestOf int `json:"bestOf,omitempty"`
+	interfaces.TakeoverOptions
 }
 
 type SelectActionPayload struct {
@@ -43,179 +104,304 @@ type SetAsideActionPayload struct {
 	EndTurn bool `json:"endTurn"`
 }
 
+// ReadyActionPayload is sent in the lobby to get ready to start, an empty payload means ready
+type ReadyActionPayload struct {
+	Ready bool `json:"ready"`
+}
+
+// rules returns the ruleset of the room, the default one for states created without
+func (state *GameState) rules() *Ruleset {
+	if state.Ruleset == nil {
+		return defaultRuleset
+	}
+	return state.Ruleset
+}
+
 func (g *DiceGame) AddPlayer(id string, name string, state *GameState) {
 	state.Players[id] = &Player{
 		ID:    id,
 		Name:  name,
 		Score: 0,
 	}
+	state.PlayerOrder = append(state.PlayerOrder, id)
+	updateHost(state)
 }
 
-func (g *DiceGame) RollDice(state *GameState) {
-	for i := range state.Dice {
-		state.Dice[i] = rand.Intn(MAX_DICE) + 1
+// RemovePlayer takes a player who left out of the game. The turn of the player passes on in
+// order, the last player left in a running game wins.
+func (g *DiceGame) RemovePlayer(room interfaces.Room, state *GameState, id string) {
+	idx := slices.Index(state.PlayerOrder, id)
+	if idx < 0 {
+		return
+	}
+	delete(state.Players, id)
+	state.PlayerOrder = slices.Delete(state.PlayerOrder, idx, idx+1)
+	updateHost(state)
+
+	if state.Winner != "" {
+		state.Series.Leave(id)
+		return
+	}
+	if !state.Started {
+		return
+	}
+	if len(state.PlayerOrder) < MinPlayers {
+		log.Info().Str("clientId", id).Msg("not enough players left, ending game")
+		state.CurrentTurn = ""
+		if len(state.PlayerOrder) > 0 {
+			winner := state.Players[state.PlayerOrder[0]]
+			state.Winner = winner.Name
+			g.gameOver(room, state, winner)
+		}
+		return
+	}
+	if state.FinalRound {
+		state.FinalTurns = slices.DeleteFunc(state.FinalTurns, func(other string) bool { return other == id })
+		if len(state.FinalTurns) == 0 {
+			g.finish(room, state)
+			return
+		}
+		if state.CurrentTurn == id {
+			resetTurn(state)
+			state.CurrentTurn = state.FinalTurns[0]
+		}
+		return
+	}
+	if state.CurrentTurn == id {
+		resetTurn(state)
+		// the player after the one who left moved up to its index
+		state.CurrentTurn = state.PlayerOrder[idx%len(state.PlayerOrder)]
 	}
 }
 
-func (g *DiceGame) CalculateScore(dice []int) (int, bool) {
-	if len(dice) == 0 {
-		return 0, false
+// updateHost makes the first human in order the host, bots can't start games
+func updateHost(state *GameState) {
+	state.Host = ""
+	for _, id := range state.PlayerOrder {
+		if !state.Players[id].Bot {
+			state.Host = id
+			return
+		}
 	}
+}
 
-	// Sort dice for easier combination checking
-	sort.Ints(dice)
-	log.Debug().Ints("dice", dice).Msg("Calculating score for dice")
+// nextPlayer returns the player after id in turn order
+func nextPlayer(state *GameState, id string) string {
+	idx := slices.Index(state.PlayerOrder, id)
+	return state.PlayerOrder[(idx+1)%len(state.PlayerOrder)]
+}
 
-	score := 0
-	usedDiceCount := 0
+// resetTurn clears the dice for the next turn
+func resetTurn(state *GameState) {
+	state.SetAside = make([]int, 0)
+	state.Dice = make([]int, MAX_DICE) // we count the amount of dice, this initializes the dice to 6 x 0
+	state.SelectedDice = make([]int, 0)
+	state.Scoring = nil
+	state.SelectedScoring = nil
+	state.busted = false
+}
 
-	// Make a copy of dice that we can modify
-	remainingDice := make([]int, len(dice))
-	copy(remainingDice, dice)
+func (g *DiceGame) RollDice(state *GameState) {
+	for i := range state.Dice {
+		state.Dice[i] = state.rng.Intn(MAX_DICE) + 1
+	}
+}
 
-	// Check for runs first
-	if len(remainingDice) >= 6 && containsRun(remainingDice, 1, 6) {
-		score = 1500
-		usedDiceCount += 6
-		remainingDice = removeRun(remainingDice, 1, 6)
-		log.Debug().Msg("Found 1-6 run: +1500")
-	} else if len(remainingDice) >= 5 && containsRun(remainingDice, 1, 5) {
-		score += 500
-		usedDiceCount += 5
-		remainingDice = removeRun(remainingDice, 1, 5)
-		log.Debug().Msg("Found 1-5 run: +500")
-	} else if len(remainingDice) >= 5 && containsRun(remainingDice, 2, 6) {
-		score += 750
-		usedDiceCount += 5
-		remainingDice = removeRun(remainingDice, 2, 6)
-		log.Debug().Msg("Found 2-6 run: +750")
+func (g *DiceGame) EndTurn(room interfaces.Room, state *GameState) {
+	player, exists := state.Players[state.CurrentTurn]
+	if !exists {
+		log.Warn().Str("currentTurn", state.CurrentTurn).Msg("no player to end the turn of")
+		return
 	}
+	log.Info().Str("currentPlayer", player.Name).Msg("ending turn")
 
-	// Count occurrences for remaining dice
-	counts := make(map[int]int)
-	for _, die := range remainingDice {
-		counts[die]++
+	rules := state.rules()
+	if !rules.canBank(player.Score, player.RoundScore) {
+		log.Info().Str("currentPlayer", player.Name).Int("roundScore", player.RoundScore).Msg("round score is below the opening score")
+		player.RoundScore = 0
 	}
-	log.Debug().Interface("counts", counts).Msg("Dice counts")
+	state.turns = append(state.turns, turnRecord{playerID: historyID(player), score: player.RoundScore, busted: state.busted})
 
-	// Check for three of a kind and beyond
-	for num, count := range counts {
-		if count >= 3 {
-			baseScore := num * 100
-			if num == 1 {
-				baseScore = 1000
-			}
-			// Double score for each additional die beyond three
-			for i := 3; i < count; i++ {
-				baseScore *= 2
-			}
-			score += baseScore
-			usedDiceCount += count
-			// Remove these dice from further consideration
-			counts[num] = 0
-			log.Debug().Int("num", num).Int("count", count).Int("baseScore", baseScore).Msg("Found three or more of a kind")
+	// Add turn score to player's total score
+	player.Score += player.RoundScore
+	player.TurnScore = 0
+	player.RoundScore = 0
+
+	// Reset turn-specific variables
+	resetTurn(state)
+
+	if state.FinalRound {
+		state.FinalTurns = slices.DeleteFunc(state.FinalTurns, func(id string) bool { return id == player.ID })
+		if len(state.FinalTurns) == 0 {
+			g.finish(room, state)
+			return
 		}
+		state.CurrentTurn = state.FinalTurns[0]
+		return
 	}
 
-	// Check for individual 1s and 5s from remaining dice
-	if counts[1] > 0 {
-		score += counts[1] * 100
-		usedDiceCount += counts[1]
-		log.Debug().Int("count", counts[1]).Msg("Found ones: +100 each")
-	}
+	if player.Score >= state.TargetScore {
+		if !rules.FinalRound {
+			// game is over
+			state.Winner = player.Name
+			state.CurrentTurn = ""
+			g.gameOver(room, state, player)
+			return
+		}
 
-	if counts[5] > 0 {
-		score += counts[5] * 50
-		usedDiceCount += counts[5]
-		log.Debug().Int("count", counts[5]).Msg("Found fives: +50 each")
+		// everyone after the player gets a last turn, in order
+		log.Info().Str("currentPlayer", player.Name).Msg("reached the target score, starting the final round")
+		state.FinalRound = true
+		idx := slices.Index(state.PlayerOrder, player.ID)
+		state.FinalTurns = append(slices.Clone(state.PlayerOrder[idx+1:]), state.PlayerOrder[:idx]...)
 	}
 
-	// Check if all dice are used in valid combinations
-	valid := usedDiceCount == len(dice)
+	// Switch to next player
+	newPlayerId := nextPlayer(state, state.CurrentTurn)
+	log.Info().Msgf("Switching turn from %s to %s", state.CurrentTurn, newPlayerId)
+	state.CurrentTurn = newPlayerId
+}
 
-	log.Info().Int("final_score", score).Bool("valid", valid).Msg("Final score calculation")
-	return score, valid
+// finish ends the final round, the player with the highest score wins. Ties go to the player
+// earlier in turn order.
+func (g *DiceGame) finish(room interfaces.Room, state *GameState) {
+	var winner *Player
+	for _, id := range state.PlayerOrder {
+		if player := state.Players[id]; winner == nil || player.Score > winner.Score {
+			winner = player
+		}
+	}
+	state.FinalTurns = nil
+	state.CurrentTurn = ""
+	if winner != nil {
+		state.Winner = winner.Name
+	}
+	g.gameOver(room, state, winner)
 }
 
-func containsRun(dice []int, start, end int) bool {
-	if len(dice) < end-start+1 {
-		return false
+// leadingScore returns the highest banked score
+func leadingScore(state *GameState) int {
+	leading := 0
+	for _, player := range state.Players {
+		leading = max(leading, player.Score)
 	}
+	return leading
+}
 
-	// Create a map to track found numbers
-	found := make(map[int]bool)
-	for _, die := range dice {
-		if die >= start && die <= end {
-			found[die] = true
-		}
+// gameOver counts the finished game in the series, reports it and stores it in the match history
+func (g *DiceGame) gameOver(room interfaces.Room, state *GameState, winner *Player) {
+	if winner != nil {
+		state.Series.Record(winner.ID)
+	} else {
+		state.Series.Record("")
 	}
+	g.reportResult(room, state)
+	g.storeMatch(room, state)
+}
 
-	// Check if all numbers in the range are present
-	for i := start; i <= end; i++ {
-		if !found[i] {
-			return false
+// placement returns the place of a player, players are placed by their score
+func placement(state *GameState, player *Player) int {
+	placement := 1
+	for _, other := range state.Players {
+		if other.Score > player.Score {
+			placement++
 		}
 	}
-	return true
+	return placement
 }
 
-// Helper function to remove run dice from the slice
-func removeRun(dice []int, start, end int) []int {
-	result := make([]int, 0)
-	runDice := make(map[int]bool)
-
-	for i := start; i <= end; i++ {
-		runDice[i] = true
+// historyID identifies a player in the match history, by account or the client ID for guests
+func historyID(player *Player) string {
+	if player.AccountID != "" {
+		return player.AccountID
 	}
+	return player.ID
+}
 
-	// Add one occurrence of each number in the run
-	usedRun := make(map[int]bool)
+// reportResult reports the finished game, players are placed by their score
+func (g *DiceGame) reportResult(room interfaces.Room, state *GameState) {
+	if g.registry == nil {
+		return
+	}
 
-	for _, die := range dice {
-		if runDice[die] && !usedRun[die] {
-			usedRun[die] = true
-			continue
-		}
-		result = append(result, die)
+	clients := room.Clients()
+	participants := make([]interfaces.Participant, 0, len(state.Players))
+	for _, id := range slices.Sorted(maps.Keys(state.Players)) {
+		player := state.Players[id]
+		client, connected := clients[id]
+		participants = append(participants, interfaces.Participant{
+			ClientID:  id,
+			AccountID: player.AccountID,
+			Name:      player.Name,
+			Bot:       connected && client.IsBot(),
+			Placement: placement(state, player),
+			Score:     float64(player.Score),
+		})
 	}
 
-	return result
+	g.registry.ReportResult(room, interfaces.GameResult{
+		StartedAt:    state.startedAt,
+		Participants: participants,
+		Metadata:     interfaces.M{"targetScore": state.TargetScore, "ruleset": state.rules().Name},
+	})
 }
 
-func (g *DiceGame) EndTurn(room interfaces.Room, state *GameState) {
-	log.Info().Str("currentPlayer", state.Players[state.CurrentTurn].Name).Msg("ending turn")
-	// Add turn score to player's total score
-	if player, exists := state.Players[state.CurrentTurn]; exists {
-		player.Score += player.RoundScore
-		player.TurnScore = 0
-		player.RoundScore = 0
-
-		if player.Score >= state.TargetScore {
-			// game is over
-			state.Winner = player.Name
-			state.CurrentTurn = ""
-			return
-		}
+// storeMatch stores the finished match with its turns and the standings of the players still in it.
+// The match is built from the state right away and written in the background, a slow database
+// doesn't hold up the room.
+func (g *DiceGame) storeMatch(room interfaces.Room, state *GameState) {
+	if g.db == nil {
+		return
+	}
 
+	matchID := uuid.New().String()
+	match := models.Match{
+		DBMatch: models.DBMatch{
+			ID:          matchID,
+			RoomID:      room.ID(),
+			Ruleset:     state.rules().Name,
+			TargetScore: state.TargetScore,
+			Winner:      state.Winner,
+			TurnCount:   len(state.turns),
+			StartedAt:   state.startedAt,
+			FinishedAt:  room.Clock().Now(),
+		},
+		Turns:     make([]models.DBTurn, 0, len(state.turns)),
+		Standings: make([]models.DBStanding, 0, len(state.Players)),
 	}
 
-	// Reset turn-specific variables
-	state.SetAside = make([]int, 0)
-	state.Dice = make([]int, MAX_DICE) // we count the amount of dice, this initializes the dice to 6 x 0
-	state.SelectedDice = make([]int, 0)
+	for _, id := range state.PlayerOrder {
+		player := state.Players[id]
+		match.Standings = append(match.Standings, models.DBStanding{
+			ID:        matchID + ":" + historyID(player),
+			MatchID:   matchID,
+			PlayerID:  historyID(player),
+			AccountID: player.AccountID,
+			Name:      player.Name,
+			Placement: placement(state, player),
+			Score:     player.Score,
+			Bot:       player.Bot,
+		})
+	}
+	for i, turn := range state.turns {
+		match.Turns = append(match.Turns, models.DBTurn{
+			ID:       fmt.Sprintf("%s:%d", matchID, i+1),
+			MatchID:  matchID,
+			Turn:     i + 1,
+			PlayerID: turn.playerID,
+			Score:    turn.score,
+			Busted:   turn.busted,
+		})
+	}
 
-	// Switch to next player
-	players := slices.Collect(maps.Values(state.Players))
-	for idx, player := range players {
-		if player.ID == state.CurrentTurn {
-			nextIndex := (idx + 1) % len(state.Players)
-			newPlayerId := players[nextIndex].ID
-
-			log.Info().Msgf("Switching turn from %s to %s", state.CurrentTurn, newPlayerId)
-			state.CurrentTurn = newPlayerId
-			break
+	roomID := room.ID()
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := g.db.StoreMatch(ctx, match); err != nil {
+			log.Error().Err(err).Str("room", roomID).Msg("failed to store match")
 		}
-	}
+	}()
 }
 
 func (g *DiceGame) handleRoll(room interfaces.Room) bool {
@@ -225,17 +411,24 @@ func (g *DiceGame) handleRoll(room interfaces.Room) bool {
 	state := room.State().(*GameState)
 	// reset state
 	state.SelectedDice = make([]int, 0)
+	state.SelectedScoring = nil
 
 	if len(state.Dice) == 0 {
 		log.Warn().Msg("dice pool is empty. resetting dice")
 		state.Dice = make([]int, MAX_DICE)
 	}
 	g.RollDice(state)
-	score, valid := g.CalculateScore(state.Dice)
+	// the dice are shown in order, the scoring refers to them by index
+	slices.Sort(state.Dice)
+	scoring := state.rules().Score(state.Dice)
+	state.Scoring = &scoring
+	log.Debug().Ints("dice", state.Dice).Any("combinations", scoring.Combinations).Msg("rolled dice")
+
 	// the first roll can be invalid but still be scoreable
-	if score == 0 && !valid {
+	if scoring.Score == 0 && !scoring.Valid {
 		busted = true
 	}
+	state.busted = busted
 
 	room.SetState(state)
 	return busted
@@ -290,7 +483,7 @@ func (g *DiceGame) handleSelect(room interfaces.Room, payload SelectActionPayloa
 		tempSelected = append(tempSelected, payload.DiceIndex)
 	}
 
-	score := 0
+	var scoring *Scoring
 	// if we have a selection, calculate new selected score
 	if len(tempSelected) > 0 {
 		selectedDice, err := g.getSelectedDiceFromIndexs(tempSelected, state.Dice)
@@ -298,14 +491,20 @@ func (g *DiceGame) handleSelect(room interfaces.Room, payload SelectActionPayloa
 			return err
 		}
 
-		score, _ = g.CalculateScore(selectedDice)
+		// the selected dice are scored in selection order, the breakdown refers to the roll
+		selected := state.rules().Score(selectedDice).remap(tempSelected)
+		scoring = &selected
 
-		log.Debug().Str("room", room.ID()).Int("score", score).Ints("selectedDice", selectedDice).Msg("selected dice")
+		log.Debug().Str("room", room.ID()).Int("score", selected.Score).Ints("selectedDice", selectedDice).Msg("selected dice")
 	}
 
 	state.SelectedDice = tempSelected
+	state.SelectedScoring = scoring
 	if player, exists := state.Players[state.CurrentTurn]; exists {
-		player.TurnScore = score
+		player.TurnScore = 0
+		if scoring != nil {
+			player.TurnScore = scoring.Score
+		}
 	}
 
 	room.SetState(state)
@@ -322,7 +521,8 @@ func (g *DiceGame) handleSetAside(room interfaces.Room, endTurn bool) error {
 		return err
 	}
 
-	selectedScore, valid := g.CalculateScore(selectedDice)
+	rules := state.rules()
+	selectedScore, valid := rules.CalculateScore(selectedDice)
 	if !valid {
 		return errors.New("invalid dice score")
 	}
@@ -331,6 +531,9 @@ func (g *DiceGame) handleSetAside(room interfaces.Room, endTurn bool) error {
 	if !exists {
 		return fmt.Errorf("failed to get current player: %s", state.CurrentTurn)
 	}
+	if endTurn && !rules.canBank(currentPlayer.Score, currentPlayer.RoundScore+selectedScore) {
+		return ErrBelowOpeningScore
+	}
 
 	if !endTurn {
 		// Move selected dice to setAside
@@ -338,8 +541,11 @@ func (g *DiceGame) handleSetAside(room interfaces.Room, endTurn bool) error {
 			state.SetAside = append(state.SetAside, dice)
 		}
 
-		// reset when all dice were successfully played
-		if MAX_DICE == len(state.SetAside) {
+		// reset when all dice were successfully played, the turn ends without hot dice
+		if MAX_DICE == len(state.SetAside) && !rules.HotDice {
+			log.Debug().Msg("all dice were successfully played without hot dice. ending turn")
+			endTurn = true
+		} else if MAX_DICE == len(state.SetAside) {
 			log.Debug().Msg("all dice were successfully played. resetting dice")
 			state.SetAside = make([]int, 0)
 			state.Dice = make([]int, MAX_DICE)
@@ -354,6 +560,8 @@ func (g *DiceGame) handleSetAside(room interfaces.Room, endTurn bool) error {
 	currentPlayer.TurnScore = 0
 	currentPlayer.RoundScore += selectedScore
 	state.SelectedDice = make([]int, 0)
+	state.Scoring = nil
+	state.SelectedScoring = nil
 
 	// TODO: align with busted endTurn logic
 	if endTurn {
@@ -390,15 +598,59 @@ func (g *DiceGame) RemoveDice(dice []int, diceIndxes []int) []int {
 	return newDice
 }
 
+// handleReady marks a player in the lobby as ready to start or not
+func (g *DiceGame) handleReady(client interfaces.Client, state *GameState, payload []byte) error {
+	action := ReadyActionPayload{Ready: true}
+	if len(payload) > 0 && string(payload) != "null" {
+		if err := json.Unmarshal(payload, &action); err != nil {
+			return ErrReadyPayloadInvalid
+		}
+	}
+	if state.Started {
+		return ErrGameStarted
+	}
+	player, exists := state.Players[client.ID()]
+	if !exists {
+		return ErrNotAPlayer
+	}
+
+	log.Debug().Str("clientId", client.ID()).Bool("ready", action.Ready).Msg("player sends ready")
+	player.Ready = action.Ready
+	return nil
+}
+
+// handleStart lets the host start the game once enough players joined and everyone is ready
+func (g *DiceGame) handleStart(client interfaces.Client, state *GameState) error {
+	if state.Started {
+		return ErrGameStarted
+	}
+	if client.ID() != state.Host {
+		return ErrNotHost
+	}
+	if len(state.PlayerOrder) < MinPlayers {
+		return ErrNotEnoughPlayers
+	}
+	for _, player := range state.Players {
+		if !player.Ready {
+			return ErrPlayersNotReady
+		}
+	}
+
+	g.start(state)
+	return nil
+}
+
 func (g *DiceGame) start(state *GameState) {
 	state.Started = true
-
-	// Randomly select the starting player
-	playerIDs := make([]string, 0, len(state.Players))
-	for id := range state.Players {
-		playerIDs = append(playerIDs, id)
+	state.startedAt = state.clock.Now()
+	resetTurn(state)
+	for _, player := range state.Players {
+		player.Ready = false
 	}
-	state.CurrentTurn = playerIDs[rand.Intn(len(playerIDs))]
+
+	// Randomly select the starting player, the order is stable so a seeded rng picks the same one
+	state.CurrentTurn = state.PlayerOrder[state.rng.Intn(len(state.PlayerOrder))]
+	state.Series.Start(state.CurrentTurn)
 	// Current player for debugging with bots
 	//for _, player := range state.Players {
 	//	log.Debug().Str("name", player.Name).Msg("SEE ME")
@@ -407,3 +659,62 @@ func (g *DiceGame) start(state *GameState) {
 	//	}
 	//}
 }
+
+// handleRematch toggles the rematch vote of a player once the game is over and reports whether
+// everyone agreed and the rematch started
+func (g *DiceGame) handleRematch(client interfaces.Client, state *GameState) (bool, error) {
+	if state.Winner == "" {
+		return false, ErrGameNotOver
+	}
+	if _, exists := state.Players[client.ID()]; !exists {
+		return false, ErrNotAPlayer
+	}
+	if len(state.PlayerOrder) < MinPlayers {
+		return false, ErrNotEnoughPlayers
+	}
+
+	wants := state.Series.Vote(client.ID())
+	log.Debug().Str("clientId", client.ID()).Bool("rematch", wants).Msg("player votes for a rematch")
+	return g.rematchIfAgreed(state), nil
+}
+
+// rematchIfAgreed starts the rematch once every player at the table voted for it, bots and
+// the players they took over for always agree
+func (g *DiceGame) rematchIfAgreed(state *GameState) bool {
+	if state.Winner == "" || len(state.PlayerOrder) < MinPlayers {
+		return false
+	}
+	voters := make([]string, 0, len(state.PlayerOrder))
+	for _, id := range state.PlayerOrder {
+		if player := state.Players[id]; !player.Bot && !player.BotControlled {
+			voters = append(voters, id)
+		}
+	}
+	if !state.Series.Agreed(voters) {
+		return false
+	}
+
+	g.rematch(state)
+	return true
+}
+
+// rematch restarts a finished game with the same players in the same order, the player after
+// the last starter starts
+func (g *DiceGame) rematch(state *GameState) {
+	log.Info().Int("games", state.Series.Games).Msg("starting a rematch")
+	for _, player := range state.Players {
+		player.Score = 0
+		player.TurnScore = 0
+		player.RoundScore = 0
+		player.Ready = false
+	}
+	state.Winner = ""
+	state.FinalRound = false
+	state.FinalTurns = nil
+	state.turns = nil
+	state.startedAt = state.clock.Now()
+	resetTurn(state)
+
+	state.CurrentTurn = state.Series.NextStarter(state.PlayerOrder)
+	state.Series.Start(state.CurrentTurn)
+}
@@ -17,20 +17,28 @@ const BOT_DELAY = 2000 // 2 second delay for bot actions
 
 type DiceGameBot struct {
 	*client.BotClient
-	game                *DiceGame
-	myTurn              bool
-	busted              bool
-	combinationToSelect []int
+	game     *DiceGame
+	strategy Strategy
+	myTurn   bool
+	busted   bool
+	// selection holds the dice indexes the strategy chose to set aside from the current roll
+	selection []int
 }
 
-func NewDiceGameBot(game *DiceGame, reg interfaces.GameRegistry) *DiceGameBot {
+func NewDiceGameBot(game *DiceGame, reg interfaces.GameRegistry, strategy Strategy) *DiceGameBot {
 	id := uuid.New().String() // create random id
+	return newDiceGameBot(id, game, reg, strategy)
+}
+
+// newDiceGameBot creates a bot with the given id, takeover bots play under the id of the seat
+func newDiceGameBot(id string, game *DiceGame, reg interfaces.GameRegistry, strategy Strategy) *DiceGameBot {
 	bot := &DiceGameBot{
-		BotClient:           client.NewBotClient(id, reg),
-		game:                game,
-		myTurn:              false,
-		busted:              false,
-		combinationToSelect: make([]int, 0),
+		BotClient: client.NewBotClient(id, reg),
+		game:      game,
+		strategy:  strategy,
+		myTurn:    false,
+		busted:    false,
+		selection: make([]int, 0),
 	}
 	bot.SetMessageHandler(bot.handleMessage)
 	return bot
@@ -94,9 +102,18 @@ func (b *DiceGameBot) getGameState(message *protocol.Response) (*GameState, bool
 	return gameState, ok
 }
 
+// makeNextMove moves after a small delay on the room clock to simulate thinking
 func (b *DiceGameBot) makeNextMove(state *GameState) {
-	// Add a small delay to simulate thinking
-	time.Sleep(BOT_DELAY * time.Millisecond)
+	room := b.Room()
+	if room == nil {
+		return
+	}
+	room.Clock().AfterFunc(BOT_DELAY*time.Millisecond, func() {
+		b.move(state)
+	})
+}
+
+func (b *DiceGameBot) move(state *GameState) {
 	log.Debug().Msg("deciding on next move")
 
 	if err := b.checkRoomStatus(); err != nil {
@@ -113,22 +130,25 @@ func (b *DiceGameBot) makeNextMove(state *GameState) {
 		return
 	}
 
-	// 2. Select some dice
-	scoringIdx := b.findScoringDiceIdx(state)
-	log.Debug().Int("scoringIdx", scoringIdx).Msg("found scoring dice")
-	// we still have scoring dice left and havent set aside too many yet
-	if scoringIdx != -1 && len(state.SetAside) <= 3 {
-		log.Debug().Int("scoringIdx", scoringIdx).Msg("selecting dice")
-		b.sendAction("select", map[string]int{"diceIndex": scoringIdx})
-		return
+	// 2. Let the strategy choose the dice to set aside and select them one by one
+	if len(b.selection) == 0 && len(state.SelectedDice) == 0 {
+		b.selection = b.strategy.SelectDice(b.turn(state))
+		log.Debug().Ints("selection", b.selection).Msg("strategy selected dice")
+	}
+	for _, idx := range b.selection {
+		if !slices.Contains(state.SelectedDice, idx) {
+			log.Debug().Int("diceIndex", idx).Msg("selecting dice")
+			b.sendAction("select", map[string]int{"diceIndex": idx})
+			return
+		}
 	}
 
 	// 3. Set dice aside
 	if len(state.SelectedDice) > 0 {
 		log.Debug().Ints("selectedDice", state.SelectedDice).Msg("setting dice aside")
 
-		// Decide whether to end turn based on risk assessment
 		endTurn := b.shouldEndTurn(state)
+		b.selection = []int{}
 		b.sendAction("set_aside", map[string]bool{"endTurn": endTurn})
 		return
 	}
@@ -139,64 +159,42 @@ func (b *DiceGameBot) makeNextMove(state *GameState) {
 		Msg("Bot should already have ended turn, but did not. Maybe we couldnt find a combination?")
 }
 
-func (b *DiceGameBot) findScoringDiceIdx(state *GameState) int {
-	// 1. select all 1s
-	// 2. no 1s left, select all 5s
-	// 3. gotta check for multiples
-	scoringPriorities := []int{1, 5}
-
-	for _, priority := range scoringPriorities {
-		for idx, die := range state.Dice {
-			// Don't select dice that are already selected
-			if slices.Contains(state.SelectedDice, idx) {
-				continue
-			}
-
-			if die == priority {
-				return idx
-			}
-		}
+// turn returns what the strategy sees of the bot's turn
+func (b *DiceGameBot) turn(state *GameState) Turn {
+	turn := Turn{Dice: slices.Clone(state.Dice), TargetScore: state.TargetScore, Rules: state.rules()}
+	if player, exists := state.Players[b.ID()]; exists {
+		turn.RoundScore = player.RoundScore
+		turn.Score = player.Score
 	}
+	return turn
+}
 
-	if len(b.combinationToSelect) == 0 {
-		b.combinationToSelect = b.detectOtherDiceToSelect(state.Dice)
+// shouldEndTurn asks the strategy whether to bank the selected dice, a bot always banks a win.
+// It rolls on while it can't bank yet, below the opening score or behind in the final round.
+func (b *DiceGameBot) shouldEndTurn(state *GameState) bool {
+	selected, err := b.game.getSelectedDiceFromIndexs(state.SelectedDice, state.Dice)
+	if err != nil {
+		return true
 	}
-
-	if len(b.combinationToSelect) > 0 {
-		combinationDie := b.combinationToSelect[0]
-		stateDiceIdx := -1
-		for idx, die := range state.Dice {
-			if die != combinationDie {
-				continue
-			}
-
-			// if we have already selected that dice, continue search
-			if slices.Contains(state.SelectedDice, idx) {
-				continue
-			}
-
-			stateDiceIdx = idx
-			// Found a valid index, so break the loop
-			break
+	rules := state.rules()
+	slices.Sort(selected)
+	selectedScore, _ := rules.scoreDice(selected)
+	diceLeft := rules.diceLeftAfter(len(state.Dice), len(selected))
+
+	turn := b.turn(state)
+	total := turn.Score + turn.RoundScore + selectedScore
+	if diceLeft > 0 {
+		if !rules.canBank(turn.Score, turn.RoundScore+selectedScore) {
+			return false
 		}
-
-		if stateDiceIdx != -1 {
-			// remove the selected dice from the combination list
-			b.combinationToSelect = slices.Delete(b.combinationToSelect, 0, 1)
-			return stateDiceIdx
+		if state.FinalRound && total <= leadingScore(state) {
+			return false
 		}
-
-		// No valid dice found in this round, so clear the combination list to avoid getting stuck
-		b.combinationToSelect = []int{}
-		return -1
 	}
-
-	return -1
-}
-
-func (b *DiceGameBot) shouldEndTurn(state *GameState) bool {
-	// end turn if we have banked at least 3 dice or are about to bank more than 3 dice
-	return len(state.SetAside) >= 3 || len(state.SetAside)+len(state.SelectedDice) >= 3
+	if total >= turn.TargetScore {
+		return true
+	}
+	return b.strategy.ShouldEndTurn(turn, selectedScore, diceLeft)
 }
 
 func (b *DiceGameBot) checkBotTurn(state *GameState) {
@@ -210,7 +208,7 @@ func (b *DiceGameBot) checkBotTurn(state *GameState) {
 		// Reset flags when it's no longer the bot's turn
 		b.myTurn = false
 		b.busted = false
-		b.combinationToSelect = []int{}
+		b.selection = []int{}
 	}
 }
 
@@ -257,25 +255,3 @@ func (b *DiceGameBot) allDiceInvalid(dice []int) bool {
 
 	return true
 }
-
-// checkMultiples checks for three of a kind and beyond.
-func (b *DiceGameBot) detectOtherDiceToSelect(stateDice []int) []int {
-	// Count occurrences for remaining dice
-	var combinations []int
-	counts := make(map[int]int)
-	for _, die := range stateDice {
-		counts[die]++
-	}
-
-	// Check for three of a kind and beyond
-	for num, count := range counts {
-		if count >= 3 {
-			log.Debug().Int("num", num).Int("count", count).Msg("Found three or more of a kind")
-			for i := 0; i < count; i++ {
-				combinations = append(combinations, num)
-			}
-		}
-	}
-
-	return combinations
-}
@@ -16,13 +16,23 @@ type TestMessage struct {
 	} `json:"data"`
 }
 
+// setupStartedGame seats players in a new room, gets them ready and lets the host start
+func setupStartedGame(helper *testicles.TestHelper, players int) []string {
+	playerIds := helper.SetupGameRoom("dicegame", players)
+	for _, id := range playerIds {
+		helper.SendMessage(id, "ready", nil)
+	}
+	helper.SendMessage(playerIds[0], "start", nil)
+	return playerIds
+}
+
 func TestDiceGameIntegration(t *testing.T) {
 	// Set up the test helper with all components
 	helper := testicles.NewTestHelper(t)
 	RegisterDiceGame(helper.Registry)
 
 	// Setup the game room with two players
-	playerIds := helper.SetupGameRoom("dicegame", 2)
+	playerIds := setupStartedGame(helper, 2)
 	player1ID := playerIds[0]
 	player2ID := playerIds[1]
 
@@ -92,3 +102,57 @@ func TestDiceGameIntegration(t *testing.T) {
 		t.Errorf("Expected player 1 to have 200 points (for setting aside a 2x 1), got %d", player1Score)
 	}
 }
+
+func TestDiceGameReportsResult(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+
+	playerIds := setupStartedGame(helper, 2)
+	winnerID, loserID := playerIds[0], playerIds[1]
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+
+	state := testRoom.State().(*GameState)
+	state.CurrentTurn = winnerID
+	state.Players[winnerID].Score = 2900
+	state.Players[loserID].Score = 1200
+	state.Dice = []int{1, 1, 3, 4, 6, 2}
+	testRoom.SetState(state)
+
+	helper.SendMessage(winnerID, "select", interfaces.M{"diceIndex": 0})
+	helper.SendMessage(winnerID, "set_aside", interfaces.M{"endTurn": true})
+
+	if state.Winner == "" {
+		t.Fatalf("Expected the game to be over")
+	}
+
+	gameResults := helper.GameResults()
+	if len(gameResults) != 1 {
+		t.Fatalf("Expected 1 game result, got %d", len(gameResults))
+	}
+	result := gameResults[0]
+	if result.GameType != "dicegame" || result.RoomID != testRoom.ID() {
+		t.Errorf("Expected result of the room, got %s/%s", result.GameType, result.RoomID)
+	}
+	if result.StartedAt.IsZero() || result.Duration < 0 {
+		t.Errorf("Expected start time and duration, got %v and %v", result.StartedAt, result.Duration)
+	}
+
+	for _, p := range result.Participants {
+		switch p.ClientID {
+		case winnerID:
+			if p.Placement != 1 || p.Outcome != interfaces.OutcomeWin || p.Score != 3000 {
+				t.Errorf("Unexpected winner: %+v", p)
+			}
+		case loserID:
+			if p.Placement != 2 || p.Outcome != interfaces.OutcomeLoss || p.Score != 1200 {
+				t.Errorf("Unexpected loser: %+v", p)
+			}
+		default:
+			t.Errorf("Unexpected participant %s", p.ClientID)
+		}
+	}
+}
@@ -0,0 +1,173 @@
+package dicegame
+
+import (
+	"sync"
+)
+
+// evScoreCap is the round score from which the expected value strategy always banks.
+// All scores are multiples of 50, so the table has a row per 50 points below it.
+const evScoreCap = 5000
+
+// rollOption is a way to set aside dice from a roll
+type rollOption struct {
+	score    int
+	diceLeft int
+}
+
+// rollOutcome is a roll of some dice, regardless of their order
+type rollOutcome struct {
+	probability float64
+	options     []rollOption
+}
+
+// evTable holds the expected round score of rolling n dice with a round score at risk,
+// [n][score/50]. Scores only grow during a turn, so it is filled from the cap down.
+type evTable [MAX_DICE + 1][evScoreCap / 50]float64
+
+// evTables holds a table per scoring of the rulesets bots played by, built on first use
+var evTables struct {
+	mu     sync.Mutex
+	tables map[string]*evTable
+}
+
+// tableFor returns the table of the scoring of a ruleset
+func tableFor(rules *Ruleset) *evTable {
+	key := rules.scoringKey()
+
+	evTables.mu.Lock()
+	defer evTables.mu.Unlock()
+	table, ok := evTables.tables[key]
+	if !ok {
+		if evTables.tables == nil {
+			evTables.tables = make(map[string]*evTable)
+		}
+		table = buildEVTable(rules)
+		evTables.tables[key] = table
+	}
+	return table
+}
+
+// rollValue returns the expected round score of rolling dice with roundScore at risk,
+// when playing on optimally. A bust scores 0.
+func rollValue(rules *Ruleset, dice int, roundScore int) float64 {
+	return tableFor(rules).value(dice, roundScore)
+}
+
+// turnValue returns the expected round score after setting aside dice, choosing the
+// better of banking roundScore and rolling diceLeft dice
+func turnValue(rules *Ruleset, diceLeft int, roundScore int) float64 {
+	return tableFor(rules).best(diceLeft, roundScore)
+}
+
+func (t *evTable) value(dice int, roundScore int) float64 {
+	if roundScore >= evScoreCap {
+		return 0
+	}
+	return t[dice][roundScore/50]
+}
+
+func (t *evTable) best(diceLeft int, roundScore int) float64 {
+	return max(float64(roundScore), t.value(diceLeft, roundScore))
+}
+
+func buildEVTable(rules *Ruleset) *evTable {
+	var outcomes [MAX_DICE + 1][]rollOutcome
+	for dice := 1; dice <= MAX_DICE; dice++ {
+		outcomes[dice] = rollOutcomes(rules, dice)
+	}
+
+	table := &evTable{}
+	for row := evScoreCap/50 - 1; row >= 0; row-- {
+		roundScore := row * 50
+		for dice := 1; dice <= MAX_DICE; dice++ {
+			value := 0.0
+			for _, outcome := range outcomes[dice] {
+				best := 0.0
+				for _, option := range outcome.options {
+					best = max(best, table.best(option.diceLeft, roundScore+option.score))
+				}
+				value += outcome.probability * best
+			}
+			table[dice][row] = value
+		}
+	}
+	return table
+}
+
+// rollOutcomes returns every distinct roll of some dice with its probability and the ways
+// to set aside dice from it
+func rollOutcomes(rules *Ruleset, dice int) []rollOutcome {
+	total := 1.0
+	for i := 0; i < dice; i++ {
+		total *= 6
+	}
+
+	var outcomes []rollOutcome
+	var counts [7]int
+	var walk func(face int, left int)
+	walk = func(face int, left int) {
+		if face == 6 {
+			counts[6] = left
+			outcomes = append(outcomes, rollOutcome{
+				probability: arrangements(counts) / total,
+				options:     setAsideOptions(rules, counts, dice),
+			})
+			return
+		}
+		for n := 0; n <= left; n++ {
+			counts[face] = n
+			walk(face+1, left-n)
+		}
+	}
+	walk(1, dice)
+	return outcomes
+}
+
+// arrangements returns in how many orders dice with the given face counts can be rolled
+func arrangements(counts [7]int) float64 {
+	factorial := func(n int) float64 {
+		f := 1.0
+		for i := 2; i <= n; i++ {
+			f *= float64(i)
+		}
+		return f
+	}
+
+	dice := 0
+	result := 1.0
+	for _, n := range counts {
+		dice += n
+		result /= factorial(n)
+	}
+	return result * factorial(dice)
+}
+
+// setAsideOptions returns every scoring set of dice that can be taken from a roll
+func setAsideOptions(rules *Ruleset, counts [7]int, dice int) []rollOption {
+	var options []rollOption
+	var taken [7]int
+	var walk func(face int)
+	walk = func(face int) {
+		if face == 7 {
+			values := make([]int, 0, dice)
+			for f := 1; f <= 6; f++ {
+				for i := 0; i < taken[f]; i++ {
+					values = append(values, f)
+				}
+			}
+			if len(values) == 0 {
+				return
+			}
+			if score, valid := rules.scoreDice(values); valid && score > 0 {
+				options = append(options, rollOption{score: score, diceLeft: rules.diceLeftAfter(dice, len(values))})
+			}
+			return
+		}
+		for n := 0; n <= counts[face]; n++ {
+			taken[face] = n
+			walk(face + 1)
+		}
+	}
+	walk(1)
+	return options
+}
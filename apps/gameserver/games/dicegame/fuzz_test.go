@@ -0,0 +1,69 @@
+package dicegame
+
+import (
+	"fmt"
+	"testing"
+
+	"gameserver/games/dicegame/database"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/testicles"
+)
+
+func FuzzDiceGame(f *testing.F) {
+	testicles.FuzzRouter(f, testicles.FuzzTarget{
+		GameType: "dicegame",
+		Register: func(registry interfaces.GameRegistry) {
+			RegisterDiceGame(registry, WithDatabase(&database.DatabaseServiceMock{}))
+		},
+		Players: 2,
+		Messages: []testicles.FuzzMessage{
+			{Type: "ready", Payloads: []string{`{"ready":true}`, `{"ready":false}`}},
+			{Type: "start"},
+			{Type: "rematch"},
+			{Type: "roll"},
+			{Type: "select", Payloads: []string{`{"diceIndex":0}`, `{"diceIndex":1}`, `{"diceIndex":2}`, `{"diceIndex":3}`, `{"diceIndex":4}`, `{"diceIndex":5}`, `{"diceIndex":6}`, `{"diceIndex":-1}`}},
+			{Type: "set_aside", Payloads: []string{`{"endTurn":false}`, `{"endTurn":true}`}},
+		},
+		Check: checkDiceGame,
+	})
+}
+
+// checkDiceGame checks that the turn belongs to a player, every player is in the turn order,
+// only rolled dice are selected and nobody's score drops below zero
+func checkDiceGame(room interfaces.Room) error {
+	state := room.State().(*GameState)
+	if err := testicles.CheckCurrentTurn(state.CurrentTurn, state.Players); err != nil {
+		return err
+	}
+	if len(state.Players) != len(state.PlayerOrder) || len(state.PlayerOrder) > MaxPlayers {
+		return fmt.Errorf("%d players in an order of %d", len(state.Players), len(state.PlayerOrder))
+	}
+	for _, id := range state.PlayerOrder {
+		if state.Players[id] == nil {
+			return fmt.Errorf("%s is in the turn order but no player", id)
+		}
+	}
+	if state.Host != "" && state.Players[state.Host] == nil {
+		return fmt.Errorf("host %s is no player", state.Host)
+	}
+	if state.Started && state.Winner == "" && len(state.Players) > 1 && state.CurrentTurn == "" {
+		return fmt.Errorf("nobody's turn in a running game")
+	}
+
+	selected := make(map[int]bool)
+	for _, index := range state.SelectedDice {
+		if index < 0 || index >= len(state.Dice) {
+			return fmt.Errorf("selected die %d of %d", index, len(state.Dice))
+		}
+		if selected[index] {
+			return fmt.Errorf("die %d selected twice", index)
+		}
+		selected[index] = true
+	}
+	for _, player := range state.Players {
+		if player.Score < 0 || player.RoundScore < 0 {
+			return fmt.Errorf("player %s scored %d (round %d)", player.ID, player.Score, player.RoundScore)
+		}
+	}
+	return nil
+}
@@ -4,13 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"gameserver/games/dicegame/database"
 	"gameserver/internal/interfaces"
 	"gameserver/internal/protocol"
-	"math/rand"
+	"gameserver/internal/rematch"
+	"gameserver/internal/rng"
 	"time"
 
-	// 	"math/rand"
-
 	"github.com/rs/zerolog/log"
 )
 
@@ -21,12 +22,27 @@ type BustedResponse struct {
 	Name     string `json:"name"`
 }
 
-func NewDiceGame() *DiceGame {
-	return &DiceGame{}
+// GameOption configures a dice game
+type GameOption func(*DiceGame)
+
+// WithDatabase stores finished matches in the match history
+func WithDatabase(db database.Database) GameOption {
+	return func(g *DiceGame) {
+		g.db = db
+	}
 }
 
-func RegisterDiceGame(r interfaces.GameRegistry) {
-	g := NewDiceGame()
+func NewDiceGame(opts ...GameOption) *DiceGame {
+	g := &DiceGame{strategies: newStrategies()}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+func RegisterDiceGame(r interfaces.GameRegistry, opts ...GameOption) {
+	g := NewDiceGame(opts...)
+	g.registry = r
 	r.RegisterGame(g)
 }
 
@@ -36,7 +52,25 @@ func (g *DiceGame) Type() string {
 }
 
 // InitializeRoom sets up a new room with the initial game state
-func (g *DiceGame) InitializeRoom(ctx context.Context, room interfaces.Room, options json.RawMessage) error {
+func (g *DiceGame) InitializeRoom(ctx context.Context, room interfaces.Room, options json.RawMessage, random rng.RNG) error {
+	var roomOptions RoomOptions
+	if options != nil {
+		if err := json.Unmarshal(options, &roomOptions); err != nil {
+			log.Warn().Err(err).Msg("Failed to parse room options, using defaults")
+		}
+	}
+
+	ruleset, err := NewRuleset(roomOptions.Ruleset, roomOptions.Rules)
+	if err != nil {
+		return err
+	}
+	if roomOptions.TargetScore < 0 {
+		return fmt.Errorf("%w: target score %d is not positive", ErrInvalidRuleset, roomOptions.TargetScore)
+	}
+	if roomOptions.TargetScore > 0 {
+		ruleset.TargetScore = roomOptions.TargetScore
+	}
+
 	// Create initial game state
 	state := GameState{
 		Players:      make(map[string]*Player),
@@ -46,7 +80,13 @@ func (g *DiceGame) InitializeRoom(ctx context.Context, room interfaces.Room, opt
 		Started:      false,
 		CurrentTurn:  "",
 		Winner:       "",
-		TargetScore:  3000,
+		TargetScore:  ruleset.TargetScore,
+		Ruleset:      ruleset,
+		Series:       rematch.NewSeries(roomOptions.BestOf),
+		rng:          random,
+		clock:        room.Clock(),
+
+		takeoverGrace: roomOptions.Grace(),
 	}
 
 	room.SetState(&state)
@@ -56,18 +96,23 @@ func (g *DiceGame) InitializeRoom(ctx context.Context, room interfaces.Room, opt
 func (g *DiceGame) OnClientJoin(client interfaces.Client, room interfaces.Room, options interfaces.CreateRoomOptions) {
 	state := room.State().(*GameState)
 
-	// Only allow 2 players
-	if len(state.Players) >= 2 {
-		client.Send(protocol.NewErrorResponse("error", "Game is full"))
+	// Players only join in the lobby
+	if state.Started {
+		client.Send(protocol.NewErrorResponse("error", ErrGameStarted.Error()))
+		return
+	}
+	if len(state.PlayerOrder) >= MaxPlayers {
+		client.Send(protocol.NewErrorResponse("error", ErrGameFull.Error()))
 		return
 	}
 
 	g.AddPlayer(client.ID(), options.PlayerName, state)
-
-	// If we now have 2 players, start the game
-	if len(state.Players) == 2 {
-		g.start(state)
-	}
+	player := state.Players[client.ID()]
+	player.AccountID = options.AccountID
+	// bots are always ready, the host starts the game
+	player.Bot = client.IsBot()
+	player.Ready = client.IsBot()
+	updateHost(state)
 
 	room.SetState(state)
 
@@ -75,59 +120,128 @@ func (g *DiceGame) OnClientJoin(client interfaces.Client, room interfaces.Room,
 	broadcastGameState(room)
 }
 
-func (g *DiceGame) OnBotAdd(client interfaces.Client, room interfaces.Room, reg interfaces.GameRegistry) (interfaces.Client, string, error) {
+func (g *DiceGame) OnBotAdd(client interfaces.Client, room interfaces.Room, reg interfaces.GameRegistry, options interfaces.BotOptions) (interfaces.Client, string, error) {
 	state := room.State().(*GameState)
 	if state.Started {
-		return nil, "", errors.New("game already started")
+		return nil, "", ErrGameStarted
 	}
-	bot := NewDiceGameBot(g, reg)
+	if len(state.PlayerOrder) >= MaxPlayers {
+		return nil, "", ErrGameFull
+	}
+	name, strategy, err := g.strategies.Select(options)
+	if err != nil {
+		return nil, "", err
+	}
+	bot := NewDiceGameBot(g, reg, strategy)
+	log.Debug().Str("botId", bot.ID()).Str("strategy", name).Msg("adding bot")
 
-	return bot.BotClient, getBotName(), nil
+	return bot.BotClient, getBotName(state.rng), nil
 }
 
+// OnClientLeave takes the player out of the game, a leaving host hands the lobby to the next player
 func (g *DiceGame) OnClientLeave(client interfaces.Client, room interfaces.Room) {
 	state := room.State().(*GameState)
-	if state.CurrentTurn == client.ID() {
-		log.Info().Str("clientId", client.ID()).Msg("client left. Ending players turn")
-		g.EndTurn(room, state)
+	if _, exists := state.Players[client.ID()]; !exists {
+		return
 	}
+	log.Info().Str("clientId", client.ID()).Bool("started", state.Started).Msg("player left")
+	g.RemovePlayer(room, state, client.ID())
+	// the players left may all have voted for a rematch already
+	rematched := g.rematchIfAgreed(state)
 	room.SetState(state)
+	broadcastGameState(room)
+	if rematched && g.registry != nil {
+		g.registry.ReportStarted(room)
+	}
 }
 
-// OnClientReconnect handles reconnecting a client to the game
-func (g *DiceGame) OnClientReconnect(client interfaces.Client, room interfaces.Room, oldClientID string) error {
+// OnClientReconnect tells everyone about a player that is back on its seat, or a bot that took it over
+func (g *DiceGame) OnClientReconnect(client interfaces.Client, room interfaces.Room) error {
 	state := room.State().(*GameState)
 
-	// Check if the old client ID was a player in this game
-	playerInfo, exists := state.Players[oldClientID]
+	// Check if the seat belongs to a player in this game
+	player, exists := state.Players[client.ID()]
 	if !exists {
 		return errors.New("no player found with provided ID")
 	}
 
-	if state.Winner != "" {
-		return errors.New("game already ended")
-	}
+	player.BotControlled = client.IsBot()
+	room.SetState(state)
+
+	// the new client needs the game state, the others see who controls the seat
+	broadcastGameState(room)
+	return nil
+}
 
-	// Replace the old client ID with the new one, maintaining the same player info
-	delete(state.Players, oldClientID)
-	playerInfo.ID = client.ID()
-	state.Players[client.ID()] = playerInfo
+// TakeoverGrace returns how long the seat of a disconnected player waits, 0 unless the room enabled bot takeover
+func (g *DiceGame) TakeoverGrace(room interfaces.Room) time.Duration {
+	return room.State().(*GameState).takeoverGrace
+}
 
-	// If it was this player's turn, update the current turn
-	if state.CurrentTurn == oldClientID {
-		state.CurrentTurn = client.ID()
+// TakeoverBot returns a bot playing the default strategy on the seat of a disconnected player
+func (g *DiceGame) TakeoverBot(seat interfaces.Client, room interfaces.Room, reg interfaces.GameRegistry) (interfaces.Client, error) {
+	state := room.State().(*GameState)
+	if _, exists := state.Players[seat.ID()]; !exists {
+		return nil, errors.New("no player found with provided ID")
+	}
+	if !state.Started || state.Winner != "" {
+		return nil, errors.New("game is not running")
 	}
 
-	room.SetState(state)
+	_, strategy, err := g.strategies.Select(interfaces.BotOptions{})
+	if err != nil {
+		return nil, err
+	}
+	bot := newDiceGameBot(seat.ID(), g, reg, strategy)
+	return bot.BotClient, nil
+}
 
-	// tell the new client the game state
-	msg := protocol.NewSuccessResponse("game_state", state)
-	client.Send(msg)
-	return nil
+// CurrentTurn returns the player who is rolling, "" before the game started and after it ended
+func (g *DiceGame) CurrentTurn(room interfaces.Room) string {
+	return room.State().(*GameState).CurrentTurn
 }
 
 func (g *DiceGame) HandleMessage(client interfaces.Client, room interfaces.Room, msgType string, payload []byte) error {
 	state := room.State().(*GameState)
+
+	// Handle lobby messages that don't require turn validation
+	switch msgType {
+	case "ready":
+		if err := g.handleReady(client, state, payload); err != nil {
+			log.Error().Err(err).Msg("ready failed")
+			client.Send(protocol.NewErrorResponse("error", err.Error()))
+			return err
+		}
+		room.SetState(state)
+		broadcastGameState(room)
+		return nil
+	case "start":
+		if err := g.handleStart(client, state); err != nil {
+			log.Error().Err(err).Msg("start failed")
+			client.Send(protocol.NewErrorResponse("error", err.Error()))
+			return err
+		}
+		room.SetState(state)
+		broadcastGameState(room)
+		if g.registry != nil {
+			g.registry.ReportStarted(room)
+		}
+		return nil
+	case "rematch":
+		rematched, err := g.handleRematch(client, state)
+		if err != nil {
+			log.Error().Err(err).Msg("rematch failed")
+			client.Send(protocol.NewErrorResponse("error", err.Error()))
+			return err
+		}
+		room.SetState(state)
+		broadcastGameState(room)
+		if rematched && g.registry != nil {
+			g.registry.ReportStarted(room)
+		}
+		return nil
+	}
+
 	// Validate it's the player's turn
 	if state.CurrentTurn != client.ID() {
 		client.Send(protocol.NewErrorResponse("error", ErrNotYourTurn.Error()))
@@ -155,11 +269,17 @@ func (g *DiceGame) HandleMessage(client interfaces.Client, room interfaces.Room,
 			bustedPlayer := state.Players[state.CurrentTurn]
 
 			// Schedule the turn end after a delay to allow for animations
-			time.AfterFunc(BustedAnimationDelay, func() {
+			room.Clock().AfterFunc(BustedAnimationDelay, func() {
+				room.Lock()
+				defer room.Unlock()
+
 				room.Broadcast(bustedMsg)
 				bustedPlayer.TurnScore = 0
 				bustedPlayer.RoundScore = 0
-				g.handleEndTurn(room)
+				// the turn already ended if the player left in the meantime
+				if state.CurrentTurn == bustedPlayer.ID {
+					g.handleEndTurn(room)
+				}
 				broadcastGameState(room)
 			})
 		}
@@ -191,6 +311,9 @@ func (g *DiceGame) HandleMessage(client interfaces.Client, room interfaces.Room,
 
 		if err := g.handleSetAside(room, action.EndTurn); err != nil {
 			log.Error().Err(err).Msg(ErrSetAsideInvalid.Error())
+			if errors.Is(err, ErrBelowOpeningScore) {
+				return err
+			}
 			return ErrSetAsideInvalid
 		}
 	//case "end_turn":
@@ -211,7 +334,7 @@ func broadcastGameState(room interfaces.Room) {
 	room.Broadcast(msg)
 }
 
-func getBotName() string {
+func getBotName(random rng.RNG) string {
 	botNames := []string{
 		"Andrew",
 		"Hans",
@@ -230,15 +353,24 @@ func getBotName() string {
 		"Pavlena",
 	}
 
-	randomIndex := rand.Intn(len(botNames))
+	randomIndex := random.Intn(len(botNames))
 	return botNames[randomIndex]
 }
 
 var (
+	ErrGameStarted            = errors.New("game already started")
+	ErrGameFull               = errors.New("game is full")
+	ErrNotAPlayer             = errors.New("not a player")
+	ErrNotHost                = errors.New("only the host can start the game")
+	ErrNotEnoughPlayers       = errors.New("not enough players to start")
+	ErrPlayersNotReady        = errors.New("not every player is ready")
+	ErrReadyPayloadInvalid    = errors.New("ready payload invalid")
 	ErrNotYourTurn            = errors.New("not your turn")
 	ErrBusted                 = errors.New("busted")
 	ErrSelectPayloadInvalid   = errors.New("select payload invalid")
 	ErrSelectInvalid          = errors.New("select invalid")
 	ErrSetAsidePayloadInvalid = errors.New("set aside payload invalid ")
 	ErrSetAsideInvalid        = errors.New("set aside invalid")
+	ErrBelowOpeningScore      = errors.New("round score is below the opening score")
+	ErrGameNotOver            = errors.New("game is not over")
 )
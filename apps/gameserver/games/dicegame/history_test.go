@@ -0,0 +1,108 @@
+package dicegame
+
+import (
+	"context"
+	"gameserver/games/dicegame/database"
+	"gameserver/games/dicegame/models"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/rng"
+	"gameserver/internal/testicles"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitForMatches waits for the matches written in the background
+func waitForMatches(t *testing.T, db *database.DatabaseServiceMock, count int) []models.Match {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		matches := db.Matches()
+		if len(matches) == count {
+			return matches
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d stored matches, got %d", count, len(matches))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDiceGame_StoresMatch(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	db := &database.DatabaseServiceMock{}
+	RegisterDiceGame(helper.Registry, WithDatabase(db))
+
+	// the first player goes first and busts
+	helper.UseRNG(rng.NewScripted(0, 1, 1, 2, 3, 5, 5))
+	playerIds := setupStartedGame(helper, 2)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+	state := testRoom.State().(*GameState)
+	state.Players[playerIds[0]].AccountID = "account-0"
+
+	startedAt := helper.Clock.Now()
+	helper.SendMessage(playerIds[0], "roll", nil)
+	helper.Clock.Advance(BustedAnimationDelay)
+
+	state.Players[playerIds[1]].Score = 2900
+	state.Dice = []int{1, 1, 3, 4, 6, 2}
+	helper.SendMessage(playerIds[1], "select", interfaces.M{"diceIndex": 0})
+	helper.SendMessage(playerIds[1], "select", interfaces.M{"diceIndex": 1})
+	helper.SendMessage(playerIds[1], "set_aside", interfaces.M{"endTurn": true})
+
+	matches := waitForMatches(t, db, 1)
+	match := matches[0]
+	if !match.StartedAt.Equal(startedAt) || !match.FinishedAt.Equal(startedAt.Add(BustedAnimationDelay)) {
+		t.Errorf("Expected the match to run on the room clock from %v, got %v to %v", startedAt, match.StartedAt, match.FinishedAt)
+	}
+	if match.RoomID != testRoom.ID() || match.Winner != state.Players[playerIds[1]].Name || match.TurnCount != 2 || match.Ruleset != RulesetKingdomCome {
+		t.Errorf("Unexpected match: %+v", match.DBMatch)
+	}
+
+	if len(match.Turns) != 2 {
+		t.Fatalf("Expected 2 turns, got %+v", match.Turns)
+	}
+	if turn := match.Turns[0]; turn.PlayerID != "account-0" || !turn.Busted || turn.Score != 0 || turn.Turn != 1 {
+		t.Errorf("Expected a bust of the account first, got %+v", turn)
+	}
+	if turn := match.Turns[1]; turn.PlayerID != playerIds[1] || turn.Busted || turn.Score != 200 {
+		t.Errorf("Expected the guest to bank 200, got %+v", turn)
+	}
+
+	for _, standing := range match.Standings {
+		winner := standing.PlayerID == playerIds[1]
+		if winner && (standing.Placement != 1 || standing.Score != 3100) || !winner && (standing.Placement != 2 || standing.AccountID != "account-0") {
+			t.Errorf("Unexpected standing: %+v", standing)
+		}
+	}
+}
+
+func TestHTTPHandler(t *testing.T) {
+	db := &database.DatabaseServiceMock{}
+	db.StoreMatch(context.Background(), models.Match{DBMatch: models.DBMatch{ID: "match-1", Winner: "Alice"}})
+	handler := NewHTTPHandler(db)
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/dicegame/players/alice/stats", http.StatusOK, `"playerId":"alice"`},
+		{"/dicegame/players/alice/matches?limit=500", http.StatusOK, `[]`},
+		{"/dicegame/matches/match-1", http.StatusOK, `"winner":"Alice"`},
+		{"/dicegame/matches/match-2", http.StatusNotFound, `"error":"match not found"`},
+	}
+	for _, tt := range tests {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if recorder.Code != tt.status || !strings.Contains(recorder.Body.String(), tt.body) {
+			t.Errorf("GET %s: expected %d with %s, got %d: %s", tt.path, tt.status, tt.body, recorder.Code, recorder.Body)
+		}
+	}
+}
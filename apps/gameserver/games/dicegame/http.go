@@ -0,0 +1,96 @@
+package dicegame
+
+import (
+	"encoding/json"
+	"errors"
+	"gameserver/games/dicegame/database"
+	"net/http"
+	"strconv"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultMatchesLimit = 20
+	maxMatchesLimit     = 100
+)
+
+// NewHTTPHandler serves the match history and player statistics, players are identified by
+// their account ID, guests by their client ID:
+//
+//	GET /dicegame/players/{playerId}/stats              average turn score, bust rate, highest turn, ...
+//	GET /dicegame/players/{playerId}/matches?limit=20   the latest matches with the player's standing
+//	GET /dicegame/matches/{matchId}                     a match with its turns and final standings
+func NewHTTPHandler(db database.Database) http.Handler {
+	h := &httpHandler{db: db}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /dicegame/players/{playerId}/stats", h.getPlayerStats)
+	mux.HandleFunc("GET /dicegame/players/{playerId}/matches", h.getPlayerMatches)
+	mux.HandleFunc("GET /dicegame/matches/{matchId}", h.getMatch)
+	return mux
+}
+
+type httpHandler struct {
+	db database.Database
+}
+
+func (h *httpHandler) getPlayerStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.db.GetPlayerStats(r.Context(), r.PathValue("playerId"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, stats)
+}
+
+func (h *httpHandler) getPlayerMatches(w http.ResponseWriter, r *http.Request) {
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 {
+		limit = defaultMatchesLimit
+	}
+	limit = min(limit, maxMatchesLimit)
+
+	matches, err := h.db.GetPlayerMatches(r.Context(), r.PathValue("playerId"), limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, matches)
+}
+
+func (h *httpHandler) getMatch(w http.ResponseWriter, r *http.Request) {
+	match, err := h.db.GetMatch(r.Context(), r.PathValue("matchId"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, match)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, database.ErrMatchNotFound):
+		status = http.StatusNotFound
+	default:
+		log.Error().Err(err).Msg("dicegame history request failed")
+		err = errors.New("internal error")
+	}
+
+	jsonData, _ := json.Marshal(map[string]string{"error": err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonData)
+}
@@ -0,0 +1,187 @@
+package dicegame
+
+import (
+	"gameserver/internal/interfaces"
+	"gameserver/internal/rng"
+	"gameserver/internal/testicles"
+	"slices"
+	"testing"
+)
+
+func TestDiceGame_HostStartsOnceEveryoneIsReady(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+
+	playerIds := helper.SetupGameRoom("dicegame", 3)
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+	state := testRoom.State().(*GameState)
+
+	if state.Started || state.Host != playerIds[0] || !slices.Equal(state.PlayerOrder, playerIds) {
+		t.Fatalf("Expected a lobby hosted by %s in join order, got host %s and order %v", playerIds[0], state.Host, state.PlayerOrder)
+	}
+
+	g := NewDiceGame()
+	host := testRoom.Clients()[playerIds[0]]
+	if err := g.HandleMessage(host, testRoom, "start", nil); err != ErrPlayersNotReady {
+		t.Errorf("Expected the start to wait for everyone to be ready, got %v", err)
+	}
+	for _, id := range playerIds {
+		helper.SendMessage(id, "ready", nil)
+	}
+	helper.SendMessage(playerIds[2], "ready", ReadyActionPayload{Ready: false})
+	if state.Players[playerIds[2]].Ready {
+		t.Error("Expected a player to take the ready back")
+	}
+	helper.SendMessage(playerIds[2], "ready", nil)
+
+	if err := g.HandleMessage(testRoom.Clients()[playerIds[1]], testRoom, "start", nil); err != ErrNotHost {
+		t.Errorf("Expected only the host to start, got %v", err)
+	}
+	helper.SendMessage(playerIds[0], "start", nil)
+	if !state.Started || state.CurrentTurn == "" {
+		t.Fatalf("Expected the host to start the game, got %+v", state)
+	}
+
+	late := helper.CreateClient("late")
+	helper.JoinRoom(late, helper.RoomID, "late")
+	if _, joined := state.Players["late"]; joined {
+		t.Error("Expected nobody to join a running game")
+	}
+}
+
+func TestDiceGame_StartNeedsTwoPlayers(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+
+	player := helper.CreateClient("player-0")
+	helper.CreateRoom(player, "dicegame", "player-0")
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+
+	helper.SendMessage("player-0", "ready", nil)
+	if err := NewDiceGame().HandleMessage(player, testRoom, "start", nil); err != ErrNotEnoughPlayers {
+		t.Errorf("Expected a single player not to start, got %v", err)
+	}
+}
+
+func TestDiceGame_SixPlayersAtMost(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+
+	helper.SetupGameRoom("dicegame", MaxPlayers)
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+
+	helper.JoinRoom(helper.CreateClient("seventh"), helper.RoomID, "seventh")
+	if _, joined := testRoom.State().(*GameState).Players["seventh"]; joined {
+		t.Error("Expected a seventh player to be turned away")
+	}
+	if _, _, err := NewDiceGame().OnBotAdd(nil, testRoom, helper.Registry, interfaces.BotOptions{}); err != ErrGameFull {
+		t.Errorf("Expected no bot to join a full game, got %v", err)
+	}
+}
+
+func TestDiceGame_TurnOrder(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+
+	// the second player starts
+	helper.UseRNG(rng.NewScripted(1))
+	playerIds := setupStartedGame(helper, 4)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+	state := testRoom.State().(*GameState)
+
+	var turns []string
+	for range 6 {
+		turns = append(turns, state.CurrentTurn)
+		NewDiceGame().EndTurn(testRoom, state)
+	}
+	want := []string{playerIds[1], playerIds[2], playerIds[3], playerIds[0], playerIds[1], playerIds[2]}
+	if !slices.Equal(turns, want) {
+		t.Errorf("Expected the turns to follow the join order, got %v", turns)
+	}
+}
+
+func TestDiceGame_PlayersLeave(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+
+	helper.UseRNG(rng.NewScripted(2))
+	playerIds := setupStartedGame(helper, 4)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+	state := testRoom.State().(*GameState)
+
+	steps := []struct {
+		leaver string
+		turn   string
+	}{
+		// somebody else leaves, the turn stays
+		{playerIds[0], playerIds[2]},
+		// the current player leaves, the next one in order plays
+		{playerIds[2], playerIds[3]},
+		// a single player is left, who wins
+		{playerIds[3], ""},
+	}
+	for i, step := range steps {
+		helper.SendMessage(step.leaver, "leave_room", nil)
+		if _, exists := state.Players[step.leaver]; exists || slices.Contains(state.PlayerOrder, step.leaver) {
+			t.Errorf("step %d: expected %s to be gone, got order %v", i, step.leaver, state.PlayerOrder)
+		}
+		if state.CurrentTurn != step.turn {
+			t.Errorf("step %d: expected the turn of %q, got %q", i, step.turn, state.CurrentTurn)
+		}
+	}
+
+	if winner := state.Players[playerIds[1]]; winner == nil || state.Winner != winner.Name {
+		t.Errorf("Expected the last player left to win, got %q", state.Winner)
+	}
+	if len(helper.GameResults()) != 1 {
+		t.Errorf("Expected the game to be reported, got %d results", len(helper.GameResults()))
+	}
+}
+
+func TestDiceGame_HostLeavesLobby(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+
+	playerIds := helper.SetupGameRoom("dicegame", 2)
+	helper.SendMessage(playerIds[0], "add_bot", nil)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+	state := testRoom.State().(*GameState)
+	if len(state.PlayerOrder) != 3 {
+		t.Fatalf("Expected a bot to join, got %v", state.PlayerOrder)
+	}
+	bot := state.Players[state.PlayerOrder[2]]
+	if !bot.Bot || !bot.Ready {
+		t.Errorf("Expected the bot to be ready, got %+v", bot)
+	}
+
+	helper.SendMessage(playerIds[0], "leave_room", nil)
+	if state.Host != playerIds[1] {
+		t.Errorf("Expected %s to host after the host left, got %q", playerIds[1], state.Host)
+	}
+
+	helper.SendMessage(playerIds[1], "leave_room", nil)
+	if state.Host != "" {
+		t.Errorf("Expected a bot never to host, got %q", state.Host)
+	}
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"time"
+)
+
+// DBMatch is a finished match
+type DBMatch struct {
+	ID          string    `json:"id" db:"id"`
+	RoomID      string    `json:"roomId" db:"room_id"`
+	Ruleset     string    `json:"ruleset" db:"ruleset"`
+	TargetScore int       `json:"targetScore" db:"target_score"`
+	Winner      string    `json:"winner" db:"winner"` // the winners name
+	TurnCount   int       `json:"turnCount" db:"turn_count"`
+	StartedAt   time.Time `json:"startedAt" db:"started_at"`
+	FinishedAt  time.Time `json:"finishedAt" db:"finished_at"`
+}
+
+// DBTurn is a turn played in a match. Players are identified by their account, guests by
+// their client ID.
+type DBTurn struct {
+	ID       string `json:"-" db:"id"` // <matchId>:<turn>
+	MatchID  string `json:"matchId" db:"match_id"`
+	Turn     int    `json:"turn" db:"turn"`
+	PlayerID string `json:"playerId" db:"player_id"`
+	Score    int    `json:"score" db:"score"` // the points banked, 0 for a bust
+	Busted   bool   `json:"busted" db:"busted"`
+}
+
+// DBStanding is the final standing of a player in a match
+type DBStanding struct {
+	ID        string `json:"-" db:"id"` // <matchId>:<playerId>
+	MatchID   string `json:"matchId" db:"match_id"`
+	PlayerID  string `json:"playerId" db:"player_id"`
+	AccountID string `json:"accountId,omitempty" db:"account_id"`
+	Name      string `json:"name" db:"name"`
+	Placement int    `json:"placement" db:"placement"`
+	Score     int    `json:"score" db:"score"`
+	Bot       bool   `json:"bot" db:"bot"`
+}
+
+// Match is a match with its turns and final standings
+type Match struct {
+	DBMatch
+	Standings []DBStanding `json:"standings"`
+	Turns     []DBTurn     `json:"turns"`
+}
+
+// PlayerMatch is a match from the view of one of its players
+type PlayerMatch struct {
+	DBMatch
+	Placement int `json:"placement" db:"placement"`
+	Score     int `json:"score" db:"score"`
+}
+
+// PlayerStats aggregates the matches and turns of a player
+type PlayerStats struct {
+	PlayerID         string  `json:"playerId"`
+	Matches          int     `json:"matches"`
+	Wins             int     `json:"wins"`
+	Turns            int     `json:"turns"`
+	Busts            int     `json:"busts"`
+	BustRate         float64 `json:"bustRate"`         // busts per turn
+	AverageTurnScore float64 `json:"averageTurnScore"` // points banked per turn, busts count as 0
+	HighestTurn      int     `json:"highestTurn"`
+}
@@ -0,0 +1,101 @@
+package dicegame
+
+import (
+	"gameserver/internal/interfaces"
+	"gameserver/internal/testicles"
+	"testing"
+)
+
+// winGame lets the player bank the points to reach the target score
+func winGame(t *testing.T, helper *testicles.TestHelper, state *GameState, id string) {
+	t.Helper()
+	state.CurrentTurn = id
+	state.Players[id].Score = state.TargetScore - 100
+	state.Dice = []int{1, 2, 3, 4, 6, 2}
+	helper.SendMessage(id, "select", interfaces.M{"diceIndex": 0})
+	helper.SendMessage(id, "set_aside", interfaces.M{"endTurn": true})
+	if state.Winner != state.Players[id].Name {
+		t.Fatalf("Expected %s to win, got winner %q", id, state.Winner)
+	}
+}
+
+func TestDiceGame_Rematch(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+	playerIds := setupStartedGame(helper, 3)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+	state := testRoom.State().(*GameState)
+	starter := state.Series.Starter
+	if starter != state.CurrentTurn {
+		t.Fatalf("Expected the series to know the starter %s, got %s", state.CurrentTurn, starter)
+	}
+
+	helper.ClearAllMessages()
+	helper.SendMessage(playerIds[1], "rematch", nil)
+	helper.AssertMessageReceived(playerIds[1], "error")
+	if len(state.Series.Votes) != 0 {
+		t.Fatalf("Expected no rematch votes while the game is running, got %v", state.Series.Votes)
+	}
+
+	winGame(t, helper, state, playerIds[0])
+	if state.Series.Games != 1 || state.Series.Wins[playerIds[0]] != 1 {
+		t.Fatalf("Expected the win to count in the series, got %+v", state.Series)
+	}
+
+	// a second vote takes the first one back
+	helper.SendMessage(playerIds[0], "rematch", nil)
+	helper.SendMessage(playerIds[0], "rematch", nil)
+	helper.SendMessage(playerIds[0], "rematch", nil)
+	helper.SendMessage(playerIds[1], "rematch", nil)
+	if state.Winner == "" || len(state.Series.Votes) != 2 {
+		t.Fatalf("Expected the rematch to wait for every player, got votes %v", state.Series.Votes)
+	}
+
+	helper.SendMessage(playerIds[2], "rematch", nil)
+	if state.Winner != "" || !state.Started || len(state.Series.Votes) != 0 {
+		t.Fatalf("Expected the rematch to start, got winner %q and votes %v", state.Winner, state.Series.Votes)
+	}
+	if len(state.PlayerOrder) != 3 || state.Players[playerIds[0]].Score != 0 {
+		t.Errorf("Expected the players to keep their seats with no score, got %v", state.PlayerOrder)
+	}
+	if next := nextPlayer(state, starter); state.CurrentTurn != next || state.Series.Starter != next {
+		t.Errorf("Expected %s to start after %s, got %s", next, starter, state.CurrentTurn)
+	}
+	if state.Series.Wins[playerIds[0]] != 1 {
+		t.Errorf("Expected the series score to carry over, got %v", state.Series.Wins)
+	}
+}
+
+func TestDiceGame_RematchAfterLeave(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+	playerIds := setupStartedGame(helper, 3)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+	state := testRoom.State().(*GameState)
+
+	winGame(t, helper, state, playerIds[1])
+	helper.SendMessage(playerIds[0], "rematch", nil)
+	helper.SendMessage(playerIds[1], "rematch", nil)
+	helper.SendMessage(playerIds[2], "leave_room", nil)
+
+	if state.Winner != "" || len(state.PlayerOrder) != 2 {
+		t.Fatalf("Expected the players left to get their rematch, got winner %q with %v", state.Winner, state.PlayerOrder)
+	}
+
+	winGame(t, helper, state, playerIds[0])
+	helper.SendMessage(playerIds[1], "leave_room", nil)
+	helper.ClearAllMessages()
+	helper.SendMessage(playerIds[0], "rematch", nil)
+	helper.AssertMessageReceived(playerIds[0], "error")
+	if state.Winner == "" {
+		t.Error("Expected no rematch for a single player")
+	}
+}
@@ -0,0 +1,111 @@
+package dicegame
+
+import (
+	"gameserver/internal/rng"
+	"gameserver/internal/testicles"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDiceGame_ScriptedBust(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+
+	// first draw picks the starting player (player-0), the rest are the dice faces
+	helper.UseRNG(rng.NewScripted(0, 1, 1, 2, 3, 5, 5))
+	playerIds := setupStartedGame(helper, 2)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+
+	state := testRoom.State().(*GameState)
+	if state.CurrentTurn != playerIds[0] {
+		t.Fatalf("expected %s to start, got %s", playerIds[0], state.CurrentTurn)
+	}
+
+	g := NewDiceGame()
+	if busted := g.handleRoll(testRoom); !busted {
+		t.Errorf("expected a bust for dice %v", state.Dice)
+	}
+
+	if !slices.Equal(state.Dice, []int{2, 2, 3, 4, 6, 6}) {
+		t.Errorf("expected scripted dice, got %v", state.Dice)
+	}
+}
+
+func TestDiceGame_BustEndsTurnAfterAnimation(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+
+	helper.UseRNG(rng.NewScripted(0, 1, 1, 2, 3, 5, 5))
+	playerIds := setupStartedGame(helper, 2)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+	state := testRoom.State().(*GameState)
+
+	helper.SendMessage(playerIds[0], "roll", nil)
+	helper.Clock.Advance(BustedAnimationDelay - time.Millisecond)
+	if state.CurrentTurn != playerIds[0] || helper.VerifyMessageReceived(playerIds[1], "busted") {
+		t.Fatalf("expected the turn to last until the bust animation is over, got the turn of %s", state.CurrentTurn)
+	}
+
+	helper.Clock.Advance(time.Millisecond)
+	helper.AssertMessageReceived(playerIds[1], "busted")
+	if state.CurrentTurn != playerIds[1] {
+		t.Errorf("expected the turn to pass after the bust animation, got %s", state.CurrentTurn)
+	}
+}
+
+func TestDiceGame_BustedPlayerLeavesDuringAnimation(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+
+	helper.UseRNG(rng.NewScripted(0, 1, 1, 2, 3, 5, 5))
+	playerIds := setupStartedGame(helper, 3)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+	state := testRoom.State().(*GameState)
+
+	helper.SendMessage(playerIds[0], "roll", nil)
+	helper.SendMessage(playerIds[0], "leave_room", nil)
+	if state.CurrentTurn != playerIds[1] {
+		t.Fatalf("expected the turn to pass when the player left, got %s", state.CurrentTurn)
+	}
+
+	// the animation ending does not end the turn of the next player
+	helper.Clock.Advance(BustedAnimationDelay)
+	if state.CurrentTurn != playerIds[1] {
+		t.Errorf("expected %s to keep the turn, got %s", playerIds[1], state.CurrentTurn)
+	}
+}
+
+func TestDiceGame_ReplaySeededRoom(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+
+	setupStartedGame(helper, 2)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+
+	state := testRoom.State().(*GameState)
+	helper.SendMessage(state.CurrentTurn, "roll", nil)
+
+	j := helper.Journal()
+	if j.Header.Seed == nil || *j.Header.Seed != testicles.DefaultSeed {
+		t.Fatalf("expected journal to record seed %v, got %v", testicles.DefaultSeed, j.Header.Seed)
+	}
+
+	testicles.AssertReplay(t, NewDiceGame(), j)
+}
@@ -0,0 +1,260 @@
+package dicegame
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Names of the rulesets a room can be created with
+const (
+	RulesetKingdomCome = "kingdom_come" // the rules of Kingdom Come: Deliverance 2, the default
+	RulesetClassic     = "classic"      // classic Farkle
+	RulesetZehntausend = "zehntausend"  // the German Zehntausend (10,000)
+	RulesetCustom      = "custom"       // a ruleset with rules overridden by the room options
+)
+
+// Ruleset are the scoring rules and the target score of a room. Every score has to be a
+// multiple of 50, the expected value strategy relies on it.
+type Ruleset struct {
+	Name string `json:"name"`
+	// Base is the ruleset a custom ruleset overrides rules of
+	Base        string `json:"base,omitempty"`
+	TargetScore int    `json:"targetScore"`
+	// Singles are the points of a single die by face, other faces only score in combinations
+	Singles map[int]int `json:"singles"`
+	// ThreeOfAKind are the points of three dice of a face
+	ThreeOfAKind map[int]int `json:"threeOfAKind"`
+	// Multiplier multiplies the points of three of a kind for every further die of the face
+	Multiplier int `json:"multiplier"`
+	// Multiples are fixed points of four, five or six of a kind by the number of dice,
+	// they replace the multiplier for those counts
+	Multiples map[int]int `json:"multiples,omitempty"`
+	// Runs score one die of every face of a range, only the first run found in the dice counts
+	Runs []Run `json:"runs"`
+	// ThreePairs, TwoTriplets and FourOfAKindAndPair are the points of six dice combinations,
+	// 0 disables them. They score when they beat the other combinations in the dice.
+	ThreePairs         int `json:"threePairs,omitempty"`
+	TwoTriplets        int `json:"twoTriplets,omitempty"`
+	FourOfAKindAndPair int `json:"fourOfAKindAndPair,omitempty"`
+	// OpeningScore is the least a player has to bank at once to get on the board
+	OpeningScore int `json:"openingScore,omitempty"`
+	// HotDice lets a player roll all six dice again after every die scored, otherwise the turn ends
+	HotDice bool `json:"hotDice"`
+	// FinalRound gives every other player a last turn once a player reached the target score,
+	// the highest score wins
+	FinalRound bool `json:"finalRound"`
+}
+
+// Run is a range of faces that scores when one die of each is set aside together
+type Run struct {
+	From   int `json:"from"`
+	To     int `json:"to"`
+	Points int `json:"points"`
+}
+
+var (
+	ErrUnknownRuleset = errors.New("unknown ruleset")
+	ErrInvalidRuleset = errors.New("invalid ruleset")
+)
+
+// rulesets are the named rulesets, NewRuleset copies them so rooms can't change them
+var rulesets = map[string]Ruleset{
+	RulesetKingdomCome: {
+		Name:         RulesetKingdomCome,
+		TargetScore:  3000,
+		Singles:      map[int]int{1: 100, 5: 50},
+		ThreeOfAKind: map[int]int{1: 1000, 2: 200, 3: 300, 4: 400, 5: 500, 6: 600},
+		Multiplier:   2,
+		Runs:         []Run{{From: 1, To: 6, Points: 1500}, {From: 1, To: 5, Points: 500}, {From: 2, To: 6, Points: 750}},
+		HotDice:      true,
+	},
+	RulesetClassic: {
+		Name:         RulesetClassic,
+		TargetScore:  10000,
+		Singles:      map[int]int{1: 100, 5: 50},
+		ThreeOfAKind: map[int]int{1: 1000, 2: 200, 3: 300, 4: 400, 5: 500, 6: 600},
+		Multiplier:   2,
+		Multiples:    map[int]int{4: 1000, 5: 2000, 6: 3000},
+		Runs:         []Run{{From: 1, To: 6, Points: 1500}},
+		HotDice:      true,
+	},
+	RulesetZehntausend: {
+		Name:         RulesetZehntausend,
+		TargetScore:  10000,
+		Singles:      map[int]int{1: 100, 5: 50},
+		ThreeOfAKind: map[int]int{1: 1000, 2: 200, 3: 300, 4: 400, 5: 500, 6: 600},
+		Multiplier:   2,
+		Runs:         []Run{{From: 1, To: 6, Points: 2000}},
+		HotDice:      true,
+	},
+}
+
+// defaultRuleset scores rooms and turns without a ruleset
+var defaultRuleset, _ = NewRuleset(RulesetKingdomCome, nil)
+
+// RulesetNames returns the names of the rulesets a room can be created with
+func RulesetNames() []string {
+	return append(slices.Sorted(maps.Keys(rulesets)), RulesetCustom)
+}
+
+// NewRuleset returns the named ruleset, the default one for "". Overrides is a partial ruleset
+// in JSON replacing single rules, e.g. {"singles":{"5":100}}, which makes the ruleset custom.
+// The custom ruleset is the default one with overrides.
+func NewRuleset(name string, overrides json.RawMessage) (*Ruleset, error) {
+	base := name
+	if name == "" || name == RulesetCustom {
+		base = RulesetKingdomCome
+	}
+	named, ok := rulesets[base]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownRuleset, name)
+	}
+
+	ruleset := named.clone()
+	if len(overrides) > 0 || name == RulesetCustom {
+		if len(overrides) > 0 {
+			decoder := json.NewDecoder(bytes.NewReader(overrides))
+			decoder.DisallowUnknownFields()
+			if err := decoder.Decode(ruleset); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidRuleset, err)
+			}
+		}
+		ruleset.Name = RulesetCustom
+		ruleset.Base = base
+	}
+
+	if err := ruleset.validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRuleset, err)
+	}
+	return ruleset, nil
+}
+
+func (r Ruleset) clone() *Ruleset {
+	r.Singles = maps.Clone(r.Singles)
+	r.ThreeOfAKind = maps.Clone(r.ThreeOfAKind)
+	r.Multiples = maps.Clone(r.Multiples)
+	r.Runs = slices.Clone(r.Runs)
+	return &r
+}
+
+func (r *Ruleset) validate() error {
+	if r.TargetScore <= 0 {
+		return fmt.Errorf("target score %d is not positive", r.TargetScore)
+	}
+	if r.Multiplier < 1 || r.Multiplier > 10 {
+		return fmt.Errorf("multiplier %d is not between 1 and 10", r.Multiplier)
+	}
+
+	checkPoints := func(rule string, byKey map[int]int, from, to int) error {
+		for key, points := range byKey {
+			if key < from || key > to {
+				return fmt.Errorf("%s of %d, which is not between %d and %d", rule, key, from, to)
+			}
+			if points < 0 || points%50 != 0 {
+				return fmt.Errorf("%s of %d score %d, which is not a multiple of 50", rule, key, points)
+			}
+		}
+		return nil
+	}
+	if err := checkPoints("singles", r.Singles, 1, MAX_DICE); err != nil {
+		return err
+	}
+	if err := checkPoints("three of a kind", r.ThreeOfAKind, 1, MAX_DICE); err != nil {
+		return err
+	}
+	if err := checkPoints("multiples", r.Multiples, 4, MAX_DICE); err != nil {
+		return err
+	}
+
+	byRule := map[string]int{
+		"three pairs":               r.ThreePairs,
+		"two triplets":              r.TwoTriplets,
+		"four of a kind and a pair": r.FourOfAKindAndPair,
+		"opening score":             r.OpeningScore,
+	}
+	for rule, points := range byRule {
+		if points < 0 || points%50 != 0 {
+			return fmt.Errorf("%s of %d is not a multiple of 50", rule, points)
+		}
+	}
+
+	for _, run := range r.Runs {
+		if run.From < 1 || run.To > MAX_DICE || run.From >= run.To {
+			return fmt.Errorf("run from %d to %d is not within 1 and %d", run.From, run.To, MAX_DICE)
+		}
+		if run.Points <= 0 || run.Points%50 != 0 {
+			return fmt.Errorf("run from %d to %d scores %d, which is not a positive multiple of 50", run.From, run.To, run.Points)
+		}
+	}
+	return nil
+}
+
+// scoringKey identifies the scoring of the ruleset, regardless of its name and the rules
+// that don't change the score of a turn
+func (r *Ruleset) scoringKey() string {
+	scoring := *r
+	scoring.Name, scoring.Base, scoring.TargetScore = "", "", 0
+	scoring.OpeningScore, scoring.FinalRound = 0, false
+	key, _ := json.Marshal(scoring)
+	return string(key)
+}
+
+// CalculateScore scores the dice and reports whether every die is part of a scoring combination.
+// The dice are sorted in place.
+func (r *Ruleset) CalculateScore(dice []int) (int, bool) {
+	// Sort dice for easier combination checking
+	sort.Ints(dice)
+	log.Debug().Ints("dice", dice).Msg("Calculating score for dice")
+
+	scoring := r.Score(dice)
+
+	log.Info().Int("final_score", scoring.Score).Bool("valid", scoring.Valid).Any("combinations", scoring.Combinations).Msg("Final score calculation")
+	return scoring.Score, scoring.Valid
+}
+
+// scoreDice scores dice and reports whether every die is part of a scoring combination
+func (r *Ruleset) scoreDice(dice []int) (int, bool) {
+	scoring := r.Score(dice)
+	return scoring.Score, scoring.Valid
+}
+
+// canBank reports whether a player with a banked score may bank the round score,
+// the first banked score has to reach the opening score
+func (r *Ruleset) canBank(score int, roundScore int) bool {
+	return score > 0 || roundScore >= r.OpeningScore
+}
+
+// diceLeftAfter returns how many dice are rolled next after setting aside count of dice,
+// none when every die scored without hot dice
+func (r *Ruleset) diceLeftAfter(dice int, count int) int {
+	if dice != count {
+		return dice - count
+	}
+	if r.HotDice {
+		return MAX_DICE
+	}
+	return 0
+}
+
+// ofAKind returns the points of count dice of a face, 0 for less than three
+func (r *Ruleset) ofAKind(face int, count int) int {
+	points := r.ThreeOfAKind[face]
+	if count < 3 || points == 0 {
+		return 0
+	}
+	if fixed := r.Multiples[count]; fixed > 0 {
+		return fixed
+	}
+	// Multiply the score for each additional die beyond three
+	for i := 3; i < count; i++ {
+		points *= r.Multiplier
+	}
+	return points
+}
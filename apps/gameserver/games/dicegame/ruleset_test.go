@@ -0,0 +1,106 @@
+package dicegame
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"gameserver/internal/testicles"
+)
+
+func TestNewRuleset(t *testing.T) {
+	ruleset, err := NewRuleset("", nil)
+	if err != nil || ruleset.Name != RulesetKingdomCome || ruleset.TargetScore != 3000 {
+		t.Fatalf("Expected the kingdom come rules by default, got %+v (%v)", ruleset, err)
+	}
+
+	if _, err := NewRuleset("yahtzee", nil); !errors.Is(err, ErrUnknownRuleset) {
+		t.Errorf("Expected an unknown ruleset to fail, got %v", err)
+	}
+
+	custom, err := NewRuleset(RulesetClassic, json.RawMessage(`{"singles":{"5":100},"targetScore":5000}`))
+	if err != nil {
+		t.Fatalf("Failed to override rules: %v", err)
+	}
+	if custom.Name != RulesetCustom || custom.Base != RulesetClassic {
+		t.Errorf("Expected a custom ruleset based on classic, got %s based on %s", custom.Name, custom.Base)
+	}
+	if custom.Singles[1] != 100 || custom.Singles[5] != 100 || custom.TargetScore != 5000 {
+		t.Errorf("Expected only the overridden rules to change, got singles %v and target %d", custom.Singles, custom.TargetScore)
+	}
+	if rulesets[RulesetClassic].Singles[5] != 50 {
+		t.Error("Expected overrides to leave the named ruleset alone")
+	}
+
+	invalid := []string{
+		`{"singles":{"5":75}}`,
+		`{"singles":{"7":100}}`,
+		`{"runs":[{"from":3,"to":8,"points":500}]}`,
+		`{"multiplier":0}`,
+		`{"targetScore":-1}`,
+		`{"jokers":true}`,
+	}
+	for _, overrides := range invalid {
+		if _, err := NewRuleset("", json.RawMessage(overrides)); !errors.Is(err, ErrInvalidRuleset) {
+			t.Errorf("Expected %s to be invalid, got %v", overrides, err)
+		}
+	}
+}
+
+func TestRuleset_CalculateScore(t *testing.T) {
+	testCases := []struct {
+		ruleset  string
+		dice     []int
+		expected int
+		valid    bool
+	}{
+		{RulesetClassic, []int{2, 2, 2, 2}, 1000, true},
+		{RulesetClassic, []int{1, 1, 1, 1, 1, 1}, 3000, true},
+		{RulesetClassic, []int{1, 2, 3, 4, 5}, 150, false},
+		{RulesetClassic, []int{1, 2, 3, 4, 5, 6}, 1500, true},
+		{RulesetZehntausend, []int{3, 3, 3, 3, 3}, 1200, true},
+		{RulesetZehntausend, []int{2, 3, 4, 5, 6}, 50, false},
+		{RulesetZehntausend, []int{1, 2, 3, 4, 5, 6}, 2000, true},
+	}
+
+	for _, tc := range testCases {
+		ruleset, _ := NewRuleset(tc.ruleset, nil)
+		score, valid := ruleset.CalculateScore(tc.dice)
+		if score != tc.expected || valid != tc.valid {
+			t.Errorf("Expected %v to score %d (valid=%v) by %s, got %d (valid=%v)", tc.dice, tc.expected, tc.valid, tc.ruleset, score, valid)
+		}
+	}
+}
+
+func TestDiceGame_RulesetOptions(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+
+	player := helper.CreateClient("player-0")
+	helper.CreateRoomWithOptions(player, "dicegame", "player-0", RoomOptions{
+		Ruleset:     RulesetZehntausend,
+		Rules:       json.RawMessage(`{"threeOfAKind":{"1":1500}}`),
+		TargetScore: 4000,
+	})
+
+	room, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+	state := room.State().(*GameState)
+	if state.TargetScore != 4000 || state.Ruleset.TargetScore != 4000 {
+		t.Errorf("Expected a target score of 4000, got %d", state.TargetScore)
+	}
+	if state.Ruleset.Base != RulesetZehntausend || state.Ruleset.ThreeOfAKind[1] != 1500 {
+		t.Errorf("Expected custom zehntausend rules, got %+v", state.Ruleset)
+	}
+
+	// clients get the scoring table with the game state
+	data, _ := json.Marshal(state)
+	var echoed struct {
+		Ruleset Ruleset `json:"ruleset"`
+	}
+	if err := json.Unmarshal(data, &echoed); err != nil || echoed.Ruleset.Runs[0].Points != 2000 {
+		t.Errorf("Expected the game state to carry the ruleset, got %s", data)
+	}
+}
@@ -0,0 +1,195 @@
+package dicegame
+
+import (
+	"slices"
+)
+
+// Names of the scoring combinations
+const (
+	CombinationSingle             = "single"
+	CombinationThreeOfAKind       = "three_of_a_kind"
+	CombinationFourOfAKind        = "four_of_a_kind"
+	CombinationFiveOfAKind        = "five_of_a_kind"
+	CombinationSixOfAKind         = "six_of_a_kind"
+	CombinationRun                = "run"
+	CombinationThreePairs         = "three_pairs"
+	CombinationTwoTriplets        = "two_triplets"
+	CombinationFourOfAKindAndPair = "four_of_a_kind_and_pair"
+)
+
+// ofAKindNames names the combinations of dice of a face by their count
+var ofAKindNames = [MAX_DICE + 1]string{
+	3: CombinationThreeOfAKind,
+	4: CombinationFourOfAKind,
+	5: CombinationFiveOfAKind,
+	6: CombinationSixOfAKind,
+}
+
+// Combination is a scoring combination found in dice
+type Combination struct {
+	Name string `json:"name"`
+	// Face is the face of singles and of a kind combinations
+	Face int `json:"face,omitempty"`
+	// Dice are the indexes of the dice the combination uses
+	Dice   []int `json:"dice"`
+	Points int   `json:"points"`
+}
+
+// Scoring is the breakdown of the score of some dice
+type Scoring struct {
+	Score int `json:"score"`
+	// Valid reports whether every die is part of a combination
+	Valid        bool          `json:"valid"`
+	Combinations []Combination `json:"combinations"`
+	// NonScoring are the indexes of the dice that are part of no combination
+	NonScoring []int `json:"nonScoring"`
+}
+
+// ScoringDice returns the indexes of the dice that are part of a combination, in order
+func (s Scoring) ScoringDice() []int {
+	indexes := make([]int, 0)
+	for _, combination := range s.Combinations {
+		indexes = append(indexes, combination.Dice...)
+	}
+	slices.Sort(indexes)
+	return indexes
+}
+
+// remap returns the scoring with the dice indexes translated, indexes[i] is the index die i
+// has elsewhere, e.g. the index of a selected die in the roll
+func (s Scoring) remap(indexes []int) Scoring {
+	mapIndexes := func(dice []int) []int {
+		mapped := make([]int, len(dice))
+		for i, idx := range dice {
+			mapped[i] = indexes[idx]
+		}
+		slices.Sort(mapped)
+		return mapped
+	}
+
+	remapped := s
+	remapped.Combinations = make([]Combination, len(s.Combinations))
+	for i, combination := range s.Combinations {
+		combination.Dice = mapIndexes(combination.Dice)
+		remapped.Combinations[i] = combination
+	}
+	remapped.NonScoring = mapIndexes(s.NonScoring)
+	return remapped
+}
+
+// Score breaks down the score of the dice into combinations, the dice are left in order.
+// Runs are found first, then multiples of a face and then single dice.
+func (r *Ruleset) Score(dice []int) Scoring {
+	scoring := Scoring{Combinations: make([]Combination, 0), NonScoring: make([]int, 0)}
+	if len(dice) == 0 {
+		return scoring
+	}
+
+	// indexes of the dice by face, dice that aren't rolled yet score nothing
+	var byFace [MAX_DICE + 1][]int
+	for i, die := range dice {
+		if die < 1 || die > MAX_DICE {
+			scoring.NonScoring = append(scoring.NonScoring, i)
+			continue
+		}
+		byFace[die] = append(byFace[die], i)
+	}
+
+	add := func(combination Combination) {
+		slices.Sort(combination.Dice)
+		scoring.Combinations = append(scoring.Combinations, combination)
+		scoring.Score += combination.Points
+	}
+
+	// Check for runs first, a run takes one die of every face
+	for _, run := range r.Runs {
+		if !containsRun(byFace, run.From, run.To) {
+			continue
+		}
+		combination := Combination{Name: CombinationRun, Points: run.Points}
+		for face := run.From; face <= run.To; face++ {
+			combination.Dice = append(combination.Dice, byFace[face][0])
+			byFace[face] = byFace[face][1:]
+		}
+		add(combination)
+		break
+	}
+
+	// Check for three of a kind and beyond
+	for face := 1; face <= MAX_DICE; face++ {
+		count := len(byFace[face])
+		if points := r.ofAKind(face, count); points > 0 {
+			add(Combination{Name: ofAKindNames[count], Face: face, Dice: byFace[face], Points: points})
+			byFace[face] = nil
+		}
+	}
+
+	// Check for individual scoring dice from remaining dice
+	for face := 1; face <= MAX_DICE; face++ {
+		points := r.Singles[face]
+		for _, idx := range byFace[face] {
+			if points > 0 {
+				add(Combination{Name: CombinationSingle, Face: face, Dice: []int{idx}, Points: points})
+			} else {
+				scoring.NonScoring = append(scoring.NonScoring, idx)
+			}
+		}
+	}
+	slices.Sort(scoring.NonScoring)
+	scoring.Valid = len(scoring.NonScoring) == 0
+
+	// Six dice combinations use every die, they count when they beat the combinations above
+	if name, points := r.sixDiceCombination(dice); points > 0 && (!scoring.Valid || points > scoring.Score) {
+		all := make([]int, len(dice))
+		for i := range all {
+			all[i] = i
+		}
+		return Scoring{
+			Score:        points,
+			Valid:        true,
+			Combinations: []Combination{{Name: name, Dice: all, Points: points}},
+			NonScoring:   make([]int, 0),
+		}
+	}
+	return scoring
+}
+
+// sixDiceCombination returns three pairs, two triplets or four of a kind and a pair in the
+// dice with their points, 0 for none or a disabled one
+func (r *Ruleset) sixDiceCombination(dice []int) (string, int) {
+	if len(dice) != MAX_DICE {
+		return "", 0
+	}
+	counts := make(map[int]int)
+	for _, die := range dice {
+		if die < 1 || die > MAX_DICE {
+			return "", 0
+		}
+		counts[die]++
+	}
+	values := make([]int, 0, len(counts))
+	for _, count := range counts {
+		values = append(values, count)
+	}
+	slices.Sort(values)
+
+	switch {
+	case slices.Equal(values, []int{2, 2, 2}):
+		return CombinationThreePairs, r.ThreePairs
+	case slices.Equal(values, []int{3, 3}):
+		return CombinationTwoTriplets, r.TwoTriplets
+	case slices.Equal(values, []int{2, 4}):
+		return CombinationFourOfAKindAndPair, r.FourOfAKindAndPair
+	}
+	return "", 0
+}
+
+// containsRun reports whether there is a die of every face from start to end
+func containsRun(byFace [MAX_DICE + 1][]int, start, end int) bool {
+	for face := start; face <= end; face++ {
+		if len(byFace[face]) == 0 {
+			return false
+		}
+	}
+	return true
+}
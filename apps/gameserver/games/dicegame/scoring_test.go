@@ -0,0 +1,157 @@
+package dicegame
+
+import (
+	"encoding/json"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/testicles"
+	"slices"
+	"testing"
+)
+
+func TestRuleset_Score(t *testing.T) {
+	advanced, err := NewRuleset("", json.RawMessage(`{"threePairs":1500}`))
+	if err != nil {
+		t.Fatalf("Failed to create ruleset: %v", err)
+	}
+
+	testCases := []struct {
+		name         string
+		rules        *Ruleset
+		dice         []int
+		combinations []Combination
+		nonScoring   []int
+	}{
+		{
+			name:  "singles and dice that don't score",
+			rules: defaultRuleset,
+			dice:  []int{5, 2, 1, 3},
+			combinations: []Combination{
+				{Name: CombinationSingle, Face: 1, Dice: []int{2}, Points: 100},
+				{Name: CombinationSingle, Face: 5, Dice: []int{0}, Points: 50},
+			},
+			nonScoring: []int{1, 3},
+		},
+		{
+			name:  "run before singles",
+			rules: defaultRuleset,
+			dice:  []int{6, 1, 4, 2, 3, 5},
+			combinations: []Combination{
+				{Name: CombinationRun, Dice: []int{0, 1, 2, 3, 4, 5}, Points: 1500},
+			},
+		},
+		{
+			name:  "run and a single of the same face",
+			rules: defaultRuleset,
+			dice:  []int{5, 4, 3, 2, 1, 5},
+			combinations: []Combination{
+				{Name: CombinationRun, Dice: []int{0, 1, 2, 3, 4}, Points: 500},
+				{Name: CombinationSingle, Face: 5, Dice: []int{5}, Points: 50},
+			},
+		},
+		{
+			name:  "four of a kind",
+			rules: defaultRuleset,
+			dice:  []int{4, 4, 6, 4, 4},
+			combinations: []Combination{
+				{Name: CombinationFourOfAKind, Face: 4, Dice: []int{0, 1, 3, 4}, Points: 800},
+			},
+			nonScoring: []int{2},
+		},
+		{
+			name:  "three pairs",
+			rules: advanced,
+			dice:  []int{2, 6, 2, 6, 3, 3},
+			combinations: []Combination{
+				{Name: CombinationThreePairs, Dice: []int{0, 1, 2, 3, 4, 5}, Points: 1500},
+			},
+		},
+		{
+			name:       "dice that aren't rolled",
+			rules:      defaultRuleset,
+			dice:       []int{0, 0, 0},
+			nonScoring: []int{0, 1, 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dice := slices.Clone(tc.dice)
+			scoring := tc.rules.Score(dice)
+			if !slices.Equal(dice, tc.dice) {
+				t.Errorf("Expected the dice to be left in order, got %v", dice)
+			}
+
+			score := 0
+			for _, combination := range tc.combinations {
+				score += combination.Points
+			}
+			if scoring.Score != score || scoring.Valid != (len(tc.nonScoring) == 0) {
+				t.Errorf("Expected a score of %d, got %d (valid=%v)", score, scoring.Score, scoring.Valid)
+			}
+			if !slices.EqualFunc(scoring.Combinations, tc.combinations, func(a, b Combination) bool {
+				return a.Name == b.Name && a.Face == b.Face && a.Points == b.Points && slices.Equal(a.Dice, b.Dice)
+			}) {
+				t.Errorf("Expected combinations %+v, got %+v", tc.combinations, scoring.Combinations)
+			}
+			if !slices.Equal(scoring.NonScoring, tc.nonScoring) {
+				t.Errorf("Expected non scoring dice %v, got %v", tc.nonScoring, scoring.NonScoring)
+			}
+
+			if total, valid := tc.rules.scoreDice(dice); total != scoring.Score || valid != scoring.Valid {
+				t.Errorf("Expected scoreDice to agree with the breakdown, got %d (valid=%v)", total, valid)
+			}
+		})
+	}
+}
+
+func TestDiceGame_ScoringInGameState(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+	playerIds := setupStartedGame(helper, 2)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+	state := testRoom.State().(*GameState)
+	state.CurrentTurn = playerIds[0]
+
+	helper.SendMessage(playerIds[0], "roll", nil)
+	if state.Scoring == nil {
+		t.Fatal("Expected the game state to carry the scoring of the roll")
+	}
+	if score, valid := state.rules().scoreDice(state.Dice); state.Scoring.Score != score || state.Scoring.Valid != valid {
+		t.Errorf("Expected the scoring of %v, got %+v", state.Dice, state.Scoring)
+	}
+
+	state.Dice = []int{2, 3, 3, 5, 1, 1}
+	helper.SendMessage(playerIds[0], "select", interfaces.M{"diceIndex": 5})
+	helper.SendMessage(playerIds[0], "select", interfaces.M{"diceIndex": 0})
+	helper.SendMessage(playerIds[0], "select", interfaces.M{"diceIndex": 3})
+
+	selected := state.SelectedScoring
+	if selected == nil || selected.Score != 150 || selected.Valid {
+		t.Fatalf("Expected the selection to score 150 with a die that doesn't score, got %+v", selected)
+	}
+	if got := selected.ScoringDice(); !slices.Equal(got, []int{3, 5}) {
+		t.Errorf("Expected the scoring dice by their index in the roll, got %v", got)
+	}
+	if !slices.Equal(selected.NonScoring, []int{0}) {
+		t.Errorf("Expected the 2 not to score, got %v", selected.NonScoring)
+	}
+
+	// clients get the breakdown with the game state
+	data, _ := json.Marshal(state)
+	var echoed struct {
+		SelectedScoring Scoring `json:"selectedScoring"`
+	}
+	if err := json.Unmarshal(data, &echoed); err != nil || len(echoed.SelectedScoring.Combinations) != 2 {
+		t.Errorf("Expected the game state to carry the selected scoring, got %s", data)
+	}
+
+	helper.SendMessage(playerIds[0], "select", interfaces.M{"diceIndex": 0})
+	helper.SendMessage(playerIds[0], "set_aside", interfaces.M{"endTurn": false})
+	if state.Scoring != nil || state.SelectedScoring != nil {
+		t.Errorf("Expected setting dice aside to clear the scoring, got %+v and %+v", state.Scoring, state.SelectedScoring)
+	}
+}
@@ -0,0 +1,118 @@
+package dicegame
+
+import (
+	"gameserver/internal/client"
+	"gameserver/internal/interfaces"
+	"slices"
+)
+
+// Turn is what a strategy sees of its bot's turn
+type Turn struct {
+	Dice        []int // the rolled dice, selections are indexes into them
+	RoundScore  int   // points set aside this turn, lost on a bust
+	Score       int   // banked points
+	TargetScore int
+	Rules       *Ruleset // the scoring rules, the default ones if nil
+}
+
+// rules returns the ruleset the turn is scored by
+func (t Turn) rules() *Ruleset {
+	if t.Rules == nil {
+		return defaultRuleset
+	}
+	return t.Rules
+}
+
+// Strategy decides the moves of a dice game bot while it is its turn
+type Strategy interface {
+	// SelectDice returns the indexes of the dice to set aside, together they have to score
+	SelectDice(turn Turn) []int
+	// ShouldEndTurn decides whether to bank after setting aside dice worth selectedScore,
+	// leaving diceLeft dice to roll (all six again after every die scored, none without hot dice)
+	ShouldEndTurn(turn Turn, selectedScore int, diceLeft int) bool
+}
+
+// newStrategies returns the strategies dice game bots can play
+func newStrategies() *client.StrategyRegistry[Strategy] {
+	strategies := client.NewStrategyRegistry[Strategy]("normal")
+	strategies.Register("easy", easyStrategy{}, interfaces.DifficultyEasy)
+	strategies.Register("normal", normalStrategy{}, interfaces.DifficultyNormal)
+	strategies.Register("expected_value", expectedValueStrategy{}, interfaces.DifficultyHard)
+	return strategies
+}
+
+// selection is a set of dice that scores on its own
+type selection struct {
+	indexes []int
+	score   int
+}
+
+// scoringSelections returns every set of dice that can be set aside
+func scoringSelections(rules *Ruleset, dice []int) []selection {
+	var selections []selection
+	for mask := 1; mask < 1<<len(dice); mask++ {
+		var indexes, values []int
+		for i, die := range dice {
+			if mask&(1<<i) != 0 {
+				indexes = append(indexes, i)
+				values = append(values, die)
+			}
+		}
+		slices.Sort(values)
+		if score, valid := rules.scoreDice(values); valid && score > 0 {
+			selections = append(selections, selection{indexes: indexes, score: score})
+		}
+	}
+	return selections
+}
+
+// easyStrategy sets aside as few dice as possible and banks early
+type easyStrategy struct{}
+
+func (easyStrategy) SelectDice(turn Turn) []int {
+	var best *selection
+	for _, s := range scoringSelections(turn.rules(), turn.Dice) {
+		if best == nil || len(s.indexes) < len(best.indexes) || len(s.indexes) == len(best.indexes) && s.score > best.score {
+			best = &s
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.indexes
+}
+
+func (easyStrategy) ShouldEndTurn(turn Turn, selectedScore int, diceLeft int) bool {
+	return turn.RoundScore+selectedScore >= 300 || diceLeft < 3
+}
+
+// normalStrategy sets aside every scoring die and banks once half the dice are played
+type normalStrategy struct{}
+
+func (normalStrategy) SelectDice(turn Turn) []int {
+	return turn.rules().Score(turn.Dice).ScoringDice()
+}
+
+func (normalStrategy) ShouldEndTurn(turn Turn, selectedScore int, diceLeft int) bool {
+	return diceLeft <= 3 || diceLeft == MAX_DICE
+}
+
+// expectedValueStrategy picks the moves that maximize the expected score of the turn
+type expectedValueStrategy struct{}
+
+func (expectedValueStrategy) SelectDice(turn Turn) []int {
+	var best []int
+	bestValue := -1.0
+	for _, s := range scoringSelections(turn.rules(), turn.Dice) {
+		value := turnValue(turn.rules(), turn.rules().diceLeftAfter(len(turn.Dice), len(s.indexes)), turn.RoundScore+s.score)
+		if value > bestValue {
+			best, bestValue = s.indexes, value
+		}
+	}
+	return best
+}
+
+func (expectedValueStrategy) ShouldEndTurn(turn Turn, selectedScore int, diceLeft int) bool {
+	roundScore := turn.RoundScore + selectedScore
+	return float64(roundScore) >= rollValue(turn.rules(), diceLeft, roundScore)
+}
@@ -0,0 +1,133 @@
+package dicegame
+
+import (
+	"errors"
+	"gameserver/internal/client"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/rng"
+	"slices"
+	"testing"
+)
+
+func TestStrategies_Select(t *testing.T) {
+	strategies := newStrategies()
+
+	tests := []struct {
+		options interfaces.BotOptions
+		want    string
+	}{
+		{interfaces.BotOptions{}, "normal"},
+		{interfaces.BotOptions{Difficulty: interfaces.DifficultyEasy}, "easy"},
+		{interfaces.BotOptions{Difficulty: interfaces.DifficultyHard}, "expected_value"},
+		{interfaces.BotOptions{Strategy: "easy", Difficulty: interfaces.DifficultyHard}, "easy"},
+	}
+	for _, tt := range tests {
+		if name, _, err := strategies.Select(tt.options); err != nil || name != tt.want {
+			t.Errorf("expected %+v to select %s, got %s (%v)", tt.options, tt.want, name, err)
+		}
+	}
+
+	if _, _, err := strategies.Select(interfaces.BotOptions{Strategy: "cheater"}); !errors.Is(err, client.ErrUnknownStrategy) {
+		t.Errorf("expected an unknown strategy to fail, got %v", err)
+	}
+	if _, _, err := strategies.Select(interfaces.BotOptions{Difficulty: "insane"}); !errors.Is(err, client.ErrUnknownDifficulty) {
+		t.Errorf("expected an unknown difficulty to fail, got %v", err)
+	}
+}
+
+func TestStrategies_SelectScoringDice(t *testing.T) {
+	rolls := [][]int{
+		{1, 2, 3, 4, 6, 6},
+		{1, 1, 1, 5, 2, 3},
+		{2, 2, 2, 3, 4, 6},
+		{1, 2, 3, 4, 5, 6},
+		{5, 3},
+		{4, 4, 4, 4},
+	}
+	for _, name := range newStrategies().Names() {
+		_, strategy, _ := newStrategies().Select(interfaces.BotOptions{Strategy: name})
+		for _, roll := range rolls {
+			indexes := strategy.SelectDice(Turn{Dice: roll, TargetScore: 3000})
+			var selected []int
+			for _, idx := range indexes {
+				selected = append(selected, roll[idx])
+			}
+			slices.Sort(selected)
+			if score, valid := defaultRuleset.scoreDice(selected); !valid || score == 0 {
+				t.Errorf("%s selected %v from %v, which doesn't score", name, selected, roll)
+			}
+		}
+	}
+
+	if indexes := (easyStrategy{}).SelectDice(Turn{Dice: []int{1, 1, 1, 5, 2, 3}}); len(indexes) != 1 {
+		t.Errorf("expected the easy strategy to set aside a single die, got %v", indexes)
+	}
+	if indexes := (expectedValueStrategy{}).SelectDice(Turn{Dice: []int{1, 2, 3, 4, 5, 6}}); len(indexes) != 6 {
+		t.Errorf("expected the expected value strategy to take the full run, got %v", indexes)
+	}
+}
+
+func TestExpectedValueStrategy_EndTurn(t *testing.T) {
+	strategy := expectedValueStrategy{}
+
+	if strategy.ShouldEndTurn(Turn{}, 50, 5) {
+		t.Error("expected to keep rolling five dice with 50 points at risk")
+	}
+	if !strategy.ShouldEndTurn(Turn{RoundScore: 900}, 100, 1) {
+		t.Error("expected to bank 1000 points instead of rolling a single die")
+	}
+	if strategy.ShouldEndTurn(Turn{RoundScore: 900}, 100, MAX_DICE) {
+		t.Error("expected to roll all six dice again after every die scored")
+	}
+	if value := rollValue(defaultRuleset, MAX_DICE, 0); value < 300 || value > 800 {
+		t.Errorf("expected a turn to be worth a few hundred points, got %.0f", value)
+	}
+}
+
+// simulateTurn plays a turn the way a bot does and returns the points banked
+func simulateTurn(strategy Strategy, random rng.RNG) int {
+	turn := Turn{TargetScore: 1 << 30}
+	dice := MAX_DICE
+	for {
+		turn.Dice = make([]int, dice)
+		for i := range turn.Dice {
+			turn.Dice[i] = random.Intn(MAX_DICE) + 1
+		}
+		slices.Sort(turn.Dice)
+		if score, _ := defaultRuleset.scoreDice(turn.Dice); score == 0 {
+			return 0
+		}
+
+		indexes := strategy.SelectDice(turn)
+		selected := make([]int, 0, len(indexes))
+		for _, idx := range indexes {
+			selected = append(selected, turn.Dice[idx])
+		}
+		slices.Sort(selected)
+		score, _ := defaultRuleset.scoreDice(selected)
+
+		dice = defaultRuleset.diceLeftAfter(dice, len(selected))
+		if strategy.ShouldEndTurn(turn, score, dice) {
+			return turn.RoundScore + score
+		}
+		turn.RoundScore += score
+	}
+}
+
+func TestStrategies_ExpectedValuePlaysBest(t *testing.T) {
+	const turns = 4000
+	averages := make(map[string]float64)
+	for _, name := range newStrategies().Names() {
+		_, strategy, _ := newStrategies().Select(interfaces.BotOptions{Strategy: name})
+		random := rng.New(rng.SeedFrom(42))
+		total := 0
+		for i := 0; i < turns; i++ {
+			total += simulateTurn(strategy, random)
+		}
+		averages[name] = float64(total) / turns
+	}
+
+	if averages["expected_value"] <= averages["normal"] || averages["normal"] <= averages["easy"] {
+		t.Errorf("expected the strategies to be ranked expected_value > normal > easy, got %v", averages)
+	}
+}
@@ -0,0 +1,71 @@
+package dicegame
+
+import (
+	"gameserver/internal/client"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/testicles"
+	"testing"
+	"time"
+)
+
+func TestDiceGame_BotTakeover(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+
+	options := RoomOptions{TakeoverOptions: interfaces.TakeoverOptions{BotTakeover: true, BotTakeoverGrace: 0.05}}
+	player1 := helper.CreateClient("player-0")
+	helper.CreateRoomWithOptions(player1, "dicegame", "player-0", options)
+	helper.JoinRoom(helper.CreateClient("player-1"), helper.RoomID, "player-1")
+	helper.SendMessage("player-0", "ready", nil)
+	helper.SendMessage("player-1", "ready", nil)
+	helper.SendMessage("player-0", "start", nil)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+	state := testRoom.State().(*GameState)
+
+	testRoom.Disconnect(player1)
+	helper.Clock.Advance(40 * time.Millisecond)
+	if seat, seated := testRoom.Clients()["player-0"]; seated {
+		t.Fatalf("Expected the seat to wait for the player during the grace period, got %v", seat)
+	}
+
+	helper.Clock.Advance(10 * time.Millisecond)
+	if seat, seated := testRoom.Clients()["player-0"]; !seated || !seat.IsBot() {
+		t.Fatal("Expected a bot to take over the seat of the disconnected player")
+	}
+	if !state.Players["player-0"].BotControlled {
+		t.Error("Expected the seat to be shown as bot-controlled")
+	}
+
+	// the player takes the seat back with a new connection
+	reconnected := client.NewClientMock("player-0-new")
+	if err = helper.Registry.HandleClientReconnect(reconnected, testRoom, "player-0"); err != nil {
+		t.Fatalf("Failed to reconnect: %v", err)
+	}
+	if state.Players["player-0"].BotControlled {
+		t.Error("Expected the player to control its seat again")
+	}
+	if seat := testRoom.Clients()["player-0"]; seat == nil || seat.IsBot() {
+		t.Errorf("Expected the player back on its seat, got %v", seat)
+	}
+
+	testicles.AssertReplay(t, NewDiceGame(), helper.Journal())
+}
+
+func TestDiceGame_NoTakeoverWithoutOption(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterDiceGame(helper.Registry)
+	helper.SetupGameRoom("dicegame", 2)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+
+	if grace := NewDiceGame().TakeoverGrace(testRoom); grace != 0 {
+		t.Errorf("Expected bots not to take over by default, got a grace of %v", grace)
+	}
+}
@@ -0,0 +1,164 @@
+package owe_drahn
+
+import (
+	"encoding/json"
+	"gameserver/internal/client"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/protocol"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const botDelay = 1500 * time.Millisecond
+
+// safeRollValue is the highest total a roll can never push over 15
+const safeRollValue = 9
+
+// Bot plays the seat of a disconnected player until it reconnects. It rolls while no roll
+// can kill it and gives up a life otherwise, as long as it has more than one.
+type Bot struct {
+	*client.BotClient
+	mu     sync.Mutex
+	state  *GameStateDTO // the latest state the bot received
+	moving bool
+	// actedOn is the version of the state the last move was made on. States arrive on their own
+	// goroutines, older ones are dropped and a move is only made on a state that shows the last one.
+	actedOn int
+}
+
+func newBot(id string, reg interfaces.GameRegistry) *Bot {
+	bot := &Bot{BotClient: client.NewBotClient(id, reg)}
+	bot.SetMessageHandler(bot.handleMessage)
+	return bot
+}
+
+func (b *Bot) handleMessage(message *protocol.Response) {
+	if b.Context().Err() != nil {
+		return
+	}
+
+	switch message.Type {
+	case "game_state", "gameInit":
+		state, ok := message.Data.(*GameStateDTO)
+		if !ok {
+			log.Error().Str("type", message.Type).Str("botId", b.ID()).Msg("bot could not handle data")
+			return
+		}
+
+		b.mu.Lock()
+		if b.state != nil && state.Version <= b.state.Version {
+			b.mu.Unlock()
+			return
+		}
+		b.state = state
+		moving := b.moving
+		b.moving = true
+		b.mu.Unlock()
+
+		if !moving {
+			b.schedule()
+		}
+	}
+}
+
+// schedule makes the next move after botDelay on the room clock
+func (b *Bot) schedule() {
+	room := b.Room()
+	if room == nil || room.IsClosed() {
+		b.mu.Lock()
+		b.moving = false
+		b.mu.Unlock()
+		return
+	}
+	room.Clock().AfterFunc(botDelay, b.play)
+}
+
+// play makes a move on the latest state and schedules the next one until there is nothing left to do
+func (b *Bot) play() {
+	if b.Context().Err() != nil {
+		return
+	}
+	room := b.Room()
+	if room == nil || room.IsClosed() {
+		return
+	}
+
+	// a state that arrives once the bot stopped moving starts it again
+	b.mu.Lock()
+	if b.state == nil || b.state.Version == b.actedOn {
+		// the previous move isn't in the state yet
+		b.moving = false
+		b.mu.Unlock()
+		return
+	}
+	action, payload, ok := b.nextMove(b.state)
+	b.moving = ok
+	if ok {
+		b.actedOn = b.state.Version
+	}
+	b.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	data, _ := json.Marshal(payload)
+	if err := b.SendMessage(action, data); err != nil {
+		log.Error().Err(err).Str("action", action).Str("botId", b.ID()).Msg("failed to send action")
+		b.mu.Lock()
+		b.moving = false
+		b.mu.Unlock()
+		return
+	}
+	b.schedule()
+}
+
+// nextMove decides what the bot does in the given state, ok is false if it has nothing to do
+func (b *Bot) nextMove(state *GameStateDTO) (action string, payload interface{}, ok bool) {
+	var me *Player
+	for _, player := range state.Players {
+		if player.ID == b.ID() {
+			me = player
+			break
+		}
+	}
+	if me == nil {
+		return "", nil, false
+	}
+
+	if !state.Started {
+		if me.IsReady {
+			return "", nil, false
+		}
+		return "ready", true, true
+	}
+	if state.Over || state.CurrentTurn != me.ID {
+		return "", nil, false
+	}
+
+	if me.IsChoosing {
+		next := nextAlivePlayer(state.Players, me.ID)
+		return "chooseNextPlayer", NextPlayerPayload{NextPlayerId: next}, next != ""
+	}
+	if state.CurrentValue <= safeRollValue || me.Life <= 1 {
+		return "roll", nil, true
+	}
+	return "loseLife", nil, true
+}
+
+// nextAlivePlayer returns the first player after id in order that is still alive
+func nextAlivePlayer(players []*Player, id string) string {
+	for i, player := range players {
+		if player.ID != id {
+			continue
+		}
+		for j := 1; j < len(players); j++ {
+			next := players[(i+j)%len(players)]
+			if next.Life > 0 {
+				return next.ID
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package owe_drahn
+
+import (
+	"testing"
+)
+
+func TestBot_NextMove(t *testing.T) {
+	bot := newBot("bot", nil)
+	defer bot.Close()
+
+	players := func(me *Player) []*Player {
+		return []*Player{{ID: "p1", Life: 0}, me, {ID: "p3", Life: 6}}
+	}
+
+	tests := []struct {
+		name       string
+		state      *GameStateDTO
+		wantAction string
+		wantOk     bool
+	}{
+		{"readies up before the game", &GameStateDTO{Players: players(&Player{ID: "bot", Life: 6})}, "ready", true},
+		{"waits once ready", &GameStateDTO{Players: players(&Player{ID: "bot", Life: 6, IsReady: true})}, "", false},
+		{"waits for its turn", &GameStateDTO{Started: true, CurrentTurn: "p3", Players: players(&Player{ID: "bot", Life: 6})}, "", false},
+		{"rolls while safe", &GameStateDTO{Started: true, CurrentTurn: "bot", CurrentValue: 9, Players: players(&Player{ID: "bot", Life: 6})}, "roll", true},
+		{"loses a life when a roll could kill", &GameStateDTO{Started: true, CurrentTurn: "bot", CurrentValue: 12, Players: players(&Player{ID: "bot", Life: 2})}, "loseLife", true},
+		{"rolls with its last life", &GameStateDTO{Started: true, CurrentTurn: "bot", CurrentValue: 12, Players: players(&Player{ID: "bot", Life: 1})}, "roll", true},
+		{"chooses the next player alive", &GameStateDTO{Started: true, CurrentTurn: "bot", Players: players(&Player{ID: "bot", Life: 5, IsChoosing: true})}, "chooseNextPlayer", true},
+		{"stops when the game is over", &GameStateDTO{Started: true, Over: true, CurrentTurn: "bot", Players: players(&Player{ID: "bot", Life: 6})}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			action, payload, ok := bot.nextMove(tt.state)
+			if action != tt.wantAction || ok != tt.wantOk {
+				t.Errorf("expected %q (%v), got %q (%v)", tt.wantAction, tt.wantOk, action, ok)
+			}
+			if next, choosing := payload.(NextPlayerPayload); choosing && next.NextPlayerId != "p3" {
+				t.Errorf("expected the bot to skip dead players, chose %s", next.NextPlayerId)
+			}
+		})
+	}
+}
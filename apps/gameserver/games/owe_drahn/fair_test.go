@@ -0,0 +1,185 @@
+package owe_drahn
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gameserver/games/owe_drahn/database"
+	"gameserver/internal/fair"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/testicles"
+)
+
+// playProvablyFairRound plays a provably fair room until someone dies and returns the gameOver payload
+func playProvablyFairRound(t *testing.T) (*testicles.TestHelper, interfaces.M) {
+	helper := testicles.NewTestHelper(t)
+	helper.RegisterGame(NewGame(&database.DatabaseServiceMock{}))
+
+	player1 := helper.CreateClient("player-0")
+	helper.CreateRoomWithOptions(player1, "owedrahn", "player-0", RoomOptions{ProvablyFair: true})
+	player2 := helper.CreateClient("player-1")
+	helper.JoinRoom(player2, helper.RoomID, "player-1")
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+
+	state := testRoom.State().(*GameState)
+	if state.ToDTO().Fairness == nil || state.Fairness.ServerSeedHash == "" {
+		t.Fatalf("server seed should be committed before anyone is ready")
+	}
+
+	helper.SendMessage(player1.ID(), "ready", ReadyPayload{Ready: true, ClientSeed: "lucky"})
+	helper.SendMessage(player2.ID(), "ready", true)
+
+	if !state.Started {
+		t.Fatalf("Game should have started after both players were ready")
+	}
+	if state.Fairness.ClientSeed != "lucky:" {
+		t.Errorf("expected combined client seed %q, got %q", "lucky:", state.Fairness.ClientSeed)
+	}
+
+	helper.ClearAllMessages()
+	for i := 0; !state.Over && i < 100; i++ {
+		helper.SendMessage(state.CurrentTurn, "roll", nil)
+	}
+	if !state.Over {
+		t.Fatalf("game should be over after 100 rolls")
+	}
+
+	gameOverMsg, found := testicles.FindMessageByType(player1.GetSentMessages(), "gameOver")
+	if !found {
+		t.Fatalf("gameOver event not found")
+	}
+	return helper, gameOverMsg.Data.(interfaces.M)
+}
+
+func TestProvablyFair_RevealVerifiesRolls(t *testing.T) {
+	_, gameOver := playProvablyFairRound(t)
+
+	reveal, ok := gameOver["fairness"].(fair.Reveal)
+	if !ok {
+		t.Fatalf("gameOver should reveal the server seed, got %v", gameOver)
+	}
+	rolls := gameOver["rolls"].([]int)
+
+	result := VerifyRolls(VerifyRequest{
+		ServerSeed:     reveal.ServerSeed,
+		ServerSeedHash: reveal.ServerSeedHash,
+		ClientSeed:     reveal.ClientSeed,
+		Rolls:          rolls,
+	})
+	if !result.Valid {
+		t.Fatalf("expected rolls to verify, got %+v", result)
+	}
+	if len(result.Rolls) != len(rolls) {
+		t.Errorf("expected %d checked rolls, got %d", len(rolls), len(result.Rolls))
+	}
+
+	// a tampered roll must be detected
+	tampered := append([]int(nil), rolls...)
+	tampered[0] = tampered[0]%6 + 1
+	result = VerifyRolls(VerifyRequest{
+		ServerSeed:     reveal.ServerSeed,
+		ServerSeedHash: reveal.ServerSeedHash,
+		ClientSeed:     reveal.ClientSeed,
+		Rolls:          tampered,
+	})
+	if result.Valid || result.Rolls[0].Valid {
+		t.Errorf("expected tampered roll to fail verification")
+	}
+}
+
+func TestProvablyFair_VerifyHandler(t *testing.T) {
+	_, gameOver := playProvablyFairRound(t)
+	reveal := gameOver["fairness"].(fair.Reveal)
+
+	body, _ := json.Marshal(VerifyRequest{
+		ServerSeed:     reveal.ServerSeed,
+		ServerSeedHash: reveal.ServerSeedHash,
+		ClientSeed:     reveal.ClientSeed,
+		Rolls:          gameOver["rolls"].([]int),
+	})
+
+	rec := httptest.NewRecorder()
+	VerifyHandler(rec, httptest.NewRequest(http.MethodPost, "/owedrahn/verify", bytes.NewReader(body)))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	var result VerifyResult
+	if err := json.Unmarshal(rec.Body.Bytes(), &result); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if !result.Valid {
+		t.Errorf("expected rolls to verify, got %+v", result)
+	}
+
+	rec = httptest.NewRecorder()
+	VerifyHandler(rec, httptest.NewRequest(http.MethodGet, "/owedrahn/verify", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status 405, got %d", rec.Code)
+	}
+}
+
+func TestProvablyFair_VerifyHandlerRejectsOversizedRequests(t *testing.T) {
+	_, gameOver := playProvablyFairRound(t)
+	reveal := gameOver["fairness"].(fair.Reveal)
+
+	tooMany, _ := json.Marshal(VerifyRequest{
+		ServerSeed:     reveal.ServerSeed,
+		ServerSeedHash: reveal.ServerSeedHash,
+		ClientSeed:     reveal.ClientSeed,
+		Rolls:          make([]int, MaxVerifyRolls+1),
+	})
+	oversized, _ := json.Marshal(VerifyRequest{
+		ServerSeed:     reveal.ServerSeed,
+		ServerSeedHash: reveal.ServerSeedHash,
+		ClientSeed:     strings.Repeat("a", maxVerifyRequestSize),
+	})
+
+	for name, body := range map[string][]byte{"too many rolls": tooMany, "oversized body": oversized} {
+		rec := httptest.NewRecorder()
+		VerifyHandler(rec, httptest.NewRequest(http.MethodPost, "/owedrahn/verify", bytes.NewReader(body)))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", name, rec.Code)
+		}
+	}
+}
+
+func TestProvablyFair_ReplaysFromJournal(t *testing.T) {
+	helper, _ := playProvablyFairRound(t)
+
+	testicles.AssertReplay(t, NewGame(&database.DatabaseServiceMock{}), helper.Journal())
+}
+
+func TestProvablyFair_ServerSeedIsNotDerivedFromRoomSeed(t *testing.T) {
+	commitment := func() (string, *testicles.TestHelper) {
+		helper := testicles.NewTestHelper(t)
+		helper.RegisterGame(NewGame(&database.DatabaseServiceMock{}))
+		// both rooms draw from the room RNG seeded with testicles.DefaultSeed
+		helper.CreateRoomWithOptions(helper.CreateClient("player-0"), "owedrahn", "player-0", RoomOptions{ProvablyFair: true})
+
+		testRoom, err := helper.GetRoom()
+		if err != nil {
+			t.Fatalf("Failed to get room: %v", err)
+		}
+		return testRoom.State().(*GameState).Fairness.ServerSeedHash, helper
+	}
+
+	first, helper := commitment()
+	second, _ := commitment()
+	if first == second {
+		t.Fatalf("rooms with the same rng seed committed to the same server seed %s", first)
+	}
+
+	seeds, err := helper.Journal().ServerSeeds()
+	if err != nil || len(seeds) != 1 || fair.HashServerSeed(seeds[0]) != first {
+		t.Errorf("expected the journal to record the committed server seed, got %d seeds (%v)", len(seeds), err)
+	}
+}
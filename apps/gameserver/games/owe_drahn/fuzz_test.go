@@ -0,0 +1,66 @@
+package owe_drahn
+
+import (
+	"fmt"
+	"testing"
+
+	"gameserver/games/owe_drahn/database"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/testicles"
+)
+
+func FuzzOweDrahn(f *testing.F) {
+	testicles.FuzzRouter(f, testicles.FuzzTarget{
+		GameType: "owedrahn",
+		Register: func(registry interfaces.GameRegistry) {
+			registry.RegisterGame(NewGame(&database.DatabaseServiceMock{}))
+		},
+		Players: 2,
+		Messages: []testicles.FuzzMessage{
+			{Type: "ready", Payloads: []string{`true`, `false`, `{"ready":true,"clientSeed":"seed"}`}},
+			{Type: "roll"},
+			{Type: "loseLife"},
+			{Type: "chooseNextPlayer", Payloads: []string{`{"nextPlayerId":"$seat"}`}},
+			{Type: "set_main_bet", Payloads: []string{`{"amount":5}`, `{"amount":0}`}},
+			{Type: "sidebet_propose", Payloads: []string{`{"opponentId":"$seat","amount":2}`}},
+			{Type: "sidebet_accept", Payloads: []string{`{"betId":"$id"}`}},
+			{Type: "sidebet_decline", Payloads: []string{`{"betId":"$id"}`}},
+			{Type: "sidebet_cancel", Payloads: []string{`{"betId":"$id"}`}},
+			{Type: "handshake", Payloads: []string{`{"uid":"user"}`}},
+		},
+		Check: checkOweDrahn,
+		IDs: func(room interfaces.Room) []string {
+			var ids []string
+			for _, bet := range room.State().(*GameState).SideBets {
+				ids = append(ids, bet.ID)
+			}
+			return ids
+		},
+	})
+}
+
+// checkOweDrahn checks that the turn belongs to a player, lives stay within 0 and 6, side bets
+// are between seated players and no money is won that somebody else did not lose
+func checkOweDrahn(room interfaces.Room) error {
+	state := room.State().(*GameState)
+	if !(&Game{}).ValidateZeroSum(state) {
+		return fmt.Errorf("balances of %v do not sum up to zero", state.ToDTO().Players)
+	}
+	if err := testicles.CheckCurrentTurn(state.CurrentTurn, state.Players); err != nil {
+		return err
+	}
+	if len(state.Players) != len(state.PlayerOrder) {
+		return fmt.Errorf("%d players in an order of %d", len(state.Players), len(state.PlayerOrder))
+	}
+	for _, player := range state.Players {
+		if player.Life < 0 || player.Life > 6 {
+			return fmt.Errorf("player %s has %d lives", player.ID, player.Life)
+		}
+	}
+	for _, bet := range state.SideBets {
+		if state.Players[bet.ChallengerID] == nil || state.Players[bet.OpponentID] == nil {
+			return fmt.Errorf("side bet %s between %s and %s, who are not both players", bet.ID, bet.ChallengerID, bet.OpponentID)
+		}
+	}
+	return nil
+}
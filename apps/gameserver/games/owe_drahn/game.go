@@ -4,7 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"math"
 	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,12 +16,20 @@ import (
 	"gameserver/games/owe_drahn/database"
 	"gameserver/games/owe_drahn/models"
 	"gameserver/games/owe_drahn/utils"
+	"gameserver/internal/auth"
+	"gameserver/internal/clock"
+	"gameserver/internal/fair"
 	"gameserver/internal/interfaces"
+	"gameserver/internal/rating"
+	"gameserver/internal/rng"
 	"gameserver/internal/testicles"
 )
 
 type Game struct {
-	dbService database.Database
+	dbService     database.Database
+	authenticator auth.Authenticator
+	registry      interfaces.GameRegistry
+	ratings       *rating.Service // ranks players with an account, optional
 }
 
 type GameState struct {
@@ -36,6 +46,14 @@ type GameState struct {
 	SideBets     []*models.SideBet
 	StartedAt    time.Time
 	FinishedAt   time.Time
+	ProvablyFair bool
+	Fairness     *fair.Round // commitment of the current round, only in provably fair rooms
+
+	rng           rng.RNG
+	clock         clock.Clock // the room clock, see interfaces.Room
+	seeds         *fair.Seeds // the server seeds of the rounds, see interfaces.Room
+	takeoverGrace time.Duration
+	version       int // counts the states sent to the players, see GameStateDTO
 }
 
 type GameStateDTO struct {
@@ -46,6 +64,9 @@ type GameStateDTO struct {
 	CurrentValue int               `json:"currentValue"`
 	MainBet      float64           `json:"mainBet"`
 	SideBets     []*models.SideBet `json:"sideBets"`
+	Fairness     *fair.Round       `json:"fairness,omitempty"`
+	// Version grows with every state sent, bots tell stale states by it
+	Version int `json:"version"`
 }
 
 func (s *GameState) ToDTO() *GameStateDTO {
@@ -57,9 +78,19 @@ func (s *GameState) ToDTO() *GameStateDTO {
 		MainBet:      s.MainBet,
 		CurrentTurn:  s.CurrentTurn,
 		SideBets:     s.SideBets,
+		Fairness:     s.Fairness,
+		Version:      s.version,
 	}
 }
 
+// diceRNG returns where rolls are drawn from, the fair round in provably fair rooms
+func (s *GameState) diceRNG() rng.RNG {
+	if s.Fairness != nil {
+		return s.Fairness
+	}
+	return s.rng
+}
+
 func (s *GameState) ToDBGame() models.DBGame {
 	return models.DBGame{
 		Players:    mapPlayersToFormattedPlayers(mapPlayersToArray(s.Players, s.PlayerOrder)),
@@ -71,6 +102,7 @@ func (s *GameState) ToDBGame() models.DBGame {
 
 type HandshakePayload struct {
 	UserID string `json:"uid"`
+	Token  string `json:"token,omitempty"` // ID token proving the uid, not needed if the socket is already authenticated
 }
 
 type NextPlayerPayload struct {
@@ -90,6 +122,19 @@ type MainBetPayload struct {
 	Amount float64 `json:"amount"`
 }
 
+// ReadyPayload is the object form of the ready message, used to send a provably fair client seed.
+// A plain boolean is accepted as well.
+type ReadyPayload struct {
+	Ready      bool   `json:"ready"`
+	ClientSeed string `json:"clientSeed"`
+}
+
+// RoomOptions are the options a room can be created with
+type RoomOptions struct {
+	ProvablyFair bool `json:"provablyFair"`
+	interfaces.TakeoverOptions
+}
+
 func (g *Game) AddPlayer(id string, name string, state *GameState) {
 	state.Players[id] = NewPlayer(id, name)
 	state.PlayerOrder = append(state.PlayerOrder, id)
@@ -101,7 +146,10 @@ func (g *Game) GetPlayer(id string, state *GameState) *Player {
 
 func (g *Game) RemovePlayer(clientId string, room interfaces.Room) {
 	state := room.State().(*GameState)
-	playerName := state.Players[clientId].Name
+	player, exists := state.Players[clientId]
+	if !exists {
+		return
+	}
 	delete(state.Players, clientId)
 
 	// Remove from player order
@@ -112,8 +160,19 @@ func (g *Game) RemovePlayer(clientId string, room interfaces.Room) {
 		}
 	}
 
+	// drop the side bets of the player, they could not be resolved without them
+	state.mu.Lock()
+	sideBets := make([]*models.SideBet, 0, len(state.SideBets))
+	for _, bet := range state.SideBets {
+		if bet.ChallengerID != clientId && bet.OpponentID != clientId {
+			sideBets = append(sideBets, bet)
+		}
+	}
+	state.SideBets = sideBets
+	state.mu.Unlock()
+
 	g.broadcastGameEvent(room, "playerLeft", interfaces.M{
-		"username": playerName,
+		"username": player.Name,
 	})
 }
 
@@ -186,14 +245,24 @@ func (g *Game) reset(state *GameState) {
 		player.Reset()
 	}
 	state.Rolls = make([]models.Roll, 0)
+	if state.ProvablyFair {
+		// commit to the next round before anyone sends ready again
+		state.Fairness = fair.NewRound(state.seeds)
+	}
 }
 
 func (g *Game) start(state *GameState) {
 	state.Started = true
-	state.StartedAt = time.Now()
+	state.StartedAt = state.clock.Now()
 
 	g.setNextPlayerRandom(state)
 
+	if state.Fairness != nil {
+		if err := state.Fairness.SetClientSeed(combineClientSeeds(state)); err != nil {
+			log.Error().Err(err).Msg("failed to set client seed")
+		}
+	}
+
 	log.Debug().Str("currentTurn", state.CurrentTurn).Msg("starting game")
 }
 
@@ -201,7 +270,7 @@ func (g *Game) handleRoll(room interfaces.Room) error {
 	state := room.State().(*GameState)
 	player := g.GetCurrentPlayer(state)
 
-	dice := utils.Random(1, 6)
+	dice := utils.Random(state.diceRNG(), 1, 6)
 	// Rule of 3, doesn't count
 	if dice != 3 {
 		state.CurrentValue += dice
@@ -336,10 +405,30 @@ func (g *Game) setNextPlayerRandom(state *GameState) {
 		log.Error().Msg("no players while trying to set next random player")
 		return
 	}
-	randomIdx := utils.Random(0, len(state.PlayerOrder)-1)
+	randomIdx := utils.Random(state.rng, 0, len(state.PlayerOrder)-1)
 	state.CurrentTurn = state.PlayerOrder[randomIdx]
 }
 
+// combineClientSeeds joins the client seeds of all players in player order
+func combineClientSeeds(state *GameState) string {
+	seeds := make([]string, 0, len(state.PlayerOrder))
+	for _, id := range state.PlayerOrder {
+		if player, exists := state.Players[id]; exists {
+			seeds = append(seeds, player.ClientSeed)
+		}
+	}
+	return strings.Join(seeds, ":")
+}
+
+// rolledDice returns the dice values of all rolls in order
+func rolledDice(rolls []models.Roll) []int {
+	dice := make([]int, len(rolls))
+	for i, roll := range rolls {
+		dice[i] = roll.Dice
+	}
+	return dice
+}
+
 func (g *Game) getSortedPlayerIDs(state *GameState) []string {
 	// Create an ordered slice of player IDs
 	playerIDs := make([]string, 0, len(state.Players))
@@ -356,27 +445,83 @@ func (g *Game) getSortedPlayerIDs(state *GameState) []string {
 func (g *Game) gameOver(room interfaces.Room, winner string, state *GameState) {
 	log.Info().Str("winner", winner).Msg("game over")
 	state.Over = true
-	state.FinishedAt = time.Now()
+	state.FinishedAt = room.Clock().Now()
 
-	g.broadcastGameEvent(room, "gameOver", interfaces.M{
+	gameOverData := interfaces.M{
 		"winner": winner,
-	})
+	}
+	if state.Fairness != nil {
+		gameOverData["fairness"] = state.Fairness.Reveal()
+		gameOverData["rolls"] = rolledDice(state.Rolls)
+	}
+	g.broadcastGameEvent(room, "gameOver", gameOverData)
 
 	g.dbService.StoreGame(state.Ctx, state.ToDBGame())
+	g.reportResult(room, state)
 	// restart after 5s
-	time.AfterFunc(5*time.Second, func() {
+	room.Clock().AfterFunc(5*time.Second, func() {
+		room.Lock()
+		defer room.Unlock()
+
 		g.reset(state)
+		state.version++
 		g.broadcastGameEvent(room, "gameInit", state.ToDTO())
 	})
 }
 
+// reportResult reports the finished game, the last player alive is placed first and everyone else second
+func (g *Game) reportResult(room interfaces.Room, state *GameState) {
+	if g.registry == nil {
+		return
+	}
+
+	participants := make([]interfaces.Participant, 0, len(state.PlayerOrder))
+	for _, player := range mapPlayersToArray(state.Players, state.PlayerOrder) {
+		placement := 2
+		if player.Life > 0 {
+			placement = 1
+		}
+		participants = append(participants, interfaces.Participant{
+			ClientID:  player.ID,
+			AccountID: player.AccountID,
+			Name:      player.Name,
+			Placement: placement,
+			Score:     player.Balance,
+		})
+	}
+
+	g.registry.ReportResult(room, interfaces.GameResult{
+		StartedAt:    state.StartedAt,
+		EndedAt:      state.FinishedAt,
+		Participants: participants,
+		Metadata: interfaces.M{
+			"mainBet":      state.MainBet,
+			"rolls":        len(state.Rolls),
+			"provablyFair": state.ProvablyFair,
+		},
+	})
+}
+
 // SetStatsOnPlayer connects the player and sets the stats.
 func (g *Game) SetStatsOnPlayer(clientId string, userId string, stats *models.PlayerStats, state *GameState) {
 	log.Info().Str("clientId", clientId).Str("userId", userId).Msg("setting registered user data")
 
 	player := g.GetPlayer(clientId, state)
 	player.UserID = userId
-	player.Stats = stats
+	player.SetStats(stats)
+}
+
+// setRank ranks a player with an account by the rating of the account
+func (g *Game) setRank(ctx context.Context, player *Player) {
+	if g.ratings == nil || player.AccountID == "" {
+		return
+	}
+	current, err := g.ratings.CurrentRating(ctx, player.AccountID, g.Type())
+	if err != nil {
+		log.Error().Err(err).Str("accountId", player.AccountID).Msg("failed to get rating")
+		return
+	}
+	player.Rank = int(math.Round(current.Rating))
 }
 
 func (g *Game) handleSetMainBet(state *GameState, payload []byte) error {
@@ -400,19 +545,25 @@ func (g *Game) handleSetMainBet(state *GameState, payload []byte) error {
 }
 
 func (g *Game) handleReady(client interfaces.Client, state *GameState, payload []byte) error {
-	var ready bool
-	if err := json.Unmarshal(payload, &ready); err != nil {
-		return errors.New("invalid ready format")
+	var readyData ReadyPayload
+	if err := json.Unmarshal(payload, &readyData.Ready); err != nil {
+		if err := json.Unmarshal(payload, &readyData); err != nil {
+			return errors.New("invalid ready format")
+		}
 	}
 
-	log.Debug().Str("clientID", client.ID()).Bool("ready", ready).Msg("player sends ready")
+	log.Debug().Str("clientID", client.ID()).Bool("ready", readyData.Ready).Msg("player sends ready")
 
 	player := state.Players[client.ID()]
-	player.IsReady = ready
+	player.IsReady = readyData.Ready
+	player.ClientSeed = readyData.ClientSeed
 
 	if g.IsEveryoneReady(state) {
 		g.start(state)
 		g.broadcastGameEvent(client.Room(), "gameStarted", nil)
+		if g.registry != nil {
+			g.registry.ReportStarted(client.Room())
+		}
 		// reset everyones ready state for UI purposes
 		for _, p := range state.Players {
 			p.IsReady = false
@@ -422,10 +573,18 @@ func (g *Game) handleReady(client interfaces.Client, state *GameState, payload [
 	return nil
 }
 
-func (g *Game) handleLoseLife(client interfaces.Client, state *GameState) {
-	log.Debug().Str("clientID", client.ID()).Msg("player loses life")
-
+// handleLoseLife lets the current player lose a life instead of rolling. The last life can
+// only be lost by rolling, and a player who already lost one has to choose who goes next.
+func (g *Game) handleLoseLife(client interfaces.Client, state *GameState) error {
 	player := g.GetCurrentPlayer(state)
+	if player.IsChoosing {
+		return errors.New("player has to choose the next player")
+	}
+	if player.Life <= 1 {
+		return errors.New("player can not lose the last life")
+	}
+
+	log.Debug().Str("clientID", client.ID()).Msg("player loses life")
 	player.Life -= 1
 	player.IsChoosing = true
 	state.CurrentValue = 0
@@ -433,6 +592,7 @@ func (g *Game) handleLoseLife(client interfaces.Client, state *GameState) {
 	g.broadcastGameEvent(client.Room(), "lostLife", interfaces.M{
 		"player": player.ToFormattedPlayer(),
 	})
+	return nil
 }
 
 //	handleHandshake
@@ -449,10 +609,15 @@ func (g *Game) handleHandshake(client interfaces.Client, state *GameState, paylo
 	if err := json.Unmarshal(payload, &handshake); err != nil {
 		return errors.New("invalid handshake format")
 	}
-	log.Debug().Str("clientId", client.ID()).Str("userId", handshake.UserID).Msg("handshake")
-	if handshake.UserID != "" {
-		if userStats, err := g.dbService.GetUserStats(state.Ctx, handshake.UserID); err == nil {
-			g.SetStatsOnPlayer(client.ID(), handshake.UserID, userStats, state)
+	userID, err := g.verifyUserID(client, state, handshake)
+	if err != nil {
+		return err
+	}
+
+	log.Debug().Str("clientId", client.ID()).Str("userId", userID).Msg("handshake")
+	if userID != "" {
+		if userStats, err := g.dbService.GetUserStats(state.Ctx, userID); err == nil {
+			g.SetStatsOnPlayer(client.ID(), userID, userStats, state)
 		} else {
 			log.Error().Err(err).Msg("error getting user stats")
 		}
@@ -462,6 +627,38 @@ func (g *Game) handleHandshake(client interfaces.Client, state *GameState, paylo
 	return nil
 }
 
+// verifyUserID returns the user id the client may play as.
+// Verified identities always win over the uid in the payload. Only without a configured
+// authenticator the uid is trusted as is, which is what local development relies on.
+func (g *Game) verifyUserID(client interfaces.Client, state *GameState, handshake HandshakePayload) (string, error) {
+	if identity := client.Identity(); identity != nil {
+		if handshake.UserID != "" && handshake.UserID != identity.Subject {
+			log.Warn().Str("clientId", client.ID()).Str("uid", handshake.UserID).Str("subject", identity.Subject).Msg("handshake uid does not match identity")
+		}
+		return identity.Subject, nil
+	}
+
+	if g.authenticator == nil {
+		return handshake.UserID, nil
+	}
+
+	if handshake.Token == "" {
+		if handshake.UserID != "" {
+			log.Warn().Str("clientId", client.ID()).Str("uid", handshake.UserID).Msg("ignoring unverified handshake uid")
+		}
+		return "", nil
+	}
+
+	identity, err := g.authenticator.Authenticate(state.Ctx, handshake.Token)
+	if err != nil {
+		log.Warn().Err(err).Str("clientId", client.ID()).Msg("handshake token rejected")
+		return "", ErrHandshakeUnauthenticated
+	}
+	client.SetIdentity(identity)
+
+	return identity.Subject, nil
+}
+
 func (g *Game) handleProposeSideBet(client interfaces.Client, state *GameState, payload []byte) (*string, error) {
 	var betPayload SidebetProposalPayload
 	if err := json.Unmarshal(payload, &betPayload); err != nil {
@@ -10,19 +10,46 @@ import (
 
 	"gameserver/games/owe_drahn/database"
 	"gameserver/games/owe_drahn/models"
+	"gameserver/internal/auth"
+	"gameserver/internal/fair"
 	"gameserver/internal/interfaces"
 	"gameserver/internal/protocol"
+	"gameserver/internal/rating"
+	"gameserver/internal/rng"
 )
 
 type GameConfig struct {
 	Stage          interfaces.Environment
 	CredentialsDir string
+	Authenticator  auth.Authenticator // optional, verifies handshake uids
+	Ratings        *rating.Service    // optional, ranks players with an account
 }
 
-func NewGame(dbService database.Database) *Game {
-	return &Game{
+// GameOption is a functional option for configuring the Game
+type GameOption func(*Game)
+
+// WithAuthenticator makes the handshake only accept user ids proven by an ID token
+func WithAuthenticator(authenticator auth.Authenticator) GameOption {
+	return func(g *Game) {
+		g.authenticator = authenticator
+	}
+}
+
+// WithRatings ranks players with an account by their rating
+func WithRatings(ratings *rating.Service) GameOption {
+	return func(g *Game) {
+		g.ratings = ratings
+	}
+}
+
+func NewGame(dbService database.Database, opts ...GameOption) *Game {
+	g := &Game{
 		dbService: dbService,
 	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
 }
 
 func RegisterGame(ctx context.Context, r interfaces.GameRegistry, config GameConfig) error {
@@ -36,7 +63,16 @@ func RegisterGame(ctx context.Context, r interfaces.GameRegistry, config GameCon
 		return err
 	}
 
-	g := NewGame(dbService)
+	var opts []GameOption
+	if config.Authenticator != nil {
+		opts = append(opts, WithAuthenticator(config.Authenticator))
+	}
+	if config.Ratings != nil {
+		opts = append(opts, WithRatings(config.Ratings))
+	}
+
+	g := NewGame(dbService, opts...)
+	g.registry = r
 	r.RegisterGame(g)
 	return nil
 }
@@ -47,7 +83,14 @@ func (g *Game) Type() string {
 }
 
 // InitializeRoom sets up a new room with the initial game state
-func (g *Game) InitializeRoom(ctx context.Context, room interfaces.Room, options json.RawMessage) error {
+func (g *Game) InitializeRoom(ctx context.Context, room interfaces.Room, options json.RawMessage, random rng.RNG) error {
+	var roomOptions RoomOptions
+	if options != nil {
+		if err := json.Unmarshal(options, &roomOptions); err != nil {
+			log.Warn().Err(err).Msg("Failed to parse room options, using defaults")
+		}
+	}
+
 	// Create initial game state
 	state := GameState{
 		Ctx:         ctx,
@@ -57,6 +100,15 @@ func (g *Game) InitializeRoom(ctx context.Context, room interfaces.Room, options
 		CurrentTurn: "",
 		MainBet:     1,
 		SideBets:    make([]*models.SideBet, 0),
+		rng:         random,
+		clock:       room.Clock(),
+		seeds:       room.ServerSeeds(),
+
+		takeoverGrace: roomOptions.Grace(),
+	}
+	if roomOptions.ProvablyFair {
+		state.ProvablyFair = true
+		state.Fairness = fair.NewRound(state.seeds)
 	}
 
 	room.SetState(&state)
@@ -73,12 +125,15 @@ func (g *Game) OnClientJoin(client interfaces.Client, room interfaces.Room, opti
 	}
 
 	g.AddPlayer(client.ID(), options.PlayerName, state)
+	player := state.Players[client.ID()]
+	player.AccountID = options.AccountID
+	g.setRank(state.Ctx, player)
 
 	room.SetState(state)
 	g.broadcastGameState(room)
 }
 
-func (g *Game) OnBotAdd(client interfaces.Client, room interfaces.Room, reg interfaces.GameRegistry) (interfaces.Client, string, error) {
+func (g *Game) OnBotAdd(client interfaces.Client, room interfaces.Room, reg interfaces.GameRegistry, _ interfaces.BotOptions) (interfaces.Client, string, error) {
 	return nil, "", errors.New("game does not support bots")
 }
 
@@ -104,43 +159,17 @@ func (g *Game) OnClientLeave(client interfaces.Client, room interfaces.Room) {
 	g.broadcastGameState(room)
 }
 
-// OnClientReconnect handles reconnecting a client to the game
-func (g *Game) OnClientReconnect(client interfaces.Client, room interfaces.Room, oldClientID string) error {
+// OnClientReconnect marks a player that is back on its seat as connected, or a bot that took it over
+func (g *Game) OnClientReconnect(client interfaces.Client, room interfaces.Room) error {
 	state := room.State().(*GameState)
 
-	// Check if the old client ID was a player in this game
-	oldPlayer, exists := state.Players[oldClientID]
-	if !exists {
+	// Check if the seat belongs to a player in this game
+	player := g.GetPlayer(client.ID(), state)
+	if player == nil {
 		return errors.New("no player found with provided ID")
 	}
-
-	// Update player ID and state map references
-	oldPlayer.ID = client.ID()
-	state.Players[client.ID()] = oldPlayer
-	delete(state.Players, oldClientID)
-
-	// Update the new player ID in the player order
-	for i, id := range state.PlayerOrder {
-		if id == oldClientID {
-			state.PlayerOrder[i] = client.ID()
-			break
-		}
-	}
-
-	// If it was this player's turn, update the current turn
-	if state.CurrentTurn == oldClientID {
-		state.CurrentTurn = client.ID()
-	}
-
-	// patch all sideBet ids too
-	for i, bet := range state.SideBets {
-		if bet.ChallengerID == oldClientID {
-			state.SideBets[i].ChallengerID = client.ID()
-		}
-		if bet.OpponentID == oldClientID {
-			state.SideBets[i].OpponentID = client.ID()
-		}
-	}
+	player.IsConnected = true
+	player.BotControlled = client.IsBot()
 
 	room.SetState(state)
 	g.broadcastGameState(room)
@@ -243,7 +272,10 @@ func (g *Game) HandleMessage(client interfaces.Client, room interfaces.Room, msg
 			return ErrRollFailed
 		}
 	case "loseLife":
-		g.handleLoseLife(client, state)
+		if err = g.handleLoseLife(client, state); err != nil {
+			log.Error().Err(err).Msg("loseLife failed")
+			return ErrLoseLifeInvalid
+		}
 	case "chooseNextPlayer":
 		if err = g.handleChooseNextPlayer(state, payload); err != nil {
 			log.Error().Err(err).Msg("chooseNextPlayer failed")
@@ -257,6 +289,40 @@ func (g *Game) HandleMessage(client interfaces.Client, room interfaces.Room, msg
 	return nil
 }
 
+// TakeoverGrace returns how long the seat of a disconnected player waits, 0 unless the room enabled bot takeover
+func (g *Game) TakeoverGrace(room interfaces.Room) time.Duration {
+	return room.State().(*GameState).takeoverGrace
+}
+
+// TakeoverBot returns a bot that plays the seat of a disconnected player
+func (g *Game) TakeoverBot(seat interfaces.Client, room interfaces.Room, reg interfaces.GameRegistry) (interfaces.Client, error) {
+	state := room.State().(*GameState)
+	if g.GetPlayer(seat.ID(), state) == nil {
+		return nil, errors.New("no player found with provided ID")
+	}
+	return newBot(seat.ID(), reg).BotClient, nil
+}
+
+// CurrentTurn returns the player who has to roll or lose, "" if no round is running
+func (g *Game) CurrentTurn(room interfaces.Room) string {
+	state := room.State().(*GameState)
+	state.mu.RLock()
+	defer state.mu.RUnlock()
+	if !state.Started || state.Over {
+		return ""
+	}
+	return state.CurrentTurn
+}
+
+// SnapshotState returns the state used to compare journaled and replayed rooms, without timestamps
+func (g *Game) SnapshotState(room interfaces.Room) interface{} {
+	state := room.State().(*GameState)
+	return interfaces.M{
+		"state": state.ToDTO(),
+		"rolls": state.Rolls,
+	}
+}
+
 func (g *Game) broadcastGameEvent(room interfaces.Room, eventName string, payload interface{}) {
 	msg := protocol.NewSuccessResponse(eventName, payload)
 	room.Broadcast(msg)
@@ -265,6 +331,7 @@ func (g *Game) broadcastGameEvent(room interfaces.Room, eventName string, payloa
 // broadcastGameState sends the current game state to all clients in the room
 func (g *Game) broadcastGameState(room interfaces.Room) {
 	state := room.State().(*GameState)
+	state.version++
 	g.broadcastGameEvent(room, "game_state", state.ToDTO())
 }
 
@@ -280,4 +347,7 @@ var (
 	ErrNotYourTurn       = errors.New("not your turn")
 	ErrRollFailed        = errors.New("roll failed")
 	ErrNextPlayerInvalid = errors.New("next player is invalid")
+	ErrLoseLifeInvalid   = errors.New("can not lose a life")
+
+	ErrHandshakeUnauthenticated = errors.New("handshake token invalid")
 )
@@ -0,0 +1,94 @@
+package owe_drahn
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gameserver/games/owe_drahn/database"
+	"gameserver/internal/auth"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/testicles"
+)
+
+func setupHandshakeRoom(t *testing.T, opts ...GameOption) (*testicles.TestHelper, *GameState) {
+	helper := testicles.NewTestHelper(t)
+	helper.RegisterGame(NewGame(&database.DatabaseServiceMock{}, opts...))
+	helper.SetupGameRoom("owedrahn", 2)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+	return helper, testRoom.State().(*GameState)
+}
+
+func TestHandshake_WithoutAuthenticatorTrustsUID(t *testing.T) {
+	helper, state := setupHandshakeRoom(t)
+
+	helper.SendMessage("player-0", "handshake", HandshakePayload{UserID: "user-1"})
+
+	if uid := state.Players["player-0"].UserID; uid != "user-1" {
+		t.Errorf("expected uid user-1, got %q", uid)
+	}
+}
+
+func TestHandshake_IgnoresUnverifiedUID(t *testing.T) {
+	authenticator := auth.NewAuthenticatorMock()
+	helper, state := setupHandshakeRoom(t, WithAuthenticator(authenticator))
+
+	helper.SendMessage("player-0", "handshake", HandshakePayload{UserID: "someone-else"})
+
+	player := state.Players["player-0"]
+	if player.UserID != "" {
+		t.Errorf("expected unverified uid to be ignored, got %q", player.UserID)
+	}
+	if !player.IsConnected {
+		t.Errorf("player should still be connected anonymously")
+	}
+}
+
+func TestHandshake_VerifiesToken(t *testing.T) {
+	authenticator := auth.NewAuthenticatorMock()
+	authenticator.AddToken("valid-token", "user-1")
+	helper, state := setupHandshakeRoom(t, WithAuthenticator(authenticator))
+
+	helper.SendMessage("player-0", "handshake", HandshakePayload{UserID: "someone-else", Token: "valid-token"})
+
+	if uid := state.Players["player-0"].UserID; uid != "user-1" {
+		t.Errorf("expected uid from token, got %q", uid)
+	}
+	if identity := helper.Clients["player-0"].Identity(); identity == nil || identity.Subject != "user-1" {
+		t.Errorf("expected identity to be attached to the client, got %+v", identity)
+	}
+
+	helper.ClearAllMessages()
+	helper.SendMessage("player-1", "handshake", HandshakePayload{UserID: "user-1", Token: "forged"})
+	helper.AssertMessageReceived("player-1", "error")
+	if uid := state.Players["player-1"].UserID; uid != "" {
+		t.Errorf("forged token must not set a uid, got %q", uid)
+	}
+}
+
+func TestHandshake_PrefersClientIdentity(t *testing.T) {
+	helper, state := setupHandshakeRoom(t, WithAuthenticator(auth.NewAuthenticatorMock()))
+	helper.Clients["player-0"].SetIdentity(&interfaces.Identity{Subject: "user-1", Name: "Alice", Email: "alice@example.com"})
+
+	helper.SendMessage("player-0", "handshake", HandshakePayload{UserID: "someone-else"})
+
+	if uid := state.Players["player-0"].UserID; uid != "user-1" {
+		t.Errorf("expected uid from client identity, got %q", uid)
+	}
+
+	// the journal keeps the subject to replay the handshake, nothing else of the identity
+	recorded := helper.Journal()
+	entry := recorded.Entries[len(recorded.Entries)-1]
+	if entry.Subject != "user-1" || entry.Identity != nil {
+		t.Errorf("expected only the subject to be journaled, got %q and %+v", entry.Subject, entry.Identity)
+	}
+	encoded, _ := json.Marshal(recorded)
+	if strings.Contains(string(encoded), "alice@example.com") || strings.Contains(string(encoded), "Alice") {
+		t.Errorf("expected no personal data in the journal, got %s", encoded)
+	}
+	testicles.AssertReplay(t, NewGame(&database.DatabaseServiceMock{}, WithAuthenticator(auth.NewAuthenticatorMock())), recorded)
+}
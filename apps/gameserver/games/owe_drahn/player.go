@@ -7,14 +7,18 @@ import (
 type Player struct {
 	ID          string              `json:"id"`
 	UserID      string              `json:"uid"`
+	AccountID   string              `json:"accountId,omitempty"`
 	Name        string              `json:"username"`
-	Rank        int                 `json:"rank"` // if the user was logged in, we can show their rank
+	Rank        int                 `json:"rank"` // the rating of the account, for guests a rank from their games
 	Stats       *models.PlayerStats `json:"stats"`
 	Life        int                 `json:"life"`
 	IsReady     bool                `json:"ready"`
 	IsChoosing  bool                `json:"choosing"`
 	IsConnected bool                `json:"connected"`
 	Balance     float64             `json:"balance"` // total wins/losses
+	ClientSeed  string              `json:"-"`       // provably fair seed sent with ready
+
+	BotControlled bool `json:"botControlled,omitempty"` // set while a bot plays for the disconnected player
 }
 
 func NewPlayer(id string, name string) *Player {
@@ -33,9 +37,12 @@ func (p *Player) Reset() {
 	p.IsChoosing = false
 }
 
+// SetStats sets the stats of a logged in user, guests without an account are ranked by them
 func (p *Player) SetStats(stats *models.PlayerStats) {
 	p.Stats = stats
-	p.Rank = calculateRank(stats.TotalGames)
+	if p.AccountID == "" {
+		p.Rank = calculateRank(stats.TotalGames)
+	}
 }
 
 func (p *Player) ToFormattedPlayer() *models.FormattedPlayer {
@@ -47,6 +54,7 @@ func (p *Player) ToFormattedPlayer() *models.FormattedPlayer {
 	}
 }
 
+// calculateRank ranks guests, players with an account are ranked by their rating
 func calculateRank(totalGames int) int {
 	return totalGames/10 + totalGames
 }
@@ -0,0 +1,64 @@
+package owe_drahn
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"gameserver/games/owe_drahn/database"
+	"gameserver/games/owe_drahn/models"
+	"gameserver/internal/database/sql"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/rating"
+)
+
+func setupRatings(t *testing.T) *rating.Service {
+	t.Helper()
+	ctx := context.Background()
+	db, err := sql.New(ctx, filepath.Join(t.TempDir(), "ratings.sqlite"), sql.WithAllowedTables([]string{"ratings", "rating_history"}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if err := rating.InitializeSchema(ctx, db); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+	ratings := rating.NewService(rating.NewSQLStore(db))
+	t.Cleanup(func() { ratings.Close() })
+	return ratings
+}
+
+func TestPlayer_RankedByRating(t *testing.T) {
+	ctx := context.Background()
+	ratings := setupRatings(t)
+	err := ratings.RecordMatch(ctx, "owedrahn", map[string]interfaces.Outcome{
+		"alice": interfaces.OutcomeWin,
+		"bob":   interfaces.OutcomeLoss,
+	})
+	if err != nil {
+		t.Fatalf("Failed to record match: %v", err)
+	}
+	g := NewGame(&database.DatabaseServiceMock{}, WithRatings(ratings))
+
+	alice := NewPlayer("player-0", "alice")
+	alice.AccountID = "alice"
+	g.setRank(ctx, alice)
+	// the stats of the handshake don't change the rank of an account
+	alice.SetStats(&models.PlayerStats{TotalGames: 50})
+	if current, _ := ratings.CurrentRating(ctx, "alice", "owedrahn"); alice.Rank != int(current.Rating+0.5) || alice.Rank <= 1500 {
+		t.Errorf("expected alice to be ranked by the account rating %.0f, got %d", current.Rating, alice.Rank)
+	}
+
+	carol := NewPlayer("player-1", "carol")
+	carol.AccountID = "carol"
+	g.setRank(ctx, carol)
+	if carol.Rank != 1500 {
+		t.Errorf("expected an unrated account to have the initial rating, got %d", carol.Rank)
+	}
+
+	guest := NewPlayer("player-2", "guest")
+	g.setRank(ctx, guest)
+	guest.SetStats(&models.PlayerStats{TotalGames: 50})
+	if guest.Rank != calculateRank(50) {
+		t.Errorf("expected a guest to be ranked by games played, got %d", guest.Rank)
+	}
+}
@@ -0,0 +1,56 @@
+package owe_drahn
+
+import (
+	"testing"
+
+	"gameserver/games/owe_drahn/database"
+	"gameserver/internal/rng"
+	"gameserver/internal/testicles"
+)
+
+func TestOweDrahn_ScriptedRollsKillPlayer(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	g := NewGame(&database.DatabaseServiceMock{})
+	helper.RegisterGame(g)
+
+	// starting player index, then the dice: 6, 6, 3 (doesn't count), 5
+	helper.UseRNG(rng.NewScripted(0, 5, 5, 2, 4))
+	playerIds := helper.SetupGameRoom("owedrahn", 2)
+
+	helper.SendMessage(playerIds[0], "ready", true)
+	helper.SendMessage(playerIds[1], "ready", true)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+
+	state := testRoom.State().(*GameState)
+	first := state.CurrentTurn
+	for range 4 {
+		helper.SendMessage(state.CurrentTurn, "roll", nil)
+	}
+
+	totals := make([]int, 0, len(state.Rolls))
+	for _, roll := range state.Rolls {
+		totals = append(totals, roll.Total)
+	}
+	expected := []int{6, 12, 12, 17}
+	if len(totals) != len(expected) {
+		t.Fatalf("expected totals %v, got %v", expected, totals)
+	}
+	for i := range expected {
+		if totals[i] != expected[i] {
+			t.Fatalf("expected totals %v, got %v", expected, totals)
+		}
+	}
+
+	for id, player := range state.Players {
+		if id == first && player.Life == 0 {
+			t.Errorf("starting player should have survived")
+		}
+		if id != first && player.Life != 0 {
+			t.Errorf("second player should have died on 17")
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package owe_drahn
+
+import (
+	"gameserver/games/owe_drahn/database"
+	"gameserver/internal/client"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/rng"
+	"gameserver/internal/testicles"
+	"testing"
+	"time"
+)
+
+// waitForRolls advances the clock by the bot delay until the room has the given number of rolls.
+// The bot gets its states on its own goroutine, so a move may need more than one delay.
+func waitForRolls(t *testing.T, helper *testicles.TestHelper, state *GameState, rolls int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(state.Rolls) < rolls {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d rolls, got %d", rolls, len(state.Rolls))
+		}
+		helper.Clock.Advance(botDelay)
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestOweDrahn_BotTakeover(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	helper.RegisterGame(NewGame(&database.DatabaseServiceMock{}))
+
+	// player-1 starts, every roll is a 1 so nobody dies
+	helper.UseRNG(rng.NewScripted(1, 0, 0, 0, 0, 0))
+	options := RoomOptions{TakeoverOptions: interfaces.TakeoverOptions{BotTakeover: true, BotTakeoverGrace: 0.05}}
+	player1 := helper.CreateClient("player-0")
+	helper.CreateRoomWithOptions(player1, "owedrahn", "player-0", options)
+	helper.JoinRoom(helper.CreateClient("player-1"), helper.RoomID, "player-1")
+	helper.SendMessage("player-0", "ready", true)
+	helper.SendMessage("player-1", "ready", true)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+	state := testRoom.State().(*GameState)
+	if !state.Started || state.CurrentTurn != "player-1" {
+		t.Fatalf("Expected the game to start with player-1, got %q", state.CurrentTurn)
+	}
+
+	testRoom.Disconnect(player1)
+	helper.Clock.Advance(40 * time.Millisecond)
+	if seat, seated := testRoom.Clients()["player-0"]; seated {
+		t.Fatalf("Expected the seat to wait for the player during the grace period, got %v", seat)
+	}
+
+	helper.Clock.Advance(10 * time.Millisecond)
+	if seat, seated := testRoom.Clients()["player-0"]; !seated || !seat.IsBot() {
+		t.Fatal("Expected a bot to take over the seat of the disconnected player")
+	}
+	if !state.Players["player-0"].BotControlled {
+		t.Error("Expected the seat to be shown as bot-controlled")
+	}
+
+	// the bot rolls once on its turn and waits for the other player
+	helper.SendMessage("player-1", "roll", nil)
+	waitForRolls(t, helper, state, 2)
+	if roll := state.Rolls[1]; roll.Player.Username != "player-0" {
+		t.Errorf("Expected the bot to roll for player-0, got %s", roll.Player.Username)
+	}
+	if state.CurrentTurn != "player-1" {
+		t.Fatalf("Expected the turn to pass to player-1, got %s", state.CurrentTurn)
+	}
+	for range 3 {
+		helper.Clock.Advance(botDelay)
+		time.Sleep(time.Millisecond)
+	}
+	if len(state.Rolls) != 2 {
+		t.Fatalf("Expected the bot to wait for its turn, got %d rolls", len(state.Rolls))
+	}
+
+	// the bot moves again on the state of its next turn
+	helper.SendMessage("player-1", "roll", nil)
+	waitForRolls(t, helper, state, 4)
+	if roll := state.Rolls[3]; roll.Player.Username != "player-0" {
+		t.Errorf("Expected the bot to roll for player-0 again, got %s", roll.Player.Username)
+	}
+
+	// the player takes the seat back with a new connection
+	reconnected := client.NewClientMock("player-0-new")
+	if err = helper.Registry.HandleClientReconnect(reconnected, testRoom, "player-0"); err != nil {
+		t.Fatalf("Failed to reconnect: %v", err)
+	}
+	if state.Players["player-0"].BotControlled {
+		t.Error("Expected the player to control its seat again")
+	}
+	if seat := testRoom.Clients()["player-0"]; seat == nil || seat.IsBot() {
+		t.Errorf("Expected the player back on its seat, got %v", seat)
+	}
+
+	// the bot that left the seat makes no more moves
+	helper.SendMessage("player-1", "roll", nil)
+	for range 3 {
+		helper.Clock.Advance(botDelay)
+		time.Sleep(time.Millisecond)
+	}
+	if len(state.Rolls) != 5 || state.CurrentTurn != "player-0" {
+		t.Errorf("Expected the player to have the turn, got %d rolls and turn %s", len(state.Rolls), state.CurrentTurn)
+	}
+}
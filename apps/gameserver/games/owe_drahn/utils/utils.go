@@ -2,7 +2,7 @@ package utils
 
 import (
 	"gameserver/games/owe_drahn/models"
-	"math/rand"
+	"gameserver/internal/rng"
 )
 
 // DefaultStats returns default player statistics
@@ -86,6 +86,6 @@ func ExtractPlayerStats(uid string, game models.DBGame) models.PlayerStatAggrega
 }
 
 // Random returns a random number between min and max (inclusive)
-func Random(min, max int) int {
-	return rand.Intn(max-min+1) + min
+func Random(random rng.RNG, min, max int) int {
+	return random.Intn(max-min+1) + min
 }
@@ -0,0 +1,89 @@
+package owe_drahn
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gameserver/internal/fair"
+)
+
+// MaxVerifyRolls is more rolls than a round plays. A player rolls at most 16 dice that count
+// before dying, so it leaves room for over a hundred players and their threes.
+const MaxVerifyRolls = 4096
+
+// maxVerifyRequestSize bounds the body of a verify request, the seeds and MaxVerifyRolls dice
+const maxVerifyRequestSize = 32 << 10
+
+// VerifyRequest is the reveal sent with gameOver in provably fair rooms
+type VerifyRequest struct {
+	ServerSeed     string `json:"serverSeed"`
+	ServerSeedHash string `json:"serverSeedHash"`
+	ClientSeed     string `json:"clientSeed"`
+	Rolls          []int  `json:"rolls"`
+}
+
+// RollCheck is the verification result of a single roll
+type RollCheck struct {
+	Nonce    int  `json:"nonce"`
+	Dice     int  `json:"dice"`
+	Expected int  `json:"expected"`
+	Valid    bool `json:"valid"`
+}
+
+// VerifyResult tells whether every roll of a round was derived from the committed seeds
+type VerifyResult struct {
+	Valid bool        `json:"valid"`
+	Error string      `json:"error,omitempty"`
+	Rolls []RollCheck `json:"rolls"`
+}
+
+// VerifyRolls recomputes every roll of a round from the revealed server seed and the client seed
+func VerifyRolls(req VerifyRequest) VerifyResult {
+	serverSeed, err := fair.ParseServerSeed(req.ServerSeed, req.ServerSeedHash)
+	if err != nil {
+		return VerifyResult{Valid: false, Error: err.Error(), Rolls: make([]RollCheck, 0)}
+	}
+
+	result := VerifyResult{Valid: true, Rolls: make([]RollCheck, len(req.Rolls))}
+	for nonce, dice := range req.Rolls {
+		expected := fair.Derive(serverSeed, req.ClientSeed, nonce, 6) + 1
+		result.Rolls[nonce] = RollCheck{
+			Nonce:    nonce,
+			Dice:     dice,
+			Expected: expected,
+			Valid:    dice == expected,
+		}
+		if dice != expected {
+			result.Valid = false
+		}
+	}
+
+	return result
+}
+
+// VerifyHandler serves POST requests with a VerifyRequest body and answers with a VerifyResult
+func VerifyHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req VerifyRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxVerifyRequestSize)).Decode(&req); err != nil {
+		http.Error(w, `{"error": "invalid verify request"}`, http.StatusBadRequest)
+		return
+	}
+	if len(req.Rolls) > MaxVerifyRolls {
+		http.Error(w, `{"error": "too many rolls"}`, http.StatusBadRequest)
+		return
+	}
+
+	jsonData, err := json.Marshal(VerifyRolls(req))
+	if err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
@@ -0,0 +1,49 @@
+package tell_it
+
+import (
+	"fmt"
+	"testing"
+
+	"gameserver/games/tell_it/database"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/testicles"
+)
+
+func FuzzTellIt(f *testing.F) {
+	testicles.FuzzRouter(f, testicles.FuzzTarget{
+		GameType: "tellit",
+		Register: func(registry interfaces.GameRegistry) {
+			registry.RegisterGame(NewGame(&database.DatabaseServiceMock{}))
+		},
+		Players: 3,
+		Messages: []testicles.FuzzMessage{
+			{Type: "start"},
+			{Type: "submit_text", Payloads: []string{`{"text":"once upon a time"}`, `{"text":""}`}},
+			{Type: "vote_finish"},
+			{Type: "vote_restart"},
+			{Type: "vote_kick", Payloads: []string{`{"kickUserID":"$seat"}`}},
+			{Type: "request_stories"},
+			{Type: "request_update"},
+		},
+		Check: checkTellIt,
+	})
+}
+
+// checkTellIt checks that every user has a place in the order and every story was written
+func checkTellIt(room interfaces.Room) error {
+	state := room.State().(*GameState)
+	if len(state.Users) != len(state.UserOrder) {
+		return fmt.Errorf("%d users in an order of %d", len(state.Users), len(state.UserOrder))
+	}
+	for _, id := range state.UserOrder {
+		if state.Users[id] == nil {
+			return fmt.Errorf("user %s in the order is not in the room", id)
+		}
+	}
+	for _, story := range state.Stories {
+		if len(story.Texts) == 0 {
+			return fmt.Errorf("story of %s has no text", story.OwnerID)
+		}
+	}
+	return nil
+}
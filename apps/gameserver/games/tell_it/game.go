@@ -25,6 +25,7 @@ func (gs GameStatus) String() string {
 
 type Game struct {
 	dbService database.Database
+	registry  interfaces.GameRegistry
 }
 
 type GameState struct {
@@ -57,7 +58,13 @@ func (s *GameState) ToMap() interfaces.M {
 	}
 }
 
+// AddUser adds a user to the room, a user that left and joins again keeps its place and stories
 func (g *Game) AddUser(clientId string, name string, state *GameState) {
+	if user, exists := state.Users[clientId]; exists {
+		user.Name = name
+		user.Disconnected = false
+		return
+	}
 	state.Users[clientId] = NewUser(clientId, name)
 	state.UserOrder = append(state.UserOrder, clientId)
 }
@@ -96,73 +103,24 @@ func (g *Game) countActiveUsers(state *GameState) int {
 	return count
 }
 
-// ReconnectUser handles reconnecting a user with a new client ID
-// This patches all references to the old ID throughout the game state
-func (g *Game) ReconnectUser(oldClientID, newClientID string, state *GameState) error {
-	// Find the old user
-	oldUser, ok := state.Users[oldClientID]
+// ReconnectUser marks a user that is back on its seat as connected
+func (g *Game) ReconnectUser(userID string, state *GameState) error {
+	user, ok := state.Users[userID]
 	if !ok {
 		return errors.New("user not found")
 	}
 
-	// Update the user's ID and disconnected state
-	oldUser.ID = newClientID
-	oldUser.Disconnected = false
-
-	// Move user to new ID in Users map
-	delete(state.Users, oldClientID)
-	state.Users[newClientID] = oldUser
-
-	// Update UserOrder
-	for i, uid := range state.UserOrder {
-		if uid == oldClientID {
-			state.UserOrder[i] = newClientID
-			break
-		}
-	}
-
-	// Update FinishVotes
-	if voted, ok := state.FinishVotes[oldClientID]; ok {
-		delete(state.FinishVotes, oldClientID)
-		state.FinishVotes[newClientID] = voted
-	}
-
-	// Update RestartVotes
-	if voted, ok := state.RestartVotes[oldClientID]; ok {
-		delete(state.RestartVotes, oldClientID)
-		state.RestartVotes[newClientID] = voted
-	}
-
-	// Update KickVotes for all users
-	for _, user := range state.Users {
-		for i, voterID := range user.KickVotes {
-			if voterID == oldClientID {
-				user.KickVotes[i] = newClientID
-			}
-		}
-	}
-
-	// Update Story owner
-	for _, story := range state.Stories {
-		if story.OwnerID == oldClientID {
-			story.OwnerID = newClientID
-			break
-		}
-	}
+	user.Disconnected = false
 
-	log.Info().
-		Str("user", oldUser.Name).
-		Str("oldID", oldClientID).
-		Str("newID", newClientID).
-		Msg("User reconnected with new ID")
+	log.Info().Str("user", user.Name).Str("id", userID).Msg("User reconnected")
 
 	return nil
 }
 
-func (g *Game) StartGame(state *GameState) {
+func (g *Game) StartGame(state *GameState, now time.Time) {
 	state.Started = true
 	state.GameStatus = GameStatusStarted
-	state.StartTime = time.Now()
+	state.StartTime = now
 	state.FinishVotes = make(map[string]bool)
 	state.RestartVotes = make(map[string]bool)
 	log.Info().Str("room", state.RoomName).Msg("Game started")
@@ -380,6 +338,7 @@ func (g *Game) EndGame(state *GameState, room interfaces.Room) {
 		"stories": stories,
 	})
 	room.Broadcast(msg)
+	g.reportResult(room, state, len(stories))
 
 	msg = protocol.NewSuccessResponse("game_status", interfaces.M{
 		"status": state.GameStatus.String(),
@@ -398,6 +357,32 @@ func (g *Game) EndGame(state *GameState, room interfaces.Room) {
 	}
 }
 
+// reportResult reports the finished story round, tell-it has no winners so nobody is placed
+func (g *Game) reportResult(room interfaces.Room, state *GameState, stories int) {
+	if g.registry == nil {
+		return
+	}
+
+	participants := make([]interfaces.Participant, 0, len(state.UserOrder))
+	for _, id := range state.UserOrder {
+		user, ok := state.Users[id]
+		if !ok {
+			continue
+		}
+		participants = append(participants, interfaces.Participant{
+			ClientID:  user.ID,
+			AccountID: user.AccountID,
+			Name:      user.Name,
+		})
+	}
+
+	g.registry.ReportResult(room, interfaces.GameResult{
+		StartedAt:    state.StartTime,
+		Participants: participants,
+		Metadata:     interfaces.M{"stories": stories},
+	})
+}
+
 func (g *Game) RestartGame(state *GameState, room interfaces.Room) {
 	state.Started = false
 	state.GameStatus = GameStatusWaiting
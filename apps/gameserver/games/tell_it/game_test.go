@@ -2,6 +2,7 @@ package tell_it
 
 import (
 	"testing"
+	"time"
 )
 
 func newTestGame() *Game {
@@ -90,7 +91,8 @@ func TestGame_StartGame(t *testing.T) {
 		RestartVotes: nil,
 	}
 
-	game.StartGame(state)
+	startedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	game.StartGame(state, startedAt)
 
 	if !state.Started {
 		t.Error("Expected Started to be true")
@@ -100,6 +102,10 @@ func TestGame_StartGame(t *testing.T) {
 		t.Errorf("Expected GameStatus to be 'started', got '%s'", state.GameStatus)
 	}
 
+	if !state.StartTime.Equal(startedAt) {
+		t.Errorf("Expected StartTime to be %v, got %v", startedAt, state.StartTime)
+	}
+
 	if state.FinishVotes == nil {
 		t.Error("Expected FinishVotes to be initialized")
 	}
@@ -162,118 +168,23 @@ func TestGame_ReconnectUser(t *testing.T) {
 		RestartVotes: make(map[string]bool),
 	}
 
-	// Add users
-	game.AddUser("oldID1", "Alice", state)
+	game.AddUser("user1", "Alice", state)
 	game.AddUser("user2", "Bob", state)
-	game.AddUser("user3", "Charlie", state)
-
-	// Set up some game state
-	story := NewStory("oldID1")
-	story.AddText("test story")
-	state.Stories = append(state.Stories, story)
-	state.Users["oldID1"].Disconnected = true
-	state.FinishVotes["oldID1"] = true
-	state.RestartVotes["oldID1"] = true
-
-	// Add kick votes from oldID1
-	state.Users["user2"].KickVotes = append(state.Users["user2"].KickVotes, "oldID1")
-
-	// Reconnect the user with a new ID
-	err := game.ReconnectUser("oldID1", "newID1", state)
-	if err != nil {
-		t.Fatalf("ReconnectUser failed: %v", err)
-	}
-
-	// Verify old ID is removed from Users map
-	if _, exists := state.Users["oldID1"]; exists {
-		t.Error("Expected old ID to be removed from Users map")
-	}
-
-	// Verify new ID exists in Users map
-	user, exists := state.Users["newID1"]
-	if !exists {
-		t.Fatal("Expected new ID to exist in Users map")
-	}
-
-	// Verify user data is preserved
-	if user.Name != "Alice" {
-		t.Errorf("Expected user name to be 'Alice', got '%s'", user.Name)
-	}
-
-	// Verify disconnected status is cleared
-	if user.Disconnected {
-		t.Error("Expected Disconnected to be false after reconnect")
-	}
-
-	// Verify user ID is updated
-	if user.ID != "newID1" {
-		t.Errorf("Expected user ID to be 'newID1', got '%s'", user.ID)
-	}
-
-	// Verify UserOrder is updated
-	found := false
-	for _, id := range state.UserOrder {
-		if id == "newID1" {
-			found = true
-		}
-		if id == "oldID1" {
-			t.Error("Old ID should not be in UserOrder")
-		}
-	}
-	if !found {
-		t.Error("New ID should be in UserOrder")
-	}
+	state.Users["user1"].Disconnected = true
 
-	// Verify FinishVotes is updated
-	if _, exists := state.FinishVotes["oldID1"]; exists {
-		t.Error("Old ID should not be in FinishVotes")
-	}
-	if !state.FinishVotes["newID1"] {
-		t.Error("New ID should be in FinishVotes")
-	}
-
-	// Verify RestartVotes is updated
-	if _, exists := state.RestartVotes["oldID1"]; exists {
-		t.Error("Old ID should not be in RestartVotes")
-	}
-	if !state.RestartVotes["newID1"] {
-		t.Error("New ID should be in RestartVotes")
-	}
-
-	// Verify KickVotes are updated
-	found = false
-	for _, voterID := range state.Users["user2"].KickVotes {
-		if voterID == "newID1" {
-			found = true
-		}
-		if voterID == "oldID1" {
-			t.Error("Old ID should not be in KickVotes")
-		}
-	}
-	if !found {
-		t.Error("New ID should be in KickVotes")
+	// The seat keeps its id, reconnecting only marks the user as connected
+	if err := game.ReconnectUser("user1", state); err != nil {
+		t.Fatalf("ReconnectUser failed: %v", err)
 	}
-
-	// Verify Stories are updated
-	found = false
-	for _, story := range state.Stories {
-		if story.OwnerID == "newID1" {
-			found = true
-			if story.Texts[0] != "test story" {
-				t.Error("Story content should remain the same")
-			}
-		}
-		if story.OwnerID == "oldID1" {
-			t.Error("Old ID should not be in Stories")
-		}
+	if state.Users["user1"].Disconnected {
+		t.Error("Expected user to be connected again")
 	}
-	if !found {
-		t.Error("New ID should be in Stories")
+	if len(state.Users) != 2 || state.UserOrder[0] != "user1" {
+		t.Errorf("Expected users to be unchanged, got %v", state.UserOrder)
 	}
 
 	// Test error case: user not found
-	err = game.ReconnectUser("nonexistent", "newID", state)
-	if err == nil {
+	if err := game.ReconnectUser("nonexistent", state); err == nil {
 		t.Error("Expected error when reconnecting nonexistent user")
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"gameserver/games/tell_it/models"
 	"gameserver/internal/interfaces"
 	"gameserver/internal/protocol"
+	"gameserver/internal/rng"
 	"github.com/rs/zerolog/log"
 	"time"
 )
@@ -34,6 +35,7 @@ func RegisterGame(ctx context.Context, r interfaces.GameRegistry, config GameCon
 	}
 
 	g := NewGame(dbService)
+	g.registry = r
 	r.RegisterGame(g)
 	return nil
 }
@@ -44,7 +46,7 @@ func (g *Game) Type() string {
 }
 
 // InitializeRoom sets up a new room with the initial game state
-func (g *Game) InitializeRoom(ctx context.Context, room interfaces.Room, options json.RawMessage) error {
+func (g *Game) InitializeRoom(ctx context.Context, room interfaces.Room, options json.RawMessage, _ rng.RNG) error {
 	// Parse room config if provided
 	config := models.RoomConfig{
 		SpectatorsAllowed: true,
@@ -84,6 +86,7 @@ func (g *Game) OnClientJoin(client interfaces.Client, room interfaces.Room, opti
 	userName := options.PlayerName
 
 	g.AddUser(client.ID(), userName, state)
+	state.Users[client.ID()].AccountID = options.AccountID
 
 	log.Info().Str("user", userName).Str("room", room.ID()).Msg("User joined tell-it room")
 
@@ -108,11 +111,11 @@ func (g *Game) OnClientLeave(client interfaces.Client, room interfaces.Room) {
 }
 
 // OnClientReconnect handles when a client reconnects to the game
-func (g *Game) OnClientReconnect(client interfaces.Client, room interfaces.Room, oldClientId string) error {
+func (g *Game) OnClientReconnect(client interfaces.Client, room interfaces.Room) error {
 	state := room.State().(*GameState)
 
-	if err := g.ReconnectUser(oldClientId, client.ID(), state); err != nil {
-		log.Error().Err(err).Str("oldID", oldClientId).Str("newID", client.ID()).Msg("Failed to reconnect user")
+	if err := g.ReconnectUser(client.ID(), state); err != nil {
+		log.Error().Err(err).Str("id", client.ID()).Msg("Failed to reconnect user")
 		return err
 	}
 
@@ -125,10 +128,18 @@ func (g *Game) OnClientReconnect(client interfaces.Client, room interfaces.Room,
 }
 
 // OnBotAdd handles adding a bot to the game (not supported for tell-it)
-func (g *Game) OnBotAdd(client interfaces.Client, room interfaces.Room, registry interfaces.GameRegistry) (interfaces.Client, string, error) {
+func (g *Game) OnBotAdd(client interfaces.Client, room interfaces.Room, registry interfaces.GameRegistry, _ interfaces.BotOptions) (interfaces.Client, string, error) {
 	return nil, "", errors.New("bots are not supported for tell-it game")
 }
 
+// SnapshotState returns the state used to compare journaled and replayed rooms, without timestamps
+func (g *Game) SnapshotState(room interfaces.Room) interface{} {
+	state := *room.State().(*GameState)
+	state.Ctx = nil
+	state.StartTime = time.Time{}
+	return state
+}
+
 func (g *Game) HandleMessage(client interfaces.Client, room interfaces.Room, msgType string, data []byte) error {
 	state := room.State().(*GameState)
 
@@ -164,7 +175,7 @@ func (g *Game) handleStart(client interfaces.Client, state *GameState, room inte
 		return
 	}
 
-	g.StartGame(state)
+	g.StartGame(state, room.Clock().Now())
 
 	room.SetState(state)
 
@@ -173,6 +184,10 @@ func (g *Game) handleStart(client interfaces.Client, state *GameState, room inte
 		"status": state.GameStatus.String(),
 	})
 	room.Broadcast(msg)
+
+	if g.registry != nil {
+		g.registry.ReportStarted(room)
+	}
 }
 
 func (g *Game) handleSubmitText(client interfaces.Client, state *GameState, room interfaces.Room, payload json.RawMessage) {
@@ -0,0 +1,74 @@
+package tell_it
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gameserver/games/tell_it/database"
+	"gameserver/internal/replay"
+	"gameserver/internal/testicles"
+	"testing"
+)
+
+func playRecordedSession(t *testing.T) *testicles.TestHelper {
+	helper := testicles.NewTestHelper(t)
+	helper.RegisterGame(NewGame(&database.DatabaseServiceMock{}))
+
+	playerIds := helper.SetupGameRoom("tellit", 3)
+
+	helper.SendMessage(playerIds[0], "start", nil)
+	helper.SendMessage(playerIds[0], "submit_text", map[string]string{"text": "Once upon a time"})
+	helper.SendMessage(playerIds[1], "submit_text", map[string]string{"text": "There was a dragon"})
+	helper.SendMessage(playerIds[1], "submit_text", map[string]string{"text": "who loved tea"})
+	helper.SendMessage(playerIds[2], "vote_kick", map[string]string{"kickUserID": playerIds[1]})
+	for _, id := range playerIds {
+		helper.SendMessage(id, "vote_finish", nil)
+	}
+
+	return helper
+}
+
+func TestReplay_MatchesRecording(t *testing.T) {
+	helper := playRecordedSession(t)
+
+	j := helper.Journal()
+	if len(j.Entries) == 0 {
+		t.Fatalf("expected journal entries to be recorded")
+	}
+
+	testicles.AssertReplay(t, NewGame(&database.DatabaseServiceMock{}), j)
+}
+
+func TestReplay_DetectsDivergence(t *testing.T) {
+	helper := playRecordedSession(t)
+	j := helper.Journal()
+
+	// Tamper with a submitted text, the replayed state can no longer match
+	for i, entry := range j.Entries {
+		if entry.MsgType == "submit_text" {
+			j.Entries[i].Data = json.RawMessage(`{"text":"A different story"}`)
+			break
+		}
+	}
+
+	err := replay.Run(context.Background(), NewGame(&database.DatabaseServiceMock{}), j)
+
+	var divergence *replay.Divergence
+	if !errors.As(err, &divergence) {
+		t.Fatalf("expected a divergence, got %v", err)
+	}
+	if divergence.Entry.MsgType != "submit_text" {
+		t.Errorf("expected divergence at submit_text, got %s", divergence.Entry.MsgType)
+	}
+}
+
+func TestReplay_RequiresSeed(t *testing.T) {
+	helper := playRecordedSession(t)
+	j := helper.Journal()
+	j.Header.Seed = nil
+
+	err := replay.Run(context.Background(), NewGame(&database.DatabaseServiceMock{}), j)
+	if !errors.Is(err, replay.ErrNoSeed) {
+		t.Errorf("expected a journal without seed to fail, got %v", err)
+	}
+}
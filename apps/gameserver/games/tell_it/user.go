@@ -7,6 +7,7 @@ import (
 
 type User struct {
 	ID           string   `json:"id"`
+	AccountID    string   `json:"accountId,omitempty"`
 	Name         string   `json:"name"`
 	Disconnected bool     `json:"disconnected"`
 	AFK          bool     `json:"afk"`
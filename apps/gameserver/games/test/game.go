@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"gameserver/internal/interfaces"
+	"gameserver/internal/rng"
 )
 
 type TestGame struct{}
@@ -24,7 +25,7 @@ func (g *TestGame) HandleMessage(client interfaces.Client, room interfaces.Room,
 	return nil
 }
 
-func (g *TestGame) InitializeRoom(ctx context.Context, room interfaces.Room, options json.RawMessage) error {
+func (g *TestGame) InitializeRoom(ctx context.Context, room interfaces.Room, options json.RawMessage, _ rng.RNG) error {
 	state := GameState{
 		Players: make(map[string]PlayerInfo),
 	}
@@ -36,7 +37,7 @@ func (g *TestGame) InitializeRoom(ctx context.Context, room interfaces.Room, opt
 func (g *TestGame) OnClientJoin(client interfaces.Client, room interfaces.Room, _ interfaces.CreateRoomOptions) {
 }
 
-func (g *TestGame) OnBotAdd(client interfaces.Client, room interfaces.Room, reg interfaces.GameRegistry) (interfaces.Client, string, error) {
+func (g *TestGame) OnBotAdd(client interfaces.Client, room interfaces.Room, reg interfaces.GameRegistry, _ interfaces.BotOptions) (interfaces.Client, string, error) {
 	id := "bot-1"
 	name := "Bot 1"
 	bot := NewBot(id, g, reg)
@@ -46,7 +47,7 @@ func (g *TestGame) OnBotAdd(client interfaces.Client, room interfaces.Room, reg
 func (g *TestGame) OnClientLeave(client interfaces.Client, room interfaces.Room) {
 }
 
-func (g *TestGame) OnClientReconnect(client interfaces.Client, room interfaces.Room, oldClientId string) error {
+func (g *TestGame) OnClientReconnect(client interfaces.Client, room interfaces.Room) error {
 	return nil
 }
 
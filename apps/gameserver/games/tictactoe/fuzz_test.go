@@ -0,0 +1,52 @@
+package tictactoe
+
+import (
+	"fmt"
+	"testing"
+
+	"gameserver/internal/interfaces"
+	"gameserver/internal/testicles"
+)
+
+func FuzzTicTacToe(f *testing.F) {
+	var moves []string
+	for row := -1; row <= 3; row++ {
+		for col := -1; col <= 3; col++ {
+			moves = append(moves, fmt.Sprintf(`{"row":%d,"col":%d}`, row, col))
+		}
+	}
+
+	testicles.FuzzRouter(f, testicles.FuzzTarget{
+		GameType: "tictactoe",
+		Register: RegisterTicTacToeGame,
+		Players:  2,
+		Messages: []testicles.FuzzMessage{
+			{Type: "make_move", Payloads: moves},
+			{Type: "restart_game"},
+			{Type: "rematch"},
+		},
+		Check: checkTicTacToe,
+	})
+}
+
+// checkTicTacToe checks that the turn belongs to a player and the players took turns on the board
+func checkTicTacToe(room interfaces.Room) error {
+	state := room.State().(GameState)
+	if err := testicles.CheckCurrentTurn(state.CurrentTurn, state.Players); err != nil {
+		return err
+	}
+
+	counts := make(map[string]int)
+	for _, cells := range state.Board {
+		for _, cell := range cells {
+			if cell != "" && cell != "X" && cell != "O" {
+				return fmt.Errorf("cell holds %q", cell)
+			}
+			counts[cell]++
+		}
+	}
+	if diff := counts["X"] - counts["O"]; diff < -1 || diff > 1 {
+		return fmt.Errorf("%d X and %d O on the board", counts["X"], counts["O"])
+	}
+	return nil
+}
@@ -6,13 +6,19 @@ import (
 	"errors"
 	"gameserver/internal/interfaces"
 	"gameserver/internal/protocol"
-	"math/rand"
+	"gameserver/internal/rematch"
+	"gameserver/internal/rng"
+	"maps"
+	"slices"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
 // TicTacToe implements the game interface
-type TicTacToe struct{}
+type TicTacToe struct {
+	registry interfaces.GameRegistry
+}
 
 // GameState represents the state of a tic tac toe game
 type GameState struct {
@@ -22,12 +28,24 @@ type GameState struct {
 	Winner      string                `json:"winner"`
 	GameOver    bool                  `json:"gameOver"`
 	DrawGame    bool                  `json:"drawGame"`
+	// Series is the score of the games the two players played and the votes for a rematch
+	Series *rematch.Series `json:"series"`
+
+	rng       rng.RNG
+	startedAt time.Time
 }
 
 // PlayerInfo stores player information
 type PlayerInfo struct {
-	Symbol string `json:"symbol"`
-	Name   string `json:"name"`
+	Symbol    string `json:"symbol"`
+	Name      string `json:"name"`
+	AccountID string `json:"accountId,omitempty"`
+}
+
+// RoomOptions are the options a room can be created with
+type RoomOptions struct {
+	// BestOf is the number of games of a series of rematches, 0 plays on without end
+	BestOf int `json:"bestOf,omitempty"`
 }
 
 // MovePayload represents a move action from a client
@@ -43,6 +61,7 @@ func NewTicTacToe() *TicTacToe {
 
 func RegisterTicTacToeGame(r interfaces.GameRegistry) {
 	g := NewTicTacToe()
+	g.registry = r
 	r.RegisterGame(g)
 }
 
@@ -52,7 +71,14 @@ func (g *TicTacToe) Type() string {
 }
 
 // InitializeRoom sets up a new room with the initial game state
-func (g *TicTacToe) InitializeRoom(ctx context.Context, room interfaces.Room, options json.RawMessage) error {
+func (g *TicTacToe) InitializeRoom(ctx context.Context, room interfaces.Room, options json.RawMessage, random rng.RNG) error {
+	var roomOptions RoomOptions
+	if options != nil {
+		if err := json.Unmarshal(options, &roomOptions); err != nil {
+			log.Warn().Err(err).Msg("Failed to parse room options, using defaults")
+		}
+	}
+
 	// Create initial game state
 	state := GameState{
 		Board:       [3][3]string{{"", "", ""}, {"", "", ""}, {"", "", ""}},
@@ -61,6 +87,8 @@ func (g *TicTacToe) InitializeRoom(ctx context.Context, room interfaces.Room, op
 		Winner:      "",
 		GameOver:    false,
 		DrawGame:    false,
+		Series:      rematch.NewSeries(roomOptions.BestOf),
+		rng:         random,
 	}
 
 	room.SetState(state)
@@ -68,7 +96,7 @@ func (g *TicTacToe) InitializeRoom(ctx context.Context, room interfaces.Room, op
 }
 
 // OnClientJoin handles a client joining the room
-func (g *TicTacToe) OnClientJoin(client interfaces.Client, room interfaces.Room, _ interfaces.CreateRoomOptions) {
+func (g *TicTacToe) OnClientJoin(client interfaces.Client, room interfaces.Room, options interfaces.CreateRoomOptions) {
 	state := room.State().(GameState)
 
 	// Only allow 2 players
@@ -86,19 +114,24 @@ func (g *TicTacToe) OnClientJoin(client interfaces.Client, room interfaces.Room,
 	}
 
 	// Add player to game state
+	name := "Player " + symbol
+	if options.AccountID != "" {
+		name = options.PlayerName
+	}
 	state.Players[client.ID()] = PlayerInfo{
-		Symbol: symbol,
-		Name:   "Player " + symbol,
+		Symbol:    symbol,
+		Name:      name,
+		AccountID: options.AccountID,
 	}
 
-	// If we now have 2 players, start the game
+	// If we now have 2 players, start the game and a new series against the new opponent
 	if len(state.Players) == 2 {
 		// Randomly select first player
-		playerIDs := make([]string, 0, len(state.Players))
-		for id := range state.Players {
-			playerIDs = append(playerIDs, id)
-		}
-		state.CurrentTurn = playerIDs[rand.Intn(len(playerIDs))]
+		playerIDs := slices.Sorted(maps.Keys(state.Players))
+		state.CurrentTurn = playerIDs[state.rng.Intn(len(playerIDs))]
+		state.startedAt = room.Clock().Now()
+		state.Series = rematch.NewSeries(state.Series.BestOf)
+		state.Series.Start(state.CurrentTurn)
 	}
 
 	// Update state
@@ -107,6 +140,10 @@ func (g *TicTacToe) OnClientJoin(client interfaces.Client, room interfaces.Room,
 	// Broadcast updated state to all clients
 	broadcastGameState(room)
 
+	if len(state.Players) == 2 {
+		g.reportStarted(room)
+	}
+
 	// Send welcome message
 	client.Send(protocol.NewSuccessResponse("joined", interfaces.M{
 		"clientId": client.ID(),
@@ -115,7 +152,7 @@ func (g *TicTacToe) OnClientJoin(client interfaces.Client, room interfaces.Room,
 	}))
 }
 
-func (g *TicTacToe) OnBotAdd(client interfaces.Client, room interfaces.Room, reg interfaces.GameRegistry) (interfaces.Client, string, error) {
+func (g *TicTacToe) OnBotAdd(client interfaces.Client, room interfaces.Room, reg interfaces.GameRegistry, _ interfaces.BotOptions) (interfaces.Client, string, error) {
 	return nil, "", errors.New("game does not support bots")
 }
 
@@ -125,11 +162,16 @@ func (g *TicTacToe) OnClientLeave(client interfaces.Client, room interfaces.Room
 
 	// Remove player from game
 	delete(state.Players, client.ID())
+	state.Series.Leave(client.ID())
 
 	// If game was in progress, end it
 	if !state.GameOver && len(state.Players) < 2 {
 		state.GameOver = true
 	}
+	// Nobody plays for a player that left
+	if state.CurrentTurn == client.ID() {
+		state.CurrentTurn = ""
+	}
 
 	// Update state
 	room.SetState(state)
@@ -138,34 +180,17 @@ func (g *TicTacToe) OnClientLeave(client interfaces.Client, room interfaces.Room
 	broadcastGameState(room)
 }
 
-// OnClientReconnect handles reconnecting a client to the game
-func (g *TicTacToe) OnClientReconnect(client interfaces.Client, room interfaces.Room, oldClientID string) error {
+// OnClientReconnect welcomes a player back on its seat
+func (g *TicTacToe) OnClientReconnect(client interfaces.Client, room interfaces.Room) error {
 	state := room.State().(GameState)
 
-	// Check if the old client ID was a player in this game
-	playerInfo, exists := state.Players[oldClientID]
+	// Check if the seat belongs to a player in this game
+	playerInfo, exists := state.Players[client.ID()]
 	if !exists {
 		return errors.New("no player found with provided ID")
 	}
 
-	// Replace the old client ID with the new one, maintaining the same player info
-	delete(state.Players, oldClientID)
-	state.Players[client.ID()] = playerInfo
-
-	// If it was this player's turn, update the current turn
-	if state.CurrentTurn == oldClientID {
-		state.CurrentTurn = client.ID()
-	}
-
-	// Update the winner reference if applicable
-	if state.Winner == oldClientID {
-		state.Winner = client.ID()
-	}
-
-	// Update state
-	room.SetState(state)
-
-	// Broadcast updated state to all clients
+	// Broadcast the state to all clients
 	broadcastGameState(room)
 
 	// Send welcome back message to the reconnected client
@@ -183,8 +208,8 @@ func (g *TicTacToe) HandleMessage(client interfaces.Client, room interfaces.Room
 	switch msgType {
 	case "make_move":
 		g.handleMakeMove(client, room, payload)
-	case "restart_game":
-		g.handleRestartGame(client, room)
+	case "rematch", "restart_game":
+		g.handleRematch(client, room)
 	default:
 		client.Send(protocol.NewErrorResponse("error", "Unknown message type: "+msgType))
 	}
@@ -192,6 +217,15 @@ func (g *TicTacToe) HandleMessage(client interfaces.Client, room interfaces.Room
 	return nil
 }
 
+// CurrentTurn returns the player who has to move, "" while waiting for players or after the game
+func (g *TicTacToe) CurrentTurn(room interfaces.Room) string {
+	state := room.State().(GameState)
+	if state.GameOver {
+		return ""
+	}
+	return state.CurrentTurn
+}
+
 // handleMakeMove processes a move from a player
 func (g *TicTacToe) handleMakeMove(client interfaces.Client, room interfaces.Room, payload []byte) {
 	// Parse move payload
@@ -240,10 +274,14 @@ func (g *TicTacToe) handleMakeMove(client interfaces.Client, room interfaces.Roo
 		state.GameOver = true
 
 		log.Info().Str("winner", client.ID()).Msg("game over")
+		state.Series.Record(client.ID())
+		g.reportResult(room, state)
 	} else if checkDraw(state.Board) {
 		state.DrawGame = true
 		state.GameOver = true
 		log.Info().Msg("game draw")
+		state.Series.Record("")
+		g.reportResult(room, state)
 	} else {
 		// Switch turns
 		for id := range state.Players {
@@ -260,17 +298,36 @@ func (g *TicTacToe) handleMakeMove(client interfaces.Client, room interfaces.Roo
 	broadcastGameState(room)
 }
 
-// handleRestartGame resets the game
-func (g *TicTacToe) handleRestartGame(client interfaces.Client, room interfaces.Room) {
-	log.Debug().Msg("restarting")
-
+// handleRematch toggles the rematch vote of a player once the game is over. The board is reset
+// once both players voted, the player who didn't start the last game starts.
+func (g *TicTacToe) handleRematch(client interfaces.Client, room interfaces.Room) {
 	state := room.State().(GameState)
 
-	// Only allow restart if game is over
+	// Only allow a rematch if game is over
 	if !state.GameOver {
 		client.Send(protocol.NewErrorResponse("error", "Cannot restart a game in progress"))
 		return
 	}
+	if _, exists := state.Players[client.ID()]; !exists {
+		client.Send(protocol.NewErrorResponse("error", "Only players can vote for a rematch"))
+		return
+	}
+	if len(state.Players) < 2 {
+		client.Send(protocol.NewErrorResponse("error", "Waiting for another player"))
+		return
+	}
+
+	wants := state.Series.Vote(client.ID())
+	log.Debug().Str("clientID", client.ID()).Bool("rematch", wants).Msg("player votes for a rematch")
+
+	playerIDs := slices.Sorted(maps.Keys(state.Players))
+	if !state.Series.Agreed(playerIDs) {
+		room.SetState(state)
+		broadcastGameState(room)
+		return
+	}
+
+	log.Debug().Msg("restarting")
 
 	// Reset the board
 	state.Board = [3][3]string{{"", "", ""}, {"", "", ""}, {"", "", ""}}
@@ -278,18 +335,52 @@ func (g *TicTacToe) handleRestartGame(client interfaces.Client, room interfaces.
 	state.GameOver = false
 	state.DrawGame = false
 
-	// Randomly select first player
-	playerIDs := make([]string, 0, len(state.Players))
-	for id := range state.Players {
-		playerIDs = append(playerIDs, id)
-	}
-	state.CurrentTurn = playerIDs[rand.Intn(len(playerIDs))]
+	// Take turns starting
+	state.CurrentTurn = state.Series.NextStarter(playerIDs)
+	state.Series.Start(state.CurrentTurn)
+	state.startedAt = room.Clock().Now()
 
 	// Update state
 	room.SetState(state)
 
 	// Broadcast updated state
 	broadcastGameState(room)
+
+	g.reportStarted(room)
+}
+
+func (g *TicTacToe) reportStarted(room interfaces.Room) {
+	if g.registry != nil {
+		g.registry.ReportStarted(room)
+	}
+}
+
+// reportResult reports the finished game, the winner is placed first and a draw places both first
+func (g *TicTacToe) reportResult(room interfaces.Room, state GameState) {
+	if g.registry == nil {
+		return
+	}
+
+	participants := make([]interfaces.Participant, 0, len(state.Players))
+	for _, id := range slices.Sorted(maps.Keys(state.Players)) {
+		player := state.Players[id]
+		placement := 2
+		if state.DrawGame || state.Winner == id {
+			placement = 1
+		}
+		participants = append(participants, interfaces.Participant{
+			ClientID:  id,
+			AccountID: player.AccountID,
+			Name:      player.Name,
+			Placement: placement,
+		})
+	}
+
+	g.registry.ReportResult(room, interfaces.GameResult{
+		StartedAt:    state.startedAt,
+		Participants: participants,
+		Metadata:     interfaces.M{"board": state.Board},
+	})
 }
 
 // checkWin returns true if there's a winning condition on the board
@@ -0,0 +1,96 @@
+package tictactoe
+
+import (
+	"testing"
+
+	"gameserver/internal/interfaces"
+	"gameserver/internal/testicles"
+)
+
+// playWin lets the player whose turn it is win along the top row and returns the winner
+func playWin(t *testing.T, helper *testicles.TestHelper, room interfaces.Room) string {
+	t.Helper()
+	moves := [][2]int{{0, 0}, {1, 0}, {0, 1}, {1, 1}, {0, 2}}
+	starter := room.State().(GameState).CurrentTurn
+	for _, move := range moves {
+		helper.SendMessage(room.State().(GameState).CurrentTurn, "make_move", MovePayload{Row: move[0], Col: move[1]})
+	}
+	if state := room.State().(GameState); !state.GameOver || state.Winner != starter {
+		t.Fatalf("Expected %s to win, got winner %q", starter, state.Winner)
+	}
+	return starter
+}
+
+func TestTicTacToe_Rematch(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterTicTacToeGame(helper.Registry)
+	playerIds := helper.SetupGameRoom("tictactoe", 2)
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+
+	helper.ClearAllMessages()
+	helper.SendMessage(playerIds[0], "rematch", nil)
+	helper.AssertMessageReceived(playerIds[0], "error")
+
+	winner := playWin(t, helper, testRoom)
+	helper.SendMessage(playerIds[0], "rematch", nil)
+	state := testRoom.State().(GameState)
+	if !state.GameOver || len(state.Series.Votes) != 1 {
+		t.Fatalf("Expected the rematch to wait for the opponent, got votes %v", state.Series.Votes)
+	}
+
+	helper.SendMessage(playerIds[1], "rematch", nil)
+	state = testRoom.State().(GameState)
+	if state.GameOver || state.Winner != "" || state.Board != [3][3]string{} {
+		t.Fatalf("Expected a fresh board for the rematch, got %v", state.Board)
+	}
+	if state.CurrentTurn == winner || state.Series.Starter != state.CurrentTurn {
+		t.Errorf("Expected the other player to start the rematch, got %s", state.CurrentTurn)
+	}
+	if state.Series.Games != 1 || state.Series.Wins[winner] != 1 {
+		t.Errorf("Expected the series to count the first game, got %+v", state.Series)
+	}
+
+	// the starter of the rematch wins it, restart_game votes too
+	second := playWin(t, helper, testRoom)
+	helper.SendMessage(playerIds[0], "restart_game", nil)
+	helper.SendMessage(playerIds[1], "restart_game", nil)
+	state = testRoom.State().(GameState)
+	if state.GameOver || state.CurrentTurn != winner {
+		t.Errorf("Expected %s to start the third game, got %s", winner, state.CurrentTurn)
+	}
+	if state.Series.Wins[winner] != 1 || state.Series.Wins[second] != 1 {
+		t.Errorf("Expected the series to be tied, got %v", state.Series.Wins)
+	}
+}
+
+func TestTicTacToe_BestOf(t *testing.T) {
+	helper := testicles.NewTestHelper(t)
+	RegisterTicTacToeGame(helper.Registry)
+	player1 := helper.CreateClient("player-0")
+	player2 := helper.CreateClient("player-1")
+	helper.CreateRoomWithOptions(player1, "tictactoe", "player-0", RoomOptions{BestOf: 3})
+	helper.JoinRoom(player2, helper.RoomID, "player-1")
+	playerIds := []string{player1.ID(), player2.ID()}
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+
+	first := playWin(t, helper, testRoom)
+	helper.SendMessage(playerIds[0], "rematch", nil)
+	helper.SendMessage(playerIds[1], "rematch", nil)
+	playWin(t, helper, testRoom)
+	helper.SendMessage(playerIds[0], "rematch", nil)
+	helper.SendMessage(playerIds[1], "rematch", nil)
+	third := playWin(t, helper, testRoom)
+
+	state := testRoom.State().(GameState)
+	if third != first || state.Series.Winner != first {
+		t.Errorf("Expected %s to win the best of 3, got %q", first, state.Series.Winner)
+	}
+}
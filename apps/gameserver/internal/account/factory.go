@@ -0,0 +1,51 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"gameserver/internal/database/sql"
+	"gameserver/internal/interfaces"
+	"os"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Factory creates the account service for an environment
+type Factory struct {
+	env interfaces.Environment
+}
+
+// NewFactory creates a new account service factory
+func NewFactory(env interfaces.Environment) *Factory {
+	return &Factory{
+		env: env,
+	}
+}
+
+// CreateService connects to ACCOUNTS_DATABASE_URL (SQLite in development) and prepares the schema
+func (f *Factory) CreateService(ctx context.Context) (*Service, error) {
+	dbURL := os.Getenv("ACCOUNTS_DATABASE_URL")
+	if dbURL == "" {
+		// Default to SQLite in development
+		if f.env == interfaces.Development {
+			dbURL = "file:./db.sqlite?cache=shared&mode=rwc"
+		} else {
+			return nil, ErrNotConfigured
+		}
+	}
+
+	db, err := sql.New(ctx, dbURL, sql.WithAllowedTables([]string{accountsTable, statsTable}))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := InitializeSchema(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	log.Info().Str("driver", db.Driver()).Msg("SQL database client initialized for accounts")
+	return NewService(NewSQLStore(db)), nil
+}
+
+var ErrNotConfigured = errors.New("ACCOUNTS_DATABASE_URL environment variable not set")
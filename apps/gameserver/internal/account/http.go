@@ -0,0 +1,124 @@
+package account
+
+import (
+	"encoding/json"
+	"errors"
+	"gameserver/internal/auth"
+	"net/http"
+
+	"github.com/rs/zerolog/log"
+)
+
+// NewHTTPHandler serves the profile endpoints:
+//
+//	GET   /accounts/me   own profile, requires a bearer ID token
+//	PATCH /accounts/me   update display name, avatar or preferences
+//	GET   /accounts/{id} public profile of any account
+func NewHTTPHandler(service *Service, authenticator auth.Authenticator) http.Handler {
+	h := &httpHandler{service: service, authenticator: authenticator}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /accounts/me", h.getMe)
+	mux.HandleFunc("PATCH /accounts/me", h.updateMe)
+	mux.HandleFunc("GET /accounts/{id}", h.getProfile)
+	return mux
+}
+
+type httpHandler struct {
+	service       *Service
+	authenticator auth.Authenticator
+}
+
+// currentAccount resolves the account of the request's ID token
+func (h *httpHandler) currentAccount(r *http.Request) (*Account, error) {
+	token := auth.TokenFromRequest(r)
+	if token == "" || h.authenticator == nil {
+		return nil, auth.ErrUnauthenticated
+	}
+
+	identity, err := h.authenticator.Authenticate(r.Context(), token)
+	if err != nil {
+		return nil, auth.ErrUnauthenticated
+	}
+
+	return h.service.ForIdentity(r.Context(), identity, "")
+}
+
+func (h *httpHandler) getMe(w http.ResponseWriter, r *http.Request) {
+	account, err := h.currentAccount(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	profile, err := h.service.GetProfile(r.Context(), account.ID)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, profile)
+}
+
+func (h *httpHandler) updateMe(w http.ResponseWriter, r *http.Request) {
+	account, err := h.currentAccount(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	var update ProfileUpdate
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 2*MaxPreferencesSize)).Decode(&update); err != nil {
+		http.Error(w, `{"error": "invalid profile update"}`, http.StatusBadRequest)
+		return
+	}
+
+	updated, err := h.service.UpdateProfile(r.Context(), account.ID, update)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, updated)
+}
+
+func (h *httpHandler) getProfile(w http.ResponseWriter, r *http.Request) {
+	profile, err := h.service.GetProfile(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	// preferences are private
+	profile.Account.Preferences = nil
+	writeJSON(w, profile)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, auth.ErrUnauthenticated):
+		status = http.StatusUnauthorized
+	case errors.Is(err, ErrAccountNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrInvalidDisplayName), errors.Is(err, ErrInvalidAvatarURL), errors.Is(err, ErrInvalidPreferences):
+		status = http.StatusBadRequest
+	default:
+		log.Error().Err(err).Msg("account request failed")
+		err = errors.New("internal error")
+	}
+
+	jsonData, _ := json.Marshal(map[string]string{"error": err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonData)
+}
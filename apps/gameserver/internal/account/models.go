@@ -0,0 +1,77 @@
+package account
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Account is a player known across games and sessions, created from a verified identity
+type Account struct {
+	ID          string      `json:"id" db:"id"`
+	Subject     string      `json:"-" db:"subject"` // issuer|sub of the identity the account belongs to
+	DisplayName string      `json:"displayName" db:"display_name"`
+	AvatarURL   string      `json:"avatarUrl" db:"avatar_url"`
+	Preferences Preferences `json:"preferences" db:"preferences"`
+	CreatedAt   time.Time   `json:"createdAt" db:"created_at"`
+	UpdatedAt   time.Time   `json:"updatedAt" db:"updated_at"`
+	LastSeenAt  time.Time   `json:"lastSeenAt" db:"last_seen_at"`
+}
+
+// GameStats are the persistent stats of an account in one game type
+type GameStats struct {
+	ID        string    `json:"-" db:"id"` // <accountId>:<gameType>
+	AccountID string    `json:"accountId" db:"account_id"`
+	GameType  string    `json:"gameType" db:"game_type"`
+	Played    int       `json:"played" db:"played"`
+	Wins      int       `json:"wins" db:"wins"`
+	Losses    int       `json:"losses" db:"losses"`
+	Draws     int       `json:"draws" db:"draws"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// Profile is the public view of an account together with its stats
+type Profile struct {
+	*Account
+	Stats []GameStats `json:"stats"`
+}
+
+// ProfileUpdate holds the fields a player can change. Nil fields are left untouched.
+type ProfileUpdate struct {
+	DisplayName *string     `json:"displayName,omitempty"`
+	AvatarURL   *string     `json:"avatarUrl,omitempty"`
+	Preferences Preferences `json:"preferences,omitempty"`
+}
+
+// Preferences are free form client settings (sound, theme, ...), stored as JSON
+type Preferences map[string]interface{}
+
+// Value implements driver.Valuer
+func (p Preferences) Value() (driver.Value, error) {
+	if p == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (p *Preferences) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case nil:
+		*p = Preferences{}
+		return nil
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		return errors.New("unsupported preferences type")
+	}
+	return json.Unmarshal(data, p)
+}
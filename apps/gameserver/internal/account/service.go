@@ -0,0 +1,186 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gameserver/internal/interfaces"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	MaxDisplayNameLength = 32
+	MaxAvatarURLLength   = 512
+	MaxPreferencesSize   = 4096
+)
+
+// Service manages player accounts shared by all games
+type Service struct {
+	store Store
+}
+
+// NewService creates an account service on the given store
+func NewService(store Store) *Service {
+	return &Service{store: store}
+}
+
+// subjectKey identifies an identity across issuers
+func subjectKey(identity *interfaces.Identity) string {
+	return identity.Issuer + "|" + identity.Subject
+}
+
+// ForIdentity returns the account of a verified identity, creating it on first sight.
+// fallbackName is used as display name if the identity doesn't carry one. Concurrent first
+// joins of an identity get the same account, the subject is unique.
+func (s *Service) ForIdentity(ctx context.Context, identity *interfaces.Identity, fallbackName string) (*Account, error) {
+	if identity == nil {
+		return nil, ErrAnonymous
+	}
+
+	account, err := s.store.GetAccountBySubject(ctx, subjectKey(identity))
+	if err == nil {
+		account.LastSeenAt = time.Now()
+		if err := s.store.UpdateAccount(ctx, account); err != nil {
+			log.Warn().Err(err).Str("accountId", account.ID).Msg("failed to update last seen")
+		}
+		return account, nil
+	}
+	if !errors.Is(err, ErrAccountNotFound) {
+		return nil, err
+	}
+
+	displayName := identity.Name
+	if displayName == "" {
+		displayName = fallbackName
+	}
+	displayName, err = normalizeDisplayName(displayName)
+	if err != nil {
+		displayName = "Player"
+	}
+
+	now := time.Now()
+	account = &Account{
+		ID:          uuid.New().String(),
+		Subject:     subjectKey(identity),
+		DisplayName: displayName,
+		AvatarURL:   identity.Picture,
+		Preferences: Preferences{},
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		LastSeenAt:  now,
+	}
+	if err := s.store.CreateAccount(ctx, account); err != nil {
+		if errors.Is(err, ErrAccountExists) {
+			// another join created the account since we looked it up
+			return s.store.GetAccountBySubject(ctx, account.Subject)
+		}
+		return nil, fmt.Errorf("failed to create account: %w", err)
+	}
+
+	log.Info().Str("accountId", account.ID).Str("subject", account.Subject).Msg("account created")
+	return account, nil
+}
+
+// ForClient returns the account of an authenticated client, ErrAnonymous for anonymous clients
+func (s *Service) ForClient(ctx context.Context, client interfaces.Client) (*Account, error) {
+	return s.ForIdentity(ctx, client.Identity(), "")
+}
+
+// GetProfile returns the account with its stats in all games
+func (s *Service) GetProfile(ctx context.Context, accountID string) (*Profile, error) {
+	account, err := s.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.store.GetStats(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Profile{Account: account, Stats: stats}, nil
+}
+
+// UpdateProfile applies the changes a player made to their profile
+func (s *Service) UpdateProfile(ctx context.Context, accountID string, update ProfileUpdate) (*Account, error) {
+	account, err := s.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.DisplayName != nil {
+		displayName, err := normalizeDisplayName(*update.DisplayName)
+		if err != nil {
+			return nil, err
+		}
+		account.DisplayName = displayName
+	}
+	if update.AvatarURL != nil {
+		avatarURL := strings.TrimSpace(*update.AvatarURL)
+		if len(avatarURL) > MaxAvatarURLLength || (avatarURL != "" && !strings.HasPrefix(avatarURL, "https://")) {
+			return nil, ErrInvalidAvatarURL
+		}
+		account.AvatarURL = avatarURL
+	}
+	if update.Preferences != nil {
+		encoded, err := update.Preferences.Value()
+		if err != nil || len(encoded.(string)) > MaxPreferencesSize {
+			return nil, ErrInvalidPreferences
+		}
+		account.Preferences = update.Preferences
+	}
+
+	account.UpdatedAt = time.Now()
+	if err := s.store.UpdateAccount(ctx, account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// RecordResult adds a finished game to the account's stats for the game type
+func (s *Service) RecordResult(ctx context.Context, accountID string, gameType string, outcome interfaces.Outcome) error {
+	return s.store.AddResult(ctx, accountID, gameType, outcome)
+}
+
+// HandleResult adds a finished game to the stats of every participant with an account.
+// It makes the service a subscriber of the results bus.
+func (s *Service) HandleResult(ctx context.Context, result interfaces.GameResult) error {
+	var errs []error
+	for _, p := range result.Participants {
+		if p.AccountID == "" || p.Bot {
+			continue
+		}
+		if err := s.RecordResult(ctx, p.AccountID, result.GameType, p.Outcome); err != nil {
+			errs = append(errs, fmt.Errorf("account %s: %w", p.AccountID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close closes the underlying store
+func (s *Service) Close() error {
+	return s.store.Close()
+}
+
+func normalizeDisplayName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxDisplayNameLength {
+		return "", ErrInvalidDisplayName
+	}
+	return name, nil
+}
+
+var (
+	ErrAnonymous          = errors.New("client is not authenticated")
+	ErrAccountNotFound    = errors.New("account not found")
+	ErrAccountExists      = errors.New("an account with the subject already exists")
+	ErrInvalidOutcome     = errors.New("invalid game outcome")
+	ErrInvalidDisplayName = errors.New("display name must be 1-32 characters")
+	ErrInvalidAvatarURL   = errors.New("avatar url must be an https url")
+	ErrInvalidPreferences = errors.New("preferences are invalid or too large")
+)
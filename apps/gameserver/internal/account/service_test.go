@@ -0,0 +1,281 @@
+package account
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gameserver/internal/auth"
+	"gameserver/internal/database/sql"
+	"gameserver/internal/interfaces"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func setupTestService(t *testing.T) *Service {
+	t.Helper()
+	ctx := context.Background()
+
+	db, err := sql.New(ctx, filepath.Join(t.TempDir(), "accounts.sqlite"), sql.WithAllowedTables([]string{accountsTable, statsTable}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if err := InitializeSchema(ctx, db); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+
+	service := NewService(NewSQLStore(db))
+	t.Cleanup(func() { service.Close() })
+	return service
+}
+
+func TestForIdentityCreatesAccountOnce(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+	identity := &interfaces.Identity{Subject: "user-1", Issuer: "https://issuer.test", Name: "Alice"}
+
+	first, err := service.ForIdentity(ctx, identity, "ignored")
+	if err != nil {
+		t.Fatalf("ForIdentity failed: %v", err)
+	}
+	if first.DisplayName != "Alice" {
+		t.Errorf("Expected display name from identity, got %q", first.DisplayName)
+	}
+
+	second, err := service.ForIdentity(ctx, identity, "")
+	if err != nil {
+		t.Fatalf("ForIdentity failed: %v", err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("Expected the same account, got %s and %s", first.ID, second.ID)
+	}
+
+	other, err := service.ForIdentity(ctx, &interfaces.Identity{Subject: "user-1", Issuer: "https://other.test"}, "Bob")
+	if err != nil {
+		t.Fatalf("ForIdentity failed: %v", err)
+	}
+	if other.ID == first.ID {
+		t.Error("Expected a different account for the same subject of another issuer")
+	}
+	if other.DisplayName != "Bob" {
+		t.Errorf("Expected fallback display name, got %q", other.DisplayName)
+	}
+
+	if _, err := service.ForIdentity(ctx, nil, "Anon"); !errors.Is(err, ErrAnonymous) {
+		t.Errorf("Expected ErrAnonymous, got %v", err)
+	}
+}
+
+// racingStore misses the account on the first lookup, as if another join created it right after
+type racingStore struct {
+	Store
+	missed bool
+}
+
+func (s *racingStore) GetAccountBySubject(ctx context.Context, subject string) (*Account, error) {
+	if !s.missed {
+		s.missed = true
+		return nil, ErrAccountNotFound
+	}
+	return s.Store.GetAccountBySubject(ctx, subject)
+}
+
+func TestForIdentityConcurrentFirstJoin(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+	identity := &interfaces.Identity{Subject: "user-1", Issuer: "https://issuer.test", Name: "Alice"}
+
+	first, err := service.ForIdentity(ctx, identity, "")
+	if err != nil {
+		t.Fatalf("ForIdentity failed: %v", err)
+	}
+
+	racing := NewService(&racingStore{Store: service.store})
+	second, err := racing.ForIdentity(ctx, identity, "")
+	if err != nil {
+		t.Fatalf("Expected the join losing the race to get the account, got %v", err)
+	}
+	if second.ID != first.ID {
+		t.Errorf("Expected the same account, got %s and %s", first.ID, second.ID)
+	}
+}
+
+func TestUpdateProfile(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	account, err := service.ForIdentity(ctx, &interfaces.Identity{Subject: "user-1", Issuer: "mock"}, "Alice")
+	if err != nil {
+		t.Fatalf("ForIdentity failed: %v", err)
+	}
+
+	name := "  Alicia "
+	avatar := "https://cdn.test/alicia.png"
+	updated, err := service.UpdateProfile(ctx, account.ID, ProfileUpdate{
+		DisplayName: &name,
+		AvatarURL:   &avatar,
+		Preferences: Preferences{"sound": false},
+	})
+	if err != nil {
+		t.Fatalf("UpdateProfile failed: %v", err)
+	}
+	if updated.DisplayName != "Alicia" {
+		t.Errorf("Expected trimmed display name, got %q", updated.DisplayName)
+	}
+
+	profile, err := service.GetProfile(ctx, account.ID)
+	if err != nil {
+		t.Fatalf("GetProfile failed: %v", err)
+	}
+	if profile.AvatarURL != avatar || profile.Preferences["sound"] != false {
+		t.Errorf("Expected stored profile changes, got %+v", profile.Account)
+	}
+
+	empty := " "
+	if _, err := service.UpdateProfile(ctx, account.ID, ProfileUpdate{DisplayName: &empty}); !errors.Is(err, ErrInvalidDisplayName) {
+		t.Errorf("Expected ErrInvalidDisplayName, got %v", err)
+	}
+	insecure := "http://cdn.test/alicia.png"
+	if _, err := service.UpdateProfile(ctx, account.ID, ProfileUpdate{AvatarURL: &insecure}); !errors.Is(err, ErrInvalidAvatarURL) {
+		t.Errorf("Expected ErrInvalidAvatarURL, got %v", err)
+	}
+	if _, err := service.UpdateProfile(ctx, "missing", ProfileUpdate{}); !errors.Is(err, ErrAccountNotFound) {
+		t.Errorf("Expected ErrAccountNotFound, got %v", err)
+	}
+}
+
+func TestRecordResult(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	account, err := service.ForIdentity(ctx, &interfaces.Identity{Subject: "user-1", Issuer: "mock"}, "Alice")
+	if err != nil {
+		t.Fatalf("ForIdentity failed: %v", err)
+	}
+
+	results := []struct {
+		gameType string
+		outcome  interfaces.Outcome
+	}{
+		{"dicegame", interfaces.OutcomeWin},
+		{"dicegame", interfaces.OutcomeLoss},
+		{"dicegame", interfaces.OutcomeWin},
+		{"tictactoe", interfaces.OutcomeDraw},
+		{"tellit", interfaces.OutcomeCompleted},
+	}
+	for _, result := range results {
+		if err := service.RecordResult(ctx, account.ID, result.gameType, result.outcome); err != nil {
+			t.Fatalf("RecordResult failed: %v", err)
+		}
+	}
+
+	if err := service.RecordResult(ctx, account.ID, "dicegame", "forfeit"); !errors.Is(err, ErrInvalidOutcome) {
+		t.Errorf("Expected ErrInvalidOutcome, got %v", err)
+	}
+
+	profile, err := service.GetProfile(ctx, account.ID)
+	if err != nil {
+		t.Fatalf("GetProfile failed: %v", err)
+	}
+	if len(profile.Stats) != 3 {
+		t.Fatalf("Expected stats for 3 game types, got %d", len(profile.Stats))
+	}
+
+	dice := profile.Stats[0]
+	if dice.GameType != "dicegame" || dice.Played != 3 || dice.Wins != 2 || dice.Losses != 1 {
+		t.Errorf("Unexpected dicegame stats: %+v", dice)
+	}
+	tellit := profile.Stats[1]
+	if tellit.GameType != "tellit" || tellit.Played != 1 || tellit.Wins+tellit.Losses+tellit.Draws != 0 {
+		t.Errorf("Unexpected tellit stats: %+v", tellit)
+	}
+	tictactoe := profile.Stats[2]
+	if tictactoe.GameType != "tictactoe" || tictactoe.Played != 1 || tictactoe.Draws != 1 {
+		t.Errorf("Unexpected tictactoe stats: %+v", tictactoe)
+	}
+}
+
+func TestHTTPHandler(t *testing.T) {
+	service := setupTestService(t)
+	authenticator := auth.NewAuthenticatorMock()
+	authenticator.AddToken("alice-token", "alice")
+	handler := NewHTTPHandler(service, authenticator)
+
+	request := func(method, path, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := request(http.MethodGet, "/accounts/me", "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without token, got %d", rec.Code)
+	}
+
+	rec := request(http.MethodPatch, "/accounts/me", "alice-token", `{"displayName": "Alice", "preferences": {"theme": "dark"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var account Account
+	if err := json.Unmarshal(rec.Body.Bytes(), &account); err != nil {
+		t.Fatalf("Failed to decode account: %v", err)
+	}
+
+	if rec := request(http.MethodPatch, "/accounts/me", "alice-token", `{"avatarUrl": "javascript:alert(1)"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for invalid avatar, got %d", rec.Code)
+	}
+
+	rec = request(http.MethodGet, "/accounts/"+account.ID, "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	var profile map[string]interface{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &profile); err != nil {
+		t.Fatalf("Failed to decode profile: %v", err)
+	}
+	if profile["displayName"] != "Alice" {
+		t.Errorf("Expected public display name, got %v", profile["displayName"])
+	}
+	if prefs, _ := profile["preferences"].(map[string]interface{}); len(prefs) != 0 {
+		t.Errorf("Expected preferences to be private, got %v", prefs)
+	}
+
+	if rec := request(http.MethodGet, "/accounts/missing", "", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", rec.Code)
+	}
+}
+
+func TestHandleResult(t *testing.T) {
+	service := setupTestService(t)
+	ctx := context.Background()
+
+	account, err := service.ForIdentity(ctx, &interfaces.Identity{Subject: "user-1", Issuer: "mock"}, "Alice")
+	if err != nil {
+		t.Fatalf("ForIdentity failed: %v", err)
+	}
+
+	err = service.HandleResult(ctx, interfaces.GameResult{
+		GameType: "owedrahn",
+		Participants: []interfaces.Participant{
+			{ClientID: "c1", AccountID: account.ID, Outcome: interfaces.OutcomeWin},
+			{ClientID: "c2", Outcome: interfaces.OutcomeLoss},
+			{ClientID: "bot", AccountID: "bot-account", Bot: true, Outcome: interfaces.OutcomeLoss},
+		},
+	})
+	if err != nil {
+		t.Fatalf("HandleResult failed: %v", err)
+	}
+
+	profile, err := service.GetProfile(ctx, account.ID)
+	if err != nil {
+		t.Fatalf("GetProfile failed: %v", err)
+	}
+	if len(profile.Stats) != 1 || profile.Stats[0].GameType != "owedrahn" || profile.Stats[0].Wins != 1 {
+		t.Errorf("Expected one owedrahn win, got %+v", profile.Stats)
+	}
+}
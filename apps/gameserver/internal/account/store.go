@@ -0,0 +1,170 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gameserver/internal/database/sql"
+	"gameserver/internal/interfaces"
+	"time"
+)
+
+const (
+	accountsTable = "accounts"
+	statsTable    = "account_game_stats"
+)
+
+// Store persists accounts and their per-game stats
+type Store interface {
+	GetAccount(ctx context.Context, id string) (*Account, error)
+	GetAccountBySubject(ctx context.Context, subject string) (*Account, error)
+	CreateAccount(ctx context.Context, account *Account) error
+	UpdateAccount(ctx context.Context, account *Account) error
+	GetStats(ctx context.Context, accountID string) ([]GameStats, error)
+	// AddResult increments the stats of the account for the game type
+	AddResult(ctx context.Context, accountID string, gameType string, outcome interfaces.Outcome) error
+	Close() error
+}
+
+// SQLStore is a Store backed by internal/database/sql
+type SQLStore struct {
+	db sql.Database
+}
+
+// NewSQLStore creates a store on a database with the account schema (see InitializeSchema)
+func NewSQLStore(db sql.Database) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+func (s *SQLStore) GetAccount(ctx context.Context, id string) (*Account, error) {
+	var account Account
+	if err := s.db.Get(ctx, accountsTable, id, &account); err != nil {
+		if errors.Is(err, sql.ErrRecordNotFound) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (s *SQLStore) GetAccountBySubject(ctx context.Context, subject string) (*Account, error) {
+	var accounts []Account
+	if err := s.db.Query(ctx, "SELECT * FROM accounts WHERE subject = ?", &accounts, subject); err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		return nil, ErrAccountNotFound
+	}
+	return &accounts[0], nil
+}
+
+func (s *SQLStore) CreateAccount(ctx context.Context, account *Account) error {
+	if err := s.db.Create(ctx, accountsTable, account); err != nil {
+		if errors.Is(err, sql.ErrDuplicateKey) {
+			return ErrAccountExists
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *SQLStore) UpdateAccount(ctx context.Context, account *Account) error {
+	if err := s.db.Update(ctx, accountsTable, account.ID, account); err != nil {
+		if errors.Is(err, sql.ErrRecordNotFound) {
+			return ErrAccountNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *SQLStore) GetStats(ctx context.Context, accountID string) ([]GameStats, error) {
+	stats := make([]GameStats, 0)
+	query := "SELECT * FROM account_game_stats WHERE account_id = ? ORDER BY game_type"
+	if err := s.db.Query(ctx, query, &stats, accountID); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (s *SQLStore) AddResult(ctx context.Context, accountID string, gameType string, outcome interfaces.Outcome) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	id := accountID + ":" + gameType
+	stats := GameStats{ID: id, AccountID: accountID, GameType: gameType}
+	exists := true
+	if err := tx.Get(ctx, statsTable, id, &stats); err != nil {
+		if !errors.Is(err, sql.ErrRecordNotFound) {
+			return err
+		}
+		exists = false
+	}
+
+	stats.Played++
+	switch outcome {
+	case interfaces.OutcomeWin:
+		stats.Wins++
+	case interfaces.OutcomeLoss:
+		stats.Losses++
+	case interfaces.OutcomeDraw:
+		stats.Draws++
+	case interfaces.OutcomeCompleted:
+	default:
+		return fmt.Errorf("%w: %s", ErrInvalidOutcome, outcome)
+	}
+	stats.UpdatedAt = time.Now()
+
+	if exists {
+		err = tx.Update(ctx, statsTable, id, &stats)
+	} else {
+		err = tx.Create(ctx, statsTable, &stats)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store stats: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// Close closes the database connection
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+// InitializeSchema creates the account tables if they don't exist
+func InitializeSchema(ctx context.Context, db sql.Database) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS accounts (
+			id TEXT PRIMARY KEY,
+			subject TEXT NOT NULL UNIQUE,
+			display_name TEXT NOT NULL,
+			avatar_url TEXT NOT NULL DEFAULT '',
+			preferences TEXT NOT NULL DEFAULT '{}',
+			created_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP NOT NULL,
+			last_seen_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS account_game_stats (
+			id TEXT PRIMARY KEY,
+			account_id TEXT NOT NULL REFERENCES accounts(id),
+			game_type TEXT NOT NULL,
+			played INTEGER NOT NULL DEFAULT 0,
+			wins INTEGER NOT NULL DEFAULT 0,
+			losses INTEGER NOT NULL DEFAULT 0,
+			draws INTEGER NOT NULL DEFAULT 0,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_account_game_stats_account ON account_game_stats (account_id)`,
+	}
+
+	for _, statement := range statements {
+		if err := db.Exec(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"gameserver/internal/interfaces"
+	"net/http"
+	"strings"
+)
+
+// Authenticator verifies a client supplied token and returns the identity behind it
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (*interfaces.Identity, error)
+}
+
+// TokenFromRequest extracts an ID token from the Authorization header or, since browsers
+// can't set headers on websocket upgrades, from the token query parameter
+func TokenFromRequest(r *http.Request) string {
+	if header := r.Header.Get("Authorization"); header != "" {
+		if token, found := strings.CutPrefix(header, "Bearer "); found {
+			return strings.TrimSpace(token)
+		}
+	}
+	return r.URL.Query().Get("token")
+}
+
+var ErrUnauthenticated = errors.New("unauthenticated")
@@ -0,0 +1,30 @@
+package auth
+
+import (
+	"context"
+	"gameserver/internal/interfaces"
+)
+
+// AuthenticatorMock accepts a fixed set of tokens, for tests
+type AuthenticatorMock struct {
+	Identities map[string]*interfaces.Identity
+}
+
+func NewAuthenticatorMock() *AuthenticatorMock {
+	return &AuthenticatorMock{
+		Identities: make(map[string]*interfaces.Identity),
+	}
+}
+
+// AddToken makes the token authenticate as the given subject
+func (m *AuthenticatorMock) AddToken(token string, subject string) {
+	m.Identities[token] = &interfaces.Identity{Subject: subject, Issuer: "mock"}
+}
+
+func (m *AuthenticatorMock) Authenticate(ctx context.Context, token string) (*interfaces.Identity, error) {
+	identity, exists := m.Identities[token]
+	if !exists {
+		return nil, ErrInvalidToken
+	}
+	return identity, nil
+}
@@ -0,0 +1,54 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// BotKeys are the API keys external bots connect with, mapped to the bot's name
+type BotKeys map[string]string
+
+// ParseBotKeys parses a comma separated list of name:key pairs
+func ParseBotKeys(value string) (BotKeys, error) {
+	keys := make(BotKeys)
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		name, key, found := strings.Cut(pair, ":")
+		name, key = strings.TrimSpace(name), strings.TrimSpace(key)
+		if !found || name == "" || key == "" {
+			return nil, ErrInvalidBotKey
+		}
+		keys[key] = name
+	}
+	return keys, nil
+}
+
+// Lookup returns the name of the bot an API key belongs to
+func (k BotKeys) Lookup(key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+	// compare every key in constant time, so timing doesn't reveal how much of a key matched
+	var name string
+	for candidate, candidateName := range k {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(key)) == 1 {
+			name = candidateName
+		}
+	}
+	return name, name != ""
+}
+
+// BotKeyFromRequest extracts a bot API key from the "Authorization: Bot <key>" header
+func BotKeyFromRequest(r *http.Request) string {
+	if key, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bot "); found {
+		return strings.TrimSpace(key)
+	}
+	return ""
+}
+
+var ErrInvalidBotKey = errors.New("bot keys must be name:key pairs")
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParseBotKeys(t *testing.T) {
+	keys, err := ParseBotKeys("alpha:key-1, beta : key-2,")
+	if err != nil {
+		t.Fatalf("failed to parse bot keys: %v", err)
+	}
+
+	if name, ok := keys.Lookup("key-2"); !ok || name != "beta" {
+		t.Errorf("expected key-2 to belong to beta, got %q", name)
+	}
+	if _, ok := keys.Lookup("key-3"); ok {
+		t.Error("expected an unknown key to be rejected")
+	}
+	if _, ok := keys.Lookup(""); ok {
+		t.Error("expected an empty key to be rejected")
+	}
+
+	for _, invalid := range []string{"alpha", "alpha:", ":key"} {
+		if _, err := ParseBotKeys(invalid); err == nil {
+			t.Errorf("expected %q to be rejected", invalid)
+		}
+	}
+}
+
+func TestBotKeyFromRequest(t *testing.T) {
+	r := httptest.NewRequest("GET", "/bots/ws", nil)
+	r.Header.Set("Authorization", "Bearer id-token")
+	if key := BotKeyFromRequest(r); key != "" {
+		t.Errorf("expected ID tokens not to count as bot keys, got %q", key)
+	}
+
+	r.Header.Set("Authorization", "Bot key-1")
+	if key := BotKeyFromRequest(r); key != "key-1" {
+		t.Errorf("expected the bot key, got %q", key)
+	}
+}
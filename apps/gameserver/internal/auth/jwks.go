@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// JWK is a single JSON Web Key as published in a JWKS document
+type JWK struct {
+	KeyID string `json:"kid"`
+	Type  string `json:"kty"`
+	Alg   string `json:"alg,omitempty"`
+	Use   string `json:"use,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+	Y     string `json:"y,omitempty"`
+}
+
+// KeySet holds the public keys ID tokens are verified against, by key id
+type KeySet struct {
+	keys map[string]crypto.PublicKey
+}
+
+// ParseJWKS parses a JWKS document ({"keys": [...]}). Keys not used for signatures are skipped.
+func ParseJWKS(data []byte) (*KeySet, error) {
+	var doc struct {
+		Keys []JWK `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid jwks: %w", err)
+	}
+
+	set := &KeySet{keys: make(map[string]crypto.PublicKey)}
+	for _, jwk := range doc.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		key, err := jwk.PublicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid key %q: %w", jwk.KeyID, err)
+		}
+		set.keys[jwk.KeyID] = key
+	}
+
+	if len(set.keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	return set, nil
+}
+
+// LoadJWKS reads a JWKS document from disk
+func LoadJWKS(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseJWKS(data)
+}
+
+// Key returns the key for the given key id. Tokens without a key id can be verified if the set holds exactly one key.
+func (s *KeySet) Key(keyID string) (crypto.PublicKey, error) {
+	if key, exists := s.keys[keyID]; exists {
+		return key, nil
+	}
+	if keyID == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, nil
+		}
+	}
+	return nil, ErrUnknownKey
+}
+
+// PublicKey decodes the key material of the JWK
+func (k JWK) PublicKey() (crypto.PublicKey, error) {
+	switch k.Type {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, errors.New("rsa exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Curve != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Curve)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if _, err := key.ECDH(); err != nil {
+			return nil, err
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q", k.Type)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+var (
+	ErrNoKeys     = errors.New("jwks contains no signing keys")
+	ErrUnknownKey = errors.New("unknown signing key")
+)
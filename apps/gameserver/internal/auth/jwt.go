@@ -0,0 +1,235 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gameserver/internal/interfaces"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultLeeway is the clock skew tolerated when checking exp and nbf
+const DefaultLeeway = time.Minute
+
+// JWTAuthenticator verifies RS256 and ES256 signed ID tokens against a JWKS
+type JWTAuthenticator struct {
+	keys     *KeySet
+	issuer   string
+	audience string
+	leeway   time.Duration
+	now      func() time.Time
+}
+
+// JWTOption is a functional option for configuring the JWTAuthenticator
+type JWTOption func(*JWTAuthenticator)
+
+// WithIssuer requires tokens to be issued by the given issuer
+func WithIssuer(issuer string) JWTOption {
+	return func(a *JWTAuthenticator) {
+		a.issuer = issuer
+	}
+}
+
+// WithAudience requires tokens to be issued for the given audience
+func WithAudience(audience string) JWTOption {
+	return func(a *JWTAuthenticator) {
+		a.audience = audience
+	}
+}
+
+// WithLeeway sets the tolerated clock skew
+func WithLeeway(leeway time.Duration) JWTOption {
+	return func(a *JWTAuthenticator) {
+		a.leeway = leeway
+	}
+}
+
+// WithNow overrides the time tokens are validated at, for tests
+func WithNow(now func() time.Time) JWTOption {
+	return func(a *JWTAuthenticator) {
+		a.now = now
+	}
+}
+
+// NewJWTAuthenticator creates an authenticator verifying tokens with the given keys
+func NewJWTAuthenticator(keys *KeySet, opts ...JWTOption) *JWTAuthenticator {
+	a := &JWTAuthenticator{
+		keys:   keys,
+		leeway: DefaultLeeway,
+		now:    time.Now,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+type jwtHeader struct {
+	Alg   string `json:"alg"`
+	KeyID string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string    `json:"sub"`
+	Issuer    string    `json:"iss"`
+	Audience  audience  `json:"aud"`
+	ExpiresAt *jsonTime `json:"exp"`
+	NotBefore *jsonTime `json:"nbf"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Picture   string    `json:"picture"`
+}
+
+// audience is either a single string or a list of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+// jsonTime is a NumericDate, seconds since the epoch
+type jsonTime struct {
+	time.Time
+}
+
+func (t *jsonTime) UnmarshalJSON(data []byte) error {
+	var seconds float64
+	if err := json.Unmarshal(data, &seconds); err != nil {
+		return err
+	}
+	t.Time = time.Unix(int64(seconds), 0)
+	return nil
+}
+
+// Authenticate verifies the token signature and claims and returns the identity it carries
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, token string) (*interfaces.Identity, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrInvalidToken
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if header.Alg != "RS256" && header.Alg != "ES256" {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, header.Alg)
+	}
+
+	key, err := a.keys.Key(header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if err := a.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return &interfaces.Identity{
+		Subject: claims.Subject,
+		Issuer:  claims.Issuer,
+		Name:    claims.Name,
+		Email:   claims.Email,
+		Picture: claims.Picture,
+	}, nil
+}
+
+func (a *JWTAuthenticator) validateClaims(claims jwtClaims) error {
+	now := a.now()
+
+	if claims.Subject == "" {
+		return ErrInvalidToken
+	}
+	if claims.ExpiresAt == nil || now.After(claims.ExpiresAt.Add(a.leeway)) {
+		return ErrTokenExpired
+	}
+	if claims.NotBefore != nil && now.Add(a.leeway).Before(claims.NotBefore.Time) {
+		return ErrTokenNotYetValid
+	}
+	if a.issuer != "" && claims.Issuer != a.issuer {
+		return ErrInvalidIssuer
+	}
+	if a.audience != "" && !slices.Contains(claims.Audience, a.audience) {
+		return ErrInvalidAudience
+	}
+	return nil
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signingInput string, signature []byte) error {
+	hash := sha256.Sum256([]byte(signingInput))
+
+	switch alg {
+	case "RS256":
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrInvalidSignature
+		}
+		if err := rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, hash[:], signature); err != nil {
+			return ErrInvalidSignature
+		}
+		return nil
+	case "ES256":
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok || len(signature) != 64 {
+			return ErrInvalidSignature
+		}
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(ecKey, hash[:], r, s) {
+			return ErrInvalidSignature
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, alg)
+	}
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+var (
+	ErrInvalidToken         = errors.New("invalid token")
+	ErrInvalidSignature     = errors.New("invalid token signature")
+	ErrUnsupportedAlgorithm = errors.New("unsupported token algorithm")
+	ErrTokenExpired         = errors.New("token expired")
+	ErrTokenNotYetValid     = errors.New("token not yet valid")
+	ErrInvalidIssuer        = errors.New("invalid token issuer")
+	ErrInvalidAudience      = errors.New("invalid token audience")
+)
@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testNow = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func encodeSegment(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal segment: %v", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	input := encodeSegment(t, map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"}) + "." + encodeSegment(t, claims)
+	hash := sha256.Sum256([]byte(input))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func signES256(t *testing.T, key *ecdsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	input := encodeSegment(t, map[string]string{"alg": "ES256", "kid": kid}) + "." + encodeSegment(t, claims)
+	hash := sha256.Sum256([]byte(input))
+	r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	sig := make([]byte, 64)
+	r.FillBytes(sig[:32])
+	s.FillBytes(sig[32:])
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func b64(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+// newTestAuthenticator creates keys and an authenticator trusting them
+func newTestAuthenticator(t *testing.T) (*JWTAuthenticator, *rsa.PrivateKey, *ecdsa.PrivateKey) {
+	t.Helper()
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ec key: %v", err)
+	}
+
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []JWK{
+			{KeyID: "rsa-1", Type: "RSA", Use: "sig", N: b64(rsaKey.N), E: b64(big.NewInt(int64(rsaKey.E)))},
+			{KeyID: "ec-1", Type: "EC", Curve: "P-256", X: b64(ecKey.X), Y: b64(ecKey.Y)},
+			{KeyID: "enc-1", Type: "RSA", Use: "enc", N: b64(rsaKey.N), E: b64(big.NewInt(int64(rsaKey.E)))},
+		},
+	})
+	keys, err := ParseJWKS(jwks)
+	if err != nil {
+		t.Fatalf("failed to parse jwks: %v", err)
+	}
+
+	a := NewJWTAuthenticator(keys,
+		WithIssuer("https://issuer.test"),
+		WithAudience("gameserver"),
+		WithNow(func() time.Time { return testNow }),
+	)
+	return a, rsaKey, ecKey
+}
+
+func validClaims() map[string]interface{} {
+	return map[string]interface{}{
+		"sub":  "user-1",
+		"iss":  "https://issuer.test",
+		"aud":  []string{"other", "gameserver"},
+		"exp":  testNow.Add(time.Hour).Unix(),
+		"name": "Ada",
+	}
+}
+
+func TestJWTAuthenticator_ValidTokens(t *testing.T) {
+	a, rsaKey, ecKey := newTestAuthenticator(t)
+
+	for name, token := range map[string]string{
+		"RS256": signRS256(t, rsaKey, "rsa-1", validClaims()),
+		"ES256": signES256(t, ecKey, "ec-1", validClaims()),
+	} {
+		identity, err := a.Authenticate(context.Background(), token)
+		if err != nil {
+			t.Fatalf("%s: expected token to verify, got %v", name, err)
+		}
+		if identity.Subject != "user-1" || identity.Name != "Ada" || identity.Issuer != "https://issuer.test" {
+			t.Errorf("%s: unexpected identity %+v", name, identity)
+		}
+	}
+}
+
+func TestJWTAuthenticator_RejectsInvalidTokens(t *testing.T) {
+	a, rsaKey, _ := newTestAuthenticator(t)
+
+	withClaim := func(key string, value interface{}) map[string]interface{} {
+		claims := validClaims()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	valid := signRS256(t, rsaKey, "rsa-1", validClaims())
+	parts := strings.Split(valid, ".")
+	tampered := parts[0] + "." + encodeSegment(t, withClaim("sub", "admin")) + "." + parts[2]
+	unsigned := encodeSegment(t, map[string]string{"alg": "none"}) + "." + parts[1] + "."
+
+	tests := map[string]struct {
+		token string
+		err   error
+	}{
+		"expired":        {signRS256(t, rsaKey, "rsa-1", withClaim("exp", testNow.Add(-time.Hour).Unix())), ErrTokenExpired},
+		"no expiry":      {signRS256(t, rsaKey, "rsa-1", withClaim("exp", nil)), ErrTokenExpired},
+		"not yet valid":  {signRS256(t, rsaKey, "rsa-1", withClaim("nbf", testNow.Add(time.Hour).Unix())), ErrTokenNotYetValid},
+		"wrong issuer":   {signRS256(t, rsaKey, "rsa-1", withClaim("iss", "https://evil.test")), ErrInvalidIssuer},
+		"wrong audience": {signRS256(t, rsaKey, "rsa-1", withClaim("aud", "other")), ErrInvalidAudience},
+		"no subject":     {signRS256(t, rsaKey, "rsa-1", withClaim("sub", nil)), ErrInvalidToken},
+		"unknown key":    {signRS256(t, rsaKey, "rsa-2", validClaims()), ErrUnknownKey},
+		"encryption key": {signRS256(t, rsaKey, "enc-1", validClaims()), ErrUnknownKey},
+		"tampered":       {tampered, ErrInvalidSignature},
+		"alg none":       {unsigned, ErrUnsupportedAlgorithm},
+		"key type mixup": {strings.Replace(valid, parts[0], encodeSegment(t, map[string]string{"alg": "ES256", "kid": "rsa-1"}), 1), ErrInvalidSignature},
+		"garbage":        {"not-a-token", ErrInvalidToken},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := a.Authenticate(context.Background(), tt.token); !errors.Is(err, tt.err) {
+				t.Errorf("expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestParseJWKS_RequiresSigningKeys(t *testing.T) {
+	if _, err := ParseJWKS([]byte(`{"keys": []}`)); !errors.Is(err, ErrNoKeys) {
+		t.Errorf("expected ErrNoKeys, got %v", err)
+	}
+	if _, err := ParseJWKS([]byte(`{"keys": [{"kid": "x", "kty": "oct"}]}`)); err == nil {
+		t.Errorf("expected unsupported key type to fail")
+	}
+}
+
+func TestTokenFromRequest(t *testing.T) {
+	r := httptest.NewRequest("GET", "/ws?token=from-query", nil)
+	if token := TokenFromRequest(r); token != "from-query" {
+		t.Errorf("expected query token, got %q", token)
+	}
+
+	r.Header.Set("Authorization", "Bearer from-header")
+	if token := TokenFromRequest(r); token != "from-header" {
+		t.Errorf("expected header token to win, got %q", token)
+	}
+}
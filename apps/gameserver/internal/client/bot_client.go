@@ -41,6 +41,15 @@ func (b *BotClient) IsBot() bool {
 	return true
 }
 
+// Identity always returns nil, bots are never authenticated
+func (b *BotClient) Identity() *interfaces.Identity {
+	return nil
+}
+
+func (b *BotClient) SetIdentity(identity *interfaces.Identity) {
+	log.Warn().Str("clientId", b.id).Msg("ignoring identity for bot client")
+}
+
 func (b *BotClient) Context() context.Context {
 	return b.cancelCtx
 }
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"gameserver/internal/interfaces"
+	"maps"
+	"slices"
+)
+
+// StrategyRegistry holds the strategies the bots of one game can play. S is the game's
+// strategy interface, every game decides what a strategy has to answer.
+type StrategyRegistry[S any] struct {
+	strategies   map[string]S
+	difficulties map[interfaces.Difficulty]string
+	defaultName  string
+}
+
+// NewStrategyRegistry creates an empty registry, bots without options play defaultName
+func NewStrategyRegistry[S any](defaultName string) *StrategyRegistry[S] {
+	return &StrategyRegistry[S]{
+		strategies:   make(map[string]S),
+		difficulties: make(map[interfaces.Difficulty]string),
+		defaultName:  defaultName,
+	}
+}
+
+// Register adds a strategy, selected by its name or by any of the given difficulties
+func (r *StrategyRegistry[S]) Register(name string, strategy S, difficulties ...interfaces.Difficulty) {
+	r.strategies[name] = strategy
+	for _, difficulty := range difficulties {
+		r.difficulties[difficulty] = name
+	}
+}
+
+// Select returns the strategy the options ask for. A strategy name wins over a difficulty.
+func (r *StrategyRegistry[S]) Select(options interfaces.BotOptions) (string, S, error) {
+	name := r.defaultName
+	if options.Strategy != "" {
+		name = options.Strategy
+	} else if options.Difficulty != "" {
+		var exists bool
+		if name, exists = r.difficulties[options.Difficulty]; !exists {
+			var none S
+			return "", none, fmt.Errorf("%w: %s", ErrUnknownDifficulty, options.Difficulty)
+		}
+	}
+
+	strategy, exists := r.strategies[name]
+	if !exists {
+		return "", strategy, fmt.Errorf("%w: %s", ErrUnknownStrategy, name)
+	}
+	return name, strategy, nil
+}
+
+// Names returns the names of all strategies, sorted
+func (r *StrategyRegistry[S]) Names() []string {
+	return slices.Sorted(maps.Keys(r.strategies))
+}
+
+var (
+	ErrUnknownStrategy   = errors.New("unknown bot strategy")
+	ErrUnknownDifficulty = errors.New("unknown bot difficulty")
+)
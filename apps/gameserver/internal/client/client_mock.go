@@ -14,6 +14,7 @@ type ClientMock struct {
 	room     interfaces.Room
 	mu       sync.Mutex
 	messages []*protocol.Response
+	identity *interfaces.Identity
 }
 
 func (m *ClientMock) ID() string {
@@ -46,6 +47,18 @@ func (m *ClientMock) SetRoom(room interfaces.Room) {
 	m.room = room
 }
 
+func (m *ClientMock) Identity() *interfaces.Identity {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.identity
+}
+
+func (m *ClientMock) SetIdentity(identity *interfaces.Identity) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.identity = identity
+}
+
 func (m *ClientMock) Close() {
 	log.Info().Str("clientId", m.id).Msg("Close()")
 	sessionStore := session.GetSessionStore()
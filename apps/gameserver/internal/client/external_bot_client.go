@@ -0,0 +1,170 @@
+package client
+
+import (
+	"errors"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/protocol"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+)
+
+// ExternalBotClient is a bot played by another process over a websocket, e.g. for bot
+// tournaments. It speaks the same protocol as players and receives the same events, but
+// counts as a bot. In turn based games it has to act within its time limit while it is its
+// turn, otherwise it forfeits and is removed from the room.
+type ExternalBotClient struct {
+	*WebSocketClient
+	name      string
+	registry  interfaces.GameRegistry
+	timeLimit time.Duration
+	handler   func(message []byte)
+
+	// updated is signaled on every event sent to the bot, acted on every message of the bot its
+	// game accepted. Messages that change nothing do not buy the bot time.
+	updated chan struct{}
+	acted   chan struct{}
+
+	// inbound serializes a forfeit with the messages of the read pump, the router sees them one at a time
+	inbound sync.Mutex
+}
+
+// NewExternalBotClient creates a bot client for an authenticated bot connection
+func NewExternalBotClient(conn *websocket.Conn, manager *Manager, gameType string, name string, registry interfaces.GameRegistry, timeLimit time.Duration) *ExternalBotClient {
+	bot := &ExternalBotClient{
+		WebSocketClient: newWebsocketClient(conn, manager),
+		name:            name,
+		registry:        registry,
+		timeLimit:       timeLimit,
+		handler:         func(message []byte) {},
+		updated:         make(chan struct{}, 1),
+		acted:           make(chan struct{}, 1),
+	}
+	bot.WebSocketClient.OnMessage = bot.handleMessage
+
+	manager.RegisterClient(bot, gameType)
+
+	return bot
+}
+
+// Name returns the name the bot's API key is registered for
+func (b *ExternalBotClient) Name() string {
+	return b.name
+}
+
+// IsBot returns true, the client is played by a program
+func (b *ExternalBotClient) IsBot() bool {
+	return true
+}
+
+// Remote returns true, unlike in-process bots the bot keeps its room open
+func (b *ExternalBotClient) Remote() bool {
+	return true
+}
+
+// SetMessageHandler sets the handler of the messages the bot sends
+func (b *ExternalBotClient) SetMessageHandler(handler func(message []byte)) {
+	b.handler = handler
+}
+
+// Send queues an event for the bot. The turn is checked asynchronously, rooms may hold
+// their lock while broadcasting.
+func (b *ExternalBotClient) Send(response *protocol.Response) error {
+	err := b.WebSocketClient.Send(response)
+	signal(b.updated)
+	return err
+}
+
+// StartPumps begins reading from and writing to the websocket and enforces the time limit
+func (b *ExternalBotClient) StartPumps() {
+	b.WebSocketClient.StartPumps()
+	go b.watchTurns()
+}
+
+func (b *ExternalBotClient) handleMessage(message []byte) {
+	b.inbound.Lock()
+	defer b.inbound.Unlock()
+	b.handler(message)
+}
+
+// Acted is called by the registry when a message of the bot changed the state of its room
+func (b *ExternalBotClient) Acted() {
+	signal(b.acted)
+}
+
+// watchTurns starts the clock whenever it becomes the bot's turn and stops it when the bot acts
+func (b *ExternalBotClient) watchTurns() {
+	var clock *time.Timer
+	var expired <-chan time.Time
+	stopClock := func() {
+		if clock != nil {
+			clock.Stop()
+		}
+		clock, expired = nil, nil
+	}
+	defer stopClock()
+
+	for {
+		select {
+		case <-b.updated:
+			if !b.hasTurn() {
+				stopClock()
+			} else if clock == nil {
+				clock = time.NewTimer(b.timeLimit)
+				expired = clock.C
+			}
+		case <-b.acted:
+			// every action gets the full time limit, the event it causes restarts the clock
+			stopClock()
+			signal(b.updated)
+		case <-expired:
+			clock, expired = nil, nil
+			b.forfeit()
+		case <-b.Done():
+			return
+		}
+	}
+}
+
+func (b *ExternalBotClient) hasTurn() bool {
+	room := b.Room()
+	if room == nil || room.IsClosed() {
+		return false
+	}
+	game, err := b.registry.GetGame(room.GameType())
+	if err != nil {
+		return false
+	}
+	keeper, ok := game.(interfaces.TurnKeeper)
+	if !ok {
+		return false
+	}
+	seat, ok := room.Seat(b)
+	return ok && keeper.CurrentTurn(room) == seat.ID()
+}
+
+// forfeit removes the bot from its room like a leave_room if it still has the turn, the
+// connection stays open. An action the read pump is handling finishes first.
+func (b *ExternalBotClient) forfeit() {
+	b.inbound.Lock()
+	defer b.inbound.Unlock()
+	if !b.hasTurn() {
+		return
+	}
+
+	log.Warn().Str("clientId", b.ID()).Str("bot", b.name).Dur("timeLimit", b.timeLimit).Msg("bot exceeded its action time limit")
+	b.Send(protocol.NewErrorResponse("action_timeout", ErrActionTimeLimit.Error()))
+	b.handler([]byte(`{"type":"leave_room"}`))
+}
+
+// signal notifies a channel without blocking, pending signals are merged
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+var ErrActionTimeLimit = errors.New("action time limit exceeded")
@@ -78,3 +78,12 @@ func (m *Manager) GetClientsByGameType(gameType string) []interfaces.Client {
 
 	return clients
 }
+
+// GetClient returns a connected client by id
+func (m *Manager) GetClient(clientID string) (interfaces.Client, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	client, exists := m.clients[clientID]
+	return client, exists
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"gameserver/internal/interfaces"
+	"gameserver/internal/session"
+	"time"
+)
+
+// storeSession keeps the seat of a disconnecting client in the session store, so it can
+// reconnect with a new connection, and disconnects it from the room
+func storeSession(client interfaces.Client, room interfaces.Room) {
+	// Get the global session store (see integration notes below)
+	sessionStore := session.GetSessionStore()
+
+	// sessions are kept by seat, a client that reconnected before has a seat id of its own
+	seatID := client.ID()
+	if seat, ok := room.Seat(client); ok {
+		seatID = seat.ID()
+	}
+
+	// Extract relevant player info from room state
+	var playerInfo interface{}
+	if state, ok := room.State().(map[string]interface{}); ok {
+		if players, exists := state["players"].(map[string]interface{}); exists {
+			playerInfo = players[seatID]
+		}
+	}
+
+	sessionStore.StoreSession(seatID, session.SessionData{
+		ClientID: seatID,
+		RoomID:   room.ID(),
+		GameType: room.GameType(),
+		LeftAt:   time.Now(),
+		// Add game-specific data if needed
+		ExtraData: map[string]interface{}{
+			"playerInfo": playerInfo,
+		},
+	})
+	room.Disconnect(client)
+}
@@ -3,7 +3,6 @@ package client
 import (
 	"gameserver/internal/interfaces"
 	"gameserver/internal/protocol"
-	"gameserver/internal/session"
 	"sync"
 	"time"
 
@@ -36,23 +35,32 @@ type WebSocketClient struct {
 	manager   *Manager
 	mu        sync.Mutex
 	closed    bool
+	identity  *interfaces.Identity
+	done      chan struct{}
+	pongWait  time.Duration
 	OnMessage func(message []byte)
 }
 
 // NewWebsocketClient creates a new WebSocketClient
 func NewWebsocketClient(conn *websocket.Conn, manager *Manager, gameType string) *WebSocketClient {
-	client := &WebSocketClient{
+	client := newWebsocketClient(conn, manager)
+
+	manager.RegisterClient(client, gameType)
+
+	return client
+}
+
+func newWebsocketClient(conn *websocket.Conn, manager *Manager) *WebSocketClient {
+	return &WebSocketClient{
 		id:        uuid.New().String(),
 		conn:      conn,
 		send:      make(chan []byte, 256),
 		closed:    false,
 		manager:   manager,
+		done:      make(chan struct{}),
+		pongWait:  pongWait,
 		OnMessage: func(message []byte) {},
 	}
-
-	manager.RegisterClient(client, gameType)
-
-	return client
 }
 
 // ID returns the client's unique ID
@@ -83,6 +91,26 @@ func (c *WebSocketClient) IsBot() bool {
 	return false
 }
 
+// Identity returns the verified identity of the client, nil if it is anonymous
+func (c *WebSocketClient) Identity() *interfaces.Identity {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.identity
+}
+
+// SetIdentity attaches a verified identity to the client
+func (c *WebSocketClient) SetIdentity(identity *interfaces.Identity) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.identity = identity
+}
+
+// SetPongWait sets how long the client may stay silent before it is dropped, it is pinged
+// at 9/10 of it. Has to be called before StartPumps.
+func (c *WebSocketClient) SetPongWait(wait time.Duration) {
+	c.pongWait = wait
+}
+
 // Room returns the client's current room
 func (c *WebSocketClient) Room() interfaces.Room {
 	c.mu.Lock()
@@ -110,34 +138,19 @@ func (c *WebSocketClient) Close() {
 
 	// Store session if client is in a room
 	if c.room != nil {
-		// Get the global session store (see integration notes below)
-		sessionStore := session.GetSessionStore()
-
-		// Extract relevant player info from room state
-		var playerInfo interface{}
-		if state, ok := c.room.State().(map[string]interface{}); ok {
-			if players, exists := state["players"].(map[string]interface{}); exists {
-				playerInfo = players[c.id]
-			}
-		}
-
-		sessionStore.StoreSession(c.id, session.SessionData{
-			ClientID: c.id,
-			RoomID:   c.room.ID(),
-			GameType: c.room.GameType(),
-			LeftAt:   time.Now(),
-			// Add game-specific data if needed
-			ExtraData: map[string]interface{}{
-				"playerInfo": playerInfo,
-			},
-		})
-		c.room.Leave(c)
+		storeSession(c, c.room)
 	}
 
 	c.manager.UnregisterClient(c)
 
 	c.conn.Close()
 	close(c.send)
+	close(c.done)
+}
+
+// Done returns a channel that is closed when the client is closed
+func (c *WebSocketClient) Done() <-chan struct{} {
+	return c.done
 }
 
 // StartPumps begins reading from and writing to the websocket
@@ -151,9 +164,9 @@ func (c *WebSocketClient) readPump() {
 	defer c.Close()
 
 	c.conn.SetReadLimit(maxMessageSize)
-	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetReadDeadline(time.Now().Add(c.pongWait))
 	c.conn.SetPongHandler(func(string) error {
-		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		c.conn.SetReadDeadline(time.Now().Add(c.pongWait))
 		return nil
 	})
 
@@ -177,7 +190,7 @@ func (c *WebSocketClient) readPump() {
 
 // writePump pumps messages from the hub to the websocket connection
 func (c *WebSocketClient) writePump() {
-	ticker := time.NewTicker(pingPeriod)
+	ticker := time.NewTicker((c.pongWait * 9) / 10)
 	defer func() {
 		ticker.Stop()
 		c.Close()
@@ -0,0 +1,234 @@
+package client
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/protocol"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// SSEClient implements the Client interface for networks that block websockets. Responses
+// are streamed as Server-Sent Events, messages arrive as HTTP POSTs (see HandleMessage).
+// Every event holds a JSON array of responses, the same batches a WebSocketClient writes.
+type SSEClient struct {
+	id        string
+	token     string
+	send      chan []byte
+	room      interfaces.Room
+	manager   *Manager
+	mu        sync.Mutex
+	closed    bool
+	identity  *interfaces.Identity
+	OnMessage func(message []byte)
+
+	// inbound serializes the posted messages, the router sees them one at a time like from a websocket
+	inbound sync.Mutex
+}
+
+// NewSSEClient creates a new SSEClient, call Serve to stream to it
+func NewSSEClient(manager *Manager, gameType string) *SSEClient {
+	client := &SSEClient{
+		id:        uuid.New().String(),
+		token:     newStreamToken(),
+		send:      make(chan []byte, 256),
+		closed:    false,
+		manager:   manager,
+		OnMessage: func(message []byte) {},
+	}
+
+	manager.RegisterClient(client, gameType)
+
+	return client
+}
+
+// newStreamToken returns the secret that authorizes the posts of a stream. Client ids are
+// shared with the other players of a room, so the id alone can't be trusted.
+func newStreamToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// ID returns the client's unique ID
+func (c *SSEClient) ID() string {
+	return c.id
+}
+
+// Token returns the secret the client sends with its posts
+func (c *SSEClient) Token() string {
+	return c.token
+}
+
+// Authorize checks the token sent with a post
+func (c *SSEClient) Authorize(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(c.token), []byte(token)) == 1
+}
+
+// Send queues a message to be streamed to the client
+func (c *SSEClient) Send(response *protocol.Response) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return ErrClientClosed
+	}
+
+	select {
+	case c.send <- response.ToBytes():
+		return nil
+	default:
+		log.Warn().Str("client", c.ID()).Msg("Dropping message due to full send channel")
+		return ErrClientClosed
+	}
+}
+
+// HandleMessage passes a posted message to the message handler
+func (c *SSEClient) HandleMessage(message []byte) error {
+	if len(message) > maxMessageSize {
+		return ErrMessageTooLarge
+	}
+
+	c.inbound.Lock()
+	defer c.inbound.Unlock()
+
+	c.mu.Lock()
+	closed := c.closed
+	c.mu.Unlock()
+	if closed {
+		return ErrClientClosed
+	}
+
+	c.OnMessage(message)
+	return nil
+}
+
+// IsBot returns false for SSEClient as it represents a human player
+func (c *SSEClient) IsBot() bool {
+	return false
+}
+
+// Identity returns the verified identity of the client, nil if it is anonymous
+func (c *SSEClient) Identity() *interfaces.Identity {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.identity
+}
+
+// SetIdentity attaches a verified identity to the client
+func (c *SSEClient) SetIdentity(identity *interfaces.Identity) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.identity = identity
+}
+
+// Room returns the client's current room
+func (c *SSEClient) Room() interfaces.Room {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.room
+}
+
+// SetRoom updates the client's current room
+func (c *SSEClient) SetRoom(room interfaces.Room) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.room = room
+}
+
+// Close ends the stream. Like a dropped websocket, the client's room is kept in the session
+// store so a new stream can reconnect to it.
+func (c *SSEClient) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.closed {
+		return
+	}
+
+	c.closed = true
+
+	// Store session if client is in a room
+	if c.room != nil {
+		storeSession(c, c.room)
+	}
+
+	c.manager.UnregisterClient(c)
+
+	close(c.send)
+}
+
+// Serve streams the client's responses until the request ends or the client is closed.
+// It closes the client when it returns.
+func (c *SSEClient) Serve(w http.ResponseWriter, r *http.Request) {
+	defer c.Close()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Stop proxies like nginx from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		log.Error().Err(err).Str("id", c.ID()).Msg("response does not support streaming")
+		return
+	}
+
+	log.Debug().Str("id", c.ID()).Msg("client connected")
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case message, ok := <-c.send:
+			if !ok {
+				return
+			}
+			rc.SetWriteDeadline(time.Now().Add(writeWait))
+
+			// Add queued messages to the same event, as a JSON array like the websocket
+			batch := append([]byte("data: ["), message...)
+			n := len(c.send)
+			for i := 0; i < n; i++ {
+				batch = append(batch, ',')
+				batch = append(batch, <-c.send...)
+			}
+			batch = append(batch, "]\n\n"...)
+
+			if _, err := w.Write(batch); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case <-ticker.C:
+			// comments keep idle connections open through proxies
+			rc.SetWriteDeadline(time.Now().Add(writeWait))
+			if _, err := w.Write([]byte(": ping\n\n")); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		case <-r.Context().Done():
+			log.Debug().Str("id", c.ID()).Msg("client disconnected")
+			return
+		}
+	}
+}
+
+var (
+	ErrClientClosed    = errors.New("client is closed")
+	ErrMessageTooLarge = errors.New("message too large")
+)
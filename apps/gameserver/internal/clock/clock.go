@@ -0,0 +1,129 @@
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is where rooms, sessions and games take the time from and schedule delayed work with
+type Clock interface {
+	Now() time.Time
+	// AfterFunc calls f once d has passed
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a call scheduled with AfterFunc
+type Timer interface {
+	// Stop prevents the call, it returns false if the call already happened or was stopped before
+	Stop() bool
+}
+
+type realClock struct{}
+
+// Real returns the clock of the system. Used in production.
+func Real() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer {
+	return time.AfterFunc(d, f)
+}
+
+// Fake is a clock that only moves when advanced, so tests can skip delays without sleeping.
+// Unlike time.AfterFunc, due calls run on the goroutine advancing the clock, one after another
+// in the order they are due.
+type Fake struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// NewFake creates a fake clock starting at now
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+// Now returns the time the clock was advanced to
+func (c *Fake) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc schedules f to be called once the clock was advanced by d
+func (c *Fake) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	timer := &fakeTimer{clock: c, at: c.now.Add(d), f: f}
+	c.timers = append(c.timers, timer)
+	return timer
+}
+
+// Advance moves the clock forward by d and makes the calls that are due by then, including calls
+// scheduled by them. The clock reads the time a call was due at while it runs.
+func (c *Fake) Advance(d time.Duration) {
+	c.mu.Lock()
+	until := c.now.Add(d)
+	c.mu.Unlock()
+
+	for {
+		c.mu.Lock()
+		next := c.next(until)
+		if next == nil {
+			c.now = until
+			c.mu.Unlock()
+			return
+		}
+		c.remove(next)
+		if next.at.After(c.now) {
+			c.now = next.at
+		}
+		c.mu.Unlock()
+
+		next.f()
+	}
+}
+
+// Pending returns how many calls are scheduled and not due yet
+func (c *Fake) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
+
+// next returns the earliest call due by until, calls due at the same time in the order they were scheduled
+func (c *Fake) next(until time.Time) *fakeTimer {
+	var next *fakeTimer
+	for _, timer := range c.timers {
+		if !timer.at.After(until) && (next == nil || timer.at.Before(next.at)) {
+			next = timer
+		}
+	}
+	return next
+}
+
+func (c *Fake) remove(timer *fakeTimer) bool {
+	for i, scheduled := range c.timers {
+		if scheduled == timer {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+type fakeTimer struct {
+	clock *Fake
+	at    time.Time
+	f     func()
+}
+
+func (t *fakeTimer) Stop() bool {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	return t.clock.remove(t)
+}
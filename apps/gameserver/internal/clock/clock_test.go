@@ -0,0 +1,80 @@
+package clock
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+var start = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func TestFake_AdvanceRunsDueCalls(t *testing.T) {
+	c := NewFake(start)
+
+	var calls []string
+	var at []time.Duration
+	record := func(name string) func() {
+		return func() {
+			calls = append(calls, name)
+			at = append(at, c.Now().Sub(start))
+		}
+	}
+	c.AfterFunc(2*time.Second, record("late"))
+	c.AfterFunc(time.Second, record("first"))
+	c.AfterFunc(time.Second, record("second"))
+
+	c.Advance(1500 * time.Millisecond)
+	if !slices.Equal(calls, []string{"first", "second"}) {
+		t.Fatalf("Expected the calls due after a second in order, got %v", calls)
+	}
+	if !slices.Equal(at, []time.Duration{time.Second, time.Second}) {
+		t.Errorf("Expected the calls to see the time they were due at, got %v", at)
+	}
+	if now := c.Now().Sub(start); now != 1500*time.Millisecond {
+		t.Errorf("Expected the clock to have advanced by 1.5s, got %v", now)
+	}
+	if c.Pending() != 1 {
+		t.Errorf("Expected one pending call, got %d", c.Pending())
+	}
+
+	c.Advance(500 * time.Millisecond)
+	if !slices.Equal(calls, []string{"first", "second", "late"}) {
+		t.Errorf("Expected the late call after two seconds, got %v", calls)
+	}
+}
+
+func TestFake_Stop(t *testing.T) {
+	c := NewFake(start)
+
+	called := false
+	timer := c.AfterFunc(time.Second, func() { called = true })
+	if !timer.Stop() {
+		t.Error("Expected stopping a pending call to succeed")
+	}
+	if timer.Stop() {
+		t.Error("Expected stopping a call twice to fail")
+	}
+
+	c.Advance(time.Hour)
+	if called {
+		t.Error("Expected a stopped call not to happen")
+	}
+}
+
+func TestFake_CallsScheduledByCalls(t *testing.T) {
+	c := NewFake(start)
+
+	// a call rescheduling itself every minute, like a ticker
+	ticks := 0
+	var tick func()
+	tick = func() {
+		ticks++
+		c.AfterFunc(time.Minute, tick)
+	}
+	c.AfterFunc(time.Minute, tick)
+
+	c.Advance(5*time.Minute + 30*time.Second)
+	if ticks != 5 {
+		t.Errorf("Expected 5 ticks in five and a half minutes, got %d", ticks)
+	}
+}
@@ -0,0 +1,267 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRing(t *testing.T) {
+	ring := NewRing(64)
+	if owner := ring.Owner("room"); owner != "" {
+		t.Errorf("Expected no owner on an empty ring, got %q", owner)
+	}
+
+	for _, id := range []string{"a", "b", "c"} {
+		ring.Add(id)
+	}
+
+	keys := make([]string, 3000)
+	owners := make(map[string]string, len(keys))
+	counts := make(map[string]int)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("room-%d", i)
+		owners[keys[i]] = ring.Owner(keys[i])
+		counts[owners[keys[i]]]++
+	}
+	for _, id := range ring.Nodes() {
+		if counts[id] < 500 {
+			t.Errorf("Expected node %s to own a fair share of keys, got %d of %d", id, counts[id], len(keys))
+		}
+	}
+
+	// the same nodes added in another order build the same ring
+	other := NewRing(64)
+	for _, id := range []string{"c", "a", "b"} {
+		other.Add(id)
+	}
+	for _, key := range keys {
+		if other.Owner(key) != owners[key] {
+			t.Fatalf("Expected rings with the same nodes to agree on %s", key)
+		}
+	}
+
+	// removing a node only moves its own keys
+	ring.Remove("b")
+	for _, key := range keys {
+		owner := ring.Owner(key)
+		if owner == "b" {
+			t.Fatalf("Expected removed node to own nothing, got %s", key)
+		}
+		if owners[key] != "b" && owner != owners[key] {
+			t.Fatalf("Expected %s to stay on %s, moved to %s", key, owners[key], owner)
+		}
+	}
+}
+
+// testNode is a node whose local rooms are set by the test
+type testNode struct {
+	*Node
+	rooms   []RoomSummary
+	changed []string
+	mu      sync.Mutex
+}
+
+func newTestNode(id string, pubsub PubSub) *testNode {
+	tn := &testNode{}
+	tn.Node = NewNode(NodeInfo{ID: id, Address: "http://" + id, PublicURL: "wss://" + id + "/ws"}, pubsub, WithGossipInterval(time.Hour))
+	tn.SetLocalRooms(func() []RoomSummary {
+		tn.mu.Lock()
+		defer tn.mu.Unlock()
+		return append([]RoomSummary(nil), tn.rooms...)
+	})
+	tn.SetRoomListChangeCallback(func(gameType string) {
+		tn.mu.Lock()
+		defer tn.mu.Unlock()
+		tn.changed = append(tn.changed, gameType)
+	})
+	return tn
+}
+
+func (tn *testNode) host(rooms ...RoomSummary) {
+	tn.mu.Lock()
+	tn.rooms = rooms
+	tn.mu.Unlock()
+	tn.publish(false)
+}
+
+func (tn *testNode) changes() []string {
+	tn.mu.Lock()
+	defer tn.mu.Unlock()
+	changed := tn.changed
+	tn.changed = nil
+	return changed
+}
+
+func TestNodeGossip(t *testing.T) {
+	pubsub := NewMemoryPubSub()
+	a := newTestNode("a", pubsub)
+	b := newTestNode("b", pubsub)
+	a.Start()
+	b.Start()
+	defer a.Stop()
+	// a answers b asynchronously, don't wait for it
+	a.publish(false)
+
+	if nodes := a.Nodes(); len(nodes) != 2 || nodes[1].ID != "b" {
+		t.Fatalf("Expected a to know b, got %+v", nodes)
+	}
+
+	b.host(RoomSummary{RoomID: "dice-1", GameType: "dicegame", PlayerCount: 1})
+	if rooms := a.RemoteRooms("dicegame"); len(rooms) != 1 || rooms[0].RoomID != "dice-1" {
+		t.Errorf("Expected b's room on a, got %+v", rooms)
+	}
+	if changed := a.changes(); len(changed) != 1 || changed[0] != "dicegame" {
+		t.Errorf("Expected a dicegame room list change, got %v", changed)
+	}
+
+	// unchanged announcements don't trigger room list updates
+	b.host(RoomSummary{RoomID: "dice-1", GameType: "dicegame", PlayerCount: 1})
+	if changed := a.changes(); len(changed) != 0 {
+		t.Errorf("Expected no change, got %v", changed)
+	}
+
+	// a room is owned by the node hosting it, whatever the ring says
+	if owner, local := a.Owner("dice-1"); local || owner.ID != "b" || owner.PublicURL != "wss://b/ws" {
+		t.Errorf("Expected dice-1 to be owned by b, got %+v", owner)
+	}
+	if owner, local := b.Owner("dice-1"); !local || owner.ID != "b" {
+		t.Errorf("Expected b to own dice-1 itself, got %+v", owner)
+	}
+
+	// unknown rooms are owned by the ring, both nodes agree
+	for i := 0; i < 20; i++ {
+		id := fmt.Sprintf("new-%d", i)
+		ownerA, _ := a.Owner(id)
+		ownerB, _ := b.Owner(id)
+		if ownerA.ID != ownerB.ID {
+			t.Errorf("Expected nodes to agree on the owner of %s, got %s and %s", id, ownerA.ID, ownerB.ID)
+		}
+	}
+	for i := 0; i < 20; i++ {
+		if owner, local := a.Owner(a.NewRoomID()); !local || owner.ID != "a" {
+			t.Fatalf("Expected new room ids of a to be owned by a, got %s", owner.ID)
+		}
+	}
+
+	b.Stop()
+	if nodes := a.Nodes(); len(nodes) != 1 {
+		t.Errorf("Expected b to have left, got %+v", nodes)
+	}
+	if rooms := a.RemoteRooms("dicegame"); len(rooms) != 0 {
+		t.Errorf("Expected b's rooms to be gone, got %+v", rooms)
+	}
+	if changed := a.changes(); len(changed) != 1 || changed[0] != "dicegame" {
+		t.Errorf("Expected a dicegame room list change, got %v", changed)
+	}
+}
+
+func TestNodeExpiresSilentPeers(t *testing.T) {
+	pubsub := NewMemoryPubSub()
+	a := newTestNode("a", pubsub)
+	b := newTestNode("b", pubsub)
+	a.unsubscribe = pubsub.Subscribe(TopicRooms, a.handleAnnouncement)
+
+	b.host(RoomSummary{RoomID: "ttt-1", GameType: "tictactoe"})
+	if nodes := a.Nodes(); len(nodes) != 2 {
+		t.Fatalf("Expected a to know b, got %+v", nodes)
+	}
+
+	a.expirePeers()
+	if nodes := a.Nodes(); len(nodes) != 2 {
+		t.Fatalf("Expected b to be kept while announcing, got %+v", nodes)
+	}
+
+	a.now = func() time.Time { return time.Now().Add(4 * time.Hour) }
+	a.changes()
+	a.expirePeers()
+	if nodes := a.Nodes(); len(nodes) != 1 {
+		t.Errorf("Expected b to time out, got %+v", nodes)
+	}
+	if changed := a.changes(); len(changed) != 1 || changed[0] != "tictactoe" {
+		t.Errorf("Expected a tictactoe room list change, got %v", changed)
+	}
+	if owner, local := a.Owner("ttt-1"); !local || owner.ID != "a" {
+		t.Errorf("Expected a to own everything alone, got %+v", owner)
+	}
+}
+
+func TestHTTPPubSub(t *testing.T) {
+	received := make(chan string, 1)
+	receiver := NewHTTPPubSub(nil, "secret")
+	receiver.Subscribe("topic", func(message []byte) { received <- string(message) })
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	sender := NewHTTPPubSub([]string{server.URL + "/"}, "secret")
+	var local []string
+	sender.Subscribe("topic", func(message []byte) { local = append(local, string(message)) })
+
+	if err := sender.Publish(context.Background(), "topic", []byte(`{"hello":"world"}`)); err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	if message := <-received; message != `{"hello":"world"}` {
+		t.Errorf("Expected the message on the peer, got %s", message)
+	}
+	if len(local) != 1 {
+		t.Errorf("Expected the message to be delivered locally too, got %v", local)
+	}
+
+	forger := NewHTTPPubSub([]string{server.URL}, "wrong")
+	if err := forger.Publish(context.Background(), "topic", []byte("forged")); err == nil {
+		t.Error("Expected a message with a wrong signature to be rejected")
+	}
+
+	unreachable := NewHTTPPubSub([]string{"http://127.0.0.1:1"}, "secret")
+	if err := unreachable.Publish(context.Background(), "topic", []byte("lost")); err == nil {
+		t.Error("Expected an error for an unreachable peer")
+	}
+}
+
+func TestProxyRooms(t *testing.T) {
+	owner := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "owner %s forwarded by %s", r.URL.Query().Get("room"), r.Header.Get(HeaderForwarded))
+	}))
+	defer owner.Close()
+
+	pubsub := NewMemoryPubSub()
+	a := newTestNode("a", pubsub)
+	b := NewNode(NodeInfo{ID: "b", Address: owner.URL}, pubsub, WithGossipInterval(time.Hour))
+	a.unsubscribe = pubsub.Subscribe(TopicRooms, a.handleAnnouncement)
+	b.SetLocalRooms(func() []RoomSummary { return []RoomSummary{{RoomID: "remote", GameType: "dicegame"}} })
+	b.publish(false)
+	a.host(RoomSummary{RoomID: "local", GameType: "dicegame"})
+
+	handler := a.ProxyRooms(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "local")
+	}))
+	get := func(path string) string {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec.Body.String()
+	}
+
+	if body := get("/ws?game=dicegame&room=remote"); body != "owner remote forwarded by a" {
+		t.Errorf("Expected the request to be proxied to b, got %q", body)
+	}
+	if body := get("/ws?game=dicegame&room=local"); body != "local" {
+		t.Errorf("Expected the local room to be served locally, got %q", body)
+	}
+	if body := get("/ws?game=dicegame"); body != "local" {
+		t.Errorf("Expected requests without a room to be served locally, got %q", body)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/ws?room=remote", nil)
+	req.Header.Set(HeaderForwarded, "c")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if !strings.Contains(rec.Body.String(), "local") {
+		t.Errorf("Expected forwarded requests not to be proxied again, got %q", rec.Body.String())
+	}
+}
@@ -0,0 +1,141 @@
+package cluster
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// PublishPath is where HTTPPubSub receives the messages of its peers
+	PublishPath = "/cluster/publish"
+	// HeaderSignature is the hex HMAC-SHA256 of the body keyed with the cluster secret
+	HeaderSignature = "X-Cluster-Signature"
+
+	maxMessageSize = 1 << 20
+)
+
+type envelope struct {
+	Topic   string `json:"topic"`
+	Message []byte `json:"message"`
+}
+
+// HTTPPubSub is a PubSub for a fixed set of peers. Messages are posted to every peer's
+// PublishPath, signed with a secret shared by the cluster. Serve it on PublishPath.
+type HTTPPubSub struct {
+	peers  []string
+	secret []byte
+	client *http.Client
+	subs   *subscriptions
+}
+
+// NewHTTPPubSub creates a PubSub posting to the peers, base URLs like http://10.0.0.2:8080
+func NewHTTPPubSub(peers []string, secret string) *HTTPPubSub {
+	trimmed := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		trimmed = append(trimmed, strings.TrimSuffix(peer, "/"))
+	}
+	return &HTTPPubSub{
+		peers:  trimmed,
+		secret: []byte(secret),
+		client: &http.Client{Timeout: 5 * time.Second},
+		subs:   newSubscriptions(),
+	}
+}
+
+// Publish delivers the message locally and posts it to all peers. Unreachable peers are
+// reported in the error but don't keep the message from the others.
+func (p *HTTPPubSub) Publish(ctx context.Context, topic string, message []byte) error {
+	p.subs.deliver(topic, message)
+
+	body, err := json.Marshal(envelope{Topic: topic, Message: message})
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+	signature := p.sign(body)
+
+	var errs []error
+	for _, peer := range p.peers {
+		if err := p.post(ctx, peer, body, signature); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", peer, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (p *HTTPPubSub) post(ctx context.Context, peer string, body []byte, signature string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, peer+PublishPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderSignature, signature)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("%w: %s", ErrPublishFailed, resp.Status)
+	}
+	return nil
+}
+
+func (p *HTTPPubSub) Subscribe(topic string, handler Handler) func() {
+	return p.subs.add(topic, handler)
+}
+
+func (p *HTTPPubSub) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
+
+// ServeHTTP receives a message published by a peer
+func (p *HTTPPubSub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+	if !hmac.Equal([]byte(p.sign(body)), []byte(r.Header.Get(HeaderSignature))) {
+		log.Warn().Str("remote", r.RemoteAddr).Msg("rejecting cluster message with invalid signature")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var msg envelope
+	if err := json.Unmarshal(body, &msg); err != nil || msg.Topic == "" {
+		http.Error(w, "Bad request", http.StatusBadRequest)
+		return
+	}
+
+	p.subs.deliver(msg.Topic, msg.Message)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (p *HTTPPubSub) sign(body []byte) string {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+var (
+	ErrPublishFailed = errors.New("peer rejected the cluster message")
+)
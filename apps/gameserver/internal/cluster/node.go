@@ -0,0 +1,349 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// TopicRooms is the topic nodes gossip their rooms on
+const TopicRooms = "cluster.rooms"
+
+// NodeInfo identifies a node and where to reach it
+type NodeInfo struct {
+	ID string `json:"id"`
+	// Address is where other nodes reach this one, e.g. http://10.0.0.2:8080. Clients are proxied to it.
+	Address string `json:"address"`
+	// PublicURL is the websocket URL clients are redirected to, e.g. wss://eu-1.example.com/ws
+	PublicURL string `json:"publicUrl"`
+}
+
+// RoomSummary is what the room lists show of a room on any node
+type RoomSummary struct {
+	RoomID      string `json:"roomId"`
+	GameType    string `json:"gameType"`
+	PlayerCount int    `json:"playerCount"`
+	Started     bool   `json:"started"`
+}
+
+// announcement is the gossip message, the full room list of a node
+type announcement struct {
+	Node    NodeInfo      `json:"node"`
+	Rooms   []RoomSummary `json:"rooms"`
+	Leaving bool          `json:"leaving,omitempty"`
+}
+
+type peer struct {
+	info     NodeInfo
+	rooms    []RoomSummary
+	lastSeen time.Time
+}
+
+// Node is this server's membership in a cluster. Every room is owned by one node: the one
+// hosting it, or for rooms that don't exist yet the node chosen by consistent hashing on the
+// room id. Nodes periodically gossip their rooms, a node that stays silent for three
+// intervals is considered gone.
+type Node struct {
+	self     NodeInfo
+	pubsub   PubSub
+	ring     *Ring
+	peers    map[string]*peer
+	interval time.Duration
+	now      func() time.Time
+
+	localRooms       func() []RoomSummary
+	onRoomListChange func(gameType string)
+
+	announce    chan struct{}
+	stop        chan struct{}
+	done        chan struct{}
+	unsubscribe func()
+	mu          sync.RWMutex
+}
+
+// NodeOption is a functional option for configuring the Node
+type NodeOption func(*Node)
+
+// WithGossipInterval sets how often the node announces its rooms, 5 seconds by default
+func WithGossipInterval(interval time.Duration) NodeOption {
+	return func(n *Node) {
+		n.interval = interval
+	}
+}
+
+// WithReplicas sets how often every node is placed on the hash ring, 64 by default
+func WithReplicas(replicas int) NodeOption {
+	return func(n *Node) {
+		n.ring = NewRing(replicas)
+	}
+}
+
+// NewNode creates a cluster node, call Start to join the cluster
+func NewNode(self NodeInfo, pubsub PubSub, opts ...NodeOption) *Node {
+	n := &Node{
+		self:       self,
+		pubsub:     pubsub,
+		ring:       NewRing(64),
+		peers:      make(map[string]*peer),
+		interval:   5 * time.Second,
+		now:        time.Now,
+		localRooms: func() []RoomSummary { return nil },
+		announce:   make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(n)
+	}
+	n.ring.Add(self.ID)
+	return n
+}
+
+// SetLocalRooms sets how the node lists the rooms it hosts
+func (n *Node) SetLocalRooms(localRooms func() []RoomSummary) {
+	n.localRooms = localRooms
+}
+
+// SetRoomListChangeCallback sets a callback to be called when the rooms of other nodes change
+func (n *Node) SetRoomListChangeCallback(callback func(gameType string)) {
+	n.onRoomListChange = callback
+}
+
+// Self returns this node
+func (n *Node) Self() NodeInfo {
+	return n.self
+}
+
+// Start subscribes to the gossip and starts announcing the local rooms
+func (n *Node) Start() {
+	n.unsubscribe = n.pubsub.Subscribe(TopicRooms, n.handleAnnouncement)
+	n.publish(false)
+
+	go func() {
+		defer close(n.done)
+		ticker := time.NewTicker(n.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				n.expirePeers()
+				n.publish(false)
+			case <-n.announce:
+				n.publish(false)
+			case <-n.stop:
+				return
+			}
+		}
+	}()
+
+	log.Info().Str("nodeId", n.self.ID).Msg("cluster node started")
+}
+
+// Stop tells the other nodes that this one is leaving and stops gossiping
+func (n *Node) Stop() {
+	close(n.stop)
+	<-n.done
+	n.publish(true)
+	if n.unsubscribe != nil {
+		n.unsubscribe()
+	}
+	log.Info().Str("nodeId", n.self.ID).Msg("cluster node stopped")
+}
+
+// Announce gossips the local rooms soon, call it when they change. It never blocks,
+// announcements requested while one is pending are merged.
+func (n *Node) Announce() {
+	select {
+	case n.announce <- struct{}{}:
+	default:
+	}
+}
+
+func (n *Node) publish(leaving bool) {
+	msg := announcement{Node: n.self, Leaving: leaving}
+	if !leaving {
+		msg.Rooms = n.localRooms()
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to encode room announcement")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), n.interval)
+	defer cancel()
+	if err := n.pubsub.Publish(ctx, TopicRooms, data); err != nil {
+		log.Debug().Err(err).Msg("failed to gossip rooms to every node")
+	}
+}
+
+func (n *Node) handleAnnouncement(data []byte) {
+	var msg announcement
+	if err := json.Unmarshal(data, &msg); err != nil {
+		log.Warn().Err(err).Msg("ignoring invalid room announcement")
+		return
+	}
+	if msg.Node.ID == "" || msg.Node.ID == n.self.ID {
+		return
+	}
+
+	n.mu.Lock()
+	existing, known := n.peers[msg.Node.ID]
+	var before []RoomSummary
+	if known {
+		before = existing.rooms
+	}
+
+	var after []RoomSummary
+	if msg.Leaving {
+		delete(n.peers, msg.Node.ID)
+		n.ring.Remove(msg.Node.ID)
+	} else {
+		after = msg.Rooms
+		n.peers[msg.Node.ID] = &peer{info: msg.Node, rooms: msg.Rooms, lastSeen: n.now()}
+		n.ring.Add(msg.Node.ID)
+	}
+	n.mu.Unlock()
+
+	if !known && !msg.Leaving {
+		log.Info().Str("nodeId", msg.Node.ID).Str("address", msg.Node.Address).Msg("cluster node joined")
+		// answer right away, so the new node doesn't have to wait a full interval for our rooms
+		n.Announce()
+	} else if msg.Leaving {
+		log.Info().Str("nodeId", msg.Node.ID).Msg("cluster node left")
+	}
+	n.notify(changedGameTypes(before, after))
+}
+
+// expirePeers forgets the nodes that missed three announcements
+func (n *Node) expirePeers() {
+	deadline := n.now().Add(-3 * n.interval)
+
+	var gone []RoomSummary
+	n.mu.Lock()
+	for id, p := range n.peers {
+		if p.lastSeen.Before(deadline) {
+			log.Warn().Str("nodeId", id).Time("lastSeen", p.lastSeen).Msg("cluster node timed out")
+			gone = append(gone, p.rooms...)
+			delete(n.peers, id)
+			n.ring.Remove(id)
+		}
+	}
+	n.mu.Unlock()
+
+	n.notify(changedGameTypes(gone, nil))
+}
+
+func (n *Node) notify(gameTypes []string) {
+	if n.onRoomListChange == nil {
+		return
+	}
+	for _, gameType := range gameTypes {
+		n.onRoomListChange(gameType)
+	}
+}
+
+// changedGameTypes returns the game types whose rooms differ between two room lists
+func changedGameTypes(before []RoomSummary, after []RoomSummary) []string {
+	byType := func(rooms []RoomSummary) map[string][]RoomSummary {
+		grouped := make(map[string][]RoomSummary)
+		for _, room := range rooms {
+			grouped[room.GameType] = append(grouped[room.GameType], room)
+		}
+		return grouped
+	}
+	b, a := byType(before), byType(after)
+
+	changed := make(map[string]struct{})
+	for gameType, rooms := range b {
+		if !slices.Equal(rooms, a[gameType]) {
+			changed[gameType] = struct{}{}
+		}
+	}
+	for gameType := range a {
+		if _, ok := b[gameType]; !ok {
+			changed[gameType] = struct{}{}
+		}
+	}
+	return slices.Sorted(maps.Keys(changed))
+}
+
+// Owner returns the node that owns a room and whether it is this node. A room is owned by the
+// node hosting it, rooms that don't exist yet belong to the node the hash ring picks.
+func (n *Node) Owner(roomID string) (NodeInfo, bool) {
+	for _, room := range n.localRooms() {
+		if room.RoomID == roomID {
+			return n.self, true
+		}
+	}
+
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	for _, p := range n.peers {
+		for _, room := range p.rooms {
+			if room.RoomID == roomID {
+				return p.info, false
+			}
+		}
+	}
+
+	ownerID := n.ring.Owner(roomID)
+	if p, ok := n.peers[ownerID]; ok {
+		return p.info, false
+	}
+	return n.self, true
+}
+
+// NewRoomID returns a fresh room id that hashes to this node, so the room stays
+// reachable by its id even before the other nodes heard of it
+func (n *Node) NewRoomID() string {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	for {
+		id := uuid.New().String()
+		if n.ring.Owner(id) == n.self.ID {
+			return id
+		}
+	}
+}
+
+// RemoteRooms returns the rooms of a game type hosted by other nodes
+func (n *Node) RemoteRooms(gameType string) []RoomSummary {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	var rooms []RoomSummary
+	for _, id := range slices.Sorted(maps.Keys(n.peers)) {
+		for _, room := range n.peers[id].rooms {
+			if room.GameType == gameType {
+				rooms = append(rooms, room)
+			}
+		}
+	}
+	return rooms
+}
+
+// Nodes returns all known nodes including this one, sorted by id
+func (n *Node) Nodes() []NodeInfo {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	nodes := []NodeInfo{n.self}
+	for _, p := range n.peers {
+		nodes = append(nodes, p.info)
+	}
+	slices.SortFunc(nodes, func(a, b NodeInfo) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return nodes
+}
@@ -0,0 +1,83 @@
+package cluster
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/rs/zerolog/log"
+)
+
+// HeaderForwarded marks requests proxied by another node, they are never proxied twice
+const HeaderForwarded = "X-Cluster-Forwarded-By"
+
+// ProxyRooms passes requests for a room of another node on to its owner, websocket upgrades
+// included. The room is read from the room query parameter, e.g. /ws?game=dicegame&room=<id>.
+// Requests without a room, for rooms of this node or for nodes without an address go to next.
+func (n *Node) ProxyRooms(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		roomID := r.URL.Query().Get("room")
+		if roomID == "" || r.Header.Get(HeaderForwarded) != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		owner, local := n.Owner(roomID)
+		if local {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		log.Debug().Str("roomId", roomID).Str("nodeId", owner.ID).Msg("proxying client to room owner")
+		n.proxy(w, r, owner, next)
+	})
+}
+
+// ProxyNodes passes requests on to the node nodeOf reads from them, for state that lives on
+// one node but isn't a room, e.g. the stream of an SSE client. Requests naming no node, this
+// node or a node that isn't known go to next.
+func (n *Node) ProxyNodes(next http.Handler, nodeOf func(r *http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nodeID := nodeOf(r)
+		if nodeID == "" || nodeID == n.self.ID || r.Header.Get(HeaderForwarded) != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		n.mu.RLock()
+		p, known := n.peers[nodeID]
+		n.mu.RUnlock()
+		if !known {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		log.Debug().Str("nodeId", nodeID).Str("path", r.URL.Path).Msg("proxying request to node")
+		n.proxy(w, r, p.info, next)
+	})
+}
+
+// proxy passes a request on to another node, or to next if the node has no valid address
+func (n *Node) proxy(w http.ResponseWriter, r *http.Request, node NodeInfo, next http.Handler) {
+	if node.Address == "" {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	target, err := url.Parse(node.Address)
+	if err != nil {
+		log.Error().Err(err).Str("nodeId", node.ID).Str("address", node.Address).Msg("invalid node address, not proxying")
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.SetXForwarded()
+			pr.Out.Host = pr.In.Host
+			pr.Out.Header.Set(HeaderForwarded, n.self.ID)
+		},
+	}
+	proxy.ServeHTTP(w, r)
+}
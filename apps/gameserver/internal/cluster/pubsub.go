@@ -0,0 +1,85 @@
+package cluster
+
+import (
+	"context"
+	"sync"
+)
+
+// Handler receives the messages published to a topic
+type Handler func(message []byte)
+
+// PubSub carries messages between the nodes of a cluster
+type PubSub interface {
+	// Publish sends the message to every subscriber of the topic on all nodes, including this one
+	Publish(ctx context.Context, topic string, message []byte) error
+	// Subscribe calls handler for every message published to the topic until unsubscribed
+	Subscribe(topic string, handler Handler) (unsubscribe func())
+	Close() error
+}
+
+// subscriptions are the local handlers per topic, shared by the PubSub implementations
+type subscriptions struct {
+	handlers map[string]map[int]Handler
+	nextID   int
+	mu       sync.RWMutex
+}
+
+func newSubscriptions() *subscriptions {
+	return &subscriptions{handlers: make(map[string]map[int]Handler)}
+}
+
+func (s *subscriptions) add(topic string, handler Handler) func() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.handlers[topic] == nil {
+		s.handlers[topic] = make(map[int]Handler)
+	}
+	id := s.nextID
+	s.nextID++
+	s.handlers[topic][id] = handler
+
+	return func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.handlers[topic], id)
+	}
+}
+
+// deliver calls the handlers of the topic without holding the lock, so they may publish or subscribe
+func (s *subscriptions) deliver(topic string, message []byte) {
+	s.mu.RLock()
+	handlers := make([]Handler, 0, len(s.handlers[topic]))
+	for _, handler := range s.handlers[topic] {
+		handlers = append(handlers, handler)
+	}
+	s.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(message)
+	}
+}
+
+// MemoryPubSub is an in-process PubSub. Nodes sharing one instance form a cluster,
+// which is how tests run several nodes in one process. Messages are delivered synchronously.
+type MemoryPubSub struct {
+	subs *subscriptions
+}
+
+// NewMemoryPubSub creates an in-process PubSub
+func NewMemoryPubSub() *MemoryPubSub {
+	return &MemoryPubSub{subs: newSubscriptions()}
+}
+
+func (m *MemoryPubSub) Publish(ctx context.Context, topic string, message []byte) error {
+	m.subs.deliver(topic, message)
+	return nil
+}
+
+func (m *MemoryPubSub) Subscribe(topic string, handler Handler) func() {
+	return m.subs.add(topic, handler)
+}
+
+func (m *MemoryPubSub) Close() error {
+	return nil
+}
@@ -0,0 +1,102 @@
+package cluster
+
+import (
+	"hash/crc32"
+	"slices"
+	"strconv"
+)
+
+// Ring assigns keys to nodes by consistent hashing. Every node is placed on the ring
+// several times, so adding or removing a node only moves about 1/n of the keys.
+// It is not safe for concurrent use.
+type Ring struct {
+	replicas int
+	points   []uint32
+	owners   map[uint32]string
+	nodes    map[string]struct{}
+}
+
+// NewRing creates an empty ring placing each node replicas times
+func NewRing(replicas int) *Ring {
+	return &Ring{
+		replicas: replicas,
+		owners:   make(map[uint32]string),
+		nodes:    make(map[string]struct{}),
+	}
+}
+
+func hashKey(key string) uint32 {
+	return crc32.ChecksumIEEE([]byte(key))
+}
+
+// Add places a node on the ring
+func (r *Ring) Add(nodeID string) {
+	if _, exists := r.nodes[nodeID]; exists {
+		return
+	}
+	r.nodes[nodeID] = struct{}{}
+
+	for i := 0; i < r.replicas; i++ {
+		point := hashKey(nodeID + "#" + strconv.Itoa(i))
+		// on the rare collision the smaller node id wins, so every node builds the same ring
+		if owner, taken := r.owners[point]; taken {
+			if owner < nodeID {
+				continue
+			}
+		} else {
+			r.points = append(r.points, point)
+		}
+		r.owners[point] = nodeID
+	}
+	slices.Sort(r.points)
+}
+
+// Remove takes a node off the ring
+func (r *Ring) Remove(nodeID string) {
+	if _, exists := r.nodes[nodeID]; !exists {
+		return
+	}
+	delete(r.nodes, nodeID)
+
+	r.points = slices.DeleteFunc(r.points, func(point uint32) bool {
+		if r.owners[point] == nodeID {
+			delete(r.owners, point)
+			return true
+		}
+		return false
+	})
+
+	// points of the removed node may have hidden a collision with another node
+	remaining := make([]string, 0, len(r.nodes))
+	for id := range r.nodes {
+		remaining = append(remaining, id)
+	}
+	for _, id := range remaining {
+		delete(r.nodes, id)
+		r.Add(id)
+	}
+}
+
+// Owner returns the node responsible for a key, the first one clockwise from its hash.
+// It returns "" if the ring is empty.
+func (r *Ring) Owner(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+	h := hashKey(key)
+	i, _ := slices.BinarySearch(r.points, h)
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.owners[r.points[i]]
+}
+
+// Nodes returns the ids of all nodes on the ring, sorted
+func (r *Ring) Nodes() []string {
+	ids := make([]string, 0, len(r.nodes))
+	for id := range r.nodes {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
@@ -11,16 +11,34 @@ import (
 	"time"
 
 	"github.com/jmoiron/sqlx"
-	_ "github.com/lib/pq"  // PostgreSQL driver
-	_ "modernc.org/sqlite" // SQLite driver
+	"github.com/lib/pq"  // PostgreSQL driver
+	"modernc.org/sqlite" // SQLite driver
+	sqlite3 "modernc.org/sqlite/lib"
 )
 
 // Common errors
 var (
 	ErrRecordNotFound   = errors.New("record not found")
 	ErrInvalidTableName = errors.New("invalid table name")
+	ErrDuplicateKey     = errors.New("duplicate key")
 )
 
+// uniqueViolation is the PostgreSQL error code of a unique constraint violation
+const uniqueViolation = "23505"
+
+// translateError wraps unique constraint violations of both drivers in ErrDuplicateKey
+func translateError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return fmt.Errorf("%w: %v", ErrDuplicateKey, err)
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && (sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE || sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY) {
+		return fmt.Errorf("%w: %v", ErrDuplicateKey, err)
+	}
+	return err
+}
+
 // validTableNames is a whitelist of allowed table names for security
 var validTableNames = make(map[string]bool)
 
@@ -240,7 +258,7 @@ func (c *Client) Create(ctx context.Context, table string, data interface{}) err
 	)
 
 	_, err := c.db.NamedExecContext(ctx, query, data)
-	return err
+	return translateError(err)
 }
 
 // Get retrieves a record by ID from the specified table
@@ -341,7 +359,7 @@ func (c *Client) Delete(ctx context.Context, table string, id string) error {
 // Query performs a custom query and scans results into dest
 // dest should be a pointer to a slice of structs with db tags
 func (c *Client) Query(ctx context.Context, query string, dest interface{}, args ...interface{}) error {
-	err := c.db.SelectContext(ctx, dest, query, args...)
+	err := c.db.SelectContext(ctx, dest, c.db.Rebind(query), args...)
 	if err != nil {
 		return fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -351,7 +369,7 @@ func (c *Client) Query(ctx context.Context, query string, dest interface{}, args
 
 // Exec executes a query without returning any rows
 func (c *Client) Exec(ctx context.Context, query string, args ...interface{}) error {
-	_, err := c.db.ExecContext(ctx, query, args...)
+	_, err := c.db.ExecContext(ctx, c.db.Rebind(query), args...)
 	if err != nil {
 		return fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -414,7 +432,7 @@ func (t *transaction) Create(ctx context.Context, table string, data interface{}
 	)
 
 	_, err := t.tx.NamedExecContext(ctx, query, data)
-	return err
+	return translateError(err)
 }
 
 // Get retrieves a record by ID from the specified table within a transaction
@@ -512,7 +530,7 @@ func (t *transaction) Delete(ctx context.Context, table string, id string) error
 
 // Query performs a custom query within a transaction
 func (t *transaction) Query(ctx context.Context, query string, dest interface{}, args ...interface{}) error {
-	err := t.tx.SelectContext(ctx, dest, query, args...)
+	err := t.tx.SelectContext(ctx, dest, t.db.Rebind(query), args...)
 	if err != nil {
 		return fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -522,7 +540,7 @@ func (t *transaction) Query(ctx context.Context, query string, dest interface{},
 
 // Exec executes a query without returning any rows within a transaction
 func (t *transaction) Exec(ctx context.Context, query string, args ...interface{}) error {
-	_, err := t.tx.ExecContext(ctx, query, args...)
+	_, err := t.tx.ExecContext(ctx, t.db.Rebind(query), args...)
 	if err != nil {
 		return fmt.Errorf("failed to execute query: %w", err)
 	}
@@ -7,13 +7,15 @@ import (
 
 // Repository defines CRUD operations for database records
 type Repository interface {
+	// Create inserts a record, ErrDuplicateKey if it takes a unique key of another record
 	Create(ctx context.Context, table string, data interface{}) error
 	Get(ctx context.Context, table string, id string, dest interface{}) error
 	Update(ctx context.Context, table string, id string, data interface{}) error
 	Delete(ctx context.Context, table string, id string) error
 }
 
-// Querier defines custom query execution capabilities
+// Querier defines custom query execution capabilities. Queries take ? placeholders,
+// they are rebound to the bind style of the driver.
 type Querier interface {
 	Query(ctx context.Context, query string, dest interface{}, args ...interface{}) error
 	// Exec executes a query without returning any rows
@@ -0,0 +1,130 @@
+package fair
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ServerSeedSize is the number of random bytes in a server seed
+const ServerSeedSize = 32
+
+// chunksPerDigest is how many 4 byte values are taken from one HMAC-SHA256 digest
+const chunksPerDigest = sha256.Size / 4
+
+// Round is a provably fair commit-reveal round.
+// The server seed is drawn when the round is created and only its hash is published.
+// Players then contribute client seeds, and every draw is derived as
+// HMAC-SHA256(serverSeed, "<clientSeed>:<nonce>:<cursor>"). Revealing the server seed
+// afterwards lets anyone recompute all draws. Round implements rng.RNG.
+type Round struct {
+	serverSeed     []byte
+	ServerSeedHash string `json:"serverSeedHash"`
+	ClientSeed     string `json:"clientSeed"`
+	Nonce          int    `json:"nonce"`
+	mu             sync.Mutex
+}
+
+// Reveal is everything needed to verify a finished round
+type Reveal struct {
+	ServerSeed     string `json:"serverSeed"`
+	ServerSeedHash string `json:"serverSeedHash"`
+	ClientSeed     string `json:"clientSeed"`
+	Nonce          int    `json:"nonce"`
+}
+
+// NewRound commits to the next server seed of the room
+func NewRound(seeds *Seeds) *Round {
+	seed := seeds.next()
+	return &Round{
+		serverSeed:     seed,
+		ServerSeedHash: HashServerSeed(seed),
+	}
+}
+
+// SetClientSeed sets the combined client seed. It can only be set before the first draw.
+func (r *Round) SetClientSeed(clientSeed string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.Nonce > 0 {
+		return ErrRoundStarted
+	}
+	r.ClientSeed = clientSeed
+	return nil
+}
+
+// Intn derives the next value in [0, n)
+func (r *Round) Intn(n int) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	value := Derive(r.serverSeed, r.ClientSeed, r.Nonce, n)
+	r.Nonce++
+	return value
+}
+
+// Reveal discloses the server seed. Only call it once the round is over.
+func (r *Round) Reveal() Reveal {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return Reveal{
+		ServerSeed:     hex.EncodeToString(r.serverSeed),
+		ServerSeedHash: r.ServerSeedHash,
+		ClientSeed:     r.ClientSeed,
+		Nonce:          r.Nonce,
+	}
+}
+
+// HashServerSeed returns the published commitment for a server seed, hex(sha256(seed))
+func HashServerSeed(serverSeed []byte) string {
+	sum := sha256.Sum256(serverSeed)
+	return hex.EncodeToString(sum[:])
+}
+
+// Derive computes the value in [0, n) for the given nonce.
+// Each digest is split into big endian uint32 chunks; chunks that would bias the modulo are
+// rejected and the next one is used. If a whole digest is rejected the cursor is increased.
+func Derive(serverSeed []byte, clientSeed string, nonce int, n int) int {
+	if n <= 0 {
+		panic(fmt.Sprintf("fair: invalid range %d", n))
+	}
+
+	limit := uint64(1<<32) - uint64(1<<32)%uint64(n)
+	for cursor := 0; ; cursor++ {
+		mac := hmac.New(sha256.New, serverSeed)
+		fmt.Fprintf(mac, "%s:%d:%d", clientSeed, nonce, cursor)
+		digest := mac.Sum(nil)
+
+		for i := range chunksPerDigest {
+			value := uint64(binary.BigEndian.Uint32(digest[i*4:]))
+			if value < limit {
+				return int(value % uint64(n))
+			}
+		}
+	}
+}
+
+// ParseServerSeed decodes a revealed server seed and checks it against the commitment
+func ParseServerSeed(serverSeed, serverSeedHash string) ([]byte, error) {
+	seed, err := hex.DecodeString(serverSeed)
+	if err != nil {
+		return nil, ErrInvalidServerSeed
+	}
+	if !hmac.Equal([]byte(HashServerSeed(seed)), []byte(strings.ToLower(serverSeedHash))) {
+		return nil, ErrCommitmentMismatch
+	}
+	return seed, nil
+}
+
+var (
+	ErrRoundStarted       = errors.New("round already started")
+	ErrInvalidServerSeed  = errors.New("invalid server seed")
+	ErrCommitmentMismatch = errors.New("server seed does not match commitment")
+)
@@ -0,0 +1,115 @@
+package fair
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestRound_RevealVerifiesDraws(t *testing.T) {
+	round := NewRound(NewSeeds())
+	if err := round.SetClientSeed("alice:bob"); err != nil {
+		t.Fatalf("failed to set client seed: %v", err)
+	}
+
+	draws := make([]int, 20)
+	for i := range draws {
+		draws[i] = round.Intn(6)
+	}
+
+	reveal := round.Reveal()
+	if reveal.Nonce != len(draws) {
+		t.Errorf("expected nonce %d, got %d", len(draws), reveal.Nonce)
+	}
+
+	serverSeed, err := ParseServerSeed(reveal.ServerSeed, reveal.ServerSeedHash)
+	if err != nil {
+		t.Fatalf("revealed seed does not match commitment: %v", err)
+	}
+
+	for nonce, draw := range draws {
+		if expected := Derive(serverSeed, reveal.ClientSeed, nonce, 6); expected != draw {
+			t.Errorf("draw %d: expected %d, got %d", nonce, expected, draw)
+		}
+	}
+}
+
+func TestRound_ClientSeedLockedAfterFirstDraw(t *testing.T) {
+	round := NewRound(NewSeeds())
+	round.Intn(6)
+
+	if err := round.SetClientSeed("late"); !errors.Is(err, ErrRoundStarted) {
+		t.Errorf("expected ErrRoundStarted, got %v", err)
+	}
+}
+
+func TestRound_ClientSeedChangesDraws(t *testing.T) {
+	seeds := NewSeeds()
+	seed := []byte("server seed")
+	seeds.Queue(seed, seed)
+	a := NewRound(seeds)
+	b := NewRound(seeds)
+	a.SetClientSeed("a")
+	b.SetClientSeed("b")
+
+	same := true
+	for range 20 {
+		if a.Intn(1000) != b.Intn(1000) {
+			same = false
+		}
+	}
+	if same {
+		t.Errorf("different client seeds should yield different draws")
+	}
+}
+
+func TestParseServerSeed_RejectsWrongCommitment(t *testing.T) {
+	seeds := NewSeeds()
+	reveal := NewRound(seeds).Reveal()
+	other := NewRound(seeds).Reveal()
+
+	if _, err := ParseServerSeed(reveal.ServerSeed, other.ServerSeedHash); !errors.Is(err, ErrCommitmentMismatch) {
+		t.Errorf("expected ErrCommitmentMismatch, got %v", err)
+	}
+	if _, err := ParseServerSeed("not hex", reveal.ServerSeedHash); !errors.Is(err, ErrInvalidServerSeed) {
+		t.Errorf("expected ErrInvalidServerSeed, got %v", err)
+	}
+}
+
+func TestDerive_CoversRange(t *testing.T) {
+	seed := []byte("server seed")
+	counts := make([]int, 6)
+	for nonce := range 6000 {
+		counts[Derive(seed, "client", nonce, 6)]++
+	}
+
+	for face, count := range counts {
+		// expected 1000 per face, allow a generous margin
+		if count < 850 || count > 1150 {
+			t.Errorf("face %d drawn %d times out of 6000", face, count)
+		}
+	}
+}
+
+func TestSeeds_QueuedThenDrawn(t *testing.T) {
+	seeds := NewSeeds()
+	queued := []byte("recorded server seed")
+	seeds.Queue(queued)
+
+	first := NewRound(seeds).Reveal()
+	second := NewRound(seeds).Reveal()
+	if first.ServerSeedHash != HashServerSeed(queued) {
+		t.Errorf("expected the queued seed first, got commitment %s", first.ServerSeedHash)
+	}
+	if second.ServerSeedHash == first.ServerSeedHash {
+		t.Error("expected a new seed once the queue is empty")
+	}
+
+	drawn := seeds.Take()
+	if len(drawn) != 2 || !bytes.Equal(drawn[0], queued) || len(drawn[1]) != ServerSeedSize {
+		t.Errorf("expected both seeds to be taken, got %d", len(drawn))
+	}
+	if len(seeds.Take()) != 0 {
+		t.Error("expected taken seeds to be gone")
+	}
+}
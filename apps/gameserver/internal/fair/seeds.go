@@ -0,0 +1,53 @@
+package fair
+
+import (
+	"crypto/rand"
+	"sync"
+)
+
+// Seeds draws the server seeds of the rounds of a room from crypto/rand. A server seed must
+// not be predictable from anything a player can learn, so it is never derived from the room RNG.
+// The seeds drawn are kept until taken so journals can record them, a replay queues them again.
+type Seeds struct {
+	queued [][]byte
+	drawn  [][]byte
+	mu     sync.Mutex
+}
+
+// NewSeeds creates a source of server seeds
+func NewSeeds() *Seeds {
+	return &Seeds{}
+}
+
+// Queue hands out the given seeds before drawing new ones, in order
+func (s *Seeds) Queue(seeds ...[]byte) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.queued = append(s.queued, seeds...)
+}
+
+// Take returns the seeds drawn since the last call
+func (s *Seeds) Take() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	drawn := s.drawn
+	s.drawn = nil
+	return drawn
+}
+
+// next returns the next queued seed or draws a new one
+func (s *Seeds) next() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var seed []byte
+	if len(s.queued) > 0 {
+		seed, s.queued = s.queued[0], s.queued[1:]
+	} else {
+		seed = make([]byte, ServerSeedSize)
+		// never fails, crypto/rand crashes the program instead of returning weak randomness
+		_, _ = rand.Read(seed)
+	}
+	s.drawn = append(s.drawn, seed)
+	return seed
+}
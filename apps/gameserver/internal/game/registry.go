@@ -4,7 +4,12 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"gameserver/internal/account"
+	"gameserver/internal/clock"
 	"gameserver/internal/interfaces"
+	"gameserver/internal/journal"
+	"gameserver/internal/results"
+	"gameserver/internal/rng"
 	"github.com/rs/zerolog/log"
 	"maps"
 	"slices"
@@ -13,16 +18,74 @@ import (
 
 // Registry manages game registrations
 type Registry struct {
-	games map[string]interfaces.Game
-	mu    sync.RWMutex
+	games    map[string]interfaces.Game
+	mu       sync.RWMutex
+	recorder journal.Recorder
+	accounts *account.Service
+	results  *results.Bus
+
+	// takeovers holds the pending bot takeovers of disconnected seats by room and seat id
+	takeovers   map[string]clock.Timer
+	takeoversMu sync.Mutex
+
+	onGameStarted  func(room interfaces.Room)
+	onPlayerJoined func(room interfaces.Room, client interfaces.Client, name string)
+	onPlayerLeft   func(room interfaces.Room, client interfaces.Client)
+}
+
+// RegistryOption is a functional option for configuring Registry
+type RegistryOption func(*Registry)
+
+// WithRecorder journals every input the registry passes to a game
+func WithRecorder(recorder journal.Recorder) RegistryOption {
+	return func(r *Registry) {
+		r.recorder = recorder
+	}
+}
+
+// WithAccounts links clients with a verified identity to their player account when they join
+func WithAccounts(accounts *account.Service) RegistryOption {
+	return func(r *Registry) {
+		r.accounts = accounts
+	}
+}
+
+// WithResults publishes the results games report to the bus
+func WithResults(bus *results.Bus) RegistryOption {
+	return func(r *Registry) {
+		r.results = bus
+	}
+}
+
+// WithGameStartedCallback sets a callback to be called when the game in a room starts
+func WithGameStartedCallback(callback func(room interfaces.Room)) RegistryOption {
+	return func(r *Registry) {
+		r.onGameStarted = callback
+	}
+}
+
+// WithPlayerCallbacks sets callbacks to be called when a player or bot takes a seat, with the
+// name the game knows it by, and when a seat loses its connection by leaving or disconnecting
+func WithPlayerCallbacks(joined func(room interfaces.Room, client interfaces.Client, name string), left func(room interfaces.Room, client interfaces.Client)) RegistryOption {
+	return func(r *Registry) {
+		r.onPlayerJoined = joined
+		r.onPlayerLeft = left
+	}
 }
 
 // NewRegistry creates a new game registry
-func NewRegistry() *Registry {
+func NewRegistry(opts ...RegistryOption) *Registry {
 	log.Debug().Msg("game registry created")
-	return &Registry{
-		games: make(map[string]interfaces.Game),
+	r := &Registry{
+		games:     make(map[string]interfaces.Game),
+		takeovers: make(map[string]clock.Timer),
 	}
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	return r
 }
 
 // RegisterGame adds a game to the registry
@@ -72,18 +135,59 @@ func (r *Registry) HandleMessage(client interfaces.Client, msgType string, data
 		return err
 	}
 
-	return game.HandleMessage(client, room, msgType, data)
+	room.Lock()
+	defer room.Unlock()
+
+	observer, _ := client.(interfaces.ActionObserver)
+	var before string
+	if observer != nil {
+		before = journal.StateDigest(game, room)
+	}
+
+	client = seatOf(client, room)
+	entry := journal.Entry{Kind: journal.KindMessage, ClientID: client.ID(), Bot: client.IsBot(), Subject: subjectOf(client), MsgType: msgType, Data: data}
+	err = r.record(game, room, entry, func() error {
+		return game.HandleMessage(client, room, msgType, data)
+	})
+	if observer != nil && journal.StateDigest(game, room) != before {
+		observer.Acted()
+	}
+	return err
 }
 
 // InitializeRoom initializes a room with game-specific state
-func (r *Registry) InitializeRoom(ctx context.Context, room interfaces.Room, options json.RawMessage) error {
+func (r *Registry) InitializeRoom(ctx context.Context, room interfaces.Room, options json.RawMessage, random rng.RNG) error {
 	gameType := room.GameType()
 	game, err := r.GetGame(gameType)
 	if err != nil {
 		return err
 	}
 
-	return game.InitializeRoom(ctx, room, options)
+	if err = game.InitializeRoom(ctx, room, options, random); err != nil {
+		return err
+	}
+
+	if r.recorder != nil {
+		header := journal.Header{
+			RoomID:    room.ID(),
+			GameType:  gameType,
+			Options:   options,
+			CreatedAt: room.Clock().Now(),
+			// seeds of the rounds the game committed to right away
+			ServerSeeds: journal.EncodeSeeds(room.ServerSeeds().Take()),
+		}
+		if seeder, ok := random.(rng.Seeder); ok {
+			seed := seeder.Seed()
+			header.Seed = &seed
+		} else {
+			log.Warn().Str("roomId", room.ID()).Msg("room rng has no seed, journal will not be replayable")
+		}
+		r.recorder.Begin(header)
+	} else {
+		// nobody journals the server seeds
+		room.ServerSeeds().Take()
+	}
+	return nil
 }
 
 // HandleClientJoin notifies the game when a client joins
@@ -94,24 +198,90 @@ func (r *Registry) HandleClientJoin(client interfaces.Client, room interfaces.Ro
 		return err
 	}
 
+	r.resolveAccount(client, &options)
+
+	room.Lock()
+	defer room.Unlock()
+
 	// Join the room
 	if err = room.Join(client); err != nil {
 		log.Error().Err(err).Str("id", room.ID()).Msg("failed to join room")
 		return err
 	}
 
-	game.OnClientJoin(client, room, options)
-	return nil
+	client = seatOf(client, room)
+	entry := journal.Entry{Kind: journal.KindJoin, ClientID: client.ID(), Bot: client.IsBot(), Subject: subjectOf(client), PlayerName: options.PlayerName}
+	err = r.record(game, room, entry, func() error {
+		game.OnClientJoin(client, room, options)
+		return nil
+	})
+	if err == nil {
+		r.playerJoined(room, client, options.PlayerName)
+	}
+	return err
 }
 
-func (r *Registry) HandleAddBot(client interfaces.Client, room interfaces.Room) error {
+// playerJoined calls the player joined callback
+func (r *Registry) playerJoined(room interfaces.Room, client interfaces.Client, name string) {
+	if r.onPlayerJoined != nil {
+		r.onPlayerJoined(room, client, name)
+	}
+}
+
+// playerLeft calls the player left callback
+func (r *Registry) playerLeft(room interfaces.Room, client interfaces.Client) {
+	if r.onPlayerLeft != nil {
+		r.onPlayerLeft(room, client)
+	}
+}
+
+// resolveAccount sets the account id of an identified client on the join options.
+// The account's display name replaces the one the client sent, so players are known by the same name in every game.
+func (r *Registry) resolveAccount(client interfaces.Client, options *interfaces.CreateRoomOptions) {
+	if r.accounts == nil || client.IsBot() || client.Identity() == nil {
+		return
+	}
+
+	acc, err := r.accounts.ForIdentity(context.Background(), client.Identity(), options.PlayerName)
+	if err != nil {
+		log.Error().Err(err).Str("clientId", client.ID()).Msg("failed to resolve account, joining anonymously")
+		return
+	}
+
+	options.AccountID = acc.ID
+	options.PlayerName = acc.DisplayName
+}
+
+// ReportStarted notifies the game started callback
+func (r *Registry) ReportStarted(room interfaces.Room) {
+	log.Info().Str("roomId", room.ID()).Str("gameType", room.GameType()).Msg("game started")
+	if r.onGameStarted != nil {
+		r.onGameStarted(room)
+	}
+}
+
+// ReportResult completes the result of a finished game and publishes it
+func (r *Registry) ReportResult(room interfaces.Room, result interfaces.GameResult) {
+	result.RoomID = room.ID()
+	result.GameType = room.GameType()
+	results.Normalize(&result, room.Clock().Now())
+
+	log.Info().Str("roomId", result.RoomID).Str("gameType", result.GameType).Int("participants", len(result.Participants)).Dur("duration", result.Duration).Msg("game finished")
+	if r.results == nil {
+		return
+	}
+	r.results.Publish(context.Background(), result)
+}
+
+// HandleAddBot lets the game create a bot playing the strategy selected by the options and joins it
+func (r *Registry) HandleAddBot(client interfaces.Client, room interfaces.Room, options interfaces.BotOptions) error {
 	gameType := room.GameType()
 	game, err := r.GetGame(gameType)
 	if err != nil {
 		return err
 	}
 
-	botClient, botName, err := game.OnBotAdd(client, client.Room(), r)
+	botClient, botName, err := game.OnBotAdd(seatOf(client, room), client.Room(), r, options)
 	if err != nil {
 		return err
 	}
@@ -129,19 +299,87 @@ func (r *Registry) HandleClientLeave(client interfaces.Client, room interfaces.R
 		return err
 	}
 
-	game.OnClientLeave(client, room)
-	return nil
+	room.Lock()
+	defer room.Unlock()
+
+	client = seatOf(client, room)
+	entry := journal.Entry{Kind: journal.KindLeave, ClientID: client.ID(), Bot: client.IsBot()}
+	err = r.record(game, room, entry, func() error {
+		game.OnClientLeave(client, room)
+		return nil
+	})
+	if err == nil {
+		r.playerLeft(room, client)
+	}
+	return err
 }
 
-// HandleClientReconnect notifies the game when a client leaves
-func (r *Registry) HandleClientReconnect(client interfaces.Client, room interfaces.Room, oldClientId string) error {
+// HandleClientReconnect binds a client to its seat in the room again and notifies the game
+func (r *Registry) HandleClientReconnect(client interfaces.Client, room interfaces.Room, seatID string) error {
 	gameType := room.GameType()
 	game, err := r.GetGame(gameType)
 	if err != nil {
 		return err
 	}
 
-	return game.OnClientReconnect(client, room, oldClientId)
+	// the player made it back in time, no bot has to take over
+	r.cancelTakeover(room, seatID)
+
+	room.Lock()
+	defer room.Unlock()
+
+	if err = room.Rejoin(client, seatID); err != nil {
+		log.Error().Err(err).Str("id", room.ID()).Str("seatId", seatID).Msg("failed to rejoin room")
+		return err
+	}
+
+	seat := seatOf(client, room)
+	entry := journal.Entry{Kind: journal.KindReconnect, ClientID: seat.ID(), Bot: seat.IsBot(), Subject: subjectOf(seat)}
+	err = r.record(game, room, entry, func() error {
+		return game.OnClientReconnect(seat, room)
+	})
+	if err == nil {
+		r.playerJoined(room, seat, "")
+	}
+	return err
+}
+
+// seatOf returns the seat of a client in the room, games are only handed seats
+func seatOf(client interfaces.Client, room interfaces.Room) interfaces.Client {
+	if seat, ok := room.Seat(client); ok {
+		return seat
+	}
+	return client
+}
+
+// subjectOf returns the subject of a client's identity, the only part of it that is journaled
+func subjectOf(client interfaces.Client) string {
+	if identity := client.Identity(); identity != nil {
+		return identity.Subject
+	}
+	return ""
+}
+
+// record runs a game callback and journals it together with the state digests around it
+func (r *Registry) record(game interfaces.Game, room interfaces.Room, entry journal.Entry, apply func() error) error {
+	if r.recorder == nil {
+		err := apply()
+		room.ServerSeeds().Take()
+		return err
+	}
+
+	entry.At = room.Clock().Now()
+	entry.StateBefore = journal.StateDigest(game, room)
+	err := apply()
+	entry.StateAfter = journal.StateDigest(game, room)
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	// including the seeds drawn by delayed game logic since the previous entry
+	entry.ServerSeeds = journal.EncodeSeeds(room.ServerSeeds().Take())
+
+	r.recorder.Append(room.ID(), entry)
+	return err
 }
 
 // ListGames returns a list of all registered game types
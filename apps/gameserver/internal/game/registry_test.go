@@ -0,0 +1,95 @@
+package game_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"gameserver/games/dicegame"
+	"gameserver/internal/account"
+	"gameserver/internal/database/sql"
+	"gameserver/internal/game"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/testicles"
+)
+
+// playerEvents records the player callbacks of a registry
+type playerEvents struct {
+	events []string
+	mu     sync.Mutex
+}
+
+func (p *playerEvents) options() game.RegistryOption {
+	return game.WithPlayerCallbacks(func(room interfaces.Room, client interfaces.Client, name string) {
+		p.add(fmt.Sprintf("joined %s %q bot=%v", client.ID(), name, client.IsBot()))
+	}, func(room interfaces.Room, client interfaces.Client) {
+		p.add(fmt.Sprintf("left %s bot=%v", client.ID(), client.IsBot()))
+	})
+}
+
+func (p *playerEvents) add(event string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+}
+
+func (p *playerEvents) take() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	events := p.events
+	p.events = nil
+	return events
+}
+
+func newAccounts(t *testing.T) *account.Service {
+	t.Helper()
+	ctx := context.Background()
+	db, err := sql.New(ctx, filepath.Join(t.TempDir(), "accounts.sqlite"), sql.WithAllowedTables([]string{"accounts", "account_game_stats"}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if err := account.InitializeSchema(ctx, db); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+	accounts := account.NewService(account.NewSQLStore(db))
+	t.Cleanup(func() { accounts.Close() })
+	return accounts
+}
+
+func TestRegistry_PlayerCallbacks(t *testing.T) {
+	var events playerEvents
+	helper := testicles.NewTestHelper(t, events.options(), game.WithAccounts(newAccounts(t)))
+	dicegame.RegisterDiceGame(helper.Registry)
+
+	host := helper.CreateClient("player-0")
+	host.SetIdentity(&interfaces.Identity{Subject: "user-0", Issuer: "https://issuer.test", Name: "Alice"})
+	helper.CreateRoom(host, "dicegame", "alice-sent-this")
+	guest := helper.CreateClient("player-1")
+	helper.JoinRoom(guest, helper.RoomID, "Bob")
+	if got, want := events.take(), []string{`joined player-0 "Alice" bot=false`, `joined player-1 "Bob" bot=false`}; !slices.Equal(got, want) {
+		t.Errorf("Expected joins with the account name, got %v", got)
+	}
+
+	helper.SendMessage("player-0", "add_bot", nil)
+	if got := events.take(); len(got) != 1 || !strings.HasSuffix(got[0], "bot=true") {
+		t.Errorf("Expected the bot to join, got %v", got)
+	}
+
+	testRoom, err := helper.GetRoom()
+	if err != nil {
+		t.Fatalf("Failed to get room: %v", err)
+	}
+	testRoom.Disconnect(guest)
+	if got, want := events.take(), []string{"left player-1 bot=false"}; !slices.Equal(got, want) {
+		t.Errorf("Expected the disconnect to leave, got %v", got)
+	}
+
+	helper.SendMessage("player-0", "leave_room", nil)
+	if got, want := events.take(), []string{"left player-0 bot=false"}; !slices.Equal(got, want) {
+		t.Errorf("Expected the player to leave, got %v", got)
+	}
+}
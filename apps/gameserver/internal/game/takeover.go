@@ -0,0 +1,98 @@
+package game
+
+import (
+	"gameserver/internal/clock"
+	"gameserver/internal/interfaces"
+
+	"github.com/rs/zerolog/log"
+)
+
+// HandleClientDisconnect notifies the game when the connection of a seated client dropped.
+// Games that implement BotTakeover get a bot on the seat once its grace period ran out.
+func (r *Registry) HandleClientDisconnect(client interfaces.Client, room interfaces.Room) error {
+	game, err := r.GetGame(room.GameType())
+	if err != nil {
+		return err
+	}
+	r.playerLeft(room, seatOf(client, room))
+
+	takeover, ok := game.(interfaces.BotTakeover)
+	if !ok {
+		return nil
+	}
+	grace := takeover.TakeoverGrace(room)
+	if grace <= 0 {
+		return nil
+	}
+
+	seat := seatOf(client, room)
+	key := takeoverKey(room, seat.ID())
+
+	r.takeoversMu.Lock()
+	defer r.takeoversMu.Unlock()
+
+	if pending, exists := r.takeovers[key]; exists {
+		pending.Stop()
+	}
+	var timer clock.Timer
+	timer = room.Clock().AfterFunc(grace, func() {
+		if r.claimTakeover(key, timer) {
+			r.takeover(takeover, seat, room)
+		}
+	})
+	r.takeovers[key] = timer
+
+	log.Debug().Str("roomId", room.ID()).Str("seatId", seat.ID()).Dur("grace", grace).Msg("bot takeover scheduled")
+	return nil
+}
+
+// takeover binds a bot to a seat that is still empty after its grace period
+func (r *Registry) takeover(game interfaces.BotTakeover, seat interfaces.Client, room interfaces.Room) {
+	if room.IsClosed() {
+		return
+	}
+	if _, connected := room.Clients()[seat.ID()]; connected {
+		return
+	}
+
+	bot, err := game.TakeoverBot(seat, room, r)
+	if err != nil {
+		log.Warn().Err(err).Str("roomId", room.ID()).Str("seatId", seat.ID()).Msg("no bot can take over the seat")
+		return
+	}
+
+	if err = r.HandleClientReconnect(bot, room, seat.ID()); err != nil {
+		log.Error().Err(err).Str("roomId", room.ID()).Str("seatId", seat.ID()).Msg("bot failed to take over the seat")
+		bot.Close()
+		return
+	}
+	log.Info().Str("roomId", room.ID()).Str("seatId", seat.ID()).Msg("bot took over the seat")
+}
+
+// claimTakeover removes a takeover that is due, unless it was cancelled or replaced meanwhile
+func (r *Registry) claimTakeover(key string, timer clock.Timer) bool {
+	r.takeoversMu.Lock()
+	defer r.takeoversMu.Unlock()
+
+	if r.takeovers[key] != timer {
+		return false
+	}
+	delete(r.takeovers, key)
+	return true
+}
+
+// cancelTakeover stops the pending takeover of a seat
+func (r *Registry) cancelTakeover(room interfaces.Room, seatID string) {
+	r.takeoversMu.Lock()
+	defer r.takeoversMu.Unlock()
+
+	key := takeoverKey(room, seatID)
+	if pending, exists := r.takeovers[key]; exists {
+		pending.Stop()
+		delete(r.takeovers, key)
+	}
+}
+
+func takeoverKey(room interfaces.Room, seatID string) string {
+	return room.ID() + "/" + seatID
+}
@@ -3,7 +3,11 @@ package interfaces
 import (
 	"context"
 	"encoding/json"
+	"gameserver/internal/clock"
+	"gameserver/internal/fair"
 	"gameserver/internal/protocol"
+	"gameserver/internal/rng"
+	"time"
 )
 
 // Environment represents the application environment, development or production
@@ -22,6 +26,19 @@ type Client interface {
 	SetRoom(room Room)
 	Close()
 	IsBot() bool
+	// Identity returns the verified identity of the client, nil if it is anonymous
+	Identity() *Identity
+	SetIdentity(identity *Identity)
+}
+
+// Identity is a user identity that was verified from a signed ID token.
+// Games can trust it, unlike names or ids sent in message payloads.
+type Identity struct {
+	Subject string `json:"sub"`
+	Issuer  string `json:"iss"`
+	Name    string `json:"name,omitempty"`
+	Email   string `json:"email,omitempty"`
+	Picture string `json:"picture,omitempty"`
 }
 
 type Room interface {
@@ -29,13 +46,30 @@ type Room interface {
 	GameType() string
 	IsClosed() bool
 	Join(client Client) error
+	// Rejoin binds client to the seat it had in the room, the seat id is what the room
+	// handed out as clientId when the seat was taken
+	Rejoin(client Client, seatID string) error
 	Leave(client Client)
+	// Disconnect unbinds a client whose connection dropped. Unlike after Leave, the game
+	// may let a bot play its seat until the client reconnects.
+	Disconnect(client Client)
+	// Seat returns the seat a client is bound to. Games are handed seats, whose ids do
+	// not change when a player reconnects.
+	Seat(client Client) (Client, bool)
 	SendTo(message *protocol.Response, clientId string)
 	Broadcast(message *protocol.Response, exclude ...Client)
 	BroadcastTo(message *protocol.Response, clients ...Client)
 	Clients() map[string]Client
 	State() interface{}
 	SetState(state interface{})
+	// Clock is what games take the time from and schedule delayed work with, so tests can fake it
+	Clock() clock.Clock
+	// ServerSeeds draws the secret seeds of provably fair rounds, journals record the seeds drawn
+	ServerSeeds() *fair.Seeds
+	// Lock and Unlock serialize what reaches the game of the room. The registry holds the lock
+	// while it handles a message, join, leave or reconnect, games while they run delayed work.
+	Lock()
+	Unlock()
 	Close()
 }
 
@@ -44,6 +78,82 @@ type CreateRoomOptions struct {
 	PlayerName string          `json:"playerName"`
 	RoomID     *string         `json:"roomId,omitempty"`
 	Options    json.RawMessage `json:"options,omitempty"`
+	// AccountID is set by the registry for clients with a verified identity, never by the client
+	AccountID string `json:"-"`
+}
+
+// Difficulty is how well a bot plays
+type Difficulty string
+
+const (
+	DifficultyEasy   Difficulty = "easy"
+	DifficultyNormal Difficulty = "normal"
+	DifficultyHard   Difficulty = "hard"
+)
+
+// BotOptions is the add_bot payload. A strategy is picked by name, or else by difficulty;
+// without either the game plays its default strategy.
+type BotOptions struct {
+	Strategy   string     `json:"strategy,omitempty"`
+	Difficulty Difficulty `json:"difficulty,omitempty"`
+}
+
+// DefaultTakeoverGrace is how long a seat waits for its player if a room enables bot takeover
+// without a grace period
+const DefaultTakeoverGrace = 15 * time.Second
+
+// TakeoverOptions are the room options of games that implement BotTakeover
+type TakeoverOptions struct {
+	// BotTakeover lets a bot play the seat of a disconnected player until it reconnects
+	BotTakeover bool `json:"botTakeover,omitempty"`
+	// BotTakeoverGrace is how many seconds a seat waits for its player before a bot takes over
+	BotTakeoverGrace float64 `json:"botTakeoverGrace,omitempty"`
+}
+
+// Grace returns how long a disconnected seat waits for its player, 0 if bots do not take over
+func (o TakeoverOptions) Grace() time.Duration {
+	if !o.BotTakeover {
+		return 0
+	}
+	if o.BotTakeoverGrace <= 0 {
+		return DefaultTakeoverGrace
+	}
+	return time.Duration(o.BotTakeoverGrace * float64(time.Second))
+}
+
+// Outcome is how a finished game ended for one player
+type Outcome string
+
+const (
+	OutcomeWin  Outcome = "win"
+	OutcomeLoss Outcome = "loss"
+	OutcomeDraw Outcome = "draw"
+	// OutcomeCompleted is for games without winners, it only counts as played
+	OutcomeCompleted Outcome = "completed"
+)
+
+// GameResult is how a finished game ended, reported by every game in the same shape
+type GameResult struct {
+	RoomID       string                 `json:"roomId"`   // set by the registry
+	GameType     string                 `json:"gameType"` // set by the registry
+	StartedAt    time.Time              `json:"startedAt"`
+	EndedAt      time.Time              `json:"endedAt"`  // defaults to the time the result is reported
+	Duration     time.Duration          `json:"duration"` // derived from StartedAt and EndedAt
+	Participants []Participant          `json:"participants"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Participant is one player of a finished game
+type Participant struct {
+	ClientID  string `json:"clientId"`
+	AccountID string `json:"accountId,omitempty"` // empty for anonymous players and bots
+	Name      string `json:"name"`
+	Bot       bool   `json:"bot,omitempty"`
+	// Placement is 1 for the winner, players with the same placement tied. 0 means the game has no placements.
+	Placement int     `json:"placement"`
+	Score     float64 `json:"score"`
+	// Outcome is derived from the placements if the game leaves it empty
+	Outcome Outcome `json:"outcome"`
 }
 
 type ClientManager interface {
@@ -57,29 +167,75 @@ type RoomManager interface {
 	GetRoom(roomID string) (Room, error)
 	RemoveRoom(roomID string)
 	GetAllRoomsByGameType(gameType string) []Room
+	ListRooms() []Room
 }
 
 // Game defines the interface for game implementations
 type Game interface {
 	Type() string
 	HandleMessage(client Client, room Room, msgType string, data []byte) error
-	InitializeRoom(ctx context.Context, room Room, options json.RawMessage) error
+	// InitializeRoom sets up the room state. All randomness of the room must be drawn from random.
+	InitializeRoom(ctx context.Context, room Room, options json.RawMessage, random rng.RNG) error
 	OnClientJoin(client Client, room Room, options CreateRoomOptions)
 	OnClientLeave(client Client, room Room)
-	OnClientReconnect(client Client, room Room, oldClientId string) error
-	OnBotAdd(client Client, room Room, registry GameRegistry) (Client, string, error)
+	// OnClientReconnect tells the game that a new connection was bound to the seat of client
+	OnClientReconnect(client Client, room Room) error
+	OnBotAdd(client Client, room Room, registry GameRegistry, options BotOptions) (Client, string, error)
+}
+
+// StateSnapshotter can be implemented by games whose room state holds values that
+// differ between otherwise identical runs (timestamps, contexts). The snapshot is
+// what gets fingerprinted when journaling and replaying rooms.
+type StateSnapshotter interface {
+	SnapshotState(room Room) interface{}
+}
+
+// TurnKeeper can be implemented by turn based games. External bots have to act within
+// their time limit while it is their turn.
+type TurnKeeper interface {
+	// CurrentTurn returns the id of the client whose turn it is, "" if it is nobody's
+	CurrentTurn(room Room) string
+}
+
+// BotTakeover can be implemented by games whose bots can play the seat of a disconnected
+// player. Once the grace period ran out the bot is bound to the seat like a reconnecting
+// client, and the player takes the seat back with a reconnect.
+type BotTakeover interface {
+	// TakeoverGrace returns how long a disconnected seat waits for its player, 0 if bots do not take over in the room
+	TakeoverGrace(room Room) time.Duration
+	// TakeoverBot returns a bot to play the seat, it has to use the seat id as its own
+	TakeoverBot(seat Client, room Room, registry GameRegistry) (Client, error)
+}
+
+// RemoteBot is implemented by bots played by another process. Unlike in-process bots
+// they keep their room open while they are connected, like players do.
+type RemoteBot interface {
+	Client
+	Remote() bool
+}
+
+// ActionObserver can be implemented by clients that want to know when a message they sent
+// was accepted by their game, i.e. changed the state of their room
+type ActionObserver interface {
+	Acted()
 }
 
 type GameRegistry interface {
 	RegisterGame(game Game)
 	GetGame(gameType string) (Game, error)
 	HasGame(gameType string) bool
-	InitializeRoom(ctx context.Context, room Room, options json.RawMessage) error
+	InitializeRoom(ctx context.Context, room Room, options json.RawMessage, random rng.RNG) error
 	HandleMessage(client Client, msgType string, data []byte) error
 	HandleClientJoin(client Client, room Room, options CreateRoomOptions) error
 	HandleClientLeave(client Client, room Room) error
-	HandleClientReconnect(client Client, room Room, oldClientId string) error
-	HandleAddBot(client Client, room Room) error
+	HandleClientReconnect(client Client, room Room, seatID string) error
+	// HandleClientDisconnect tells the game that the connection of a seated client dropped
+	HandleClientDisconnect(client Client, room Room) error
+	HandleAddBot(client Client, room Room, options BotOptions) error
+	// ReportStarted tells everything interested in room lifecycles that the game in the room started
+	ReportStarted(room Room)
+	// ReportResult publishes the result of a finished game to everything subscribed to game results
+	ReportResult(room Room, result GameResult)
 }
 
 type M map[string]interface{}
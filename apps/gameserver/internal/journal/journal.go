@@ -0,0 +1,186 @@
+package journal
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/rng"
+	"io"
+	"os"
+	"slices"
+	"time"
+)
+
+// Kind describes what happened to a room in a journal entry
+type Kind string
+
+const (
+	KindJoin      Kind = "join"
+	KindLeave     Kind = "leave"
+	KindReconnect Kind = "reconnect"
+	KindMessage   Kind = "message"
+)
+
+// Header describes the room a journal belongs to and how it was initialized
+type Header struct {
+	RoomID    string          `json:"roomId"`
+	GameType  string          `json:"gameType"`
+	Options   json.RawMessage `json:"options,omitempty"`
+	Seed      *rng.Seed       `json:"seed,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+	// ServerSeeds are the server seeds of provably fair rounds drawn while the room was initialized, hex encoded
+	ServerSeeds []string `json:"serverSeeds,omitempty"`
+}
+
+// Entry is a single recorded input to a game together with the state digests around it
+type Entry struct {
+	At          time.Time            `json:"at"`
+	Kind        Kind                 `json:"kind"`
+	ClientID    string               `json:"clientId"` // the seat id
+	Bot         bool                 `json:"bot,omitempty"`
+	Subject     string               `json:"subject,omitempty"`  // of the client's identity, journals hold no other personal data
+	Identity    *interfaces.Identity `json:"identity,omitempty"` // only set by journals recorded before Subject
+	PlayerName  string               `json:"playerName,omitempty"`
+	OldClientID string               `json:"oldClientId,omitempty"` // only set by journals recorded before seats
+	MsgType     string               `json:"msgType,omitempty"`
+	Data        json.RawMessage      `json:"data,omitempty"`
+	Error       string               `json:"error,omitempty"`
+	StateBefore string               `json:"stateBefore"`
+	StateAfter  string               `json:"stateAfter"`
+	// ServerSeeds are the server seeds drawn since the previous entry, hex encoded. A replay draws
+	// them again in order, they can't be derived from the room seed.
+	ServerSeeds []string `json:"serverSeeds,omitempty"`
+}
+
+// Journal is the full recording of a single room
+type Journal struct {
+	Header  Header  `json:"header"`
+	Entries []Entry `json:"entries"`
+}
+
+// Recorder receives room journals while games are being played
+type Recorder interface {
+	Begin(header Header)
+	Append(roomID string, entry Entry)
+}
+
+// EncodeSeeds hex encodes server seeds for a journal
+func EncodeSeeds(seeds [][]byte) []string {
+	if len(seeds) == 0 {
+		return nil
+	}
+	encoded := make([]string, len(seeds))
+	for i, seed := range seeds {
+		encoded[i] = hex.EncodeToString(seed)
+	}
+	return encoded
+}
+
+// ServerSeeds returns every server seed recorded in the journal in the order they were drawn
+func (j *Journal) ServerSeeds() ([][]byte, error) {
+	encoded := slices.Clone(j.Header.ServerSeeds)
+	for _, entry := range j.Entries {
+		encoded = append(encoded, entry.ServerSeeds...)
+	}
+
+	seeds := make([][]byte, len(encoded))
+	for i, seed := range encoded {
+		decoded, err := hex.DecodeString(seed)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidServerSeed, err)
+		}
+		seeds[i] = decoded
+	}
+	return seeds, nil
+}
+
+// Digest returns a stable fingerprint of a game state
+func Digest(state interface{}) string {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return "unmarshalable:" + err.Error()
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// StateDigest fingerprints the room state the way the game wants it compared
+func StateDigest(game interfaces.Game, room interfaces.Room) string {
+	if snapshotter, ok := game.(interfaces.StateSnapshotter); ok {
+		return Digest(snapshotter.SnapshotState(room))
+	}
+	return Digest(room.State())
+}
+
+// Read parses a journal in the JSON lines format written by FileStore.
+// The first line is the header, every following line is an entry.
+func Read(r io.Reader) (*Journal, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		return nil, ErrEmptyJournal
+	}
+
+	j := &Journal{}
+	if err := json.Unmarshal(scanner.Bytes(), &j.Header); err != nil {
+		return nil, fmt.Errorf("invalid journal header: %w", err)
+	}
+
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, fmt.Errorf("invalid journal entry %d: %w", len(j.Entries), err)
+		}
+		j.Entries = append(j.Entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return j, nil
+}
+
+// Load reads a journal file from disk
+func Load(path string) (*Journal, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Read(f)
+}
+
+// Write serializes a journal in the JSON lines format
+func Write(w io.Writer, j *Journal) error {
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(j.Header); err != nil {
+		return err
+	}
+	for _, entry := range j.Entries {
+		if err := enc.Encode(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	ErrEmptyJournal      = errors.New("journal is empty")
+	ErrJournalNotFound   = errors.New("journal not found")
+	ErrInvalidServerSeed = errors.New("invalid server seed in journal")
+)
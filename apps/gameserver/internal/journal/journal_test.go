@@ -0,0 +1,67 @@
+package journal
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestJournal(t *testing.T) {
+	header := Header{RoomID: "room-1", GameType: "dicegame", Options: json.RawMessage(`{"target":3000}`)}
+	entries := []Entry{
+		{Kind: KindJoin, ClientID: "player-0", PlayerName: "Hans"},
+		{Kind: KindMessage, ClientID: "player-0", MsgType: "roll", Error: "not your turn"},
+	}
+
+	t.Run("write and read roundtrip", func(t *testing.T) {
+		var buf bytes.Buffer
+		if err := Write(&buf, &Journal{Header: header, Entries: entries}); err != nil {
+			t.Fatalf("failed to write journal: %v", err)
+		}
+
+		j, err := Read(&buf)
+		if err != nil {
+			t.Fatalf("failed to read journal: %v", err)
+		}
+		if j.Header.RoomID != "room-1" || j.Header.GameType != "dicegame" {
+			t.Errorf("unexpected header: %+v", j.Header)
+		}
+		if len(j.Entries) != 2 || j.Entries[1].Error != "not your turn" {
+			t.Errorf("unexpected entries: %+v", j.Entries)
+		}
+	})
+
+	t.Run("read empty journal", func(t *testing.T) {
+		if _, err := Read(&bytes.Buffer{}); err != ErrEmptyJournal {
+			t.Errorf("expected ErrEmptyJournal, got %v", err)
+		}
+	})
+
+	t.Run("file store appends entries", func(t *testing.T) {
+		store, err := NewFileStore(t.TempDir())
+		if err != nil {
+			t.Fatalf("failed to create file store: %v", err)
+		}
+
+		store.Begin(header)
+		for _, entry := range entries {
+			store.Append(header.RoomID, entry)
+		}
+
+		j, err := Load(store.Path(header.RoomID))
+		if err != nil {
+			t.Fatalf("failed to load journal: %v", err)
+		}
+		if len(j.Entries) != len(entries) {
+			t.Errorf("expected %d entries, got %d", len(entries), len(j.Entries))
+		}
+	})
+
+	t.Run("digest is stable for maps", func(t *testing.T) {
+		a := Digest(map[string]int{"a": 1, "b": 2})
+		b := Digest(map[string]int{"b": 2, "a": 1})
+		if a != b {
+			t.Errorf("expected equal digests, got %s and %s", a, b)
+		}
+	})
+}
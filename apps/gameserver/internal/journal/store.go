@@ -0,0 +1,113 @@
+package journal
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// MemoryStore keeps journals in memory, mainly for tests
+type MemoryStore struct {
+	journals map[string]*Journal
+	mu       sync.RWMutex
+}
+
+// NewMemoryStore creates an empty in-memory journal store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		journals: make(map[string]*Journal),
+	}
+}
+
+// Begin starts a new journal for a room, replacing any previous one
+func (s *MemoryStore) Begin(header Header) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.journals[header.RoomID] = &Journal{Header: header, Entries: make([]Entry, 0)}
+}
+
+// Append adds an entry to the room's journal
+func (s *MemoryStore) Append(roomID string, entry Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, exists := s.journals[roomID]
+	if !exists {
+		log.Warn().Str("roomId", roomID).Msg("journal entry for unknown room dropped")
+		return
+	}
+	j.Entries = append(j.Entries, entry)
+}
+
+// Get returns a copy of the journal for a room
+func (s *MemoryStore) Get(roomID string) (*Journal, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	j, exists := s.journals[roomID]
+	if !exists {
+		return nil, ErrJournalNotFound
+	}
+
+	return &Journal{
+		Header:  j.Header,
+		Entries: append([]Entry(nil), j.Entries...),
+	}, nil
+}
+
+// FileStore appends journals as JSON lines to <dir>/<roomId>.jsonl.
+// Every entry is written immediately, so a crash still leaves a usable journal.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore creates a store writing journals to the given directory
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Path returns the journal file path for a room
+func (s *FileStore) Path(roomID string) string {
+	return filepath.Join(s.dir, filepath.Base(roomID)+".jsonl")
+}
+
+// Begin creates (or truncates) the room's journal file and writes the header
+func (s *FileStore) Begin(header Header) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.Create(s.Path(header.RoomID))
+	if err != nil {
+		log.Error().Err(err).Str("roomId", header.RoomID).Msg("failed to create journal")
+		return
+	}
+	defer f.Close()
+
+	if err := json.NewEncoder(f).Encode(header); err != nil {
+		log.Error().Err(err).Str("roomId", header.RoomID).Msg("failed to write journal header")
+	}
+}
+
+// Append writes an entry to the room's journal file
+func (s *FileStore) Append(roomID string, entry Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.Path(roomID), os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		log.Error().Err(err).Str("roomId", roomID).Msg("failed to open journal")
+		return
+	}
+	defer f.Close()
+
+	if err := json.NewEncoder(f).Encode(entry); err != nil {
+		log.Error().Err(err).Str("roomId", roomID).Msg("failed to write journal entry")
+	}
+}
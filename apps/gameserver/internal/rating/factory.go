@@ -0,0 +1,64 @@
+package rating
+
+import (
+	"context"
+	"gameserver/internal/account"
+	"gameserver/internal/database/sql"
+	"gameserver/internal/interfaces"
+	"os"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Factory creates the rating service for an environment
+type Factory struct {
+	env interfaces.Environment
+}
+
+// NewFactory creates a new rating service factory
+func NewFactory(env interfaces.Environment) *Factory {
+	return &Factory{
+		env: env,
+	}
+}
+
+// CreateService connects to the accounts database (ACCOUNTS_DATABASE_URL, SQLite in development) and
+// prepares the schema. RATING_SYSTEM selects elo (default) or glicko2.
+func (f *Factory) CreateService(ctx context.Context, opts ...ServiceOption) (*Service, error) {
+	dbURL := os.Getenv("ACCOUNTS_DATABASE_URL")
+	if dbURL == "" {
+		// Default to SQLite in development
+		if f.env == interfaces.Development {
+			dbURL = "file:./db.sqlite?cache=shared&mode=rwc"
+		} else {
+			return nil, account.ErrNotConfigured
+		}
+	}
+
+	db, err := sql.New(ctx, dbURL, sql.WithAllowedTables([]string{ratingsTable, historyTable}))
+	if err != nil {
+		return nil, err
+	}
+
+	// leaderboards join the accounts, make sure they exist even if ratings start first
+	if err := account.InitializeSchema(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	if err := InitializeSchema(ctx, db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if name := os.Getenv("RATING_SYSTEM"); name != "" {
+		system := SystemByName(name)
+		if system == nil {
+			db.Close()
+			return nil, ErrUnknownSystem
+		}
+		opts = append([]ServiceOption{WithSystem(system)}, opts...)
+	}
+
+	log.Info().Str("driver", db.Driver()).Msg("SQL database client initialized for ratings")
+	return NewService(NewSQLStore(db), opts...), nil
+}
@@ -0,0 +1,139 @@
+package rating
+
+import (
+	"encoding/json"
+	"errors"
+	"gameserver/internal/account"
+	"gameserver/internal/auth"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// NewHTTPHandler serves the leaderboards and rating history:
+//
+//	GET /leaderboards/{gameType}?period=global|weekly|friends&page=1&pageSize=20&friends=id1,id2
+//	GET /ratings/{accountId}/{gameType}   current rating with its latest changes
+//
+// The friends leaderboard includes the requesting player if a bearer ID token is sent.
+func NewHTTPHandler(service *Service, accounts *account.Service, authenticator auth.Authenticator) http.Handler {
+	h := &httpHandler{service: service, accounts: accounts, authenticator: authenticator}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /leaderboards/{gameType}", h.getLeaderboard)
+	mux.HandleFunc("GET /ratings/{accountId}/{gameType}", h.getRating)
+	return mux
+}
+
+type httpHandler struct {
+	service       *Service
+	accounts      *account.Service
+	authenticator auth.Authenticator
+}
+
+// requesterAccountID resolves the account of the request's ID token, empty if there is none
+func (h *httpHandler) requesterAccountID(r *http.Request) string {
+	token := auth.TokenFromRequest(r)
+	if token == "" || h.authenticator == nil || h.accounts == nil {
+		return ""
+	}
+
+	identity, err := h.authenticator.Authenticate(r.Context(), token)
+	if err != nil {
+		return ""
+	}
+	acc, err := h.accounts.ForIdentity(r.Context(), identity, "")
+	if err != nil {
+		return ""
+	}
+	return acc.ID
+}
+
+func (h *httpHandler) getLeaderboard(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	query := LeaderboardQuery{
+		GameType: r.PathValue("gameType"),
+		Period:   Period(params.Get("period")),
+	}
+	query.Page, _ = strconv.Atoi(params.Get("page"))
+	query.PageSize, _ = strconv.Atoi(params.Get("pageSize"))
+	if friends := params.Get("friends"); friends != "" {
+		query.Friends = strings.Split(friends, ",")
+	}
+	if query.Period == PeriodFriends {
+		query.Friends = WithSelf(query.Friends, h.requesterAccountID(r))
+	}
+
+	board, err := h.service.Leaderboard(r.Context(), query)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, board)
+}
+
+func (h *httpHandler) getRating(w http.ResponseWriter, r *http.Request) {
+	accountID, gameType := r.PathValue("accountId"), r.PathValue("gameType")
+
+	rating, err := h.service.GetRating(r.Context(), accountID, gameType)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	history, err := h.service.GetHistory(r.Context(), accountID, gameType, limit)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	writeJSON(w, map[string]interface{}{
+		"rating":  rating,
+		"history": history,
+	})
+}
+
+// WithSelf adds the requesting player to a friends list
+func WithSelf(friends []string, self string) []string {
+	if self == "" {
+		return friends
+	}
+	for _, id := range friends {
+		if id == self {
+			return friends
+		}
+	}
+	return append(friends, self)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(jsonData)
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrRatingNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrGameTypeRequired), errors.Is(err, ErrNoFriends), errors.Is(err, ErrInvalidQuery):
+		status = http.StatusBadRequest
+	default:
+		log.Error().Err(err).Msg("rating request failed")
+		err = errors.New("internal error")
+	}
+
+	jsonData, _ := json.Marshal(map[string]string{"error": err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(jsonData)
+}
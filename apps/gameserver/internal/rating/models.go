@@ -0,0 +1,93 @@
+package rating
+
+import (
+	"fmt"
+	"gameserver/internal/interfaces"
+	"time"
+)
+
+// PlayerRating is the current rating of an account in one game type
+type PlayerRating struct {
+	ID         string    `json:"-" db:"id"` // <accountId>:<gameType>
+	AccountID  string    `json:"accountId" db:"account_id"`
+	GameType   string    `json:"gameType" db:"game_type"`
+	System     string    `json:"system" db:"system"`
+	Rating     float64   `json:"rating" db:"rating"`
+	Deviation  float64   `json:"deviation" db:"deviation"`
+	Volatility float64   `json:"volatility" db:"volatility"`
+	Games      int       `json:"games" db:"games"`
+	UpdatedAt  time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+func (p *PlayerRating) value() Rating {
+	return Rating{Rating: p.Rating, Deviation: p.Deviation, Volatility: p.Volatility}
+}
+
+// HistoryEntry is the rating change of one account in one match
+type HistoryEntry struct {
+	ID           string             `json:"-" db:"id"` // <matchId>:<accountId>
+	MatchID      string             `json:"matchId" db:"match_id"`
+	AccountID    string             `json:"accountId" db:"account_id"`
+	GameType     string             `json:"gameType" db:"game_type"`
+	Outcome      interfaces.Outcome `json:"outcome" db:"outcome"`
+	RatingBefore float64            `json:"ratingBefore" db:"rating_before"`
+	RatingAfter  float64            `json:"ratingAfter" db:"rating_after"`
+	Delta        float64            `json:"delta" db:"delta"`
+	Week         string             `json:"week" db:"week"` // ISO week, e.g. 2025-W07
+	CreatedAt    time.Time          `json:"createdAt" db:"created_at"`
+}
+
+// Period selects which leaderboard to show
+type Period string
+
+const (
+	// PeriodGlobal ranks all players by their current rating
+	PeriodGlobal Period = "global"
+	// PeriodWeekly ranks the players of the current ISO week by the rating they gained in it
+	PeriodWeekly Period = "weekly"
+	// PeriodFriends ranks the given accounts by their current rating
+	PeriodFriends Period = "friends"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+	MaxFriends      = 200
+)
+
+// LeaderboardQuery selects one page of a leaderboard
+type LeaderboardQuery struct {
+	GameType string   `json:"gameType"`
+	Period   Period   `json:"period"`
+	Page     int      `json:"page"`     // starts at 1
+	PageSize int      `json:"pageSize"` // defaults to DefaultPageSize
+	Friends  []string `json:"friends,omitempty"`
+}
+
+// LeaderboardEntry is one ranked player
+type LeaderboardEntry struct {
+	Rank        int     `json:"rank" db:"-"`
+	AccountID   string  `json:"accountId" db:"account_id"`
+	DisplayName string  `json:"displayName" db:"display_name"`
+	Rating      float64 `json:"rating" db:"rating"`
+	Deviation   float64 `json:"deviation" db:"deviation"`
+	Games       int     `json:"games" db:"games"`
+	Delta       float64 `json:"delta,omitempty" db:"delta"` // rating gained in the week, weekly leaderboard only
+}
+
+// Leaderboard is one page of ranked players
+type Leaderboard struct {
+	GameType string             `json:"gameType"`
+	Period   Period             `json:"period"`
+	Week     string             `json:"week,omitempty"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"pageSize"`
+	Total    int                `json:"total"`
+	Entries  []LeaderboardEntry `json:"entries"`
+}
+
+// isoWeek returns the ISO week of t, e.g. 2025-W07
+func isoWeek(t time.Time) string {
+	year, week := t.UTC().ISOWeek()
+	return fmt.Sprintf("%d-W%02d", year, week)
+}
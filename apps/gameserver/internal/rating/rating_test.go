@@ -0,0 +1,336 @@
+package rating
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gameserver/internal/account"
+	"gameserver/internal/database/sql"
+	"gameserver/internal/interfaces"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func assertClose(t *testing.T, name string, got, want, tolerance float64) {
+	t.Helper()
+	if math.Abs(got-want) > tolerance {
+		t.Errorf("%s: expected %.4f, got %.4f", name, want, got)
+	}
+}
+
+func TestGlicko2PaperExample(t *testing.T) {
+	// example calculation from http://www.glicko.net/glicko/glicko2.pdf
+	system := NewGlicko2()
+	player := Rating{Rating: 1500, Deviation: 200, Volatility: 0.06}
+
+	rated := system.Rate(player, []Opponent{
+		{Rating: Rating{Rating: 1400, Deviation: 30}, Score: 1},
+		{Rating: Rating{Rating: 1550, Deviation: 100}, Score: 0},
+		{Rating: Rating{Rating: 1700, Deviation: 300}, Score: 0},
+	})
+
+	assertClose(t, "rating", rated.Rating, 1464.06, 0.01)
+	assertClose(t, "deviation", rated.Deviation, 151.52, 0.01)
+	assertClose(t, "volatility", rated.Volatility, 0.05999, 0.00001)
+}
+
+func TestGlicko2WithoutGamesGrowsDeviation(t *testing.T) {
+	system := NewGlicko2()
+	player := Rating{Rating: 1700, Deviation: 50, Volatility: 0.06}
+
+	rated := system.Rate(player, nil)
+	if rated.Rating != 1700 {
+		t.Errorf("Expected rating to stay 1700, got %f", rated.Rating)
+	}
+	if rated.Deviation <= 50 {
+		t.Errorf("Expected deviation to grow, got %f", rated.Deviation)
+	}
+}
+
+func TestElo(t *testing.T) {
+	system := NewElo()
+
+	rated := system.Rate(system.Initial(), []Opponent{{Rating: system.Initial(), Score: 1}})
+	assertClose(t, "even win", rated.Rating, 1516, 0.001)
+
+	// beating a much weaker player is worth little
+	rated = system.Rate(Rating{Rating: 1800}, []Opponent{{Rating: Rating{Rating: 1400}, Score: 1}})
+	assertClose(t, "expected win", rated.Rating, 1802.91, 0.01)
+
+	// several opponents never move the rating more than K
+	rated = system.Rate(system.Initial(), []Opponent{
+		{Rating: system.Initial(), Score: 0},
+		{Rating: system.Initial(), Score: 0},
+		{Rating: system.Initial(), Score: 0},
+	})
+	assertClose(t, "multiplayer loss", rated.Rating, 1484, 0.001)
+}
+
+func setupTestService(t *testing.T, opts ...ServiceOption) (*Service, *account.Service) {
+	t.Helper()
+	ctx := context.Background()
+	dbFile := filepath.Join(t.TempDir(), "ratings.sqlite")
+
+	accountDB, err := sql.New(ctx, dbFile, sql.WithAllowedTables([]string{"accounts", "account_game_stats"}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if err := account.InitializeSchema(ctx, accountDB); err != nil {
+		t.Fatalf("Failed to initialize account schema: %v", err)
+	}
+	accounts := account.NewService(account.NewSQLStore(accountDB))
+	t.Cleanup(func() { accounts.Close() })
+
+	db, err := sql.New(ctx, dbFile, sql.WithAllowedTables([]string{ratingsTable, historyTable}))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	if err := InitializeSchema(ctx, db); err != nil {
+		t.Fatalf("Failed to initialize schema: %v", err)
+	}
+	service := NewService(NewSQLStore(db), opts...)
+	t.Cleanup(func() { service.Close() })
+
+	return service, accounts
+}
+
+func createAccount(t *testing.T, accounts *account.Service, name string) string {
+	t.Helper()
+	acc, err := accounts.ForIdentity(context.Background(), &interfaces.Identity{Subject: name, Issuer: "mock", Name: name}, "")
+	if err != nil {
+		t.Fatalf("Failed to create account: %v", err)
+	}
+	return acc.ID
+}
+
+func TestRecordMatch(t *testing.T) {
+	service, accounts := setupTestService(t)
+	ctx := context.Background()
+	alice := createAccount(t, accounts, "alice")
+	bob := createAccount(t, accounts, "bob")
+
+	err := service.RecordMatch(ctx, "tictactoe", map[string]interfaces.Outcome{
+		alice: interfaces.OutcomeWin,
+		bob:   interfaces.OutcomeLoss,
+	})
+	if err != nil {
+		t.Fatalf("RecordMatch failed: %v", err)
+	}
+
+	aliceRating, err := service.GetRating(ctx, alice, "tictactoe")
+	if err != nil {
+		t.Fatalf("GetRating failed: %v", err)
+	}
+	bobRating, err := service.GetRating(ctx, bob, "tictactoe")
+	if err != nil {
+		t.Fatalf("GetRating failed: %v", err)
+	}
+	assertClose(t, "winner", aliceRating.Rating, 1516, 0.001)
+	assertClose(t, "loser", bobRating.Rating, 1484, 0.001)
+	if aliceRating.Games != 1 || aliceRating.System != "elo" {
+		t.Errorf("Unexpected rating: %+v", aliceRating)
+	}
+
+	// a second match starts from the stored ratings
+	err = service.RecordMatch(ctx, "tictactoe", map[string]interfaces.Outcome{
+		alice: interfaces.OutcomeDraw,
+		bob:   interfaces.OutcomeDraw,
+	})
+	if err != nil {
+		t.Fatalf("RecordMatch failed: %v", err)
+	}
+
+	history, err := service.GetHistory(ctx, bob, "tictactoe", 10)
+	if err != nil {
+		t.Fatalf("GetHistory failed: %v", err)
+	}
+	if len(history) != 2 {
+		t.Fatalf("Expected 2 history entries, got %d", len(history))
+	}
+	total := 0.0
+	for _, entry := range history {
+		total += entry.Delta
+	}
+	bobRating, _ = service.GetRating(ctx, bob, "tictactoe")
+	assertClose(t, "history adds up", 1500+total, bobRating.Rating, 0.0001)
+	if bobRating.Rating <= 1484 {
+		t.Errorf("Expected the weaker player to gain from a draw, got %f", bobRating.Rating)
+	}
+
+	// other game types have their own ratings
+	if _, err := service.GetRating(ctx, alice, "dicegame"); !errors.Is(err, ErrRatingNotFound) {
+		t.Errorf("Expected ErrRatingNotFound, got %v", err)
+	}
+}
+
+func TestRecordMatchIgnoresUnratedMatches(t *testing.T) {
+	service, accounts := setupTestService(t)
+	ctx := context.Background()
+	alice := createAccount(t, accounts, "alice")
+	bob := createAccount(t, accounts, "bob")
+
+	matches := []map[string]interfaces.Outcome{
+		{alice: interfaces.OutcomeWin},
+		{alice: interfaces.OutcomeCompleted, bob: interfaces.OutcomeCompleted},
+		{},
+	}
+	for _, outcomes := range matches {
+		if err := service.RecordMatch(ctx, "tellit", outcomes); err != nil {
+			t.Fatalf("RecordMatch failed: %v", err)
+		}
+	}
+
+	if _, err := service.GetRating(ctx, alice, "tellit"); !errors.Is(err, ErrRatingNotFound) {
+		t.Errorf("Expected no rating, got %v", err)
+	}
+}
+
+func TestLeaderboards(t *testing.T) {
+	service, accounts := setupTestService(t, WithGameSystem("dicegame", NewGlicko2()))
+	ctx := context.Background()
+	alice := createAccount(t, accounts, "alice")
+	bob := createAccount(t, accounts, "bob")
+	carol := createAccount(t, accounts, "carol")
+
+	lastWeek := time.Date(2025, 3, 3, 12, 0, 0, 0, time.UTC)
+	thisWeek := lastWeek.AddDate(0, 0, 7)
+
+	// alice won a lot last week, bob beats carol this week
+	service.now = func() time.Time { return lastWeek }
+	for i := 0; i < 3; i++ {
+		if err := service.RecordMatch(ctx, "dicegame", map[string]interfaces.Outcome{alice: interfaces.OutcomeWin, carol: interfaces.OutcomeLoss}); err != nil {
+			t.Fatalf("RecordMatch failed: %v", err)
+		}
+	}
+	service.now = func() time.Time { return thisWeek }
+	if err := service.RecordMatch(ctx, "dicegame", map[string]interfaces.Outcome{bob: interfaces.OutcomeWin, carol: interfaces.OutcomeLoss}); err != nil {
+		t.Fatalf("RecordMatch failed: %v", err)
+	}
+
+	t.Run("global", func(t *testing.T) {
+		board, err := service.Leaderboard(ctx, LeaderboardQuery{GameType: "dicegame"})
+		if err != nil {
+			t.Fatalf("Leaderboard failed: %v", err)
+		}
+		if board.Total != 3 || len(board.Entries) != 3 {
+			t.Fatalf("Expected 3 entries, got %d of %d", len(board.Entries), board.Total)
+		}
+		if board.Entries[0].AccountID != alice || board.Entries[0].DisplayName != "alice" || board.Entries[0].Rank != 1 {
+			t.Errorf("Expected alice first, got %+v", board.Entries[0])
+		}
+		if board.Entries[2].AccountID != carol {
+			t.Errorf("Expected carol last, got %+v", board.Entries[2])
+		}
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		board, err := service.Leaderboard(ctx, LeaderboardQuery{GameType: "dicegame", Page: 2, PageSize: 2})
+		if err != nil {
+			t.Fatalf("Leaderboard failed: %v", err)
+		}
+		if board.Total != 3 || len(board.Entries) != 1 {
+			t.Fatalf("Expected 1 entry of 3, got %d of %d", len(board.Entries), board.Total)
+		}
+		if board.Entries[0].Rank != 3 || board.Entries[0].AccountID != carol {
+			t.Errorf("Expected carol at rank 3, got %+v", board.Entries[0])
+		}
+	})
+
+	t.Run("weekly", func(t *testing.T) {
+		board, err := service.Leaderboard(ctx, LeaderboardQuery{GameType: "dicegame", Period: PeriodWeekly})
+		if err != nil {
+			t.Fatalf("Leaderboard failed: %v", err)
+		}
+		if board.Week != "2025-W11" {
+			t.Errorf("Expected week 2025-W11, got %s", board.Week)
+		}
+		if board.Total != 2 || len(board.Entries) != 2 {
+			t.Fatalf("Expected bob and carol, got %+v", board.Entries)
+		}
+		if board.Entries[0].AccountID != bob || board.Entries[0].Delta <= 0 || board.Entries[0].Games != 1 {
+			t.Errorf("Expected bob first with a gain, got %+v", board.Entries[0])
+		}
+		if board.Entries[1].AccountID != carol || board.Entries[1].Delta >= 0 {
+			t.Errorf("Expected carol second with a loss, got %+v", board.Entries[1])
+		}
+	})
+
+	t.Run("friends", func(t *testing.T) {
+		board, err := service.Leaderboard(ctx, LeaderboardQuery{GameType: "dicegame", Period: PeriodFriends, Friends: []string{carol, bob}})
+		if err != nil {
+			t.Fatalf("Leaderboard failed: %v", err)
+		}
+		if board.Total != 2 || board.Entries[0].AccountID != bob || board.Entries[1].AccountID != carol {
+			t.Errorf("Expected bob and carol, got %+v", board.Entries)
+		}
+
+		if _, err := service.Leaderboard(ctx, LeaderboardQuery{GameType: "dicegame", Period: PeriodFriends}); !errors.Is(err, ErrNoFriends) {
+			t.Errorf("Expected ErrNoFriends, got %v", err)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := service.Leaderboard(ctx, LeaderboardQuery{GameType: "dicegame", Period: "monthly"}); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("Expected ErrInvalidQuery, got %v", err)
+		}
+		if _, err := service.Leaderboard(ctx, LeaderboardQuery{}); !errors.Is(err, ErrGameTypeRequired) {
+			t.Errorf("Expected ErrGameTypeRequired, got %v", err)
+		}
+	})
+}
+
+func TestHTTPHandler(t *testing.T) {
+	service, accounts := setupTestService(t)
+	ctx := context.Background()
+	alice := createAccount(t, accounts, "alice")
+	bob := createAccount(t, accounts, "bob")
+	if err := service.RecordMatch(ctx, "tictactoe", map[string]interfaces.Outcome{alice: interfaces.OutcomeWin, bob: interfaces.OutcomeLoss}); err != nil {
+		t.Fatalf("RecordMatch failed: %v", err)
+	}
+
+	handler := NewHTTPHandler(service, accounts, nil)
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	rec := get("/leaderboards/tictactoe?period=friends&friends=" + bob)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var board Leaderboard
+	if err := json.Unmarshal(rec.Body.Bytes(), &board); err != nil {
+		t.Fatalf("Failed to decode leaderboard: %v", err)
+	}
+	if len(board.Entries) != 1 || board.Entries[0].AccountID != bob {
+		t.Errorf("Expected only bob, got %+v", board.Entries)
+	}
+
+	if rec := get("/leaderboards/tictactoe?period=yearly"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400, got %d", rec.Code)
+	}
+
+	rec = get("/ratings/" + alice + "/tictactoe")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	var response struct {
+		Rating  PlayerRating   `json:"rating"`
+		History []HistoryEntry `json:"history"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to decode rating: %v", err)
+	}
+	if response.Rating.Rating <= 1500 || len(response.History) != 1 || response.History[0].Outcome != interfaces.OutcomeWin {
+		t.Errorf("Unexpected rating response: %+v", response)
+	}
+
+	if rec := get("/ratings/" + alice + "/dicegame"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,261 @@
+package rating
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gameserver/internal/interfaces"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// Service rates the players of finished matches and serves the leaderboards
+type Service struct {
+	store   Store
+	system  System
+	systems map[string]System
+	now     func() time.Time
+}
+
+// ServiceOption is a functional option for configuring the Service
+type ServiceOption func(*Service)
+
+// WithSystem sets the rating system of all game types without their own, Elo by default
+func WithSystem(system System) ServiceOption {
+	return func(s *Service) {
+		s.system = system
+	}
+}
+
+// WithGameSystem sets the rating system of one game type
+func WithGameSystem(gameType string, system System) ServiceOption {
+	return func(s *Service) {
+		s.systems[gameType] = system
+	}
+}
+
+// NewService creates a rating service on the given store
+func NewService(store Store, opts ...ServiceOption) *Service {
+	s := &Service{
+		store:   store,
+		system:  NewElo(),
+		systems: make(map[string]System),
+		now:     time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Service) systemFor(gameType string) System {
+	if system, ok := s.systems[gameType]; ok {
+		return system
+	}
+	return s.system
+}
+
+// points orders outcomes, the player with more points beat the other
+func points(outcome interfaces.Outcome) (float64, bool) {
+	switch outcome {
+	case interfaces.OutcomeWin:
+		return 1, true
+	case interfaces.OutcomeDraw:
+		return 0.5, true
+	case interfaces.OutcomeLoss:
+		return 0, true
+	default:
+		// completed games have no winner and don't change ratings
+		return 0, false
+	}
+}
+
+// RecordMatch rates a finished match. Every account is compared to every other one, a win beats
+// a draw beats a loss and equal outcomes count as a draw. Matches with fewer than two rated accounts are ignored.
+func (s *Service) RecordMatch(ctx context.Context, gameType string, outcomes map[string]interfaces.Outcome) error {
+	scores := make(map[string]float64, len(outcomes))
+	for accountID, outcome := range outcomes {
+		if p, rated := points(outcome); rated && accountID != "" {
+			scores[accountID] = p
+		}
+	}
+	if len(scores) < 2 {
+		return nil
+	}
+
+	accountIDs := make([]string, 0, len(scores))
+	for accountID := range scores {
+		accountIDs = append(accountIDs, accountID)
+	}
+	slices.Sort(accountIDs)
+
+	system := s.systemFor(gameType)
+	matchID := uuid.New().String()
+	now := s.now()
+
+	return s.store.UpdateRatings(ctx, gameType, accountIDs, func(current map[string]*PlayerRating) ([]*PlayerRating, []*HistoryEntry, error) {
+		// everyone is rated against the ratings from before the match
+		before := make(map[string]Rating, len(accountIDs))
+		for _, accountID := range accountIDs {
+			if rating := current[accountID]; rating != nil {
+				before[accountID] = rating.value()
+			} else {
+				before[accountID] = system.Initial()
+			}
+		}
+
+		ratings := make([]*PlayerRating, 0, len(accountIDs))
+		history := make([]*HistoryEntry, 0, len(accountIDs))
+		for _, accountID := range accountIDs {
+			opponents := make([]Opponent, 0, len(accountIDs)-1)
+			for _, opponentID := range accountIDs {
+				if opponentID == accountID {
+					continue
+				}
+				score := 0.5
+				if scores[accountID] > scores[opponentID] {
+					score = 1
+				} else if scores[accountID] < scores[opponentID] {
+					score = 0
+				}
+				opponents = append(opponents, Opponent{Rating: before[opponentID], Score: score})
+			}
+
+			after := system.Rate(before[accountID], opponents)
+
+			rating := current[accountID]
+			if rating == nil {
+				rating = &PlayerRating{ID: ratingID(accountID, gameType), AccountID: accountID, GameType: gameType}
+			}
+			rating.System = system.Name()
+			rating.Rating = after.Rating
+			rating.Deviation = after.Deviation
+			rating.Volatility = after.Volatility
+			rating.Games++
+			rating.UpdatedAt = now
+			ratings = append(ratings, rating)
+
+			history = append(history, &HistoryEntry{
+				ID:           matchID + ":" + accountID,
+				MatchID:      matchID,
+				AccountID:    accountID,
+				GameType:     gameType,
+				Outcome:      outcomes[accountID],
+				RatingBefore: before[accountID].Rating,
+				RatingAfter:  after.Rating,
+				Delta:        after.Rating - before[accountID].Rating,
+				Week:         isoWeek(now),
+				CreatedAt:    now,
+			})
+		}
+
+		log.Debug().Str("matchId", matchID).Str("gameType", gameType).Int("players", len(ratings)).Msg("match rated")
+		return ratings, history, nil
+	})
+}
+
+// HandleResult rates a finished game, making the service a subscriber of the results bus
+func (s *Service) HandleResult(ctx context.Context, result interfaces.GameResult) error {
+	outcomes := make(map[string]interfaces.Outcome)
+	for _, p := range result.Participants {
+		if p.AccountID != "" && !p.Bot {
+			outcomes[p.AccountID] = p.Outcome
+		}
+	}
+	return s.RecordMatch(ctx, result.GameType, outcomes)
+}
+
+// GetRating returns the current rating of an account in a game type
+func (s *Service) GetRating(ctx context.Context, accountID string, gameType string) (*PlayerRating, error) {
+	return s.store.GetRating(ctx, accountID, gameType)
+}
+
+// CurrentRating returns the rating of an account in a game type, accounts that weren't rated
+// yet have the initial rating of the game's system
+func (s *Service) CurrentRating(ctx context.Context, accountID string, gameType string) (Rating, error) {
+	current, err := s.store.GetRating(ctx, accountID, gameType)
+	if errors.Is(err, ErrRatingNotFound) {
+		return s.systemFor(gameType).Initial(), nil
+	}
+	if err != nil {
+		return Rating{}, err
+	}
+	return current.value(), nil
+}
+
+// GetHistory returns the latest rating changes of an account in a game type, newest first
+func (s *Service) GetHistory(ctx context.Context, accountID string, gameType string, limit int) ([]HistoryEntry, error) {
+	if limit <= 0 || limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	return s.store.GetHistory(ctx, accountID, gameType, limit)
+}
+
+// Leaderboard returns one page of a leaderboard
+func (s *Service) Leaderboard(ctx context.Context, query LeaderboardQuery) (*Leaderboard, error) {
+	if query.GameType == "" {
+		return nil, ErrGameTypeRequired
+	}
+	if query.Period == "" {
+		query.Period = PeriodGlobal
+	}
+	switch query.Period {
+	case PeriodGlobal, PeriodWeekly:
+	case PeriodFriends:
+		if len(query.Friends) == 0 {
+			return nil, ErrNoFriends
+		}
+		if len(query.Friends) > MaxFriends {
+			return nil, fmt.Errorf("%w: at most %d friends", ErrInvalidQuery, MaxFriends)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown period %q", ErrInvalidQuery, query.Period)
+	}
+	if query.Page < 1 {
+		query.Page = 1
+	}
+	if query.PageSize < 1 {
+		query.PageSize = DefaultPageSize
+	}
+	if query.PageSize > MaxPageSize {
+		query.PageSize = MaxPageSize
+	}
+
+	board := &Leaderboard{
+		GameType: query.GameType,
+		Period:   query.Period,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+	}
+	if query.Period == PeriodWeekly {
+		board.Week = isoWeek(s.now())
+	}
+
+	entries, total, err := s.store.Leaderboard(ctx, query, board.Week)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Rank = (query.Page-1)*query.PageSize + i + 1
+	}
+
+	board.Entries = entries
+	board.Total = total
+	return board, nil
+}
+
+// Close closes the underlying store
+func (s *Service) Close() error {
+	return s.store.Close()
+}
+
+var (
+	ErrRatingNotFound   = errors.New("rating not found")
+	ErrGameTypeRequired = errors.New("game type is required")
+	ErrNoFriends        = errors.New("friends leaderboard needs at least one account")
+	ErrInvalidQuery     = errors.New("invalid leaderboard query")
+	ErrUnknownSystem    = errors.New("RATING_SYSTEM must be elo or glicko2")
+)
@@ -0,0 +1,202 @@
+package rating
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gameserver/internal/database/sql"
+	"strings"
+)
+
+const (
+	ratingsTable = "ratings"
+	historyTable = "rating_history"
+)
+
+// Store persists ratings and their history
+type Store interface {
+	// UpdateRatings loads the ratings of the accounts in a game type, missing ones are nil, and stores
+	// what update returns together with the history entries in one transaction
+	UpdateRatings(ctx context.Context, gameType string, accountIDs []string, update func(current map[string]*PlayerRating) ([]*PlayerRating, []*HistoryEntry, error)) error
+	GetRating(ctx context.Context, accountID string, gameType string) (*PlayerRating, error)
+	GetHistory(ctx context.Context, accountID string, gameType string, limit int) ([]HistoryEntry, error)
+	Leaderboard(ctx context.Context, query LeaderboardQuery, week string) ([]LeaderboardEntry, int, error)
+	Close() error
+}
+
+// SQLStore is a Store backed by internal/database/sql. It shares the database with the
+// accounts, whose display names it joins into the leaderboards.
+type SQLStore struct {
+	db sql.Database
+}
+
+// NewSQLStore creates a store on a database with the accounts and rating schema (see InitializeSchema)
+func NewSQLStore(db sql.Database) *SQLStore {
+	return &SQLStore{db: db}
+}
+
+func ratingID(accountID string, gameType string) string {
+	return accountID + ":" + gameType
+}
+
+func (s *SQLStore) UpdateRatings(ctx context.Context, gameType string, accountIDs []string, update func(current map[string]*PlayerRating) ([]*PlayerRating, []*HistoryEntry, error)) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	current := make(map[string]*PlayerRating, len(accountIDs))
+	for _, accountID := range accountIDs {
+		var rating PlayerRating
+		if err := tx.Get(ctx, ratingsTable, ratingID(accountID, gameType), &rating); err != nil {
+			if !errors.Is(err, sql.ErrRecordNotFound) {
+				return err
+			}
+			current[accountID] = nil
+			continue
+		}
+		current[accountID] = &rating
+	}
+
+	ratings, history, err := update(current)
+	if err != nil {
+		return err
+	}
+
+	for _, rating := range ratings {
+		if current[rating.AccountID] != nil {
+			err = tx.Update(ctx, ratingsTable, rating.ID, rating)
+		} else {
+			err = tx.Create(ctx, ratingsTable, rating)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to store rating: %w", err)
+		}
+	}
+	for _, entry := range history {
+		if err := tx.Create(ctx, historyTable, entry); err != nil {
+			return fmt.Errorf("failed to store rating history: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (s *SQLStore) GetRating(ctx context.Context, accountID string, gameType string) (*PlayerRating, error) {
+	var rating PlayerRating
+	if err := s.db.Get(ctx, ratingsTable, ratingID(accountID, gameType), &rating); err != nil {
+		if errors.Is(err, sql.ErrRecordNotFound) {
+			return nil, ErrRatingNotFound
+		}
+		return nil, err
+	}
+	return &rating, nil
+}
+
+func (s *SQLStore) GetHistory(ctx context.Context, accountID string, gameType string, limit int) ([]HistoryEntry, error) {
+	history := make([]HistoryEntry, 0)
+	query := "SELECT * FROM rating_history WHERE account_id = ? AND game_type = ? ORDER BY created_at DESC LIMIT ?"
+	if err := s.db.Query(ctx, query, &history, accountID, gameType, limit); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+func (s *SQLStore) Leaderboard(ctx context.Context, query LeaderboardQuery, week string) ([]LeaderboardEntry, int, error) {
+	var selectQuery, countQuery string
+	var args []interface{}
+
+	switch query.Period {
+	case PeriodWeekly:
+		selectQuery = `SELECT h.account_id, COALESCE(a.display_name, '') AS display_name, r.rating, r.deviation,
+				COUNT(*) AS games, SUM(h.delta) AS delta
+			FROM rating_history h
+			JOIN ratings r ON r.account_id = h.account_id AND r.game_type = h.game_type
+			LEFT JOIN accounts a ON a.id = h.account_id
+			WHERE h.game_type = ? AND h.week = ?
+			GROUP BY h.account_id, a.display_name, r.rating, r.deviation
+			ORDER BY delta DESC, h.account_id`
+		countQuery = `SELECT COUNT(DISTINCT account_id) AS count FROM rating_history WHERE game_type = ? AND week = ?`
+		args = []interface{}{query.GameType, week}
+	default:
+		filter := ""
+		args = []interface{}{query.GameType}
+		if query.Period == PeriodFriends {
+			filter = " AND r.account_id IN (?" + strings.Repeat(", ?", len(query.Friends)-1) + ")"
+			for _, id := range query.Friends {
+				args = append(args, id)
+			}
+		}
+		selectQuery = `SELECT r.account_id, COALESCE(a.display_name, '') AS display_name, r.rating, r.deviation,
+				r.games, 0 AS delta
+			FROM ratings r
+			LEFT JOIN accounts a ON a.id = r.account_id
+			WHERE r.game_type = ?` + filter + `
+			ORDER BY r.rating DESC, r.account_id`
+		countQuery = `SELECT COUNT(*) AS count FROM ratings r WHERE r.game_type = ?` + filter
+	}
+
+	var counts []struct {
+		Count int `db:"count"`
+	}
+	if err := s.db.Query(ctx, countQuery, &counts, args...); err != nil {
+		return nil, 0, err
+	}
+	total := 0
+	if len(counts) > 0 {
+		total = counts[0].Count
+	}
+
+	entries := make([]LeaderboardEntry, 0, query.PageSize)
+	pageArgs := append(args, query.PageSize, (query.Page-1)*query.PageSize)
+	if err := s.db.Query(ctx, selectQuery+" LIMIT ? OFFSET ?", &entries, pageArgs...); err != nil {
+		return nil, 0, err
+	}
+	return entries, total, nil
+}
+
+// Close closes the database connection
+func (s *SQLStore) Close() error {
+	return s.db.Close()
+}
+
+// InitializeSchema creates the rating tables if they don't exist.
+// The leaderboards join the accounts table, see account.InitializeSchema.
+func InitializeSchema(ctx context.Context, db sql.Database) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS ratings (
+			id TEXT PRIMARY KEY,
+			account_id TEXT NOT NULL,
+			game_type TEXT NOT NULL,
+			system TEXT NOT NULL,
+			rating DOUBLE PRECISION NOT NULL,
+			deviation DOUBLE PRECISION NOT NULL DEFAULT 0,
+			volatility DOUBLE PRECISION NOT NULL DEFAULT 0,
+			games INTEGER NOT NULL DEFAULT 0,
+			updated_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_ratings_game_type_rating ON ratings (game_type, rating)`,
+		`CREATE TABLE IF NOT EXISTS rating_history (
+			id TEXT PRIMARY KEY,
+			match_id TEXT NOT NULL,
+			account_id TEXT NOT NULL,
+			game_type TEXT NOT NULL,
+			outcome TEXT NOT NULL,
+			rating_before DOUBLE PRECISION NOT NULL,
+			rating_after DOUBLE PRECISION NOT NULL,
+			delta DOUBLE PRECISION NOT NULL,
+			week TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_rating_history_account ON rating_history (account_id, game_type)`,
+		`CREATE INDEX IF NOT EXISTS idx_rating_history_week ON rating_history (game_type, week)`,
+	}
+
+	for _, statement := range statements {
+		if err := db.Exec(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
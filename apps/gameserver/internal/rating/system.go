@@ -0,0 +1,177 @@
+package rating
+
+import (
+	"math"
+)
+
+// Rating is the skill estimate of a player in one game type.
+// Deviation and Volatility are only used by Glicko-2.
+type Rating struct {
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+}
+
+// Opponent is the result of a match against one other player.
+// Score is 1 for a win, 0.5 for a draw and 0 for a loss.
+type Opponent struct {
+	Rating Rating
+	Score  float64
+}
+
+// System is a rating algorithm
+type System interface {
+	Name() string
+	// Initial is the rating of a player without games
+	Initial() Rating
+	// Rate returns the new rating of a player after a match against the given opponents
+	Rate(player Rating, opponents []Opponent) Rating
+}
+
+// Elo is the classic Elo system. Matches with several opponents are split into pairwise
+// games, each weighted with 1/opponents so a match moves the rating by at most K.
+type Elo struct {
+	K float64
+}
+
+// NewElo creates an Elo system with the common K-factor of 32
+func NewElo() *Elo {
+	return &Elo{K: 32}
+}
+
+func (e *Elo) Name() string {
+	return "elo"
+}
+
+func (e *Elo) Initial() Rating {
+	return Rating{Rating: 1500}
+}
+
+func (e *Elo) Rate(player Rating, opponents []Opponent) Rating {
+	if len(opponents) == 0 {
+		return player
+	}
+
+	change := 0.0
+	for _, opponent := range opponents {
+		expected := 1 / (1 + math.Pow(10, (opponent.Rating.Rating-player.Rating)/400))
+		change += opponent.Score - expected
+	}
+
+	player.Rating += e.K * change / float64(len(opponents))
+	return player
+}
+
+// glicko2Scale converts between the Glicko and the Glicko-2 scale
+const glicko2Scale = 173.7178
+
+// Glicko2 is Mark Glickman's Glicko-2 system, every match is treated as one rating period.
+// See http://www.glicko.net/glicko/glicko2.pdf
+type Glicko2 struct {
+	// Tau constrains the change in volatility over time, reasonable values are 0.3 to 1.2
+	Tau float64
+}
+
+// NewGlicko2 creates a Glicko-2 system with tau 0.5
+func NewGlicko2() *Glicko2 {
+	return &Glicko2{Tau: 0.5}
+}
+
+func (g *Glicko2) Name() string {
+	return "glicko2"
+}
+
+func (g *Glicko2) Initial() Rating {
+	return Rating{Rating: 1500, Deviation: 350, Volatility: 0.06}
+}
+
+func (g *Glicko2) Rate(player Rating, opponents []Opponent) Rating {
+	mu := (player.Rating - 1500) / glicko2Scale
+	phi := player.Deviation / glicko2Scale
+	sigma := player.Volatility
+
+	if len(opponents) == 0 {
+		// only the deviation grows for players that didn't play
+		phi = math.Sqrt(phi*phi + sigma*sigma)
+		player.Deviation = phi * glicko2Scale
+		return player
+	}
+
+	// estimated variance and improvement
+	vInv := 0.0
+	improvement := 0.0
+	for _, opponent := range opponents {
+		muJ := (opponent.Rating.Rating - 1500) / glicko2Scale
+		phiJ := opponent.Rating.Deviation / glicko2Scale
+		gPhi := 1 / math.Sqrt(1+3*phiJ*phiJ/(math.Pi*math.Pi))
+		expected := 1 / (1 + math.Exp(-gPhi*(mu-muJ)))
+
+		vInv += gPhi * gPhi * expected * (1 - expected)
+		improvement += gPhi * (opponent.Score - expected)
+	}
+	v := 1 / vInv
+	delta := v * improvement
+
+	sigma = g.volatility(phi, sigma, v, delta)
+
+	phiStar := math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phiStar*phiStar)+1/v)
+	mu = mu + phi*phi*improvement
+
+	return Rating{
+		Rating:     mu*glicko2Scale + 1500,
+		Deviation:  phi * glicko2Scale,
+		Volatility: sigma,
+	}
+}
+
+// volatility finds the new volatility with the Illinois algorithm (step 5 of the paper)
+func (g *Glicko2) volatility(phi, sigma, v, delta float64) float64 {
+	const epsilon = 0.000001
+
+	a := math.Log(sigma * sigma)
+	tau2 := g.Tau * g.Tau
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/tau2
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*g.Tau) < 0 {
+			k++
+		}
+		B = a - k*g.Tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA = fA / 2
+		}
+		B, fB = C, fC
+	}
+
+	return math.Exp(A / 2)
+}
+
+// SystemByName returns the rating system with the given name, nil if there is none
+func SystemByName(name string) System {
+	switch name {
+	case "elo":
+		return NewElo()
+	case "glicko2":
+		return NewGlicko2()
+	default:
+		return nil
+	}
+}
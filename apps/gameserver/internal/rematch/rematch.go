@@ -0,0 +1,96 @@
+package rematch
+
+import "slices"
+
+// Series is the running score of the games played in a room and the votes for the next one.
+// Players vote for a rematch once a game is over, the game restarts with the same seats once
+// everyone voted. The starting player rotates through the seats from game to game.
+type Series struct {
+	// BestOf is the number of games of the series, 0 for a series without end
+	BestOf int `json:"bestOf,omitempty"`
+	// Games are the games finished in the series
+	Games int            `json:"games"`
+	Wins  map[string]int `json:"wins"`
+	Draws int            `json:"draws"`
+	// Winner is the player who won more than half of the games of a best of series
+	Winner string `json:"winner,omitempty"`
+	// Starter is the player who started the current game
+	Starter string `json:"starter"`
+	// Votes are the players who want a rematch, in the order they voted
+	Votes []string `json:"votes"`
+}
+
+// NewSeries returns a series of bestOf games, 0 plays on until the players leave
+func NewSeries(bestOf int) *Series {
+	return &Series{
+		BestOf: max(bestOf, 0),
+		Wins:   make(map[string]int),
+		Votes:  make([]string, 0),
+	}
+}
+
+// Start starts the next game of the series. A decided series starts over.
+func (s *Series) Start(starter string) {
+	if s.Over() {
+		s.Games, s.Draws, s.Winner = 0, 0, ""
+		clear(s.Wins)
+	}
+	s.Starter = starter
+	s.Votes = s.Votes[:0]
+}
+
+// Record counts a finished game, "" is a draw
+func (s *Series) Record(winner string) {
+	s.Games++
+	if winner == "" {
+		s.Draws++
+		return
+	}
+	s.Wins[winner]++
+	if s.BestOf > 0 && s.Wins[winner] > s.BestOf/2 {
+		s.Winner = winner
+	}
+}
+
+// Over reports whether a player won the best of series
+func (s *Series) Over() bool {
+	return s.Winner != ""
+}
+
+// Vote toggles the rematch vote of a player and reports whether the player wants a rematch now
+func (s *Series) Vote(id string) bool {
+	if idx := slices.Index(s.Votes, id); idx >= 0 {
+		s.Votes = slices.Delete(s.Votes, idx, idx+1)
+		return false
+	}
+	s.Votes = append(s.Votes, id)
+	return true
+}
+
+// Leave takes back the vote of a player who left
+func (s *Series) Leave(id string) {
+	s.Votes = slices.DeleteFunc(s.Votes, func(other string) bool { return other == id })
+}
+
+// Agreed reports whether every one of the voters wants a rematch
+func (s *Series) Agreed(voters []string) bool {
+	if len(voters) == 0 {
+		return false
+	}
+	for _, id := range voters {
+		if !slices.Contains(s.Votes, id) {
+			return false
+		}
+	}
+	return true
+}
+
+// NextStarter returns the player after the last starter in order, the first one if the last
+// starter left or there was no game yet
+func (s *Series) NextStarter(order []string) string {
+	if len(order) == 0 {
+		return ""
+	}
+	idx := slices.Index(order, s.Starter)
+	return order[(idx+1)%len(order)]
+}
@@ -0,0 +1,92 @@
+package rematch
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSeries_BestOf(t *testing.T) {
+	series := NewSeries(3)
+	series.Start("a")
+
+	series.Record("a")
+	series.Record("")
+	if series.Over() {
+		t.Fatal("expected the series to go on after a win and a draw")
+	}
+	series.Record("b")
+	series.Record("a")
+	if !series.Over() || series.Winner != "a" {
+		t.Fatalf("expected a to win the series, got %q", series.Winner)
+	}
+	if series.Games != 4 || series.Draws != 1 || series.Wins["a"] != 2 || series.Wins["b"] != 1 {
+		t.Errorf("expected 4 games with a draw and 2:1 wins, got %+v", series)
+	}
+
+	// the next game starts a new series
+	series.Start("b")
+	if series.Over() || series.Games != 0 || series.Wins["a"] != 0 {
+		t.Errorf("expected a new series, got %+v", series)
+	}
+}
+
+func TestSeries_EndlessSeries(t *testing.T) {
+	series := NewSeries(0)
+	for range 10 {
+		series.Record("a")
+	}
+	if series.Over() {
+		t.Error("expected a series without best of to go on")
+	}
+}
+
+func TestSeries_Votes(t *testing.T) {
+	series := NewSeries(0)
+	voters := []string{"a", "b"}
+
+	if !series.Vote("a") || series.Agreed(voters) {
+		t.Fatal("expected a vote of a alone not to agree on a rematch")
+	}
+	if series.Vote("a") {
+		t.Fatal("expected a second vote to take the vote back")
+	}
+	series.Vote("a")
+	series.Vote("b")
+	if !series.Agreed(voters) {
+		t.Fatalf("expected both players to agree, got votes %v", series.Votes)
+	}
+
+	series.Leave("b")
+	if series.Agreed(voters) || !series.Agreed([]string{"a"}) {
+		t.Errorf("expected the vote of b to be gone, got votes %v", series.Votes)
+	}
+	if series.Agreed(nil) {
+		t.Error("expected nobody to agree without voters")
+	}
+
+	series.Start("a")
+	if len(series.Votes) != 0 {
+		t.Errorf("expected a new game to clear the votes, got %v", series.Votes)
+	}
+}
+
+func TestSeries_NextStarter(t *testing.T) {
+	order := []string{"a", "b", "c"}
+	series := NewSeries(0)
+
+	var starters []string
+	starter := "b"
+	for range 4 {
+		series.Start(starter)
+		starters = append(starters, starter)
+		starter = series.NextStarter(order)
+	}
+	if !slices.Equal(starters, []string{"b", "c", "a", "b"}) {
+		t.Errorf("expected the starter to rotate, got %v", starters)
+	}
+
+	series.Start("gone")
+	if next := series.NextStarter(order); next != "a" {
+		t.Errorf("expected the first player to start after the starter left, got %s", next)
+	}
+}
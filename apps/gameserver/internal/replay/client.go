@@ -0,0 +1,59 @@
+package replay
+
+import (
+	"gameserver/internal/interfaces"
+	"gameserver/internal/protocol"
+	"sync"
+)
+
+// replayClient stands in for the recorded websocket and bot clients.
+// Outgoing messages are dropped, only the game state matters during a replay.
+type replayClient struct {
+	id       string
+	bot      bool
+	room     interfaces.Room
+	identity *interfaces.Identity
+	mu       sync.Mutex
+}
+
+func newReplayClient(id string, bot bool) *replayClient {
+	return &replayClient{id: id, bot: bot}
+}
+
+func (c *replayClient) ID() string {
+	return c.id
+}
+
+func (c *replayClient) IsBot() bool {
+	return c.bot
+}
+
+func (c *replayClient) Send(message *protocol.Response) error {
+	return nil
+}
+
+func (c *replayClient) Room() interfaces.Room {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.room
+}
+
+func (c *replayClient) SetRoom(room interfaces.Room) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.room = room
+}
+
+func (c *replayClient) Identity() *interfaces.Identity {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.identity
+}
+
+func (c *replayClient) SetIdentity(identity *interfaces.Identity) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.identity = identity
+}
+
+func (c *replayClient) Close() {}
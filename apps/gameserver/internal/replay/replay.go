@@ -0,0 +1,147 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gameserver/internal/clock"
+	"gameserver/internal/game"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/journal"
+	"gameserver/internal/rng"
+	"gameserver/internal/room"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Divergence describes the first entry at which the replay no longer matches the recording
+type Divergence struct {
+	Index  int
+	Entry  journal.Entry
+	Reason string
+}
+
+func (d *Divergence) Error() string {
+	return fmt.Sprintf("replay diverged at entry %d (%s %s %s): %s", d.Index, d.Entry.Kind, d.Entry.ClientID, d.Entry.MsgType, d.Reason)
+}
+
+// Run replays the journal against the given game, which must be a fresh instance
+// of the recorded game type. The room RNG is recreated from the recorded seed and the room draws
+// the recorded server seeds again. The room runs on a fake clock that is advanced to the time of
+// every entry, so delayed game logic (e.g. dicegame's bust animation, owe_drahn's restart) runs
+// when it did in the recording. It returns a *Divergence if the states do not match.
+func Run(ctx context.Context, g interfaces.Game, j *journal.Journal) error {
+	if g.Type() != j.Header.GameType {
+		return fmt.Errorf("%w: journal is %s, game is %s", ErrGameTypeMismatch, j.Header.GameType, g.Type())
+	}
+
+	registry := game.NewRegistry()
+	registry.RegisterGame(g)
+
+	// without the seed the replayed rolls can't match the recorded ones
+	if j.Header.Seed == nil {
+		return fmt.Errorf("%w: room %s", ErrNoSeed, j.Header.RoomID)
+	}
+	random := rng.New(*j.Header.Seed)
+
+	roomID := j.Header.RoomID
+	replayRoom := room.NewRoom(room.NewRoomManagerMock(), j.Header.GameType, &roomID)
+	replayClock := clock.NewFake(j.Header.CreatedAt)
+	replayRoom.SetClock(replayClock)
+	seeds, err := j.ServerSeeds()
+	if err != nil {
+		return err
+	}
+	replayRoom.ServerSeeds().Queue(seeds...)
+	if err := registry.InitializeRoom(ctx, replayRoom, j.Header.Options, random); err != nil {
+		return fmt.Errorf("failed to initialize room: %w", err)
+	}
+
+	clients := make(map[string]*replayClient)
+	getClient := func(entry journal.Entry) *replayClient {
+		c, exists := clients[entry.ClientID]
+		if !exists {
+			c = newReplayClient(entry.ClientID, entry.Bot)
+			clients[entry.ClientID] = c
+		}
+		// a seat is played by a bot while it took over for a disconnected player
+		c.bot = entry.Bot
+		return c
+	}
+
+	for idx, entry := range j.Entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if elapsed := entry.At.Sub(replayClock.Now()); elapsed > 0 {
+			replayClock.Advance(elapsed)
+		}
+		if journal.StateDigest(g, replayRoom) != entry.StateBefore {
+			return &Divergence{Index: idx, Entry: entry, Reason: "state before entry differs"}
+		}
+
+		c := getClient(entry)
+		if !entry.Bot {
+			c.SetIdentity(identityOf(entry))
+		}
+		err := apply(registry, replayRoom, c, entry)
+
+		errMsg := ""
+		if err != nil {
+			errMsg = err.Error()
+		}
+		if errMsg != entry.Error {
+			return &Divergence{Index: idx, Entry: entry, Reason: fmt.Sprintf("expected error %q, got %q", entry.Error, errMsg)}
+		}
+
+		if journal.StateDigest(g, replayRoom) != entry.StateAfter {
+			return &Divergence{Index: idx, Entry: entry, Reason: "state after entry differs"}
+		}
+	}
+
+	log.Debug().Str("roomId", roomID).Int("entries", len(j.Entries)).Msg("replay matched recording")
+	return nil
+}
+
+// identityOf returns the identity of the client of an entry, which journals only keep the subject of
+func identityOf(entry journal.Entry) *interfaces.Identity {
+	if entry.Subject != "" {
+		return &interfaces.Identity{Subject: entry.Subject}
+	}
+	return entry.Identity
+}
+
+// apply feeds a single entry into the registry the same way the router would
+func apply(registry *game.Registry, replayRoom interfaces.Room, c *replayClient, entry journal.Entry) error {
+	switch entry.Kind {
+	case journal.KindJoin:
+		return registry.HandleClientJoin(c, replayRoom, interfaces.CreateRoomOptions{
+			PlayerName: entry.PlayerName,
+		})
+	case journal.KindLeave:
+		if err := registry.HandleClientLeave(c, replayRoom); err != nil {
+			return err
+		}
+		replayRoom.Leave(c)
+		c.SetRoom(nil)
+		return nil
+	case journal.KindReconnect:
+		// journals recorded before seats name the new connection and the seat it took over
+		seatID := entry.ClientID
+		if entry.OldClientID != "" {
+			seatID = entry.OldClientID
+		}
+		return registry.HandleClientReconnect(c, replayRoom, seatID)
+	case journal.KindMessage:
+		return registry.HandleMessage(c, entry.MsgType, entry.Data)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownEntryKind, entry.Kind)
+	}
+}
+
+var (
+	ErrGameTypeMismatch = errors.New("game type mismatch")
+	ErrUnknownEntryKind = errors.New("unknown journal entry kind")
+	ErrNoSeed           = errors.New("journal has no rng seed, the room is not replayable")
+)
@@ -0,0 +1,70 @@
+package results
+
+import (
+	"context"
+	"fmt"
+	"gameserver/internal/interfaces"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Subscriber consumes the results of finished games, e.g. stats, leaderboards or webhooks
+type Subscriber interface {
+	HandleResult(ctx context.Context, result interfaces.GameResult) error
+}
+
+// SubscriberFunc adapts a function to a Subscriber
+type SubscriberFunc func(ctx context.Context, result interfaces.GameResult) error
+
+func (f SubscriberFunc) HandleResult(ctx context.Context, result interfaces.GameResult) error {
+	return f(ctx, result)
+}
+
+type subscription struct {
+	name       string
+	subscriber Subscriber
+}
+
+// Bus delivers every published game result to all subscribers.
+// A failing subscriber is logged and doesn't keep the result from the others.
+type Bus struct {
+	subscribers []subscription
+	mu          sync.RWMutex
+}
+
+// NewBus creates a results bus without subscribers
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+// Subscribe adds a subscriber, the name identifies it in logs
+func (b *Bus) Subscribe(name string, subscriber Subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscribers = append(b.subscribers, subscription{name: name, subscriber: subscriber})
+	log.Debug().Str("subscriber", name).Msg("subscribed to game results")
+}
+
+// Publish hands the result to every subscriber in the order they subscribed
+func (b *Bus) Publish(ctx context.Context, result interfaces.GameResult) {
+	b.mu.RLock()
+	subscribers := append([]subscription(nil), b.subscribers...)
+	b.mu.RUnlock()
+
+	for _, sub := range subscribers {
+		if err := deliver(ctx, sub.subscriber, result); err != nil {
+			log.Error().Err(err).Str("subscriber", sub.name).Str("roomId", result.RoomID).Str("gameType", result.GameType).Msg("failed to handle game result")
+		}
+	}
+}
+
+// deliver calls the subscriber, turning a panic into an error so one subscriber can't take the game down
+func deliver(ctx context.Context, subscriber Subscriber, result interfaces.GameResult) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("subscriber panicked: %v", r)
+		}
+	}()
+	return subscriber.HandleResult(ctx, result)
+}
@@ -0,0 +1,87 @@
+package results
+
+import (
+	"context"
+	"errors"
+	"gameserver/internal/interfaces"
+	"testing"
+	"time"
+)
+
+func TestNormalize(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("placements", func(t *testing.T) {
+		result := interfaces.GameResult{
+			StartedAt: now.Add(-5 * time.Minute),
+			Participants: []interfaces.Participant{
+				{ClientID: "a", Placement: 2},
+				{ClientID: "b", Placement: 1},
+				{ClientID: "c", Placement: 3, Outcome: interfaces.OutcomeDraw},
+			},
+		}
+		Normalize(&result, now)
+
+		if !result.EndedAt.Equal(now) || result.Duration != 5*time.Minute {
+			t.Errorf("Expected end time and duration to be filled, got %v and %v", result.EndedAt, result.Duration)
+		}
+		expected := []interfaces.Outcome{interfaces.OutcomeLoss, interfaces.OutcomeWin, interfaces.OutcomeDraw}
+		for i, p := range result.Participants {
+			if p.Outcome != expected[i] {
+				t.Errorf("Participant %s: expected %s, got %s", p.ClientID, expected[i], p.Outcome)
+			}
+		}
+	})
+
+	t.Run("everyone first is a draw", func(t *testing.T) {
+		result := interfaces.GameResult{
+			Participants: []interfaces.Participant{{ClientID: "a", Placement: 1}, {ClientID: "b", Placement: 1}},
+		}
+		Normalize(&result, now)
+
+		for _, p := range result.Participants {
+			if p.Outcome != interfaces.OutcomeDraw {
+				t.Errorf("Expected draw, got %s", p.Outcome)
+			}
+		}
+		if result.Duration != 0 {
+			t.Errorf("Expected no duration without start time, got %v", result.Duration)
+		}
+	})
+
+	t.Run("no placements", func(t *testing.T) {
+		result := interfaces.GameResult{
+			Participants: []interfaces.Participant{{ClientID: "a"}, {ClientID: "b"}},
+		}
+		Normalize(&result, now)
+
+		for _, p := range result.Participants {
+			if p.Outcome != interfaces.OutcomeCompleted {
+				t.Errorf("Expected completed, got %s", p.Outcome)
+			}
+		}
+	})
+}
+
+func TestBus(t *testing.T) {
+	bus := NewBus()
+	var calls []string
+
+	bus.Subscribe("first", SubscriberFunc(func(ctx context.Context, result interfaces.GameResult) error {
+		calls = append(calls, "first:"+result.RoomID)
+		return errors.New("storage down")
+	}))
+	bus.Subscribe("panics", SubscriberFunc(func(ctx context.Context, result interfaces.GameResult) error {
+		panic("boom")
+	}))
+	bus.Subscribe("last", SubscriberFunc(func(ctx context.Context, result interfaces.GameResult) error {
+		calls = append(calls, "last:"+result.RoomID)
+		return nil
+	}))
+
+	bus.Publish(context.Background(), interfaces.GameResult{RoomID: "room-1"})
+
+	if len(calls) != 2 || calls[0] != "first:room-1" || calls[1] != "last:room-1" {
+		t.Errorf("Expected every subscriber to get the result in order, got %v", calls)
+	}
+}
@@ -0,0 +1,43 @@
+package results
+
+import (
+	"gameserver/internal/interfaces"
+	"time"
+)
+
+// Normalize fills what the game left out of a result: the end time, the duration and
+// the outcome of every participant. The winners are the participants placed first, if
+// everyone is placed first the game was a draw. Without placements nobody won.
+func Normalize(result *interfaces.GameResult, now time.Time) {
+	if result.EndedAt.IsZero() {
+		result.EndedAt = now
+	}
+	if !result.StartedAt.IsZero() && result.Duration == 0 {
+		result.Duration = result.EndedAt.Sub(result.StartedAt)
+	}
+
+	allFirst := true
+	for _, p := range result.Participants {
+		if p.Placement != 1 {
+			allFirst = false
+			break
+		}
+	}
+
+	for i := range result.Participants {
+		p := &result.Participants[i]
+		if p.Outcome != "" {
+			continue
+		}
+		switch {
+		case p.Placement == 0:
+			p.Outcome = interfaces.OutcomeCompleted
+		case p.Placement == 1 && allFirst && len(result.Participants) > 1:
+			p.Outcome = interfaces.OutcomeDraw
+		case p.Placement == 1:
+			p.Outcome = interfaces.OutcomeWin
+		default:
+			p.Outcome = interfaces.OutcomeLoss
+		}
+	}
+}
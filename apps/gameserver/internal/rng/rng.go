@@ -0,0 +1,138 @@
+package rng
+
+import (
+	cryptorand "crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+)
+
+// RNG is the source of randomness a room's game draws from
+type RNG interface {
+	// Intn returns a number in [0, n)
+	Intn(n int) int
+}
+
+// Seeder is implemented by RNGs that can be reproduced from a seed
+type Seeder interface {
+	Seed() Seed
+}
+
+// Seed is the full 256 bit state a Source starts from. It is written as hex in journals.
+type Seed [32]byte
+
+// SeedFrom expands a number into a seed, for tests and tools that want short fixed seeds
+func SeedFrom(n uint64) Seed {
+	var seed Seed
+	binary.LittleEndian.PutUint64(seed[:], n)
+	return seed
+}
+
+// String returns the seed hex encoded
+func (s Seed) String() string {
+	return hex.EncodeToString(s[:])
+}
+
+// MarshalText encodes the seed as hex
+func (s Seed) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a hex encoded seed
+func (s *Seed) UnmarshalText(text []byte) error {
+	decoded, err := hex.DecodeString(string(text))
+	if err != nil || len(decoded) != len(s) {
+		return fmt.Errorf("%w: %q", ErrInvalidSeed, text)
+	}
+	copy(s[:], decoded)
+	return nil
+}
+
+// Source is a seeded, goroutine-safe ChaCha8 RNG. The same seed always yields the same sequence.
+type Source struct {
+	seed Seed
+	r    *rand.Rand
+	mu   sync.Mutex
+}
+
+// New creates a deterministic RNG from the given seed
+func New(seed Seed) *Source {
+	return &Source{
+		seed: seed,
+		r:    rand.New(rand.NewChaCha8(seed)),
+	}
+}
+
+// NewRandomSeeded creates an RNG with a seed from crypto/rand, used in production. The seed
+// can't be recovered from observed draws. Still, never use the room RNG for secrets such as
+// the server seeds of provably fair rounds (see fair.Seeds), journals record its seed.
+func NewRandomSeeded() *Source {
+	var seed Seed
+	// crypto/rand does not fail on supported platforms, it crashes the program if it can't read
+	cryptorand.Read(seed[:])
+	return New(seed)
+}
+
+// Seed returns the seed the source was created with
+func (s *Source) Seed() Seed {
+	return s.seed
+}
+
+// Intn returns a number in [0, n)
+func (s *Source) Intn(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.r.IntN(n)
+}
+
+// Scripted returns a predefined sequence of values, so tests can force exact outcomes.
+// It panics when the script is exhausted or a value doesn't fit the requested range.
+type Scripted struct {
+	values []int
+	next   int
+	mu     sync.Mutex
+}
+
+// NewScripted creates an RNG returning the given values from Intn in order
+func NewScripted(values ...int) *Scripted {
+	return &Scripted{values: values}
+}
+
+// Dice creates a scripted RNG for games rolling `Intn(6) + 1`, taking die faces (1-6) instead of raw values
+func Dice(faces ...int) *Scripted {
+	values := make([]int, len(faces))
+	for i, face := range faces {
+		values[i] = face - 1
+	}
+	return NewScripted(values...)
+}
+
+// Intn returns the next scripted value
+func (s *Scripted) Intn(n int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.next >= len(s.values) {
+		panic(fmt.Sprintf("rng: scripted values exhausted after %d draws", len(s.values)))
+	}
+
+	value := s.values[s.next]
+	if value < 0 || value >= n {
+		panic(fmt.Sprintf("rng: scripted value %d at draw %d is out of range [0, %d)", value, s.next, n))
+	}
+
+	s.next++
+	return value
+}
+
+// Remaining returns how many scripted values have not been drawn yet
+func (s *Scripted) Remaining() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.values) - s.next
+}
+
+var ErrInvalidSeed = errors.New("invalid rng seed")
@@ -0,0 +1,89 @@
+package rng
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSource_SameSeedSameSequence(t *testing.T) {
+	a := New(SeedFrom(42))
+	b := New(SeedFrom(42))
+
+	for i := range 100 {
+		if x, y := a.Intn(6), b.Intn(6); x != y {
+			t.Fatalf("draw %d differs: %d vs %d", i, x, y)
+		}
+	}
+
+	if a.Seed() != SeedFrom(42) {
+		t.Errorf("expected seed %v, got %v", SeedFrom(42), a.Seed())
+	}
+}
+
+func TestSeed_RoundTripsAsText(t *testing.T) {
+	seed := NewRandomSeeded().Seed()
+	text, err := seed.MarshalText()
+	if err != nil {
+		t.Fatalf("failed to marshal seed: %v", err)
+	}
+
+	var decoded Seed
+	if err := decoded.UnmarshalText(text); err != nil {
+		t.Fatalf("failed to unmarshal seed: %v", err)
+	}
+	if decoded != seed {
+		t.Errorf("expected %v, got %v", seed, decoded)
+	}
+
+	if err := decoded.UnmarshalText([]byte("1234")); !errors.Is(err, ErrInvalidSeed) {
+		t.Errorf("expected a short seed to be rejected, got %v", err)
+	}
+}
+
+func TestNewRandomSeeded_IsReproducible(t *testing.T) {
+	a := NewRandomSeeded()
+	b := New(a.Seed())
+
+	for i := range 100 {
+		if x, y := a.Intn(100), b.Intn(100); x != y {
+			t.Fatalf("draw %d differs from reseeded source: %d vs %d", i, x, y)
+		}
+	}
+}
+
+func TestDice_MapsFacesToRolls(t *testing.T) {
+	r := Dice(1, 6, 3)
+
+	for _, face := range []int{1, 6, 3} {
+		if got := r.Intn(6) + 1; got != face {
+			t.Errorf("expected face %d, got %d", face, got)
+		}
+	}
+
+	if r.Remaining() != 0 {
+		t.Errorf("expected script to be used up, %d remaining", r.Remaining())
+	}
+}
+
+func TestScripted_PanicsWhenExhausted(t *testing.T) {
+	r := NewScripted(0)
+	r.Intn(2)
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic after script was exhausted")
+		}
+	}()
+	r.Intn(2)
+}
+
+func TestScripted_PanicsOutOfRange(t *testing.T) {
+	r := NewScripted(5)
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic for value outside the requested range")
+		}
+	}()
+	r.Intn(2)
+}
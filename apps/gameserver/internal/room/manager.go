@@ -3,7 +3,9 @@ package room
 import (
 	"context"
 	"errors"
+	"gameserver/internal/clock"
 	"gameserver/internal/interfaces"
+	"gameserver/internal/rng"
 	"maps"
 	"slices"
 	"sync"
@@ -18,9 +20,11 @@ type RoomManager struct {
 	mu               sync.RWMutex
 	gameRegistry     interfaces.GameRegistry
 	cleanupInterval  time.Duration
-	cleanupTicker    *time.Ticker
+	cleanupTimer     clock.Timer
 	cleanupStop      chan struct{}
 	onRoomListChange func(gameType string)
+	newRNG           func() rng.RNG
+	clock            clock.Clock
 }
 
 // RoomManagerOption is a functional option for configuring RoomManager
@@ -40,6 +44,20 @@ func WithRoomListChangeCallback(callback func(gameType string)) RoomManagerOptio
 	}
 }
 
+// WithRNGFactory sets how the per-room RNG is created, e.g. to script outcomes in tests
+func WithRNGFactory(factory func() rng.RNG) RoomManagerOption {
+	return func(rm *RoomManager) {
+		rm.newRNG = factory
+	}
+}
+
+// WithClock sets the clock of the manager and its rooms, e.g. a clock.Fake to skip delays in tests
+func WithClock(c clock.Clock) RoomManagerOption {
+	return func(rm *RoomManager) {
+		rm.clock = c
+	}
+}
+
 func (rm *RoomManager) SetRoomListChangeCallback(callback func(gameType string)) {
 	rm.onRoomListChange = callback
 }
@@ -51,6 +69,10 @@ func NewRoomManager(registry interfaces.GameRegistry, opts ...RoomManagerOption)
 		gameRegistry:    registry,
 		cleanupInterval: 5 * time.Minute, // Default: 5 minutes
 		cleanupStop:     make(chan struct{}),
+		newRNG: func() rng.RNG {
+			return rng.NewRandomSeeded()
+		},
+		clock: clock.Real(),
 	}
 
 	// Apply options
@@ -58,25 +80,25 @@ func NewRoomManager(registry interfaces.GameRegistry, opts ...RoomManagerOption)
 		opt(rm)
 	}
 
-	rm.startCleanup()
+	rm.scheduleCleanup()
 
 	return rm
 }
 
-// startCleanup starts the background cleanup routine
-func (m *RoomManager) startCleanup() {
-	m.cleanupTicker = time.NewTicker(m.cleanupInterval)
-	go func() {
-		for {
-			select {
-			case <-m.cleanupTicker.C:
-				m.Cleanup()
-			case <-m.cleanupStop:
-				m.cleanupTicker.Stop()
-				return
-			}
-		}
-	}()
+// scheduleCleanup removes empty rooms every cleanup interval until the manager is stopped
+func (m *RoomManager) scheduleCleanup() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	select {
+	case <-m.cleanupStop:
+		return
+	default:
+	}
+	m.cleanupTimer = m.clock.AfterFunc(m.cleanupInterval, func() {
+		m.Cleanup()
+		m.scheduleCleanup()
+	})
 }
 
 // Stop gracefully stops the room manager and its cleanup routine
@@ -86,6 +108,7 @@ func (m *RoomManager) Stop() {
 	// Close all remaining rooms
 	m.mu.Lock()
 	defer m.mu.Unlock()
+	m.cleanupTimer.Stop()
 
 	for id, room := range m.rooms {
 		room.Close()
@@ -103,10 +126,12 @@ func (m *RoomManager) CreateRoom(ctx context.Context, createOptions interfaces.C
 	}
 
 	room := NewRoom(m, createOptions.GameType, createOptions.RoomID)
+	room.registry = m.gameRegistry
+	room.clock = m.clock
 	log.Info().Str("id", room.ID()).Str("type", room.GameType()).Msg("room created")
 
 	// Initialize with game-specific settings
-	if err := m.gameRegistry.InitializeRoom(ctx, room, createOptions.Options); err != nil {
+	if err := m.gameRegistry.InitializeRoom(ctx, room, createOptions.Options, m.newRNG()); err != nil {
 		return nil, err
 	}
 
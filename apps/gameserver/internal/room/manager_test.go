@@ -3,10 +3,12 @@ package room
 import (
 	"context"
 	testgame "gameserver/games/test"
+	"gameserver/internal/clock"
 	"gameserver/internal/game"
 	"gameserver/internal/interfaces"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestRoomManager(t *testing.T) {
@@ -107,6 +109,28 @@ func TestRoomManager(t *testing.T) {
 		}
 	})
 
+	t.Run("cleanup runs every interval", func(t *testing.T) {
+		fake := clock.NewFake(time.Now())
+		manager := NewRoomManager(registry, WithCleanupInterval(time.Minute), WithClock(fake))
+		t.Cleanup(manager.Stop)
+
+		room, err := manager.CreateRoom(testCtx, interfaces.CreateRoomOptions{
+			GameType: "testGame",
+		})
+		if err != nil {
+			t.Fatalf("unexpected error creating room: %v", err)
+		}
+
+		fake.Advance(59 * time.Second)
+		if _, err = manager.GetRoom(room.ID()); err != nil {
+			t.Errorf("expected the room to exist until the cleanup ran: %v", err)
+		}
+		fake.Advance(time.Second)
+		if _, err = manager.GetRoom(room.ID()); err == nil {
+			t.Error("expected the cleanup to remove the empty room")
+		}
+	})
+
 	t.Run("create room with invalid game type", func(t *testing.T) {
 		room, err := manager.CreateRoom(testCtx, interfaces.CreateRoomOptions{
 			GameType: "invalid-game",
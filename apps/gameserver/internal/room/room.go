@@ -2,9 +2,12 @@ package room
 
 import (
 	"errors"
+	"gameserver/internal/clock"
+	"gameserver/internal/fair"
 	"gameserver/internal/interfaces"
 	"gameserver/internal/protocol"
 	"maps"
+	"slices"
 	"sync"
 	"time"
 
@@ -18,14 +21,28 @@ type GameRoom struct {
 	id       string
 	gameType string
 	manager  interfaces.RoomManager
-	clients  map[string]interfaces.Client
-	state    interface{}
-	closed   bool
-	mu       sync.RWMutex
-
-	closeTimer *time.Timer // handling delayed room closure
+	// registry is told about dropped connections, so games can let bots take over seats
+	registry interfaces.GameRegistry
+	// seats are keyed by seat id, connections maps the id of every bound connection to its seat
+	seats       map[string]*Seat
+	connections map[string]*Seat
+	state       interface{}
+	closed      bool
+	mu          sync.RWMutex
+	// clientsMu guards seats and connections. It is never held while calling clients or acquiring mu,
+	// so rooms can broadcast while mu is held and clients can leave at any time.
+	clientsMu sync.RWMutex
+	// handling serializes the game's handling of the room, it is acquired before mu
+	handling sync.Mutex
+
+	clock      clock.Clock
+	closeTimer clock.Timer // handling delayed room closure
+	seeds      *fair.Seeds
 }
 
+// closeDelay is how long a room without humans waits for them to come back before it closes
+const closeDelay = 30 * time.Second
+
 // NewRoom creates a new game room
 func NewRoom(manager interfaces.RoomManager, gameType string, roomId *string) *GameRoom {
 	var id string
@@ -36,11 +53,14 @@ func NewRoom(manager interfaces.RoomManager, gameType string, roomId *string) *G
 	}
 
 	return &GameRoom{
-		id:       id,
-		gameType: gameType,
-		clients:  make(map[string]interfaces.Client),
-		manager:  manager,
-		closed:   false,
+		id:          id,
+		gameType:    gameType,
+		seats:       make(map[string]*Seat),
+		connections: make(map[string]*Seat),
+		manager:     manager,
+		closed:      false,
+		clock:       clock.Real(),
+		seeds:       fair.NewSeeds(),
 	}
 }
 
@@ -54,12 +74,38 @@ func (room *GameRoom) GameType() string {
 	return room.gameType
 }
 
+// Clock returns the clock the room and its game schedule delayed work with
+func (room *GameRoom) Clock() clock.Clock {
+	return room.clock
+}
+
+// SetClock replaces the clock of a room created without a manager, e.g. to replay a journal
+// on a fake clock. It has to be set before the room is initialized.
+func (room *GameRoom) SetClock(c clock.Clock) {
+	room.clock = c
+}
+
+// ServerSeeds returns the source of the server seeds of the provably fair rounds in the room
+func (room *GameRoom) ServerSeeds() *fair.Seeds {
+	return room.seeds
+}
+
+// Lock waits until the game is done handling the room and keeps others from handling it
+func (room *GameRoom) Lock() {
+	room.handling.Lock()
+}
+
+// Unlock lets others handle the room again
+func (room *GameRoom) Unlock() {
+	room.handling.Unlock()
+}
+
 // IsClosed returns the room's closed status
 func (room *GameRoom) IsClosed() bool {
 	return room.closed
 }
 
-// Join adds a client to the room
+// Join seats a client in the room. A client that had a seat with its id before takes it again.
 func (room *GameRoom) Join(client interfaces.Client) error {
 	room.mu.Lock()
 	defer room.mu.Unlock()
@@ -70,6 +116,82 @@ func (room *GameRoom) Join(client interfaces.Client) error {
 		return ErrRoomClosed
 	}
 
+	room.enter(client)
+	bot, identity := client.IsBot(), client.Identity()
+
+	room.clientsMu.Lock()
+	seat, exists := room.connections[client.ID()]
+	if !exists {
+		if seat, exists = room.seats[client.ID()]; exists {
+			seat.bind(client, bot, identity)
+		} else {
+			seat = newSeat(room, client, bot, identity)
+			room.seats[seat.ID()] = seat
+		}
+		room.connections[client.ID()] = seat
+	}
+	room.clientsMu.Unlock()
+
+	room.announce(seat, client)
+	return nil
+}
+
+// Rejoin binds client to an existing seat, replacing the connection still bound to it.
+// In-process bots only take over empty seats, they never push a player off its seat.
+func (room *GameRoom) Rejoin(client interfaces.Client, seatID string) error {
+	room.mu.Lock()
+	defer room.mu.Unlock()
+	log.Debug().Str("roomId", room.ID()).Str("clientId", client.ID()).Str("seatId", seatID).Msg("client rejoining")
+
+	if room.closed {
+		return ErrRoomClosed
+	}
+
+	bot, identity := client.IsBot(), client.Identity()
+	takeover := !keepsRoomOpen(client)
+
+	room.clientsMu.Lock()
+	seat, exists := room.seats[seatID]
+	var previous interfaces.Client
+	taken := false
+	if exists {
+		if current := seat.Client(); takeover && current != nil && current != client {
+			taken = true
+		} else {
+			if other, seated := room.connections[client.ID()]; seated && other != seat {
+				other.unbind(client)
+			}
+			previous = seat.bind(client, bot, identity)
+			if previous != nil {
+				delete(room.connections, previous.ID())
+			}
+			room.connections[client.ID()] = seat
+		}
+	}
+	room.clientsMu.Unlock()
+
+	if !exists {
+		return ErrSeatNotFound
+	}
+	if taken {
+		return ErrSeatTaken
+	}
+
+	// a connection that lost its seat is no longer in the room, a bot that played it is done
+	if previous != nil && previous.ID() != client.ID() {
+		previous.SetRoom(nil)
+		if !keepsRoomOpen(previous) {
+			log.Info().Str("roomId", room.ID()).Str("botId", previous.ID()).Str("seatId", seatID).Msg("closing bot that played the seat")
+			previous.Close()
+		}
+	}
+	room.enter(client)
+	room.announce(seat, client)
+	return nil
+}
+
+// enter moves client over from the room it was in before
+func (room *GameRoom) enter(client interfaces.Client) {
 	// Leave the old room if it was different
 	oldRoom := client.Room()
 	if oldRoom != nil && oldRoom.ID() != room.id {
@@ -77,11 +199,12 @@ func (room *GameRoom) Join(client interfaces.Client) error {
 	}
 
 	client.SetRoom(room)
+}
 
-	room.clients[client.ID()] = client
-
+// announce tells the others about a client that took a seat
+func (room *GameRoom) announce(seat *Seat, client interfaces.Client) {
 	// If this is a human client and we have a pending close timer, cancel it
-	if !client.IsBot() && room.closeTimer != nil {
+	if keepsRoomOpen(client) && room.closeTimer != nil {
 		log.Debug().Str("roomId", room.ID()).Msg("stoping room close timer")
 		room.closeTimer.Stop()
 		room.closeTimer = nil
@@ -89,22 +212,28 @@ func (room *GameRoom) Join(client interfaces.Client) error {
 
 	// Notify other clients about the new joiner
 	joinMessage := protocol.NewSuccessResponse("client_joined", interfaces.M{
-		"clientId": client.ID(),
+		"clientId": seat.ID(),
 	})
 
-	room.Broadcast(joinMessage, client)
-
-	return nil
+	room.Broadcast(joinMessage, seat)
 }
 
-// Leave removes a client from the room
+// Leave unbinds a client from its seat. The seat stays, so the client can rejoin it.
 func (room *GameRoom) Leave(client interfaces.Client) {
-	if _, exists := room.clients[client.ID()]; exists {
-		delete(room.clients, client.ID())
+	room.clientsMu.Lock()
+	seat, exists := room.seatOf(client)
+	if exists {
+		connection := seat.Client()
+		if exists = connection != nil && seat.unbind(connection); exists {
+			delete(room.connections, connection.ID())
+		}
+	}
+	room.clientsMu.Unlock()
 
+	if exists {
 		// Notify other clients about the departure
 		leaveMessage := protocol.NewSuccessResponse("client_left", interfaces.M{
-			"clientId": client.ID(),
+			"clientId": seat.ID(),
 		})
 
 		room.Broadcast(leaveMessage)
@@ -117,25 +246,67 @@ func (room *GameRoom) Leave(client interfaces.Client) {
 	}
 }
 
-// SendTo sends a message to the specific client with clientId
+// Disconnect unbinds a client whose connection dropped, the game may let a bot play its seat
+func (room *GameRoom) Disconnect(client interfaces.Client) {
+	seat, seated := room.Seat(client)
+	room.Leave(client)
+
+	if !seated || room.registry == nil {
+		return
+	}
+	if err := room.registry.HandleClientDisconnect(seat, room); err != nil {
+		log.Error().Err(err).Str("roomId", room.ID()).Str("seatId", seat.ID()).Msg("failed to handle disconnect")
+	}
+}
+
+// Seat returns the seat a client is bound to, or the seat itself when it is given one
+func (room *GameRoom) Seat(client interfaces.Client) (interfaces.Client, bool) {
+	room.clientsMu.RLock()
+	defer room.clientsMu.RUnlock()
+
+	if seat, exists := room.seatOf(client); exists {
+		return seat, true
+	}
+	return nil, false
+}
+
+// seatOf looks up the seat of a client, clientsMu has to be held
+func (room *GameRoom) seatOf(client interfaces.Client) (*Seat, bool) {
+	if seat, ok := client.(*Seat); ok {
+		return seat, seat.room == room
+	}
+	seat, exists := room.connections[client.ID()]
+	return seat, exists
+}
+
+// SendTo sends a message to the seat with clientId, or the seat of the connection with that id
 func (room *GameRoom) SendTo(message *protocol.Response, clientId string) {
 	// Send to specific user
-	if client, ok := room.clients[clientId]; ok {
-		client.Send(message)
+	room.clientsMu.RLock()
+	seat, ok := room.seats[clientId]
+	if !ok {
+		seat, ok = room.connections[clientId]
+	}
+	room.clientsMu.RUnlock()
+	if ok {
+		seat.Send(message)
 	}
 }
 
-// Broadcast sends a message to all clients in the room except excluded ones
+// Broadcast sends a message to all clients in the room except excluded ones,
+// which can be given as seats or connections
 func (room *GameRoom) Broadcast(message *protocol.Response, exclude ...interfaces.Client) {
 	excludeMap := make(map[string]bool)
 	for _, client := range exclude {
 		excludeMap[client.ID()] = true
 	}
 
-	for _, client := range room.clients {
-		if !excludeMap[client.ID()] {
-			client.Send(message)
+	for _, seat := range room.connectedSeats() {
+		client := seat.Client()
+		if client == nil || excludeMap[seat.ID()] || excludeMap[client.ID()] {
+			continue
 		}
+		client.Send(message)
 	}
 }
 
@@ -146,12 +317,21 @@ func (room *GameRoom) BroadcastTo(message *protocol.Response, clients ...interfa
 	}
 }
 
-// Clients returns a map of clients in the room
+// Clients returns the seats with a connected client, keyed by seat id
 func (room *GameRoom) Clients() map[string]interfaces.Client {
-	room.mu.RLock()
-	defer room.mu.RUnlock()
+	clients := make(map[string]interfaces.Client)
+	for _, seat := range room.connectedSeats() {
+		clients[seat.ID()] = seat
+	}
+	return clients
+}
 
-	return maps.Clone(room.clients)
+// connectedSeats returns the seats with a connected client, to call them without holding a lock
+func (room *GameRoom) connectedSeats() []*Seat {
+	room.clientsMu.RLock()
+	defer room.clientsMu.RUnlock()
+
+	return slices.Collect(maps.Values(room.connections))
 }
 
 // State returns the room's current state
@@ -175,8 +355,8 @@ func (room *GameRoom) ScheduleClose() {
 		return
 	}
 
-	// Set a timer to close the room after the timeout (e.g., 30 seconds)
-	room.closeTimer = time.AfterFunc(30*time.Second, func() {
+	// Set a timer to close the room after the timeout
+	room.closeTimer = room.clock.AfterFunc(closeDelay, func() {
 		log.Debug().Str("roomId", room.ID()).Msg("room checking for closure after timeout")
 
 		room.mu.Lock()
@@ -217,25 +397,36 @@ func (room *GameRoom) Close() {
 
 	room.Broadcast(closeMessage)
 
-	// Explicitly close all bot clients to ensure proper cleanup
-	for id, client := range room.clients {
-		if client.IsBot() {
-			log.Info().Str("roomId", room.ID()).Str("botId", id).Msg("closing bot client")
+	// Explicitly close all in-process bot clients to ensure proper cleanup
+	for _, seat := range room.connectedSeats() {
+		if client := seat.Client(); client != nil && !keepsRoomOpen(client) {
+			log.Info().Str("roomId", room.ID()).Str("botId", client.ID()).Msg("closing bot client")
 			client.Close()
 		}
 	}
 }
 
 func (room *GameRoom) hasHumanClients() bool {
-	for _, c := range room.clients {
-		if !c.IsBot() {
+	for _, seat := range room.connectedSeats() {
+		if c := seat.Client(); c != nil && keepsRoomOpen(c) {
 			return true
 		}
 	}
 	return false
 }
 
+// keepsRoomOpen reports whether a client is connected from outside, a player or an external bot
+func keepsRoomOpen(client interfaces.Client) bool {
+	if !client.IsBot() {
+		return true
+	}
+	remote, ok := client.(interfaces.RemoteBot)
+	return ok && remote.Remote()
+}
+
 // Error definitions
 var (
-	ErrRoomClosed = errors.New("room is closed")
+	ErrRoomClosed   = errors.New("room is closed")
+	ErrSeatNotFound = errors.New("seat not found")
+	ErrSeatTaken    = errors.New("seat is taken")
 )
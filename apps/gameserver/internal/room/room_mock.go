@@ -28,6 +28,11 @@ func (m *RoomManagerMock) GetAllRoomsByGameType(gameType string) []interfaces.Ro
 	return nil
 }
 
+// ListRooms mocks listing all rooms
+func (m *RoomManagerMock) ListRooms() []interfaces.Room {
+	return nil
+}
+
 // NewRoomManagerMock creates a new mock room manager
 func NewRoomManagerMock() interfaces.RoomManager {
 	return &RoomManagerMock{}
@@ -2,9 +2,11 @@ package room
 
 import (
 	"gameserver/internal/client"
+	"gameserver/internal/clock"
 	"gameserver/internal/interfaces"
 	"gameserver/internal/protocol"
 	"testing"
+	"time"
 )
 
 func TestRoom(t *testing.T) {
@@ -142,7 +144,7 @@ func TestRoom(t *testing.T) {
 		}
 
 		// Check if client2 is removed from the room
-		if _, exists := room.clients[client2.ID()]; exists {
+		if _, exists := room.Clients()[client2.ID()]; exists {
 			t.Errorf("client2 still exists in the room after leaving")
 		}
 	})
@@ -210,4 +212,115 @@ func TestRoom(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("client_rejoin_behavior", func(t *testing.T) {
+		client1 := client.NewClientMock("client1")
+		client2 := client.NewClientMock("client2")
+		reconnected := client.NewClientMock("client2-new")
+		testMessage := protocol.NewSuccessResponse("test", "hello")
+
+		room := NewRoom(managerMock, "testGame", nil)
+		room.Join(client1)
+		room.Join(client2)
+		room.Leave(client2)
+
+		if err := room.Rejoin(reconnected, "missing"); err != ErrSeatNotFound {
+			t.Errorf("expected rejoining a missing seat to fail, got %v", err)
+		}
+		if err := room.Rejoin(reconnected, "client2"); err != nil {
+			t.Fatalf("failed to rejoin seat: %v", err)
+		}
+
+		// the seat keeps its id, the game never sees the new connection id
+		seat, ok := room.Seat(reconnected)
+		if !ok || seat.ID() != "client2" {
+			t.Fatalf("expected the new connection to sit on seat client2, got %v", seat)
+		}
+		if _, exists := room.Clients()["client2"]; !exists || len(room.Clients()) != 2 {
+			t.Errorf("expected seats client1 and client2, got %v", room.Clients())
+		}
+		if reconnected.Room() != room {
+			t.Error("expected the new connection to be in the room")
+		}
+
+		// messages to the seat reach the new connection
+		client2.ClearMessages()
+		room.SendTo(testMessage, "client2")
+		if len(reconnected.GetSentMessages()) != 1 || len(client2.GetSentMessages()) != 0 {
+			t.Error("expected messages to the seat to go to the new connection")
+		}
+
+		reconnected.ClearMessages()
+		room.Broadcast(testMessage, seat)
+		if len(reconnected.GetSentMessages()) != 0 {
+			t.Error("expected an excluded seat not to receive the broadcast")
+		}
+
+		room.Leave(reconnected)
+		if _, exists := room.Seat(reconnected); exists {
+			t.Error("expected the connection to be unbound after leaving")
+		}
+	})
+
+	t.Run("bot_takeover_behavior", func(t *testing.T) {
+		client1 := client.NewClientMock("client1")
+		client2 := client.NewClientMock("client2")
+		reconnected := client.NewClientMock("client2-new")
+
+		room := NewRoom(managerMock, "testGame", nil)
+		room.Join(client1)
+		room.Join(client2)
+
+		// a bot does not push a player off its seat
+		if err := room.Rejoin(client.NewBotClient("client2", nil), "client2"); err != ErrSeatTaken {
+			t.Errorf("expected a bot not to take a connected seat, got %v", err)
+		}
+
+		room.Disconnect(client2)
+		bot := client.NewBotClient("client2", nil)
+		if err := room.Rejoin(bot, "client2"); err != nil {
+			t.Fatalf("expected a bot to take over the empty seat: %v", err)
+		}
+		if seat, ok := room.Seat(bot); !ok || !seat.IsBot() {
+			t.Fatal("expected the seat to be played by the bot")
+		}
+
+		// the player takes the seat back and the bot is done
+		if err := room.Rejoin(reconnected, "client2"); err != nil {
+			t.Fatalf("failed to rejoin seat: %v", err)
+		}
+		if bot.Room() != nil || bot.Context().Err() == nil {
+			t.Error("expected the bot to be closed after the player took its seat back")
+		}
+		if seat, ok := room.Seat(reconnected); !ok || seat.IsBot() {
+			t.Error("expected the player back on its seat")
+		}
+	})
+
+	t.Run("delayed_close_behavior", func(t *testing.T) {
+		client1 := client.NewClientMock("client1")
+		fake := clock.NewFake(time.Now())
+
+		room := NewRoom(managerMock, "testGame", nil)
+		room.clock = fake
+		room.Join(client1)
+
+		// the room waits for its players to come back
+		room.Leave(client1)
+		fake.Advance(closeDelay - time.Second)
+		if room.IsClosed() {
+			t.Fatal("expected the room to stay open until the close delay passed")
+		}
+		room.Rejoin(client1, "client1")
+		fake.Advance(time.Second)
+		if room.IsClosed() {
+			t.Fatal("expected a returning player to keep the room open")
+		}
+
+		room.Leave(client1)
+		fake.Advance(closeDelay)
+		if !room.IsClosed() {
+			t.Error("expected the room to close once nobody came back")
+		}
+	})
 }
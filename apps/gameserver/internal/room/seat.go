@@ -0,0 +1,125 @@
+package room
+
+import (
+	"gameserver/internal/interfaces"
+	"gameserver/internal/protocol"
+	"sync"
+)
+
+// Seat is a player's place in a room. Games only ever see seats, so their state stays keyed
+// by the same id when the player reconnects with a new connection.
+// A seat takes the id of the connection that first took it and keeps it for good.
+type Seat struct {
+	id   string
+	room *GameRoom
+
+	mu       sync.RWMutex
+	client   interfaces.Client
+	bot      bool
+	identity *interfaces.Identity
+}
+
+func newSeat(room *GameRoom, client interfaces.Client, bot bool, identity *interfaces.Identity) *Seat {
+	seat := &Seat{id: client.ID(), room: room}
+	seat.bind(client, bot, identity)
+	return seat
+}
+
+// ID returns the seat id, which never changes
+func (s *Seat) ID() string {
+	return s.id
+}
+
+// Client returns the connection bound to the seat, nil while nobody is connected
+func (s *Seat) Client() interfaces.Client {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.client
+}
+
+// Connected reports whether a connection is bound to the seat
+func (s *Seat) Connected() bool {
+	return s.Client() != nil
+}
+
+// Send sends to the bound connection, messages to an empty seat are dropped
+func (s *Seat) Send(message *protocol.Response) error {
+	if client := s.Client(); client != nil {
+		return client.Send(message)
+	}
+	return nil
+}
+
+// Room returns the room of the seat, also while nobody is connected
+func (s *Seat) Room() interfaces.Room {
+	return s.room
+}
+
+// SetRoom sets the room of the bound connection
+func (s *Seat) SetRoom(room interfaces.Room) {
+	if client := s.Client(); client != nil {
+		client.SetRoom(room)
+	}
+}
+
+// Close closes the bound connection
+func (s *Seat) Close() {
+	if client := s.Client(); client != nil {
+		client.Close()
+	}
+}
+
+// IsBot reports whether the seat is played by a bot, an empty seat by its last connection
+func (s *Seat) IsBot() bool {
+	if client := s.Client(); client != nil {
+		return client.IsBot()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.bot
+}
+
+// Identity returns the verified identity of the connection, an empty seat keeps the last one
+func (s *Seat) Identity() *interfaces.Identity {
+	if client := s.Client(); client != nil {
+		return client.Identity()
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.identity
+}
+
+// SetIdentity sets the identity of the seat and its bound connection
+func (s *Seat) SetIdentity(identity *interfaces.Identity) {
+	s.mu.Lock()
+	s.identity = identity
+	client := s.client
+	s.mu.Unlock()
+	if client != nil {
+		client.SetIdentity(identity)
+	}
+}
+
+// bind makes client the connection of the seat and returns the one it replaces.
+// The seat remembers whether the client is a bot and who it is for while the seat is empty.
+// They are passed in, the room does not call clients while it holds its locks.
+func (s *Seat) bind(client interfaces.Client, bot bool, identity *interfaces.Identity) interfaces.Client {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	previous := s.client
+	s.client = client
+	s.bot = bot
+	s.identity = identity
+	return previous
+}
+
+// unbind leaves the seat empty if client is still bound to it
+func (s *Seat) unbind(client interfaces.Client) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.client == nil || s.client.ID() != client.ID() {
+		return false
+	}
+	s.client = nil
+	return true
+}
@@ -4,9 +4,16 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"gameserver/internal/account"
+	"gameserver/internal/auth"
+	"gameserver/internal/cluster"
 	"gameserver/internal/interfaces"
 	"gameserver/internal/protocol"
+	"gameserver/internal/rating"
 	"gameserver/internal/session"
+	"slices"
+	"strings"
+
 	"github.com/getsentry/sentry-go"
 	"github.com/rs/zerolog/log"
 )
@@ -17,6 +24,42 @@ type Router struct {
 	clientManager interfaces.ClientManager
 	roomManager   interfaces.RoomManager
 	gameRegistry  interfaces.GameRegistry
+	authenticator auth.Authenticator
+	ratings       *rating.Service
+	accounts      *account.Service
+	cluster       *cluster.Node
+}
+
+// RouterOption is a functional option for configuring the Router
+type RouterOption func(*Router)
+
+// WithAuthenticator enables the authenticate message, verifying ID tokens sent after connecting
+func WithAuthenticator(authenticator auth.Authenticator) RouterOption {
+	return func(r *Router) {
+		r.authenticator = authenticator
+	}
+}
+
+// WithLeaderboards enables the get_leaderboard message. Accounts are optional and add
+// authenticated players to their own friends leaderboard.
+func WithLeaderboards(ratings *rating.Service, accounts *account.Service) RouterOption {
+	return func(r *Router) {
+		r.ratings = ratings
+		r.accounts = accounts
+	}
+}
+
+// WithCluster makes the router cluster aware: room lists include the rooms of all nodes and
+// clients joining a room of another node are redirected to it
+func WithCluster(node *cluster.Node) RouterOption {
+	return func(r *Router) {
+		r.cluster = node
+	}
+}
+
+// AuthenticatePayload the authenticate message
+type AuthenticatePayload struct {
+	Token string `json:"token"`
 }
 
 // ReconnectPayload the reconnect message
@@ -36,6 +79,13 @@ type JoinResponse struct {
 	RoomID   string `json:"roomId"`
 }
 
+// RedirectResponse tells a client to reconnect to the node hosting a room
+type RedirectResponse struct {
+	RoomID string `json:"roomId"`
+	NodeID string `json:"nodeId"`
+	URL    string `json:"url"`
+}
+
 type RoomListInfo struct {
 	RoomId      string `json:"roomId"`
 	PlayerCount int    `json:"playerCount"`
@@ -43,15 +93,19 @@ type RoomListInfo struct {
 }
 
 // NewRouter creates a new message router
-func NewRouter(ctx context.Context, clientManager interfaces.ClientManager, roomManager interfaces.RoomManager, gameRegistry interfaces.GameRegistry) *Router {
+func NewRouter(ctx context.Context, clientManager interfaces.ClientManager, roomManager interfaces.RoomManager, gameRegistry interfaces.GameRegistry, opts ...RouterOption) *Router {
 	log.Debug().Msg("creating new router")
 
-	return &Router{
+	r := &Router{
 		ctx:           ctx,
 		clientManager: clientManager,
 		roomManager:   roomManager,
 		gameRegistry:  gameRegistry,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // HandleMessage processes an incoming message from a client
@@ -74,9 +128,13 @@ func (r *Router) HandleMessage(client interfaces.Client, messageData []byte) {
 	case "game_action":
 		r.handleGameAction(client, message.Data)
 	case "add_bot":
-		r.handleAddBot(client)
+		r.handleAddBot(client, message.Data)
 	case "get_room_list":
 		r.handleGetRoomList(client, message.Data)
+	case "authenticate":
+		r.handleAuthenticate(client, message.Data)
+	case "get_leaderboard":
+		r.handleGetLeaderboard(client, message.Data)
 	default:
 		// Forward to game-specific handler
 		if client.Room() != nil {
@@ -111,8 +169,12 @@ func (r *Router) handleJoinRoom(ctx context.Context, client interfaces.Client, d
 	if client.Room() != nil {
 		log.Warn().Str("id", client.ID()).Msg("client tried to join room but already in room")
 		//client.Send(protocol.NewErrorResponse("join_room_result", ErrClientAlreadyInRoom.Error()))
+		clientID := client.ID()
+		if seat, ok := client.Room().Seat(client); ok {
+			clientID = seat.ID()
+		}
 		response := &JoinResponse{
-			ClientID: client.ID(),
+			ClientID: clientID,
 			RoomID:   client.Room().ID(),
 		}
 
@@ -135,6 +197,12 @@ func (r *Router) handleJoinRoom(ctx context.Context, client interfaces.Client, d
 
 	log.Debug().Fields(joinOptions).Msg("client joining room")
 
+	// new rooms get an id of this node, so they can be found by id before the cluster heard of them
+	if r.cluster != nil && (joinOptions.RoomID == nil || *joinOptions.RoomID == "") {
+		roomID := r.cluster.NewRoomID()
+		joinOptions.RoomID = &roomID
+	}
+
 	var room interfaces.Room
 	if joinOptions.RoomID == nil {
 		log.Info().Msg("Room id not provided, creating new room")
@@ -152,6 +220,9 @@ func (r *Router) handleJoinRoom(ctx context.Context, client interfaces.Client, d
 		tr, err := r.roomManager.GetRoom(*joinOptions.RoomID)
 		room = tr
 		if err != nil {
+			if r.redirectToOwner(client, *joinOptions.RoomID) {
+				return
+			}
 			log.Info().Str("id", *joinOptions.RoomID).Msg("Room not found, creating new room with provided id")
 			tr, err = r.handleCreateRoom(r.ctx, joinOptions)
 			room = tr
@@ -189,7 +260,7 @@ func (r *Router) handleJoinRoom(ctx context.Context, client interfaces.Client, d
 	log.Info().Str("roomID", room.ID()).Msg("client joined room")
 
 	client.Send(protocol.NewSuccessResponse("join_room_result", response))
-	r.BroadcastRoomListChange(room.GameType())
+	r.roomListChanged(room.GameType())
 }
 
 // handleLeaveRoom leaves the current room
@@ -211,17 +282,21 @@ func (r *Router) handleLeaveRoom(client interfaces.Client) {
 		return
 	}
 
+	player := client
+	if seat, ok := room.Seat(client); ok {
+		player = seat
+	}
 	room.Leave(client)
 	client.SetRoom(nil)
 
 	// Clear session since player explicitly left
 	sessionStore := session.GetSessionStore()
-	sessionStore.RemoveSession(client.ID())
+	sessionStore.RemoveSession(player.ID())
 
-	log.Info().Str("clientId", client.ID()).Str("roomID", roomID).Msg("client left room")
+	log.Info().Str("clientId", player.ID()).Str("roomID", roomID).Msg("client left room")
 
 	client.Send(protocol.NewSuccessResponse("leave_room_result", nil))
-	r.BroadcastRoomListChange(room.GameType())
+	r.roomListChanged(room.GameType())
 }
 
 // handleReconnect tries to reconnect the new socket to an existing room
@@ -239,6 +314,11 @@ func (r *Router) handleReconnect(client interfaces.Client, data json.RawMessage)
 
 	log.Debug().Str("oldClientID", recon.ClientID).Str("newClientID", client.ID()).Msg("client reconnecting to room")
 
+	// sessions are kept by the node hosting the room
+	if recon.RoomID != "" && r.redirectToOwner(client, recon.RoomID) {
+		return
+	}
+
 	sessionStore := session.GetSessionStore()
 	sessionData, exists := sessionStore.GetSession(recon.ClientID)
 	if !exists {
@@ -264,14 +344,7 @@ func (r *Router) handleReconnect(client interfaces.Client, data json.RawMessage)
 		return
 	}
 
-	// Join room and reconnect
-	if err = targetRoom.Join(client); err != nil {
-		log.Error().Str("room", roomID).Err(err).Msg("client failed to join during reconnect")
-		client.Send(protocol.NewErrorResponse("reconnect_result", err.Error()))
-		return
-	}
-
-	// Handle reconnection at game level
+	// Take the seat again, the game is only notified
 	if err = r.gameRegistry.HandleClientReconnect(client, targetRoom, recon.ClientID); err != nil {
 		log.Error().Str("room", roomID).Err(err).Msg("game failed to reconnect client")
 		client.Send(protocol.NewErrorResponse("reconnect_result", err.Error()))
@@ -283,7 +356,7 @@ func (r *Router) handleReconnect(client interfaces.Client, data json.RawMessage)
 
 	response := &ReconnectResponse{
 		RoomID:   targetRoom.ID(),
-		ClientID: client.ID(),
+		ClientID: recon.ClientID,
 		GameType: targetRoom.GameType(),
 	}
 
@@ -330,14 +403,22 @@ func (r *Router) handleGameAction(client interfaces.Client, data json.RawMessage
 	})
 }
 
-// handleAddBot adds a bot to the current room
-func (r *Router) handleAddBot(client interfaces.Client) {
+// handleAddBot adds a bot to the current room, playing the strategy selected in the payload
+func (r *Router) handleAddBot(client interfaces.Client, data json.RawMessage) {
 	if client.Room() == nil {
 		client.Send(protocol.NewErrorResponse("add_bot_result", ErrClientWithoutRoom.Error()))
 		return
 	}
 
-	err := r.gameRegistry.HandleAddBot(client, client.Room())
+	var options interfaces.BotOptions
+	if len(data) > 0 && string(data) != "null" {
+		if err := json.Unmarshal(data, &options); err != nil {
+			client.Send(protocol.NewErrorResponse("add_bot_result", ErrMessageInvalid.Error()))
+			return
+		}
+	}
+
+	err := r.gameRegistry.HandleAddBot(client, client.Room(), options)
 	if err != nil {
 		client.Send(protocol.NewErrorResponse("add_bot_result", err.Error()))
 		return
@@ -356,27 +437,87 @@ func (r *Router) getRoomList(gameType string) []RoomListInfo {
 	for _, room := range rooms {
 		clients := room.Clients()
 
-		// Safely check the Started property from room state
-		started := false
-		if state := room.State(); state != nil {
-			if stateMap, ok := state.(map[string]interface{}); ok {
-				if startedVal, exists := stateMap["Started"]; exists {
-					started, _ = startedVal.(bool)
-				}
-			}
-		}
-
 		roomInfo := RoomListInfo{
 			RoomId:      room.ID(),
 			PlayerCount: len(clients),
-			GameStarted: started,
+			GameStarted: roomStarted(room),
 		}
 		roomList = append(roomList, roomInfo)
 	}
 
+	if r.cluster != nil {
+		for _, remote := range r.cluster.RemoteRooms(gameType) {
+			roomList = append(roomList, RoomListInfo{
+				RoomId:      remote.RoomID,
+				PlayerCount: remote.PlayerCount,
+				GameStarted: remote.Started,
+			})
+		}
+	}
+
 	return roomList
 }
 
+// roomStarted safely checks the Started property from room state
+func roomStarted(room interfaces.Room) bool {
+	started := false
+	if state := room.State(); state != nil {
+		if stateMap, ok := state.(map[string]interface{}); ok {
+			if startedVal, exists := stateMap["Started"]; exists {
+				started, _ = startedVal.(bool)
+			}
+		}
+	}
+	return started
+}
+
+// LocalRooms lists the rooms of this node for the cluster gossip, sorted by id
+func (r *Router) LocalRooms() []cluster.RoomSummary {
+	rooms := r.roomManager.ListRooms()
+
+	summaries := make([]cluster.RoomSummary, 0, len(rooms))
+	for _, room := range rooms {
+		summaries = append(summaries, cluster.RoomSummary{
+			RoomID:      room.ID(),
+			GameType:    room.GameType(),
+			PlayerCount: len(room.Clients()),
+			Started:     roomStarted(room),
+		})
+	}
+	slices.SortFunc(summaries, func(a, b cluster.RoomSummary) int {
+		return strings.Compare(a.RoomID, b.RoomID)
+	})
+	return summaries
+}
+
+// redirectToOwner sends the client to the node hosting a room of another node.
+// It returns false if the room belongs to this node.
+func (r *Router) redirectToOwner(client interfaces.Client, roomID string) bool {
+	if r.cluster == nil {
+		return false
+	}
+	owner, local := r.cluster.Owner(roomID)
+	if local {
+		return false
+	}
+
+	log.Info().Str("clientId", client.ID()).Str("roomId", roomID).Str("nodeId", owner.ID).Msg("redirecting client to room owner")
+	client.Send(protocol.NewSuccessResponse("redirect", &RedirectResponse{
+		RoomID: roomID,
+		NodeID: owner.ID,
+		URL:    owner.PublicURL,
+	}))
+	return true
+}
+
+// roomListChanged updates the room lists of the local clients and the other nodes
+func (r *Router) roomListChanged(gameType string) {
+	r.BroadcastRoomListChange(gameType)
+	if r.cluster != nil {
+		r.cluster.Announce()
+	}
+}
+
 func (r *Router) BroadcastRoomListChange(gameType string) {
 	// find all clients that are connected to a certain game type and inform them of the room list change
 	roomList := r.getRoomList(gameType)
@@ -407,6 +548,61 @@ func (r *Router) handleGetRoomList(client interfaces.Client, data json.RawMessag
 	client.Send(response)
 }
 
+// handleAuthenticate verifies an ID token and attaches the identity to the client
+func (r *Router) handleAuthenticate(client interfaces.Client, data json.RawMessage) {
+	if r.authenticator == nil {
+		client.Send(protocol.NewErrorResponse("authenticate_result", ErrAuthenticationDisabled.Error()))
+		return
+	}
+
+	var payload AuthenticatePayload
+	if err := json.Unmarshal(data, &payload); err != nil || payload.Token == "" {
+		client.Send(protocol.NewErrorResponse("authenticate_result", auth.ErrInvalidToken.Error()))
+		return
+	}
+
+	identity, err := r.authenticator.Authenticate(r.ctx, payload.Token)
+	if err != nil {
+		log.Warn().Err(err).Str("clientId", client.ID()).Msg("client failed to authenticate")
+		client.Send(protocol.NewErrorResponse("authenticate_result", err.Error()))
+		return
+	}
+
+	client.SetIdentity(identity)
+	log.Info().Str("clientId", client.ID()).Str("subject", identity.Subject).Msg("client authenticated")
+
+	client.Send(protocol.NewSuccessResponse("authenticate_result", identity))
+}
+
+// handleGetLeaderboard sends one page of a leaderboard to the requesting client
+func (r *Router) handleGetLeaderboard(client interfaces.Client, data json.RawMessage) {
+	if r.ratings == nil {
+		client.Send(protocol.NewErrorResponse("get_leaderboard_result", ErrLeaderboardsDisabled.Error()))
+		return
+	}
+
+	var query rating.LeaderboardQuery
+	if err := json.Unmarshal(data, &query); err != nil {
+		client.Send(protocol.NewErrorResponse("get_leaderboard_result", "Invalid request format"))
+		return
+	}
+
+	if query.Period == rating.PeriodFriends && r.accounts != nil && client.Identity() != nil {
+		if acc, err := r.accounts.ForClient(r.ctx, client); err == nil {
+			query.Friends = rating.WithSelf(query.Friends, acc.ID)
+		}
+	}
+
+	board, err := r.ratings.Leaderboard(r.ctx, query)
+	if err != nil {
+		log.Warn().Err(err).Str("clientId", client.ID()).Msg("failed to load leaderboard")
+		client.Send(protocol.NewErrorResponse("get_leaderboard_result", err.Error()))
+		return
+	}
+
+	client.Send(protocol.NewSuccessResponse("get_leaderboard_result", board))
+}
+
 // BroadcastTo sends a message to specific clients
 func (r *Router) BroadcastTo(message *protocol.Response, clients []interfaces.Client) {
 	for _, client := range clients {
@@ -421,6 +617,9 @@ var (
 	ErrSessionInvalid      = errors.New("session expired or not found")
 	ErrMessageInvalid      = errors.New("invalid message format")
 
+	ErrAuthenticationDisabled = errors.New("authentication is not configured")
+	ErrLeaderboardsDisabled   = errors.New("leaderboards are not configured")
+
 	ErrGameTypeRequired   = errors.New("game type is required")
 	ErrGameOptionsInvalid = errors.New("game options are invalid")
 	ErrPlayerNameRequired = errors.New("player name is required")
@@ -3,12 +3,20 @@ package router
 import (
 	"context"
 	testgame "gameserver/games/test"
+	"gameserver/internal/account"
+	"gameserver/internal/auth"
 	"gameserver/internal/client"
+	"gameserver/internal/cluster"
+	"gameserver/internal/database/sql"
 	"gameserver/internal/game"
+	"gameserver/internal/interfaces"
 	"gameserver/internal/protocol"
+	"gameserver/internal/rating"
 	"gameserver/internal/room"
 	"gameserver/internal/session"
+	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestRouter(t *testing.T) {
@@ -300,3 +308,198 @@ func TestRouter(t *testing.T) {
 		}
 	})
 }
+
+func TestRouter_Authenticate(t *testing.T) {
+	testCtx := context.Background()
+	registry := game.NewRegistry()
+	clientManager := client.NewManager()
+	roomManager := room.NewRoomManager(registry)
+
+	authenticator := auth.NewAuthenticatorMock()
+	authenticator.AddToken("valid-token", "user-1")
+	router := NewRouter(testCtx, clientManager, roomManager, registry, WithAuthenticator(authenticator))
+
+	t.Run("valid token attaches identity", func(t *testing.T) {
+		client1 := client.NewClientMock("test1")
+		router.HandleMessage(client1, CreateMessage("authenticate", AuthenticatePayload{Token: "valid-token"}))
+
+		messages := client1.GetSentMessages()
+		if len(messages) != 1 || !messages[0].Success || messages[0].Type != "authenticate_result" {
+			t.Fatalf("expected successful authenticate_result, got %+v", messages)
+		}
+		if identity := client1.Identity(); identity == nil || identity.Subject != "user-1" {
+			t.Errorf("expected identity user-1, got %+v", identity)
+		}
+	})
+
+	t.Run("invalid token is rejected", func(t *testing.T) {
+		client1 := client.NewClientMock("test1")
+		router.HandleMessage(client1, CreateMessage("authenticate", AuthenticatePayload{Token: "forged"}))
+
+		messages := client1.GetSentMessages()
+		if len(messages) != 1 || messages[0].Success {
+			t.Fatalf("expected failed authenticate_result, got %+v", messages)
+		}
+		if client1.Identity() != nil {
+			t.Errorf("expected client to stay anonymous")
+		}
+	})
+
+	t.Run("authentication disabled", func(t *testing.T) {
+		noAuthRouter := NewRouter(testCtx, clientManager, roomManager, registry)
+		client1 := client.NewClientMock("test1")
+		noAuthRouter.HandleMessage(client1, CreateMessage("authenticate", AuthenticatePayload{Token: "valid-token"}))
+
+		messages := client1.GetSentMessages()
+		if len(messages) != 1 || messages[0].Error != ErrAuthenticationDisabled.Error() {
+			t.Fatalf("expected authentication disabled error, got %+v", messages)
+		}
+	})
+}
+
+func TestRouter_GetLeaderboard(t *testing.T) {
+	testCtx := context.Background()
+	registry := game.NewRegistry()
+	clientManager := client.NewManager()
+	roomManager := room.NewRoomManager(registry)
+
+	t.Run("leaderboards disabled", func(t *testing.T) {
+		router := NewRouter(testCtx, clientManager, roomManager, registry)
+		client1 := client.NewClientMock("test1")
+		router.HandleMessage(client1, CreateMessage("get_leaderboard", rating.LeaderboardQuery{GameType: "testGame"}))
+
+		messages := client1.GetSentMessages()
+		if len(messages) != 1 || messages[0].Error != ErrLeaderboardsDisabled.Error() {
+			t.Fatalf("expected leaderboards disabled error, got %+v", messages)
+		}
+	})
+
+	db, err := sql.New(testCtx, filepath.Join(t.TempDir(), "ratings.sqlite"), sql.WithAllowedTables([]string{"accounts", "account_game_stats", "ratings", "rating_history"}))
+	if err != nil {
+		t.Fatalf("failed to create database: %v", err)
+	}
+	if err := account.InitializeSchema(testCtx, db); err != nil {
+		t.Fatalf("failed to initialize accounts: %v", err)
+	}
+	if err := rating.InitializeSchema(testCtx, db); err != nil {
+		t.Fatalf("failed to initialize ratings: %v", err)
+	}
+	accounts := account.NewService(account.NewSQLStore(db))
+	ratings := rating.NewService(rating.NewSQLStore(db))
+	defer ratings.Close()
+
+	alice, _ := accounts.ForIdentity(testCtx, &interfaces.Identity{Subject: "alice", Issuer: "mock"}, "Alice")
+	bob, _ := accounts.ForIdentity(testCtx, &interfaces.Identity{Subject: "bob", Issuer: "mock"}, "Bob")
+	carol, _ := accounts.ForIdentity(testCtx, &interfaces.Identity{Subject: "carol", Issuer: "mock"}, "Carol")
+	for _, loser := range []string{bob.ID, carol.ID} {
+		if err := ratings.RecordMatch(testCtx, "testGame", map[string]interfaces.Outcome{alice.ID: interfaces.OutcomeWin, loser: interfaces.OutcomeLoss}); err != nil {
+			t.Fatalf("failed to record match: %v", err)
+		}
+	}
+
+	router := NewRouter(testCtx, clientManager, roomManager, registry, WithLeaderboards(ratings, accounts))
+
+	t.Run("friends leaderboard includes the player", func(t *testing.T) {
+		client1 := client.NewClientMock("test1")
+		client1.SetIdentity(&interfaces.Identity{Subject: "bob", Issuer: "mock"})
+		router.HandleMessage(client1, CreateMessage("get_leaderboard", rating.LeaderboardQuery{
+			GameType: "testGame",
+			Period:   rating.PeriodFriends,
+			Friends:  []string{alice.ID},
+		}))
+
+		messages := client1.GetSentMessages()
+		if len(messages) != 1 || !messages[0].Success || messages[0].Type != "get_leaderboard_result" {
+			t.Fatalf("expected successful get_leaderboard_result, got %+v", messages)
+		}
+		board := messages[0].Data.(*rating.Leaderboard)
+		if board.Total != 2 || board.Entries[0].AccountID != alice.ID || board.Entries[1].AccountID != bob.ID {
+			t.Errorf("expected alice and bob, got %+v", board.Entries)
+		}
+	})
+
+	t.Run("invalid period", func(t *testing.T) {
+		client1 := client.NewClientMock("test1")
+		router.HandleMessage(client1, CreateMessage("get_leaderboard", rating.LeaderboardQuery{GameType: "testGame", Period: "monthly"}))
+
+		messages := client1.GetSentMessages()
+		if len(messages) != 1 || messages[0].Success {
+			t.Fatalf("expected failed get_leaderboard_result, got %+v", messages)
+		}
+	})
+}
+
+func TestRouter_Cluster(t *testing.T) {
+	session.InitGlobalStore(2)
+	testCtx := context.Background()
+	pubsub := cluster.NewMemoryPubSub()
+
+	newNodeRouter := func(id string) (*Router, *cluster.Node) {
+		registry := game.NewRegistry()
+		testgame.RegisterTestGame(registry)
+		node := cluster.NewNode(cluster.NodeInfo{ID: id, PublicURL: "wss://" + id + "/ws"}, pubsub, cluster.WithGossipInterval(time.Hour))
+		router := NewRouter(testCtx, client.NewManager(), room.NewRoomManager(registry), registry, WithCluster(node))
+		node.SetLocalRooms(router.LocalRooms)
+		node.SetRoomListChangeCallback(router.BroadcastRoomListChange)
+		node.Start()
+		t.Cleanup(node.Stop)
+		return router, node
+	}
+	routerA, nodeA := newNodeRouter("a")
+	routerB, nodeB := newNodeRouter("b")
+
+	client1 := client.NewClientMock("test1")
+	routerA.HandleMessage(client1, CreateMessage("join_room", map[string]interface{}{
+		"gameType":   "testGame",
+		"playerName": "Alice",
+	}))
+	messages := client1.GetSentMessages()
+	if len(messages) == 0 || !messages[0].Success || messages[0].Type != "join_room_result" {
+		t.Fatalf("expected successful join_room_result, got %+v", messages)
+	}
+	roomID := client1.Room().ID()
+	if owner, local := nodeA.Owner(roomID); !local || owner.ID != "a" {
+		t.Errorf("expected the new room to be owned by a, got %s", owner.ID)
+	}
+
+	// node a gossips the room asynchronously after the join
+	deadline := time.Now().Add(2 * time.Second)
+	for len(nodeB.RemoteRooms("testGame")) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	t.Run("room lists include rooms of other nodes", func(t *testing.T) {
+		client2 := client.NewClientMock("test2")
+		routerB.HandleMessage(client2, CreateMessage("get_room_list", map[string]interface{}{"gameType": "testGame"}))
+
+		messages := client2.GetSentMessages()
+		if len(messages) != 1 {
+			t.Fatalf("expected 1 message, got %d", len(messages))
+		}
+		rooms, _ := messages[0].Data.([]RoomListInfo)
+		if len(rooms) != 1 || rooms[0].RoomId != roomID || rooms[0].PlayerCount != 1 {
+			t.Errorf("expected the room of node a, got %+v", messages[0].Data)
+		}
+	})
+
+	t.Run("joining a room of another node redirects", func(t *testing.T) {
+		client2 := client.NewClientMock("test2")
+		routerB.HandleMessage(client2, CreateMessage("join_room", map[string]interface{}{
+			"gameType":   "testGame",
+			"playerName": "Bob",
+			"roomId":     roomID,
+		}))
+
+		messages := client2.GetSentMessages()
+		if len(messages) != 1 || messages[0].Type != "redirect" {
+			t.Fatalf("expected a redirect, got %+v", messages)
+		}
+		redirect, _ := messages[0].Data.(*RedirectResponse)
+		if redirect == nil || redirect.NodeID != "a" || redirect.URL != "wss://a/ws" || redirect.RoomID != roomID {
+			t.Errorf("expected a redirect to node a, got %+v", messages[0].Data)
+		}
+		if client2.Room() != nil {
+			t.Error("expected the client not to join a room on node b")
+		}
+	})
+}
@@ -0,0 +1,375 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"gameserver/internal/auth"
+	"gameserver/internal/client"
+	"gameserver/internal/cluster"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/protocol"
+	"gameserver/internal/room"
+	"gameserver/internal/router"
+
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+)
+
+// Registry is the game registry the endpoints list the games of
+type Registry interface {
+	interfaces.GameRegistry
+	ListGames() []string
+}
+
+// Bots is how external bots authenticate and how long they may take per action
+type Bots struct {
+	Keys      auth.BotKeys
+	TimeLimit time.Duration
+}
+
+// Config is everything the endpoints of the game server are served with
+type Config struct {
+	Router        *router.Router
+	Clients       *client.Manager
+	Registry      Registry
+	Rooms         *room.RoomManager
+	Authenticator auth.Authenticator // nil if client identities are not verified
+	Bots          *Bots              // nil if external bots are disabled
+	Node          *cluster.Node      // nil when running as a single node
+	// CheckOrigin decides which browser origins may connect, nil allows drdreo.com only
+	CheckOrigin func(r *http.Request) bool
+	// PongWait is how long websocket clients may stay silent before they are dropped, 0 for the default
+	PongWait time.Duration
+}
+
+// Register adds the endpoints players and bots connect to, and the game and room listings, to mux
+func Register(mux *http.ServeMux, cfg Config) {
+	if cfg.CheckOrigin == nil {
+		cfg.CheckOrigin = checkOrigin
+	}
+	h := &handlers{
+		Config: cfg,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin:     cfg.CheckOrigin,
+		},
+		// bots are programs authenticated by their API key, which browsers can't send on websocket upgrades
+		botUpgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin: func(r *http.Request) bool {
+				return true
+			},
+		},
+	}
+
+	mux.HandleFunc("/", homeHandler)
+
+	ws := http.Handler(http.HandlerFunc(h.ws))
+	// Server-Sent Events and HTTP POST for networks that block websockets
+	sse := http.Handler(http.HandlerFunc(h.sse))
+	sseSend := http.Handler(http.HandlerFunc(h.sseSend))
+	if cfg.Node != nil {
+		// clients connecting with ?room=<id> are passed on to the node hosting the room
+		ws = cfg.Node.ProxyRooms(ws)
+		sse = cfg.Node.ProxyRooms(sse)
+		// posts go to the node the stream lives on, the stream token names it
+		sseSend = cfg.Node.ProxyNodes(sseSend, func(r *http.Request) string {
+			nodeID, _ := splitStreamToken(r.Header.Get("X-Client-Token"))
+			return nodeID
+		})
+	}
+	mux.Handle("/ws", ws)
+	mux.Handle("/sse", sse)
+	mux.Handle("/sse/send", sseSend)
+
+	// Bots played by other programs, e.g. for bot tournaments
+	if cfg.Bots != nil {
+		botWs := http.Handler(http.HandlerFunc(h.bot))
+		if cfg.Node != nil {
+			botWs = cfg.Node.ProxyRooms(botWs)
+		}
+		mux.Handle("/bots/ws", botWs)
+	}
+
+	mux.HandleFunc("/games", h.games)
+	mux.HandleFunc("/rooms", h.rooms)
+}
+
+// checkOrigin accepts browsers on drdreo.com and its subdomains
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false // Reject requests with no origin
+	}
+
+	// Parse the origin URL
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		log.Error().Err(err).Str("origin", origin).Msg("Failed to parse origin URL")
+		return false
+	}
+
+	// verify hostname
+	return strings.HasSuffix(originURL.Host, "drdreo.com")
+}
+
+type handlers struct {
+	Config
+	upgrader    websocket.Upgrader
+	botUpgrader websocket.Upgrader
+}
+
+func homeHandler(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if r.Method != "GET" {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(`{"status": "Game server running"}`))
+}
+
+// identify verifies the optional ID token of a connecting client. A token is optional,
+// but if one is sent it has to be valid, otherwise the request is rejected and ok is false.
+func (h *handlers) identify(w http.ResponseWriter, r *http.Request) (identity *interfaces.Identity, ok bool) {
+	token := auth.TokenFromRequest(r)
+	if token == "" || h.Authenticator == nil {
+		return nil, true
+	}
+
+	identity, err := h.Authenticator.Authenticate(r.Context(), token)
+	if err != nil {
+		log.Warn().Err(err).Str("path", r.URL.Path).Msg("rejecting connection with invalid token")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+	return identity, true
+}
+
+func (h *handlers) ws(w http.ResponseWriter, r *http.Request) {
+	// Get interested game type info from query parameters
+	gameType := r.URL.Query().Get("game")
+
+	identity, ok := h.identify(w, r)
+	if !ok {
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("Error upgrading connection")
+		return
+	}
+
+	c := client.NewWebsocketClient(conn, h.Clients, gameType)
+	c.SetIdentity(identity)
+	if h.PongWait > 0 {
+		c.SetPongWait(h.PongWait)
+	}
+
+	// Set message handler
+	c.OnMessage = func(message []byte) {
+		h.Router.HandleMessage(c, message)
+	}
+
+	// Start read/write pumps
+	c.StartPumps()
+
+	// Send welcome message
+	welcomeMsg := protocol.NewSuccessResponse("welcome", interfaces.M{
+		"message": "Connected to game server. Interested in game: " + gameType,
+	})
+	c.Send(welcomeMsg)
+}
+
+// bot connects an external bot, authenticated by its API key
+func (h *handlers) bot(w http.ResponseWriter, r *http.Request) {
+	gameType := r.URL.Query().Get("game")
+
+	name, ok := h.Bots.Keys.Lookup(auth.BotKeyFromRequest(r))
+	if !ok {
+		log.Warn().Str("remoteAddr", r.RemoteAddr).Msg("rejecting bot with invalid API key")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	conn, err := h.botUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Error().Err(err).Msg("Error upgrading connection")
+		return
+	}
+
+	c := client.NewExternalBotClient(conn, h.Clients, gameType, name, h.Registry, h.Bots.TimeLimit)
+	if h.PongWait > 0 {
+		c.SetPongWait(h.PongWait)
+	}
+	c.SetMessageHandler(func(message []byte) {
+		h.Router.HandleMessage(c, message)
+	})
+	c.StartPumps()
+
+	log.Info().Str("clientId", c.ID()).Str("bot", name).Str("gameType", gameType).Msg("external bot connected")
+
+	c.Send(protocol.NewSuccessResponse("welcome", interfaces.M{
+		"message":         "Connected to game server as bot " + name + ". Interested in game: " + gameType,
+		"clientId":        c.ID(),
+		"actionTimeLimit": h.Bots.TimeLimit.Milliseconds(),
+	}))
+}
+
+// sse streams server events to a client, which sends its messages to sseSend
+func (h *handlers) sse(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.allowCORS(w, r)
+
+	// Get interested game type info from query parameters
+	gameType := r.URL.Query().Get("game")
+
+	identity, ok := h.identify(w, r)
+	if !ok {
+		return
+	}
+
+	c := client.NewSSEClient(h.Clients, gameType)
+	c.SetIdentity(identity)
+
+	// Set message handler
+	c.OnMessage = func(message []byte) {
+		h.Router.HandleMessage(c, message)
+	}
+
+	// Send welcome message, with the id and token the client posts its messages with
+	welcomeMsg := protocol.NewSuccessResponse("welcome", interfaces.M{
+		"message":  "Connected to game server. Interested in game: " + gameType,
+		"clientId": c.ID(),
+		"token":    h.streamToken(c),
+	})
+	c.Send(welcomeMsg)
+
+	c.Serve(w, r)
+}
+
+// sseSend passes a message posted by an SSE client to the router
+func (h *handlers) sseSend(w http.ResponseWriter, r *http.Request) {
+	h.allowCORS(w, r)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	registered, exists := h.Clients.GetClient(r.Header.Get("X-Client-Id"))
+	c, isSSE := registered.(*client.SSEClient)
+	_, token := splitStreamToken(r.Header.Get("X-Client-Token"))
+	if !exists || !isSSE || !c.Authorize(token) {
+		http.Error(w, "Unknown client", http.StatusUnauthorized)
+		return
+	}
+
+	message, err := io.ReadAll(http.MaxBytesReader(w, r.Body, 4096))
+	if err != nil {
+		http.Error(w, "Message too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	if err := c.HandleMessage(message); err != nil {
+		if errors.Is(err, client.ErrClientClosed) {
+			http.Error(w, "Stream closed", http.StatusGone)
+		} else {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	// Responses arrive on the stream
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// streamToken returns the token an SSE client posts with. In cluster mode it starts with the
+// id of this node, posts can land on any node and are passed on to the one serving the stream.
+func (h *handlers) streamToken(c *client.SSEClient) string {
+	if h.Node == nil {
+		return c.Token()
+	}
+	return h.Node.Self().ID + "." + c.Token()
+}
+
+// splitStreamToken splits the token of a post into the node of the stream and the client token
+func splitStreamToken(token string) (nodeID string, clientToken string) {
+	idx := strings.LastIndex(token, ".")
+	if idx < 0 {
+		return "", token
+	}
+	return token[:idx], token[idx+1:]
+}
+
+// allowCORS lets the origins that may open websockets use the HTTP transport from the browser
+func (h *handlers) allowCORS(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if origin == "" || !h.CheckOrigin(r) {
+		return
+	}
+	w.Header().Set("Access-Control-Allow-Origin", origin)
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type, X-Client-Id, X-Client-Token")
+	w.Header().Add("Vary", "Origin")
+}
+
+// games lists the available games
+func (h *handlers) games(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	games := h.Registry.ListGames()
+	response := map[string][]string{"games": games}
+
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(jsonData)
+}
+
+// rooms lists all rooms
+func (h *handlers) rooms(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	rooms := h.Rooms.ListRooms()
+	response := make([]interfaces.M, 0, len(rooms))
+
+	for _, r := range rooms {
+		roomInfo := interfaces.M{
+			"id":          r.ID(),
+			"type":        r.GameType(),
+			"clientCount": len(r.Clients()),
+		}
+		response = append(response, roomInfo)
+	}
+
+	jsonData, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, `{"error": "failed to encode response"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(jsonData)
+}
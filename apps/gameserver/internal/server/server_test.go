@@ -0,0 +1,330 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"gameserver/games/tictactoe"
+	"gameserver/internal/auth"
+	"gameserver/internal/client"
+	"gameserver/internal/cluster"
+	"gameserver/internal/game"
+	"gameserver/internal/room"
+	"gameserver/internal/router"
+	"gameserver/internal/session"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// sseStream reads the events of an SSE connection
+type sseStream struct {
+	cancel context.CancelFunc
+	events chan []map[string]interface{}
+}
+
+func openSSEStream(t *testing.T, serverURL string) *sseStream {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, serverURL+"/sse?game=tictactoe", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		cancel()
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected an event stream, got %q", ct)
+	}
+
+	stream := &sseStream{cancel: cancel, events: make(chan []map[string]interface{}, 16)}
+	go func() {
+		defer resp.Body.Close()
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			data, found := strings.CutPrefix(scanner.Text(), "data: ")
+			if !found {
+				continue
+			}
+			var batch []map[string]interface{}
+			if err := json.Unmarshal([]byte(data), &batch); err == nil {
+				stream.events <- batch
+			}
+		}
+	}()
+	return stream
+}
+
+// next returns the next response of the given type
+func (s *sseStream) next(t *testing.T, responseType string) map[string]interface{} {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case batch := <-s.events:
+			for _, response := range batch {
+				if response["type"] == responseType {
+					return response
+				}
+			}
+		case <-timeout:
+			t.Fatalf("Timed out waiting for %s", responseType)
+		}
+	}
+}
+
+func postSSEMessage(t *testing.T, serverURL string, clientID string, token string, message string) int {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, serverURL+"/sse/send", strings.NewReader(message))
+	req.Header.Set("X-Client-Id", clientID)
+	req.Header.Set("X-Client-Token", token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to post message: %v", err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode
+}
+
+func TestSSETransport(t *testing.T) {
+	session.InitGlobalStore(60)
+	testCtx := context.Background()
+	registry := game.NewRegistry()
+	tictactoe.RegisterTicTacToeGame(registry)
+	clientManager := client.NewManager()
+	roomManager := room.NewRoomManager(registry)
+	testRouter := router.NewRouter(testCtx, clientManager, roomManager, registry)
+
+	mux := http.NewServeMux()
+	Register(mux, Config{Router: testRouter, Clients: clientManager, Registry: registry, Rooms: roomManager})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	stream := openSSEStream(t, server.URL)
+	welcome := stream.next(t, "welcome")["data"].(map[string]interface{})
+	clientID, token := welcome["clientId"].(string), welcome["token"].(string)
+
+	if status := postSSEMessage(t, server.URL, clientID, "forged", `{"type":"get_room_list","data":{"gameType":"tictactoe"}}`); status != http.StatusUnauthorized {
+		t.Errorf("Expected a post with a wrong token to be rejected, got %d", status)
+	}
+
+	status := postSSEMessage(t, server.URL, clientID, token, `{"type":"join_room","data":{"gameType":"tictactoe","playerName":"tester-1"}}`)
+	if status != http.StatusAccepted {
+		t.Fatalf("Expected the message to be accepted, got %d", status)
+	}
+	joined := stream.next(t, "join_room_result")
+	if joined["success"] != true {
+		t.Fatalf("Expected to join, got %v", joined)
+	}
+	roomID := joined["data"].(map[string]interface{})["roomId"].(string)
+
+	// dropping the stream keeps the session, like a dropped websocket
+	stream.cancel()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, exists := clientManager.GetClient(clientID); !exists {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the client to be closed with its stream")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if status := postSSEMessage(t, server.URL, clientID, token, `{"type":"leave_room"}`); status != http.StatusUnauthorized {
+		t.Errorf("Expected posts of a closed stream to be rejected, got %d", status)
+	}
+
+	reconnected := openSSEStream(t, server.URL)
+	defer reconnected.cancel()
+	welcome = reconnected.next(t, "welcome")["data"].(map[string]interface{})
+
+	message := fmt.Sprintf(`{"type":"reconnect","data":{"clientId":%q,"roomId":%q}}`, clientID, roomID)
+	postSSEMessage(t, server.URL, welcome["clientId"].(string), welcome["token"].(string), message)
+	result := reconnected.next(t, "reconnect_result")
+	if result["success"] != true || result["data"].(map[string]interface{})["roomId"] != roomID {
+		t.Errorf("Expected to reconnect to %s, got %v", roomID, result)
+	}
+}
+
+func TestExternalBot(t *testing.T) {
+	session.InitGlobalStore(60)
+	testCtx := context.Background()
+	registry := game.NewRegistry()
+	tictactoe.RegisterTicTacToeGame(registry)
+	clientManager := client.NewManager()
+	roomManager := room.NewRoomManager(registry)
+	testRouter := router.NewRouter(testCtx, clientManager, roomManager, registry)
+
+	bots := &Bots{Keys: auth.BotKeys{"bot-key": "alpha"}, TimeLimit: 100 * time.Millisecond}
+	mux := http.NewServeMux()
+	Register(mux, Config{Router: testRouter, Clients: clientManager, Registry: registry, Rooms: roomManager, Bots: bots})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/bots/ws?game=tictactoe"
+
+	_, resp, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Authorization": {"Bot wrong-key"}})
+	if err == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected a wrong API key to be rejected")
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL, http.Header{"Authorization": {"Bot bot-key"}})
+	if err != nil {
+		t.Fatalf("Failed to connect bot: %v", err)
+	}
+	defer conn.Close()
+
+	responses := make(chan map[string]interface{}, 64)
+	go func() {
+		for {
+			var batch []map[string]interface{}
+			if err := conn.ReadJSON(&batch); err != nil {
+				close(responses)
+				return
+			}
+			for _, response := range batch {
+				responses <- response
+			}
+		}
+	}()
+	next := func(responseType string) map[string]interface{} {
+		t.Helper()
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case response, ok := <-responses:
+				if !ok {
+					t.Fatalf("Connection closed while waiting for %s", responseType)
+				}
+				if response["type"] == responseType {
+					return response
+				}
+			case <-timeout:
+				t.Fatalf("Timed out waiting for %s", responseType)
+			}
+		}
+	}
+
+	welcome := next("welcome")["data"].(map[string]interface{})
+	botID := welcome["clientId"].(string)
+	if bot, _ := clientManager.GetClient(botID); bot == nil || !bot.IsBot() {
+		t.Fatalf("Expected the connection to be a bot client")
+	}
+
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"join_room","data":{"gameType":"tictactoe","playerName":"alpha"}}`))
+	roomID := next("join_room_result")["data"].(map[string]interface{})["roomId"].(string)
+
+	human := client.NewClientMock("human")
+	testRouter.HandleMessage(human, []byte(fmt.Sprintf(`{"type":"join_room","data":{"roomId":%q,"playerName":"human"}}`, roomID)))
+
+	state := next("game_state")["data"].(map[string]interface{})
+	for state["currentTurn"] != botID {
+		testRouter.HandleMessage(human, []byte(`{"type":"make_move","data":{"row":0,"col":0}}`))
+		state = next("game_state")["data"].(map[string]interface{})
+	}
+
+	// the bot acts in time
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"make_move","data":{"row":1,"col":1}}`))
+	state = next("game_state")["data"].(map[string]interface{})
+	if state["currentTurn"] == botID {
+		t.Fatalf("Expected the bot's move to pass the turn")
+	}
+	testRouter.HandleMessage(human, []byte(`{"type":"make_move","data":{"row":2,"col":2}}`))
+
+	// and then lets its time run out, stalling with messages that are no action
+	start := time.Now()
+	stall := make(chan struct{})
+	stalled := make(chan struct{})
+	go func() {
+		defer close(stalled)
+		ticker := time.NewTicker(10 * time.Millisecond)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stall:
+				return
+			case <-ticker.C:
+				conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"get_room_list","data":{"gameType":"tictactoe"}}`))
+				conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"make_move","data":{"row":1,"col":1}}`))
+				conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"no_such_message"}`))
+			}
+		}
+	}()
+	timeout := next("action_timeout")
+	close(stall)
+	<-stalled
+	if timeout["success"] != false {
+		t.Errorf("Expected the time out to be an error, got %v", timeout)
+	}
+	if elapsed := time.Since(start); elapsed < bots.TimeLimit {
+		t.Errorf("Expected the bot to get %v, timed out after %v", bots.TimeLimit, elapsed)
+	}
+	next("leave_room_result")
+
+	gameRoom, err := roomManager.GetRoom(roomID)
+	if err != nil {
+		t.Fatalf("Expected the room to stay open for the human: %v", err)
+	}
+	if _, seated := gameRoom.Clients()[botID]; seated {
+		t.Errorf("Expected the bot to be removed from the room")
+	}
+}
+
+// startNode serves the endpoints of a cluster node
+func startNode(t *testing.T, id string, pubsub cluster.PubSub) (*httptest.Server, *cluster.Node) {
+	t.Helper()
+	registry := game.NewRegistry()
+	tictactoe.RegisterTicTacToeGame(registry)
+	clientManager := client.NewManager()
+	roomManager := room.NewRoomManager(registry)
+	nodeRouter := router.NewRouter(context.Background(), clientManager, roomManager, registry)
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	node := cluster.NewNode(cluster.NodeInfo{ID: id, Address: server.URL}, pubsub, cluster.WithGossipInterval(time.Hour))
+	Register(mux, Config{Router: nodeRouter, Clients: clientManager, Registry: registry, Rooms: roomManager, Node: node})
+	node.Start()
+	t.Cleanup(node.Stop)
+	return server, node
+}
+
+func TestSSETransport_Cluster(t *testing.T) {
+	session.InitGlobalStore(60)
+	pubsub := cluster.NewMemoryPubSub()
+	a, nodeA := startNode(t, "a", pubsub)
+	b, nodeB := startNode(t, "b", pubsub)
+	nodeA.Announce()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(nodeA.Nodes()) != 2 || len(nodeB.Nodes()) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the nodes to know each other")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	stream := openSSEStream(t, a.URL)
+	defer stream.cancel()
+	welcome := stream.next(t, "welcome")["data"].(map[string]interface{})
+	clientID, token := welcome["clientId"].(string), welcome["token"].(string)
+	if !strings.HasPrefix(token, "a.") {
+		t.Fatalf("Expected the token to name the node of the stream, got %q", token)
+	}
+
+	// node b doesn't know the client, the post is passed on to node a
+	status := postSSEMessage(t, b.URL, clientID, token, `{"type":"join_room","data":{"gameType":"tictactoe","playerName":"tester-1"}}`)
+	if status != http.StatusAccepted {
+		t.Fatalf("Expected the post to reach the node of the stream, got %d", status)
+	}
+	if joined := stream.next(t, "join_room_result"); joined["success"] != true {
+		t.Errorf("Expected to join, got %v", joined)
+	}
+
+	if status := postSSEMessage(t, b.URL, clientID, "a.forged", `{"type":"leave_room"}`); status != http.StatusUnauthorized {
+		t.Errorf("Expected a forged token to be rejected by the node of the stream, got %d", status)
+	}
+}
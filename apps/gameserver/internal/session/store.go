@@ -1,6 +1,7 @@
 package session
 
 import (
+	"gameserver/internal/clock"
 	"gameserver/internal/interfaces"
 	"sync"
 	"time"
@@ -20,6 +21,20 @@ type Store struct {
 	sessions      map[string]SessionData
 	mu            sync.RWMutex
 	expirySeconds int64
+	clock         clock.Clock
+}
+
+// cleanupInterval is how often expired sessions are dropped
+const cleanupInterval = 5 * time.Minute
+
+// StoreOption is a functional option for configuring Store
+type StoreOption func(*Store)
+
+// WithClock sets the clock sessions expire by, e.g. a clock.Fake in tests
+func WithClock(c clock.Clock) StoreOption {
+	return func(s *Store) {
+		s.clock = c
+	}
 }
 
 // Global session store instance
@@ -28,8 +43,8 @@ var (
 )
 
 // InitGlobalStore initializes the global session store with the given expiry
-func InitGlobalStore(expirySeconds int64) {
-	globalStore = NewStore(expirySeconds)
+func InitGlobalStore(expirySeconds int64, opts ...StoreOption) {
+	globalStore = NewStore(expirySeconds, opts...)
 }
 
 // GetSessionStore returns the global session store instance
@@ -37,22 +52,26 @@ func GetSessionStore() *Store {
 	return globalStore
 }
 
-func NewStore(expirySeconds int64) *Store {
+func NewStore(expirySeconds int64, opts ...StoreOption) *Store {
 	store := &Store{
 		sessions:      make(map[string]SessionData),
 		expirySeconds: expirySeconds,
+		clock:         clock.Real(),
+	}
+	for _, opt := range opts {
+		opt(store)
 	}
 
 	log.Debug().Int64("expiry", expirySeconds).Msg("created new session store")
 
-	go store.cleanupRoutine()
+	store.scheduleCleanup()
 	return store
 }
 
 func (s *Store) StoreSession(clientID string, data SessionData) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	data.LeftAt = time.Now()
+	data.LeftAt = s.clock.Now()
 
 	s.sessions[clientID] = data
 	log.Debug().Str("clientId", clientID).Time("leftAt", data.LeftAt).Msg("session stored")
@@ -66,7 +85,7 @@ func (s *Store) GetSession(clientID string) (SessionData, bool) {
 		return SessionData{}, false
 	}
 
-	if time.Since(data.LeftAt).Seconds() > float64(s.expirySeconds) {
+	if s.clock.Now().Sub(data.LeftAt).Seconds() > float64(s.expirySeconds) {
 		return SessionData{}, false
 	}
 	return data, true
@@ -80,13 +99,12 @@ func (s *Store) RemoveSession(clientID string) {
 	delete(s.sessions, clientID)
 }
 
-func (s *Store) cleanupRoutine() {
-	ticker := time.NewTicker(5 * time.Minute)
-	defer ticker.Stop()
-
-	for range ticker.C {
+// scheduleCleanup drops expired sessions every cleanupInterval
+func (s *Store) scheduleCleanup() {
+	s.clock.AfterFunc(cleanupInterval, func() {
 		s.cleanup()
-	}
+		s.scheduleCleanup()
+	})
 }
 
 func (s *Store) cleanup() {
@@ -94,7 +112,7 @@ func (s *Store) cleanup() {
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	now := time.Now()
+	now := s.clock.Now()
 	for id, session := range s.sessions {
 		if now.Sub(session.LeftAt).Seconds() > float64(s.expirySeconds) {
 			delete(s.sessions, id)
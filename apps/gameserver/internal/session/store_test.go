@@ -2,6 +2,9 @@ package session
 
 import (
 	"testing"
+	"time"
+
+	"gameserver/internal/clock"
 )
 
 func TestSessionStore(t *testing.T) {
@@ -47,18 +50,29 @@ func TestSessionStore(t *testing.T) {
 		}
 	})
 
-	// t.Run("session expiry", func(t *testicles.T) {
-	// 	// Store session
-	// 	data := SessionData{ClientID: "test3", RoomID: "room1"}
-	// 	store.StoreSession("test3", data)
+}
+
+func TestSessionStoreExpiry(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	store := NewStore(2, WithClock(fake))
+
+	store.StoreSession("test3", SessionData{ClientID: "test3", RoomID: "room1"})
+
+	fake.Advance(2 * time.Second)
+	if _, exists := store.GetSession("test3"); !exists {
+		t.Error("session should exist until it expired")
+	}
 
-	// 	// Wait for expiry (2 seconds)
-	// 	time.Sleep(3 * time.Second)
+	fake.Advance(time.Second)
+	if _, exists := store.GetSession("test3"); exists {
+		t.Error("session should be expired")
+	}
 
-	// 	// Verify session is expired
-	// 	_, exists := store.GetSession("test3")
-	// 	if exists {
-	// 		t.Error("session should be expired")
-	// 	}
-	// })
+	// expired sessions are dropped by the cleanup
+	fake.Advance(cleanupInterval)
+	store.mu.RLock()
+	defer store.mu.RUnlock()
+	if len(store.sessions) != 0 {
+		t.Errorf("expected the cleanup to drop the expired session, got %v", store.sessions)
+	}
 }
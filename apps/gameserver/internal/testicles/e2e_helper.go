@@ -0,0 +1,384 @@
+package testicles
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gameserver/internal/interfaces"
+	"gameserver/internal/router"
+	"gameserver/internal/server"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultTimeout is how long the E2E helpers wait for a response unless told otherwise
+const DefaultTimeout = 2 * time.Second
+
+// E2EHelper serves the full HTTP mux of the game server on an httptest.Server and connects
+// real websocket clients to it. Unlike the TestHelper it exercises the client pumps, the
+// batching of responses, keep-alive pings and the sessions stored on disconnect.
+type E2EHelper struct {
+	*TestHelper
+	Server *httptest.Server
+}
+
+// E2EOption configures the server of an E2EHelper
+type E2EOption func(cfg *server.Config)
+
+// WithPongWait drops websocket clients that stay silent for longer than wait, they are
+// pinged at 9/10 of it
+func WithPongWait(wait time.Duration) E2EOption {
+	return func(cfg *server.Config) {
+		cfg.PongWait = wait
+	}
+}
+
+// NewE2EHelper starts a game server with the components of a TestHelper, it is closed when the test ends
+func NewE2EHelper(t *testing.T, opts ...E2EOption) *E2EHelper {
+	th := NewTestHelper(t)
+	th.roomManager.SetRoomListChangeCallback(th.Router.BroadcastRoomListChange)
+
+	cfg := server.Config{
+		Router:   th.Router,
+		Clients:  th.clientManager,
+		Registry: th.registry,
+		Rooms:    th.roomManager,
+		CheckOrigin: func(r *http.Request) bool {
+			return true
+		},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	mux := http.NewServeMux()
+	server.Register(mux, cfg)
+	h := &E2EHelper{TestHelper: th, Server: httptest.NewServer(mux)}
+	t.Cleanup(h.Server.Close)
+	return h
+}
+
+// URL returns the websocket URL of path on the server, e.g. URL("/ws?game=dicegame")
+func (h *E2EHelper) URL(path string) string {
+	return "ws" + strings.TrimPrefix(h.Server.URL, "http") + path
+}
+
+// E2EResponse is a response as a client receives it, its data still encoded
+type E2EResponse struct {
+	Type    string          `json:"type"`
+	Success bool            `json:"success"`
+	Error   string          `json:"error,omitempty"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Decode unmarshals the data of the response into v
+func (r E2EResponse) Decode(v interface{}) error {
+	return json.Unmarshal(r.Data, v)
+}
+
+// WSClient is a player connected over a real websocket. Responses are read as the batches
+// the server writes and queued until they are expected, the latest one of each type is kept
+// apart, e.g. the state a game broadcasts before it confirms a join.
+type WSClient struct {
+	// ID is the seat of the client once it joined a room, it stays the same across reconnects
+	ID     string
+	RoomID string
+
+	helper    *E2EHelper
+	gameType  string
+	conn      *websocket.Conn
+	writeMu   sync.Mutex
+	responses chan E2EResponse
+	done      chan struct{}
+
+	mu      sync.Mutex
+	latest  map[string]E2EResponse
+	batches []int
+	pings   int
+	err     error
+}
+
+// Connect opens a websocket to the server for the given game type and waits for the welcome
+func (h *E2EHelper) Connect(gameType string) *WSClient {
+	h.t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial(h.URL("/ws?game="+gameType), nil)
+	if err != nil {
+		h.t.Fatalf("Failed to connect: %v", err)
+	}
+
+	c := &WSClient{
+		helper:    h,
+		gameType:  gameType,
+		conn:      conn,
+		responses: make(chan E2EResponse, 256),
+		done:      make(chan struct{}),
+		latest:    make(map[string]E2EResponse),
+	}
+	conn.SetPingHandler(func(data string) error {
+		c.mu.Lock()
+		c.pings++
+		c.mu.Unlock()
+		c.writeMu.Lock()
+		defer c.writeMu.Unlock()
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+	go c.readLoop()
+	h.t.Cleanup(func() { c.conn.Close() })
+
+	c.ExpectMessage("welcome", DefaultTimeout)
+	return c
+}
+
+// readLoop queues the responses of every batch until the connection closes
+func (c *WSClient) readLoop() {
+	defer close(c.done)
+	defer close(c.responses)
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			c.fail(err)
+			return
+		}
+
+		var batch []E2EResponse
+		if err := json.Unmarshal(message, &batch); err != nil {
+			c.fail(fmt.Errorf("message is not a batch of responses: %w: %s", err, message))
+			return
+		}
+		c.mu.Lock()
+		c.batches = append(c.batches, len(batch))
+		for _, response := range batch {
+			c.latest[response.Type] = response
+		}
+		c.mu.Unlock()
+		for _, response := range batch {
+			c.responses <- response
+		}
+	}
+}
+
+func (c *WSClient) fail(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.err == nil {
+		c.err = err
+	}
+}
+
+// Batches returns the number of responses in each websocket message received so far
+func (c *WSClient) Batches() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]int(nil), c.batches...)
+}
+
+// Pings returns how many keep-alive pings the server sent
+func (c *WSClient) Pings() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pings
+}
+
+// Send sends a message of the given type, data is marshalled unless it is already []byte
+func (c *WSClient) Send(msgType string, data interface{}) {
+	c.helper.t.Helper()
+	message, ok := data.([]byte)
+	if !ok {
+		message = CreateGameMessage(msgType, data)
+	}
+
+	c.writeMu.Lock()
+	err := c.conn.WriteMessage(websocket.TextMessage, message)
+	c.writeMu.Unlock()
+	if err != nil {
+		c.helper.t.Fatalf("Failed to send %s: %v", msgType, err)
+	}
+}
+
+// ExpectMessage skips responses until one of the given type arrives. The test fails if none
+// arrives within timeout or the connection closes before.
+func (c *WSClient) ExpectMessage(msgType string, timeout time.Duration) E2EResponse {
+	c.helper.t.Helper()
+	deadline := time.After(timeout)
+	for {
+		select {
+		case response, ok := <-c.responses:
+			if !ok {
+				c.helper.t.Fatalf("Connection closed while waiting for %s: %v", msgType, c.closeErr())
+			}
+			if response.Type == msgType {
+				return response
+			}
+		case <-deadline:
+			c.helper.t.Fatalf("Timed out after %v waiting for %s", timeout, msgType)
+		}
+	}
+}
+
+// ExpectSuccess expects a response of the given type and fails the test if it is an error
+func (c *WSClient) ExpectSuccess(msgType string) E2EResponse {
+	c.helper.t.Helper()
+	response := c.ExpectMessage(msgType, DefaultTimeout)
+	if !response.Success {
+		c.helper.t.Fatalf("Expected %s to succeed, got error %q", msgType, response.Error)
+	}
+	return response
+}
+
+// ExpectState expects the next response of the given type and decodes its data into state
+func (c *WSClient) ExpectState(msgType string, state interface{}) {
+	c.helper.t.Helper()
+	response := c.ExpectMessage(msgType, DefaultTimeout)
+	if err := response.Decode(state); err != nil {
+		c.helper.t.Fatalf("Failed to decode %s: %v", msgType, err)
+	}
+}
+
+// AwaitState decodes the responses of the given type into state until done returns true,
+// skipping states that were broadcast before the awaited change
+func (c *WSClient) AwaitState(msgType string, state interface{}, done func() bool) {
+	c.helper.t.Helper()
+	for {
+		c.ExpectState(msgType, state)
+		if done() {
+			return
+		}
+	}
+}
+
+// LatestState decodes the data of the latest response of the given type received so far into
+// state, whether it was expected or skipped
+func (c *WSClient) LatestState(msgType string, state interface{}) {
+	c.helper.t.Helper()
+	c.mu.Lock()
+	response, ok := c.latest[msgType]
+	c.mu.Unlock()
+	if !ok {
+		c.helper.t.Fatalf("No %s received yet", msgType)
+	}
+	if err := response.Decode(state); err != nil {
+		c.helper.t.Fatalf("Failed to decode %s: %v", msgType, err)
+	}
+}
+
+// Drain drops the responses that arrived so far
+func (c *WSClient) Drain() {
+	for {
+		select {
+		case _, ok := <-c.responses:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (c *WSClient) closeErr() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// Join creates a room of the client's game type with the given options (nil for the
+// defaults) and waits until the client is seated in it
+func (c *WSClient) Join(playerName string, options interface{}) {
+	c.helper.t.Helper()
+	createOptions := interfaces.CreateRoomOptions{GameType: c.gameType, PlayerName: playerName}
+	if options != nil {
+		data, err := json.Marshal(options)
+		if err != nil {
+			c.helper.t.Fatalf("Failed to marshal room options: %v", err)
+		}
+		createOptions.Options = data
+	}
+	c.join(createOptions)
+}
+
+// JoinRoom seats the client in an existing room
+func (c *WSClient) JoinRoom(roomID, playerName string) {
+	c.helper.t.Helper()
+	c.join(interfaces.CreateRoomOptions{GameType: c.gameType, PlayerName: playerName, RoomID: &roomID})
+}
+
+func (c *WSClient) join(options interfaces.CreateRoomOptions) {
+	c.helper.t.Helper()
+	c.Send("join_room", options)
+
+	var joined router.JoinResponse
+	if err := c.ExpectSuccess("join_room_result").Decode(&joined); err != nil {
+		c.helper.t.Fatalf("Failed to decode join_room_result: %v", err)
+	}
+	c.ID, c.RoomID = joined.ClientID, joined.RoomID
+	c.helper.RoomID = joined.RoomID
+}
+
+// Leave leaves the room of the client
+func (c *WSClient) Leave() {
+	c.helper.t.Helper()
+	c.Send("leave_room", nil)
+	c.ExpectSuccess("leave_room_result")
+	c.RoomID = ""
+}
+
+// Disconnect closes the websocket like a browser does and waits until the server hung up,
+// by then the server stored the session of the client's seat
+func (c *WSClient) Disconnect() {
+	c.helper.t.Helper()
+	c.writeMu.Lock()
+	err := c.conn.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	c.writeMu.Unlock()
+	if err != nil && !errors.Is(err, websocket.ErrCloseSent) {
+		c.helper.t.Fatalf("Failed to close: %v", err)
+	}
+
+	select {
+	case <-c.done:
+	case <-time.After(DefaultTimeout):
+		c.helper.t.Fatalf("Timed out waiting for the server to close the connection")
+	}
+	c.conn.Close()
+}
+
+// Reconnect opens a new websocket and takes the seat of the disconnected client back
+func (c *WSClient) Reconnect() *WSClient {
+	c.helper.t.Helper()
+	reconnected := c.helper.Connect(c.gameType)
+	reconnected.Send("reconnect", router.ReconnectPayload{ClientID: c.ID, RoomID: c.RoomID})
+
+	var joined router.JoinResponse
+	if err := reconnected.ExpectSuccess("reconnect_result").Decode(&joined); err != nil {
+		c.helper.t.Fatalf("Failed to decode reconnect_result: %v", err)
+	}
+	if joined.ClientID != c.ID || joined.RoomID != c.RoomID {
+		c.helper.t.Fatalf("Expected to reconnect to seat %s in %s, got %s in %s", c.ID, c.RoomID, joined.ClientID, joined.RoomID)
+	}
+	reconnected.ID, reconnected.RoomID = joined.ClientID, joined.RoomID
+	return reconnected
+}
+
+// SetupE2ERoom connects the given number of players, the first one creates a room of the
+// game type and the others join it
+func (h *E2EHelper) SetupE2ERoom(gameType string, amountPlayers int, options interface{}) []*WSClient {
+	h.t.Helper()
+	players := make([]*WSClient, amountPlayers)
+	for idx := range amountPlayers {
+		players[idx] = h.Connect(gameType)
+		name := fmt.Sprintf("player-%d", idx)
+		if idx == 0 {
+			players[idx].Join(name, options)
+		} else {
+			players[idx].JoinRoom(h.RoomID, name)
+		}
+	}
+	return players
+}
@@ -0,0 +1,198 @@
+package testicles_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"gameserver/games/dicegame"
+	"gameserver/games/owe_drahn"
+	owedrahndb "gameserver/games/owe_drahn/database"
+	"gameserver/games/tictactoe"
+	"gameserver/internal/rng"
+	"gameserver/internal/testicles"
+)
+
+// mover returns the player whose turn it is
+func mover(t *testing.T, players []*testicles.WSClient, currentTurn string) *testicles.WSClient {
+	t.Helper()
+	for _, p := range players {
+		if p.ID == currentTurn {
+			return p
+		}
+	}
+	t.Fatalf("Expected the turn of a player, got %q", currentTurn)
+	return nil
+}
+
+func TestE2ETicTacToeLifecycle(t *testing.T) {
+	helper := testicles.NewE2EHelper(t)
+	tictactoe.RegisterTicTacToeGame(helper.Registry)
+
+	players := helper.SetupE2ERoom("tictactoe", 2, nil)
+
+	var state tictactoe.GameState
+	players[1].LatestState("game_state", &state)
+	first := mover(t, players, state.CurrentTurn)
+	first.Send("make_move", tictactoe.MovePayload{Row: 0, Col: 0})
+	players[0].AwaitState("game_state", &state, func() bool { return state.Board[0][0] != "" })
+
+	// the second player drops mid game and takes the seat back
+	second := mover(t, players, state.CurrentTurn)
+	second.Disconnect()
+	second = second.Reconnect()
+	second.LatestState("game_state", &state)
+	if state.CurrentTurn != second.ID {
+		t.Fatalf("Expected the reconnected player to keep the turn, got %s", state.CurrentTurn)
+	}
+
+	// the first player wins along the top row
+	moves := []tictactoe.MovePayload{{Row: 1, Col: 0}, {Row: 0, Col: 1}, {Row: 1, Col: 1}, {Row: 0, Col: 2}}
+	for i, move := range moves {
+		p := first
+		if i%2 == 0 {
+			p = second
+		}
+		p.Send("make_move", move)
+		first.AwaitState("game_state", &state, func() bool { return state.Board[move.Row][move.Col] != "" })
+	}
+	if !state.GameOver || state.Winner != first.ID {
+		t.Fatalf("Expected %s to win, got %+v", first.ID, state)
+	}
+
+	first.Leave()
+	second.Leave()
+}
+
+func TestE2EDiceGameLifecycle(t *testing.T) {
+	helper := testicles.NewE2EHelper(t)
+	dicegame.RegisterDiceGame(helper.Registry)
+
+	// first draw picks the starting player, the rest are the dice faces
+	helper.UseRNG(rng.NewScripted(0, 0, 0, 0, 4, 4, 1))
+	players := helper.SetupE2ERoom("dicegame", 2, nil)
+	for _, p := range players {
+		p.Send("ready", nil)
+	}
+
+	var state dicegame.GameState
+	host := players[0]
+	host.AwaitState("game_state", &state, func() bool {
+		return state.Players[players[0].ID].Ready && state.Players[players[1].ID].Ready
+	})
+	host.Send("start", nil)
+	players[1].AwaitState("game_state", &state, func() bool { return state.Started })
+	current := mover(t, players, state.CurrentTurn)
+
+	current.Send("roll", nil)
+	current.AwaitState("game_state", &state, func() bool { return len(state.Dice) > 0 && state.Dice[0] != 0 })
+	for _, index := range []int{0, 1, 2} {
+		current.Send("select", dicegame.SelectActionPayload{DiceIndex: index})
+		current.AwaitState("game_state", &state, func() bool { return len(state.SelectedDice) == index+1 })
+	}
+	current.Send("set_aside", dicegame.SetAsideActionPayload{EndTurn: true})
+	current.AwaitState("game_state", &state, func() bool { return state.CurrentTurn != current.ID })
+
+	current.Disconnect()
+	current = current.Reconnect()
+	current.LatestState("game_state", &state)
+	if score := state.Players[current.ID].Score; score != 1000 {
+		t.Errorf("Expected the banked triple ones to keep scoring 1000 after reconnecting, got %d", score)
+	}
+	if state.CurrentTurn == current.ID {
+		t.Errorf("Expected the turn to pass after banking")
+	}
+}
+
+func TestE2EOweDrahnLifecycle(t *testing.T) {
+	helper := testicles.NewE2EHelper(t)
+	helper.RegisterGame(owe_drahn.NewGame(&owedrahndb.DatabaseServiceMock{}))
+
+	players := helper.SetupE2ERoom("owedrahn", 2, nil)
+	for _, p := range players {
+		p.Send("ready", true)
+	}
+
+	var state owe_drahn.GameStateDTO
+	players[0].AwaitState("game_state", &state, func() bool { return state.Started })
+
+	current := mover(t, players, state.CurrentTurn)
+	current.Send("roll", nil)
+	for _, p := range players {
+		var rolled struct {
+			Dice  int `json:"dice"`
+			Total int `json:"total"`
+		}
+		p.ExpectState("rolledDice", &rolled)
+		if rolled.Dice < 1 || rolled.Dice > 6 {
+			t.Errorf("Expected a die face, got %+v", rolled)
+		}
+	}
+
+	current.Disconnect()
+	current = current.Reconnect()
+	current.Leave()
+}
+
+func TestE2EBatchesResponses(t *testing.T) {
+	helper := testicles.NewE2EHelper(t)
+	tictactoe.RegisterTicTacToeGame(helper.Registry)
+
+	c := helper.Connect("tictactoe")
+	for range 20 {
+		c.Send("get_room_list", json.RawMessage(`{"gameType":"tictactoe"}`))
+	}
+	for range 20 {
+		c.ExpectSuccess("room_list_update")
+	}
+
+	responses := 0
+	for _, size := range c.Batches() {
+		if size == 0 {
+			t.Errorf("Expected every websocket message to hold a response")
+		}
+		responses += size
+	}
+	if responses != 21 {
+		t.Errorf("Expected the welcome and 20 room lists, got %d responses in %v", responses, c.Batches())
+	}
+}
+
+func TestE2EKeepAlive(t *testing.T) {
+	helper := testicles.NewE2EHelper(t, testicles.WithPongWait(100*time.Millisecond))
+	tictactoe.RegisterTicTacToeGame(helper.Registry)
+
+	c := helper.Connect("tictactoe")
+	time.Sleep(300 * time.Millisecond)
+	if c.Pings() == 0 {
+		t.Fatalf("Expected the server to ping the client")
+	}
+
+	// answering the pings kept the otherwise silent connection open
+	c.Send("get_room_list", json.RawMessage(`{"gameType":"tictactoe"}`))
+	c.ExpectSuccess("room_list_update")
+}
+
+func TestE2EServesListings(t *testing.T) {
+	helper := testicles.NewE2EHelper(t)
+	tictactoe.RegisterTicTacToeGame(helper.Registry)
+	helper.SetupE2ERoom("tictactoe", 1, nil)
+
+	resp, err := http.Get(helper.Server.URL + "/rooms")
+	if err != nil {
+		t.Fatalf("Failed to list rooms: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var rooms []struct {
+		ID          string `json:"id"`
+		ClientCount int    `json:"clientCount"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&rooms); err != nil {
+		t.Fatalf("Failed to decode rooms: %v", err)
+	}
+	if len(rooms) != 1 || rooms[0].ID != helper.RoomID || rooms[0].ClientCount != 1 {
+		t.Errorf("Expected the room %s with one client, got %+v", helper.RoomID, rooms)
+	}
+}
@@ -0,0 +1,257 @@
+package testicles
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"gameserver/internal/client"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/router"
+
+	"github.com/rs/zerolog"
+)
+
+// FuzzTarget describes the game FuzzRouter drives
+type FuzzTarget struct {
+	GameType string
+	Register func(registry interfaces.GameRegistry)
+	// Players is how many players are seated before the messages are sent, one more client
+	// starts outside of the room
+	Players int
+	// Options are the options the room is created with, nil for the defaults
+	Options interface{}
+	// Messages are the game messages to send besides join_room, leave_room, reconnect,
+	// add_bot, get_room_list and disconnects
+	Messages []FuzzMessage
+	// Check returns an error if the state of the room breaks an invariant of the game
+	Check func(room interfaces.Room) error
+	// IDs returns the ids of the room $id in payloads is replaced with, e.g. of open bets
+	IDs func(room interfaces.Room) []string
+}
+
+// FuzzMessage is a game message with valid payloads to choose from. $seat in a payload is
+// replaced with the id of a seat and $id with one of FuzzTarget.IDs, the fuzzer also sends
+// payloads of its own.
+type FuzzMessage struct {
+	Type     string
+	Payloads []string
+}
+
+// maxFuzzSteps limits how many messages one input sends
+const maxFuzzSteps = 64
+
+// routerSteps are the steps every target takes besides its game messages
+var routerSteps = []string{"join_room", "leave_room", "reconnect", "add_bot", "get_room_list", "disconnect"}
+
+// fuzzStep is one message decoded from a fuzz input
+type fuzzStep struct {
+	client int
+	kind   int
+	pick   int
+	raw    []byte // sent instead of a valid payload if set
+}
+
+// decodeFuzzSteps splits an input into steps of a client byte, a kind byte and a payload
+// byte. Payload bytes with the low two bits set are followed by a length and that many raw bytes.
+func decodeFuzzSteps(data []byte) []fuzzStep {
+	var steps []fuzzStep
+	for len(data) >= 3 && len(steps) < maxFuzzSteps {
+		step := fuzzStep{client: int(data[0]), kind: int(data[1]), pick: int(data[2] >> 2)}
+		raw := data[2]&3 == 3
+		data = data[3:]
+		if raw && len(data) > 0 {
+			n := min(int(data[0])%64, len(data)-1)
+			step.raw = append([]byte{}, data[1:1+n]...)
+			data = data[1+n:]
+		}
+		steps = append(steps, step)
+	}
+	return steps
+}
+
+// FuzzRouter seeds f with a step of every kind and fuzzes sequences of messages sent through
+// Router.HandleMessage to a room of the target game. After every message the room has to
+// satisfy target.Check. Panics fail the input, `go test -fuzz` keeps failing inputs in
+// testdata/fuzz/<FuzzName> where every later `go test` replays them.
+func FuzzRouter(f *testing.F, target FuzzTarget) {
+	level := zerolog.GlobalLevel()
+	zerolog.SetGlobalLevel(zerolog.Disabled)
+	f.Cleanup(func() { zerolog.SetGlobalLevel(level) })
+
+	kinds := len(routerSteps) + len(target.Messages)
+	for kind := range kinds {
+		for c := range target.Players {
+			f.Add([]byte{byte(c), byte(kind), 0})
+		}
+	}
+	// every game message of every player in turn, twice
+	var round []byte
+	for range 2 {
+		for i := range target.Messages {
+			for c := range target.Players {
+				round = append(round, byte(c), byte(len(routerSteps)+i), 0)
+			}
+		}
+	}
+	f.Add(round)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		newFuzzRun(t, target).play(decodeFuzzSteps(data))
+	})
+}
+
+// fuzzRun is the room one fuzz input plays in
+type fuzzRun struct {
+	*TestHelper
+	target  FuzzTarget
+	clients []*client.ClientMock
+	seats   []string
+	next    int
+}
+
+func newFuzzRun(t *testing.T, target FuzzTarget) *fuzzRun {
+	th := NewTestHelper(t)
+	target.Register(th.Registry)
+	run := &fuzzRun{TestHelper: th, target: target}
+
+	for range target.Players + 1 {
+		run.clients = append(run.clients, run.newClient())
+	}
+	th.CreateRoomWithOptions(run.clients[0], target.GameType, run.clients[0].ID(), target.Options)
+	run.seats = append(run.seats, run.clients[0].ID())
+	for _, c := range run.clients[1:target.Players] {
+		th.JoinRoom(c, th.RoomID, c.ID())
+		run.seats = append(run.seats, c.ID())
+	}
+	run.check("setup")
+	return run
+}
+
+func (run *fuzzRun) newClient() *client.ClientMock {
+	c := run.CreateClient(fmt.Sprintf("player-%d", run.next))
+	run.next++
+	return c
+}
+
+func (run *fuzzRun) play(steps []fuzzStep) {
+	for i, step := range steps {
+		idx := step.client % len(run.clients)
+		c := run.clients[idx]
+
+		name := run.stepName(step)
+		if name == "disconnect" {
+			run.disconnect(idx)
+		} else {
+			run.Router.HandleMessage(c, run.message(name, step))
+		}
+		run.collectSeats()
+		run.check(fmt.Sprintf("step %d (%s by %s)", i, name, c.ID()))
+	}
+}
+
+func (run *fuzzRun) stepName(step fuzzStep) string {
+	kind := step.kind % (len(routerSteps) + len(run.target.Messages))
+	if kind < len(routerSteps) {
+		return routerSteps[kind]
+	}
+	return run.target.Messages[kind-len(routerSteps)].Type
+}
+
+// message encodes the step, raw bytes that are no JSON are sent as the whole message
+func (run *fuzzRun) message(name string, step fuzzStep) []byte {
+	if step.raw != nil {
+		if !json.Valid(step.raw) {
+			return step.raw
+		}
+		return CreateGameMessage(name, json.RawMessage(step.raw))
+	}
+
+	seat := run.seats[step.pick%len(run.seats)]
+	switch name {
+	case "join_room":
+		roomID := run.RoomID
+		return CreateGameMessage(name, interfaces.CreateRoomOptions{GameType: run.target.GameType, PlayerName: "joiner", RoomID: &roomID})
+	case "reconnect":
+		return CreateGameMessage(name, router.ReconnectPayload{ClientID: seat, RoomID: run.RoomID})
+	case "add_bot":
+		return CreateGameMessage(name, interfaces.BotOptions{})
+	case "get_room_list":
+		return CreateGameMessage(name, interfaces.M{"gameType": run.target.GameType})
+	case "leave_room":
+		return CreateGameMessage(name, nil)
+	}
+
+	for _, m := range run.target.Messages {
+		if m.Type != name || len(m.Payloads) == 0 {
+			continue
+		}
+		payload := strings.ReplaceAll(m.Payloads[step.pick%len(m.Payloads)], "$seat", seat)
+		if strings.Contains(payload, "$id") {
+			payload = strings.ReplaceAll(payload, "$id", run.id(step.pick))
+		}
+		return CreateGameMessage(name, json.RawMessage(payload))
+	}
+	return CreateGameMessage(name, nil)
+}
+
+// id picks one of the ids of the room, "" if it has none
+func (run *fuzzRun) id(pick int) string {
+	room, err := run.RoomManager.GetRoom(run.RoomID)
+	if err != nil || run.target.IDs == nil {
+		return ""
+	}
+	ids := run.target.IDs(room)
+	if len(ids) == 0 {
+		return ""
+	}
+	return ids[pick%len(ids)]
+}
+
+// disconnect drops the connection of a client like a closed websocket, a new client takes its place
+func (run *fuzzRun) disconnect(idx int) {
+	c := run.clients[idx]
+	if room := c.Room(); room != nil {
+		c.Close()
+		room.Disconnect(c)
+	}
+	run.clients[idx] = run.newClient()
+}
+
+// collectSeats remembers the seats clients got, so reconnects can ask for them
+func (run *fuzzRun) collectSeats() {
+	for _, c := range run.clients {
+		for _, message := range c.GetSentMessages() {
+			if message.Type != "join_room_result" && message.Type != "reconnect_result" || !message.Success {
+				continue
+			}
+			if joined, ok := message.Data.(*router.JoinResponse); ok && !slices.Contains(run.seats, joined.ClientID) {
+				run.seats = append(run.seats, joined.ClientID)
+			}
+		}
+		c.ClearMessages()
+	}
+}
+
+func (run *fuzzRun) check(after string) {
+	room, err := run.RoomManager.GetRoom(run.RoomID)
+	if err != nil || run.target.Check == nil {
+		return
+	}
+	if err := run.target.Check(room); err != nil {
+		run.t.Fatalf("after %s: %v", after, err)
+	}
+}
+
+// CheckCurrentTurn returns an error unless currentTurn is empty or one of the players
+func CheckCurrentTurn[P any](currentTurn string, players map[string]P) error {
+	if currentTurn == "" {
+		return nil
+	}
+	if _, ok := players[currentTurn]; !ok {
+		return fmt.Errorf("current turn %q is not a player", currentTurn)
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package testicles
+
+import (
+	"context"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/journal"
+	"gameserver/internal/replay"
+	"testing"
+)
+
+// AssertReplay replays a journal against a fresh game instance and fails the test on divergence
+func AssertReplay(t *testing.T, g interfaces.Game, j *journal.Journal) {
+	t.Helper()
+	if err := replay.Run(context.Background(), g, j); err != nil {
+		t.Errorf("replay failed: %v", err)
+	}
+}
+
+// AssertReplayFile loads a recorded journal (e.g. from a production bug report in testdata)
+// and replays it against a fresh game instance
+func AssertReplayFile(t *testing.T, g interfaces.Game, path string) {
+	t.Helper()
+	j, err := journal.Load(path)
+	if err != nil {
+		t.Fatalf("failed to load journal %s: %v", path, err)
+	}
+	AssertReplay(t, g, j)
+}
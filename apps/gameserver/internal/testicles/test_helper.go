@@ -2,14 +2,22 @@ package testicles
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"gameserver/internal/client"
+	"gameserver/internal/clock"
 	"gameserver/internal/game"
 	"gameserver/internal/interfaces"
+	"gameserver/internal/journal"
+	"gameserver/internal/results"
+	"gameserver/internal/rng"
 	"gameserver/internal/room"
 	"gameserver/internal/router"
+	"gameserver/internal/session"
 	"math"
+	"sync"
 	"testing"
+	"time"
 )
 
 // TestHelper provides a test setup for game integration tests
@@ -19,30 +27,82 @@ type TestHelper struct {
 	ClientManager interfaces.ClientManager
 	RoomManager   interfaces.RoomManager
 	Router        *router.Router
+	Journals      *journal.MemoryStore
+	Results       *results.Bus
+	// Clock is the fake clock of the rooms, advance it to run delayed work like bot takeovers or closing empty rooms
+	Clock         *clock.Fake
 	Clients       map[string]*client.ClientMock
 	RoomID        string
 	t             *testing.T
+	registry      *game.Registry
+	clientManager *client.Manager
+	roomManager   *room.RoomManager
+	nextRNG       rng.RNG
+	gameResults   []interfaces.GameResult
+	resultsMu     sync.Mutex
 }
 
-// NewTestHelper creates a new test helper for game integration tests
-func NewTestHelper(t *testing.T) *TestHelper {
+// DefaultSeed is the seed of every room RNG created by a TestHelper unless UseRNG is called
+var DefaultSeed = rng.SeedFrom(1)
+
+// SessionExpiry is how many seconds on the Clock of a TestHelper disconnected players can reconnect for
+const SessionExpiry int64 = 60
+
+// NewTestHelper creates a new test helper for game integration tests, opts are added to the registry's
+func NewTestHelper(t *testing.T, opts ...game.RegistryOption) *TestHelper {
 	testCtx := context.Background()
 
 	// Set up the complete system with real components
-	registry := game.NewRegistry()
+	journals := journal.NewMemoryStore()
+	bus := results.NewBus()
+	registry := game.NewRegistry(append([]game.RegistryOption{game.WithRecorder(journals), game.WithResults(bus)}, opts...)...)
 	clientManager := client.NewManager()
-	roomManager := room.NewRoomManager(registry)
-	testRouter := router.NewRouter(testCtx, clientManager, roomManager, registry)
 
-	return &TestHelper{
+	th := &TestHelper{
 		Ctx:           testCtx,
 		Registry:      registry,
 		ClientManager: clientManager,
-		RoomManager:   roomManager,
-		Router:        testRouter,
+		Journals:      journals,
+		Results:       bus,
+		Clock:         clock.NewFake(time.Now()),
 		Clients:       make(map[string]*client.ClientMock),
 		t:             t,
+		registry:      registry,
+		clientManager: clientManager,
+	}
+	bus.Subscribe("test-helper", results.SubscriberFunc(func(_ context.Context, result interfaces.GameResult) error {
+		th.resultsMu.Lock()
+		defer th.resultsMu.Unlock()
+		th.gameResults = append(th.gameResults, result)
+		return nil
+	}))
+
+	// the router keeps sessions in the global store, they expire on the clock of this helper
+	session.InitGlobalStore(SessionExpiry, session.WithClock(th.Clock))
+
+	roomManager := room.NewRoomManager(registry, room.WithRNGFactory(th.newRNG), room.WithClock(th.Clock))
+	// closing the rooms stops their bots, which would keep playing after the test
+	t.Cleanup(roomManager.Stop)
+	th.RoomManager = roomManager
+	th.roomManager = roomManager
+	th.Router = router.NewRouter(testCtx, clientManager, roomManager, registry)
+
+	return th
+}
+
+// UseRNG makes the next created room draw from the given RNG, e.g. rng.Dice(1, 5, 5) to force rolls
+func (th *TestHelper) UseRNG(random rng.RNG) {
+	th.nextRNG = random
+}
+
+// newRNG hands out the RNG set by UseRNG once, otherwise a deterministic RNG seeded with DefaultSeed
+func (th *TestHelper) newRNG() rng.RNG {
+	if th.nextRNG != nil {
+		random := th.nextRNG
+		th.nextRNG = nil
+		return random
 	}
+	return rng.New(DefaultSeed)
 }
 
 // RegisterGame registers a game with the registry
@@ -59,8 +119,31 @@ func (th *TestHelper) CreateClient(id string) *client.ClientMock {
 
 // CreateRoom creates a new room for the given game type and returns the room ID
 func (th *TestHelper) CreateRoom(client *client.ClientMock, gameType, playerName string) string {
-	joinRoomMsg := fmt.Sprintf(`{"type":"join_room","data":{"gameType":"%s","playerName":"%s"}}`, gameType, playerName)
-	th.Router.HandleMessage(client, []byte(joinRoomMsg))
+	return th.CreateRoomWithOptions(client, gameType, playerName, nil)
+}
+
+// CreateRoomWithOptions creates a new room passing the given game options and returns the room ID
+func (th *TestHelper) CreateRoomWithOptions(client *client.ClientMock, gameType, playerName string, options interface{}) string {
+	createOptions := interfaces.CreateRoomOptions{
+		GameType:   gameType,
+		PlayerName: playerName,
+	}
+	if options != nil {
+		optionsData, err := json.Marshal(options)
+		if err != nil {
+			th.t.Fatalf("Failed to marshal room options: %v", err)
+		}
+		createOptions.Options = optionsData
+	}
+
+	joinRoomMsg, err := json.Marshal(interfaces.M{
+		"type": "join_room",
+		"data": createOptions,
+	})
+	if err != nil {
+		th.t.Fatalf("Failed to marshal join_room message: %v", err)
+	}
+	th.Router.HandleMessage(client, joinRoomMsg)
 
 	messages := client.GetSentMessages()
 	if len(messages) == 0 {
@@ -92,6 +175,22 @@ func (th *TestHelper) GetRoom() (interfaces.Room, error) {
 	return th.RoomManager.GetRoom(th.RoomID)
 }
 
+// Journal returns the recorded journal of the current room
+func (th *TestHelper) Journal() *journal.Journal {
+	j, err := th.Journals.Get(th.RoomID)
+	if err != nil {
+		th.t.Fatalf("Failed to get journal for room %s: %v", th.RoomID, err)
+	}
+	return j
+}
+
+// GameResults returns the results the games reported so far
+func (th *TestHelper) GameResults() []interfaces.GameResult {
+	th.resultsMu.Lock()
+	defer th.resultsMu.Unlock()
+	return append([]interfaces.GameResult(nil), th.gameResults...)
+}
+
 // ClearAllMessages clears messages for all registered clients
 func (th *TestHelper) ClearAllMessages() {
 	for _, c := range th.Clients {
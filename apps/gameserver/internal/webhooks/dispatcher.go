@@ -0,0 +1,250 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderID        = "X-Webhook-Id"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	// HeaderSignature is sha256=<hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the endpoint secret>
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Dispatcher posts events to the webhook endpoints. Every endpoint has its own queue and
+// worker, so a slow or failing receiver only delays its own events.
+type Dispatcher struct {
+	workers     []*worker
+	client      *http.Client
+	maxAttempts int
+	backoff     time.Duration
+	maxBackoff  time.Duration
+	queueSize   int
+	now         func() time.Time
+	done        chan struct{}
+	wg          sync.WaitGroup
+	closeOnce   sync.Once
+	mu          sync.RWMutex
+	closed      bool
+}
+
+type worker struct {
+	endpoint Endpoint
+	queue    chan Event
+}
+
+// DispatcherOption is a functional option for configuring the Dispatcher
+type DispatcherOption func(*Dispatcher)
+
+// WithHTTPClient sets the client used to post events
+func WithHTTPClient(client *http.Client) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.client = client
+	}
+}
+
+// WithRetries sets how often a delivery is attempted and the backoff before the first retry,
+// which doubles with every further retry up to maxBackoff
+func WithRetries(maxAttempts int, backoff time.Duration, maxBackoff time.Duration) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.maxAttempts = maxAttempts
+		d.backoff = backoff
+		d.maxBackoff = maxBackoff
+	}
+}
+
+// WithQueueSize sets how many events may wait per endpoint before new ones are dropped
+func WithQueueSize(size int) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.queueSize = size
+	}
+}
+
+// NewDispatcher creates a dispatcher and starts a worker per endpoint
+func NewDispatcher(endpoints []Endpoint, opts ...DispatcherOption) *Dispatcher {
+	d := &Dispatcher{
+		client:      &http.Client{Timeout: 10 * time.Second},
+		maxAttempts: 5,
+		backoff:     time.Second,
+		maxBackoff:  time.Minute,
+		queueSize:   256,
+		now:         time.Now,
+		done:        make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	for _, endpoint := range endpoints {
+		w := &worker{endpoint: endpoint, queue: make(chan Event, d.queueSize)}
+		d.workers = append(d.workers, w)
+		d.wg.Add(1)
+		go d.run(w)
+	}
+
+	log.Info().Int("endpoints", len(endpoints)).Msg("webhook dispatcher started")
+	return d
+}
+
+// Emit queues an event for every endpoint that wants it. It never blocks, events for
+// endpoints with a full queue are dropped.
+func (d *Dispatcher) Emit(eventType EventType, roomID string, gameType string, data interface{}) {
+	event := Event{
+		ID:        uuid.New().String(),
+		Type:      eventType,
+		CreatedAt: d.now().UTC(),
+		RoomID:    roomID,
+		GameType:  gameType,
+		Data:      data,
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return
+	}
+
+	for _, w := range d.workers {
+		if !w.endpoint.wants(eventType) {
+			continue
+		}
+		select {
+		case w.queue <- event:
+		default:
+			log.Warn().Str("url", w.endpoint.URL).Str("event", string(eventType)).Msg("webhook queue full, dropping event")
+		}
+	}
+}
+
+// Close stops accepting events and waits until the queued ones are delivered.
+// Once ctx is done, pending retries are given up.
+func (d *Dispatcher) Close(ctx context.Context) {
+	d.closeOnce.Do(func() {
+		d.mu.Lock()
+		d.closed = true
+		for _, w := range d.workers {
+			close(w.queue)
+		}
+		d.mu.Unlock()
+
+		drained := make(chan struct{})
+		go func() {
+			d.wg.Wait()
+			close(drained)
+		}()
+
+		select {
+		case <-drained:
+		case <-ctx.Done():
+			close(d.done)
+			<-drained
+		}
+		log.Info().Msg("webhook dispatcher stopped")
+	})
+}
+
+func (d *Dispatcher) run(w *worker) {
+	defer d.wg.Done()
+	for event := range w.queue {
+		if err := d.deliver(w.endpoint, event); err != nil {
+			log.Error().Err(err).Str("url", w.endpoint.URL).Str("event", string(event.Type)).Str("eventId", event.ID).Msg("failed to deliver webhook")
+		}
+	}
+}
+
+// deliver posts the event until the endpoint accepts it, retrying server errors and
+// rate limits with exponential backoff. Other client errors are not retried.
+func (d *Dispatcher) deliver(endpoint Endpoint, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	backoff := d.backoff
+	for attempt := 1; ; attempt++ {
+		retry, err := d.post(endpoint, event, body)
+		if err == nil {
+			log.Debug().Str("url", endpoint.URL).Str("event", string(event.Type)).Int("attempt", attempt).Msg("webhook delivered")
+			return nil
+		}
+		if !retry || attempt >= d.maxAttempts {
+			return fmt.Errorf("attempt %d: %w", attempt, err)
+		}
+
+		log.Warn().Err(err).Str("url", endpoint.URL).Int("attempt", attempt).Dur("backoff", backoff).Msg("webhook delivery failed, retrying")
+		select {
+		case <-time.After(backoff):
+		case <-d.done:
+			return fmt.Errorf("attempt %d: %w", attempt, ErrDispatcherClosed)
+		}
+		backoff = min(backoff*2, d.maxBackoff)
+	}
+}
+
+// post sends one delivery attempt and reports whether a failure is worth retrying
+func (d *Dispatcher) post(endpoint Endpoint, event Event, body []byte) (bool, error) {
+	timestamp := strconv.FormatInt(d.now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gameserver-webhooks")
+	req.Header.Set(HeaderEvent, string(event.Type))
+	req.Header.Set(HeaderID, event.ID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("%w: %s", ErrDeliveryFailed, resp.Status)
+	default:
+		return false, fmt.Errorf("%w: %s", ErrDeliveryFailed, resp.Status)
+	}
+}
+
+// Sign returns the signature header value of a body sent at timestamp
+func Sign(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a signature header, receivers should also reject old timestamps to prevent replays
+func Verify(secret string, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+var (
+	ErrDeliveryFailed   = errors.New("webhook endpoint rejected the event")
+	ErrDispatcherClosed = errors.New("webhook dispatcher closed")
+	ErrInvalidURL       = errors.New("webhook URL must be http or https")
+	ErrSecretRequired   = errors.New("WEBHOOK_SECRET environment variable not set")
+	ErrUnknownEvent     = errors.New("unknown webhook event")
+)
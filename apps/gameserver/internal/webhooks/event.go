@@ -0,0 +1,111 @@
+package webhooks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EventType names a room or game lifecycle event
+type EventType string
+
+const (
+	EventRoomCreated  EventType = "room.created"
+	EventRoomClosed   EventType = "room.closed"
+	EventPlayerJoined EventType = "player.joined"
+	EventPlayerLeft   EventType = "player.left"
+	EventGameStarted  EventType = "game.started"
+	// EventGameFinished carries the interfaces.GameResult of the game as data
+	EventGameFinished EventType = "game.finished"
+)
+
+// EventTypes lists every event a webhook can subscribe to
+var EventTypes = []EventType{
+	EventRoomCreated,
+	EventRoomClosed,
+	EventPlayerJoined,
+	EventPlayerLeft,
+	EventGameStarted,
+	EventGameFinished,
+}
+
+// Event is the JSON body posted to webhook endpoints
+type Event struct {
+	ID        string      `json:"id"`
+	Type      EventType   `json:"type"`
+	CreatedAt time.Time   `json:"createdAt"`
+	RoomID    string      `json:"roomId"`
+	GameType  string      `json:"gameType"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// PlayerData is the data of player.joined and player.left events
+type PlayerData struct {
+	ClientID string `json:"clientId"`
+	Name     string `json:"name,omitempty"`
+	Bot      bool   `json:"bot"`
+}
+
+// Endpoint is a receiver of webhook events
+type Endpoint struct {
+	URL    string
+	Secret string
+	// Events the endpoint receives, all if empty
+	Events []EventType
+}
+
+func (e Endpoint) wants(eventType EventType) bool {
+	if len(e.Events) == 0 {
+		return true
+	}
+	for _, t := range e.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseEndpoints builds the endpoints from comma separated lists of URLs and event types.
+// All endpoints share the secret, no event types means every event.
+func ParseEndpoints(urls string, secret string, events string) ([]Endpoint, error) {
+	var eventTypes []EventType
+	for _, name := range splitList(events) {
+		eventType, err := parseEventType(name)
+		if err != nil {
+			return nil, err
+		}
+		eventTypes = append(eventTypes, eventType)
+	}
+
+	var endpoints []Endpoint
+	for _, url := range splitList(urls) {
+		if !strings.HasPrefix(url, "https://") && !strings.HasPrefix(url, "http://") {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidURL, url)
+		}
+		endpoints = append(endpoints, Endpoint{URL: url, Secret: secret, Events: eventTypes})
+	}
+	if len(endpoints) > 0 && secret == "" {
+		return nil, ErrSecretRequired
+	}
+	return endpoints, nil
+}
+
+func parseEventType(name string) (EventType, error) {
+	for _, t := range EventTypes {
+		if string(t) == name {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownEvent, name)
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,77 @@
+package webhooks
+
+import (
+	"context"
+	"gameserver/internal/interfaces"
+	"sync"
+)
+
+// Notifier turns what happens in rooms into webhook events
+type Notifier struct {
+	dispatcher *Dispatcher
+	// rooms are the ids of the open rooms per game type, as of the last room list change
+	rooms map[string]map[string]struct{}
+	mu    sync.Mutex
+}
+
+// NewNotifier creates a notifier emitting to the dispatcher
+func NewNotifier(dispatcher *Dispatcher) *Notifier {
+	return &Notifier{
+		dispatcher: dispatcher,
+		rooms:      make(map[string]map[string]struct{}),
+	}
+}
+
+// RoomListChanged compares the open rooms of a game type to the last change and emits
+// room.created and room.closed for the difference. Call it from the room manager's
+// room list change callback. Only room ids are read, the rooms may be locked by the caller.
+func (n *Notifier) RoomListChanged(gameType string, rooms []interfaces.Room) {
+	current := make(map[string]struct{}, len(rooms))
+	for _, room := range rooms {
+		current[room.ID()] = struct{}{}
+	}
+
+	n.mu.Lock()
+	previous := n.rooms[gameType]
+	n.rooms[gameType] = current
+	n.mu.Unlock()
+
+	for id := range current {
+		if _, known := previous[id]; !known {
+			n.dispatcher.Emit(EventRoomCreated, id, gameType, nil)
+		}
+	}
+	for id := range previous {
+		if _, open := current[id]; !open {
+			n.dispatcher.Emit(EventRoomClosed, id, gameType, nil)
+		}
+	}
+}
+
+// PlayerJoined emits player.joined, see game.WithPlayerCallbacks. The name is empty for reconnects.
+func (n *Notifier) PlayerJoined(room interfaces.Room, client interfaces.Client, name string) {
+	n.dispatcher.Emit(EventPlayerJoined, room.ID(), room.GameType(), PlayerData{
+		ClientID: client.ID(),
+		Name:     name,
+		Bot:      client.IsBot(),
+	})
+}
+
+// PlayerLeft emits player.left, see game.WithPlayerCallbacks
+func (n *Notifier) PlayerLeft(room interfaces.Room, client interfaces.Client) {
+	n.dispatcher.Emit(EventPlayerLeft, room.ID(), room.GameType(), PlayerData{
+		ClientID: client.ID(),
+		Bot:      client.IsBot(),
+	})
+}
+
+// GameStarted emits game.started, see game.WithGameStartedCallback
+func (n *Notifier) GameStarted(room interfaces.Room) {
+	n.dispatcher.Emit(EventGameStarted, room.ID(), room.GameType(), nil)
+}
+
+// HandleResult emits game.finished with the result, making the notifier a subscriber of the results bus
+func (n *Notifier) HandleResult(ctx context.Context, result interfaces.GameResult) error {
+	n.dispatcher.Emit(EventGameFinished, result.RoomID, result.GameType, result)
+	return nil
+}
@@ -0,0 +1,236 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gameserver/internal/interfaces"
+	"gameserver/internal/room"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// receiver is a webhook endpoint that records the events it accepted
+type receiver struct {
+	server *httptest.Server
+	secret string
+	// statuses are answered one per request before accepting
+	statuses []int
+	requests int
+	events   []Event
+	mu       sync.Mutex
+}
+
+func newReceiver(t *testing.T, secret string, statuses ...int) *receiver {
+	t.Helper()
+	r := &receiver{secret: secret, statuses: statuses}
+	r.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests++
+
+		if !Verify(r.secret, req.Header.Get(HeaderTimestamp), body, req.Header.Get(HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if len(r.statuses) > 0 {
+			status := r.statuses[0]
+			r.statuses = r.statuses[1:]
+			w.WriteHeader(status)
+			return
+		}
+
+		var event Event
+		if err := json.Unmarshal(body, &event); err != nil || req.Header.Get(HeaderEvent) != string(event.Type) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.events = append(r.events, event)
+	}))
+	t.Cleanup(r.server.Close)
+	return r
+}
+
+func (r *receiver) endpoint(events ...EventType) Endpoint {
+	return Endpoint{URL: r.server.URL, Secret: r.secret, Events: events}
+}
+
+func (r *receiver) received() ([]Event, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Event(nil), r.events...), r.requests
+}
+
+func newTestDispatcher(endpoints ...Endpoint) *Dispatcher {
+	return NewDispatcher(endpoints, WithRetries(4, time.Millisecond, 4*time.Millisecond))
+}
+
+func TestDispatcherSignsAndDelivers(t *testing.T) {
+	r := newReceiver(t, "secret")
+	forged := newReceiver(t, "other-secret")
+	d := newTestDispatcher(r.endpoint(), Endpoint{URL: forged.server.URL, Secret: "secret"})
+
+	d.Emit(EventRoomCreated, "room-1", "dicegame", nil)
+	d.Emit(EventPlayerJoined, "room-1", "dicegame", PlayerData{ClientID: "c1", Name: "Alice"})
+	d.Close(context.Background())
+
+	events, _ := r.received()
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+	if events[0].Type != EventRoomCreated || events[0].RoomID != "room-1" || events[0].GameType != "dicegame" || events[0].ID == "" {
+		t.Errorf("Unexpected event: %+v", events[0])
+	}
+	if data, _ := events[1].Data.(map[string]interface{}); data["name"] != "Alice" {
+		t.Errorf("Expected player data, got %v", events[1].Data)
+	}
+
+	// a receiver with another secret rejects the signature, which is not retried
+	if events, requests := forged.received(); len(events) != 0 || requests != 2 {
+		t.Errorf("Expected 2 rejected requests, got %d events in %d requests", len(events), requests)
+	}
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	t.Run("server errors are retried", func(t *testing.T) {
+		r := newReceiver(t, "secret", http.StatusServiceUnavailable, http.StatusTooManyRequests)
+		d := newTestDispatcher(r.endpoint())
+
+		d.Emit(EventRoomClosed, "room-1", "tictactoe", nil)
+		d.Close(context.Background())
+
+		if events, requests := r.received(); len(events) != 1 || requests != 3 {
+			t.Errorf("Expected delivery on the third attempt, got %d events in %d requests", len(events), requests)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		r := newReceiver(t, "secret", 500, 500, 500, 500, 500)
+		d := newTestDispatcher(r.endpoint())
+
+		d.Emit(EventRoomClosed, "room-1", "tictactoe", nil)
+		d.Close(context.Background())
+
+		if events, requests := r.received(); len(events) != 0 || requests != 4 {
+			t.Errorf("Expected 4 failed attempts, got %d events in %d requests", len(events), requests)
+		}
+	})
+
+	t.Run("closing gives up retries", func(t *testing.T) {
+		r := newReceiver(t, "secret", 500, 500, 500, 500, 500)
+		d := NewDispatcher([]Endpoint{r.endpoint()}, WithRetries(4, time.Hour, time.Hour))
+
+		d.Emit(EventRoomClosed, "room-1", "tictactoe", nil)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		d.Close(ctx)
+
+		if _, requests := r.received(); requests != 1 {
+			t.Errorf("Expected a single attempt before closing, got %d", requests)
+		}
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		r := newReceiver(t, "secret", http.StatusGone)
+		d := newTestDispatcher(r.endpoint())
+
+		d.Emit(EventRoomClosed, "room-1", "tictactoe", nil)
+		d.Close(context.Background())
+
+		if _, requests := r.received(); requests != 1 {
+			t.Errorf("Expected a single attempt, got %d", requests)
+		}
+	})
+}
+
+func TestDispatcherFiltersEvents(t *testing.T) {
+	r := newReceiver(t, "secret")
+	d := newTestDispatcher(r.endpoint(EventGameFinished))
+
+	d.Emit(EventGameStarted, "room-1", "owedrahn", nil)
+	d.Emit(EventGameFinished, "room-1", "owedrahn", nil)
+	d.Close(context.Background())
+	d.Emit(EventGameFinished, "room-2", "owedrahn", nil)
+
+	events, _ := r.received()
+	if len(events) != 1 || events[0].Type != EventGameFinished || events[0].RoomID != "room-1" {
+		t.Errorf("Expected only the finished event before closing, got %+v", events)
+	}
+}
+
+func TestNotifier(t *testing.T) {
+	r := newReceiver(t, "secret")
+	d := newTestDispatcher(r.endpoint())
+	n := NewNotifier(d)
+
+	id1, id2 := "room-1", "room-2"
+	room1 := room.NewRoom(nil, "dicegame", &id1)
+	room2 := room.NewRoom(nil, "dicegame", &id2)
+
+	n.RoomListChanged("dicegame", []interfaces.Room{room1})
+	n.RoomListChanged("dicegame", []interfaces.Room{room1}) // no change, e.g. a player joined
+	n.GameStarted(room1)
+	n.RoomListChanged("dicegame", []interfaces.Room{room2})
+	if err := n.HandleResult(context.Background(), interfaces.GameResult{
+		RoomID:       "room-2",
+		GameType:     "dicegame",
+		Participants: []interfaces.Participant{{ClientID: "c1", Placement: 1, Outcome: interfaces.OutcomeWin}},
+	}); err != nil {
+		t.Fatalf("HandleResult failed: %v", err)
+	}
+	d.Close(context.Background())
+
+	events, _ := r.received()
+	expected := []struct {
+		eventType EventType
+		roomID    string
+	}{
+		{EventRoomCreated, "room-1"},
+		{EventGameStarted, "room-1"},
+		{EventRoomCreated, "room-2"},
+		{EventRoomClosed, "room-1"},
+		{EventGameFinished, "room-2"},
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %+v", len(expected), events)
+	}
+	for i, e := range expected {
+		if events[i].Type != e.eventType || events[i].RoomID != e.roomID {
+			t.Errorf("Event %d: expected %s of %s, got %s of %s", i, e.eventType, e.roomID, events[i].Type, events[i].RoomID)
+		}
+	}
+
+	data, _ := events[4].Data.(map[string]interface{})
+	if participants, _ := data["participants"].([]interface{}); len(participants) != 1 {
+		t.Errorf("Expected the game result as data, got %v", events[4].Data)
+	}
+}
+
+func TestParseEndpoints(t *testing.T) {
+	endpoints, err := ParseEndpoints(" https://a.test/hook, http://b.test ", "secret", "game.finished,room.created")
+	if err != nil {
+		t.Fatalf("ParseEndpoints failed: %v", err)
+	}
+	if len(endpoints) != 2 || endpoints[0].URL != "https://a.test/hook" || len(endpoints[1].Events) != 2 {
+		t.Errorf("Unexpected endpoints: %+v", endpoints)
+	}
+
+	if endpoints, err := ParseEndpoints("", "", ""); err != nil || len(endpoints) != 0 {
+		t.Errorf("Expected no endpoints, got %v, %v", endpoints, err)
+	}
+	if _, err := ParseEndpoints("https://a.test", "", ""); !errors.Is(err, ErrSecretRequired) {
+		t.Errorf("Expected ErrSecretRequired, got %v", err)
+	}
+	if _, err := ParseEndpoints("ftp://a.test", "secret", ""); !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("Expected ErrInvalidURL, got %v", err)
+	}
+	if _, err := ParseEndpoints("https://a.test", "secret", "game.paused"); !errors.Is(err, ErrUnknownEvent) {
+		t.Errorf("Expected ErrUnknownEvent, got %v", err)
+	}
+}